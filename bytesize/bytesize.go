@@ -0,0 +1,152 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package bytesize parses and formats human-readable byte sizes, such as
+// "128MiB" or "1.5GB".
+//
+// The Size type implements encoding.TextUnmarshaler, so size limits can be
+// given as strings in configuration files.
+package bytesize
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Size is a number of bytes.
+type Size int64
+
+// Decimal and binary units.
+const (
+	B  Size = 1
+	KB Size = 1000 * B
+	MB Size = 1000 * KB
+	GB Size = 1000 * MB
+	TB Size = 1000 * GB
+
+	KiB Size = 1 << 10
+	MiB Size = 1 << 20
+	GiB Size = 1 << 30
+	TiB Size = 1 << 40
+)
+
+// units maps unit suffixes to their sizes. Suffixes are matched
+// case-insensitively.
+var units = map[string]Size{
+	"":    B,
+	"b":   B,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+}
+
+// formatUnits lists the units used by Format, from the largest.
+var formatUnits = []struct {
+	size Size
+	name string
+}{
+	{TiB, "TiB"},
+	{GiB, "GiB"},
+	{MiB, "MiB"},
+	{KiB, "KiB"},
+}
+
+// Parse parses a human-readable byte size, such as "128MiB" or "1.5GB".
+//
+// Supported units are B, kB, MB, GB, TB (powers of 1000) and KiB, MiB, GiB,
+// TiB (powers of 1024). Units are case-insensitive, and a number without a
+// unit is interpreted as bytes. The result must be a whole, non-negative
+// number of bytes.
+func Parse(s string) (Size, error) {
+	str := strings.TrimSpace(s)
+	idx := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if idx == -1 {
+		idx = len(str)
+	}
+	num, unit := str[:idx], strings.ToLower(strings.TrimSpace(str[idx:]))
+	mul, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", str, str[idx:])
+	}
+	r, ok := new(big.Rat).SetString(num)
+	if !ok || num == "" {
+		return 0, fmt.Errorf("invalid byte size %q", str)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(int64(mul)))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("invalid byte size %q: must be a whole number of bytes", str)
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("invalid byte size %q: too large", str)
+	}
+	return Size(r.Num().Int64()), nil
+}
+
+// MustParse works like Parse, but panics on error.
+func MustParse(s string) Size {
+	n, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// Format formats a number of bytes using the largest binary unit that is
+// not greater than the size, with up to two decimal places, e.g. "128MiB"
+// or "1.5GiB". Sizes under 1KiB are formatted in bytes, e.g. "512B".
+//
+// The result is rounded, so it can be parsed back to a slightly different
+// size.
+func Format(n int64) string {
+	for _, u := range formatUnits {
+		if abs(n) >= int64(u.size) {
+			v := float64(n) / float64(u.size)
+			return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64) + u.name
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+// String implements the fmt.Stringer interface, see Format.
+func (s Size) String() string {
+	return Format(int64(s))
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, see
+// Parse.
+func (s *Size) UnmarshalText(text []byte) error {
+	n, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*s = n
+	return nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
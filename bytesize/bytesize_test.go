@@ -0,0 +1,83 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package bytesize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Size
+		wantErr string
+	}{
+		{input: "128MiB", want: 128 * MiB},
+		{input: "1.5GB", want: 1500 * MB},
+		{input: "1.5 gib", want: 1536 * MiB},
+		{input: "  42 ", want: 42},
+		{input: "10kB", want: 10 * KB},
+		{input: "2TiB", want: 2 * TiB},
+		{input: "1.5B", wantErr: `invalid byte size "1.5B": must be a whole number of bytes`},
+		{input: "1PB", wantErr: `invalid byte size "1PB": unknown unit "PB"`},
+		{input: "MiB", wantErr: `invalid byte size "MiB"`},
+		{input: "-1MiB", wantErr: `invalid byte size "-1MiB": unknown unit "-1MiB"`},
+		{input: "99999999TiB", wantErr: `invalid byte size "99999999TiB": too large`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input int64
+		want  string
+	}{
+		{input: 0, want: "0B"},
+		{input: 512, want: "512B"},
+		{input: 1024, want: "1KiB"},
+		{input: int64(128 * MiB), want: "128MiB"},
+		{input: int64(1536 * MiB), want: "1.5GiB"},
+		{input: int64(GB), want: "953.67MiB"},
+		{input: int64(3 * TiB), want: "3TiB"},
+		{input: -2048, want: "-2KiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, Format(tt.input))
+			assert.Equal(t, tt.want, Size(tt.input).String())
+		})
+	}
+}
+
+func TestSize_UnmarshalText(t *testing.T) {
+	var s Size
+	require.NoError(t, s.UnmarshalText([]byte("64KiB")))
+	assert.Equal(t, 64*KiB, s)
+	assert.Error(t, s.UnmarshalText([]byte("64XB")))
+	assert.Equal(t, 64*KiB, s)
+}
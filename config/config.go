@@ -0,0 +1,575 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package config loads HCL configuration files from local and remote
+// locations.
+//
+// The Load function fetches the configuration using the fsutil package,
+// applies the HCL extensions from the pipeline package and decodes the
+// result into a Go value:
+//
+//	var cfg Config
+//	err := config.Load(ctx, "ipfs://bafy.../config.hcl?checksum=0x...", &cfg)
+//
+// Supported URI schemes are "file", "http", "https", "ipfs", "dav" and
+// "davs" for WebDAV servers, such as Nextcloud, and "git+https",
+// "git+http", "git+ssh" and "git+file" for files in Git repositories,
+// e.g. "git+https://github.com/org/repo//config.hcl?ref=v1.0.0". Files in
+// GitHub repositories can also be read using the contents API with the
+// "github" scheme, e.g. "github://org/repo/config.hcl?ref=v1.0.0", which
+// supports private repositories if a token is set using WithGitHubOptions.
+// The "env" scheme reads the configuration from an environment variable,
+// e.g. "env://APP_CONFIG?encoding=base64,gzip", see fsutil.NewEnvProto.
+// Files staged in memory can be loaded using the "mem" scheme, see
+// WithMemFS, and files compiled into the binary using the "embed" scheme,
+// see WithEmbedRegistry. IPNS names are resolved to IPFS paths using the
+// "ipns" scheme, e.g. "ipns://k51.../config.hcl", see fsutil.NewIPFSProto.
+// Domain names in "ipfs" URIs, e.g. "ipfs://example.com/config.hcl", are
+// resolved using DNSLink.
+// Remote files are fetched with retries. If the URI contains a "checksum" parameter, the
+// checksum of the file is verified. Use the WithProtocol option to support
+// other schemes.
+//
+// The WithRequireChecksum option enables a strict mode, in which remote
+// files, including files included by remote files, must have a checksum.
+//
+// If a decryption key is set using the WithDecryptionKey option, encrypted
+// files can be loaded using the "enc+" scheme prefix, e.g.
+// "enc+https://example.com/config.hcl.ecies". Files included by an
+// encrypted file are decrypted as well.
+//
+// Configurations can be split into layers, e.g. a base configuration,
+// environment specific overrides and host specific overrides. The LoadLayers
+// function loads the files in the given order and merges them before
+// decoding:
+//
+//	err := config.LoadLayers(ctx, []string{
+//		"file://config/base.hcl",
+//		"file://config/prod.hcl",
+//		"file://config/host.hcl",
+//	}, &cfg)
+//
+// Later layers take precedence over earlier ones:
+//   - An attribute replaces the attribute with the same name defined in
+//     earlier layers.
+//   - Blocks with the same type and labels are merged recursively, using the
+//     same rules. The n-th block with a given type and labels is merged with
+//     the n-th such block from earlier layers; blocks without a counterpart
+//     are appended.
+//   - Includes are resolved within each layer before merging, so included
+//     files have the precedence of the layer that includes them.
+//   - Variables, secrets and dynamic blocks are evaluated after merging, so
+//     a layer may override a variable used in an earlier layer.
+//
+// The DryRun function prints the effective merged configuration with secrets
+// redacted, which is useful to check the result of layering.
+//
+// The Watch function can be used to reload the configuration periodically
+// and receive a new snapshot every time it changes.
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	netURL "net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/fsutil"
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+	"github.com/chronicleprotocol/go-lib/hcl/ext/include"
+	"github.com/chronicleprotocol/go-lib/hcl/funcs"
+	"github.com/chronicleprotocol/go-lib/hcl/pipeline"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+const (
+	// DefaultRetryAttempts is the default number of attempts to fetch
+	// a remote file.
+	DefaultRetryAttempts = 3
+
+	// DefaultRetryDelay is the default delay between attempts to fetch
+	// a remote file.
+	DefaultRetryDelay = time.Second
+)
+
+// Option is a functional option for the Load function.
+type Option func(*options)
+
+type options struct {
+	proto           fsutil.Protocol
+	retryAttempts   int
+	retryDelay      time.Duration
+	cache           bool
+	cacheOpts       []fsutil.CacheFSOption
+	noChecksum      bool
+	requireChecksum bool
+	noIncludes      bool
+	evalCtx         *hcl.EvalContext
+	pipelineOpts    []pipeline.Option
+	ipfsOpts        []fsutil.IPFSOption
+	httpOpts        []fsutil.HTTPFSOption
+	gitOpts         []fsutil.GitOption
+	githubOpts      []fsutil.GitHubOption
+	memFS           *fsutil.MemFS
+	embedRegistry   *fsutil.EmbedRegistry
+	decryptionKey   fsutil.KeyProvider
+	maxIncludeDepth int
+	pollInterval    time.Duration
+	pollJitter      float64
+	clock           timeutil.Clock
+	logger          logutil.Logger
+	onReloadErr     func(error)
+}
+
+// WithProtocol sets the protocol used to fetch the configuration. It
+// replaces the default one, so the retry, cache, checksum, HTTP, IPFS, Git
+// and GitHub options are ignored.
+func WithProtocol(proto fsutil.Protocol) Option {
+	return func(o *options) {
+		o.proto = proto
+	}
+}
+
+// WithRetry sets the number of attempts and the delay between them used to
+// fetch remote files.
+func WithRetry(attempts int, delay time.Duration) Option {
+	return func(o *options) {
+		o.retryAttempts = attempts
+		o.retryDelay = delay
+	}
+}
+
+// WithCache enables caching of remote files. See fsutil.NewCacheFS for
+// details.
+func WithCache(opts ...fsutil.CacheFSOption) Option {
+	return func(o *options) {
+		o.cache = true
+		o.cacheOpts = opts
+	}
+}
+
+// WithoutChecksum disables the verification of the "checksum" parameter.
+func WithoutChecksum() Option {
+	return func(o *options) {
+		o.noChecksum = true
+	}
+}
+
+// WithRequireChecksum makes Load refuse to load remote files without the
+// "checksum" parameter, so production configurations cannot silently depend
+// on unverified remote content. Only "file", "env", "mem" and "embed" URIs
+// and URIs without a scheme are considered local.
+//
+// Files included by a remote file are read from the same remote location,
+// so every include of a remote file must have a checksum as well, e.g.
+// include = ["feeds.hcl?checksum=0x..."]. Includes without a checksum are
+// reported as diagnostics that name the offending include.
+//
+// It cannot be used along with WithoutChecksum. If a custom protocol is set
+// using WithProtocol, it must verify the checksums.
+func WithRequireChecksum() Option {
+	return func(o *options) {
+		o.requireChecksum = true
+	}
+}
+
+// WithHTTPOptions sets the options used to create the HTTP and WebDAV file
+// systems.
+func WithHTTPOptions(opts ...fsutil.HTTPFSOption) Option {
+	return func(o *options) {
+		o.httpOpts = opts
+	}
+}
+
+// WithIPFSOptions sets the options used to create the IPFS file system.
+func WithIPFSOptions(opts ...fsutil.IPFSOption) Option {
+	return func(o *options) {
+		o.ipfsOpts = opts
+	}
+}
+
+// WithGitOptions sets the options used to create the Git protocol.
+func WithGitOptions(opts ...fsutil.GitOption) Option {
+	return func(o *options) {
+		o.gitOpts = opts
+	}
+}
+
+// WithGitHubOptions sets the options used to create the GitHub file
+// system, such as the token, see fsutil.WithGitHubToken.
+func WithGitHubOptions(opts ...fsutil.GitHubOption) Option {
+	return func(o *options) {
+		o.githubOpts = opts
+	}
+}
+
+// WithMemFS registers the "mem" scheme, which reads files from the given
+// in-memory file system, e.g. "mem://config.hcl". It can be used to stage
+// configuration files in tests or in programs that generate them.
+func WithMemFS(m *fsutil.MemFS) Option {
+	return func(o *options) {
+		o.memFS = m
+	}
+}
+
+// WithEmbedRegistry registers the "embed" scheme, which reads files from
+// the embedded file systems in the given registry, e.g.
+// "embed://app/defaults/config.hcl", see fsutil.NewEmbedProto.
+func WithEmbedRegistry(r *fsutil.EmbedRegistry) Option {
+	return func(o *options) {
+		o.embedRegistry = r
+	}
+}
+
+// WithDecryptionKey sets the key provider used to decrypt files loaded
+// using the "enc+" scheme prefix, such as "enc+https". Files are encrypted
+// with the public key of an Ethereum account, using the same scheme as
+// secrets, see fsutil.NewEncryptedFS. The checksum parameter, if present,
+// is verified against the encrypted file.
+func WithDecryptionKey(key fsutil.KeyProvider) Option {
+	return func(o *options) {
+		o.decryptionKey = key
+	}
+}
+
+// WithoutIncludes disables the "include" attribute.
+func WithoutIncludes() Option {
+	return func(o *options) {
+		o.noIncludes = true
+	}
+}
+
+// WithMaxIncludeDepth sets the maximum depth of nested includes.
+func WithMaxIncludeDepth(depth int) Option {
+	return func(o *options) {
+		o.maxIncludeDepth = depth
+	}
+}
+
+// WithEvalContext sets the HCL evaluation context. By default, a context
+// created by funcs.NewEvalContext is used. The context is modified by the
+// extensions, so it must not be shared between concurrent calls.
+func WithEvalContext(ctx *hcl.EvalContext) Option {
+	return func(o *options) {
+		o.evalCtx = ctx
+	}
+}
+
+// WithPipelineOptions adds options passed to the pipeline.Decode function.
+func WithPipelineOptions(opts ...pipeline.Option) Option {
+	return func(o *options) {
+		o.pipelineOpts = append(o.pipelineOpts, opts...)
+	}
+}
+
+// WithLogger sets the logger passed to the fsutil protocols used by the
+// default protocol and to the pipeline. By default, nothing is logged.
+func WithLogger(logger logutil.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// DiagnosticsError is returned by Load when the configuration cannot be
+// parsed or decoded. It is tagged with the errutil.CodeConfig code.
+type DiagnosticsError struct {
+	// Diagnostics contains all diagnostics, including warnings.
+	Diagnostics hcl.Diagnostics
+
+	// Files contains the parsed files, used to render the diagnostics.
+	Files map[string]*hcl.File
+}
+
+// Error implements the error interface.
+func (e *DiagnosticsError) Error() string {
+	return e.Diagnostics.Error()
+}
+
+// Write writes human-readable diagnostics, including the source code
+// excerpts, to the writer.
+func (e *DiagnosticsError) Write(w io.Writer, opts ...utilHCL.DiagnosticsOption) error {
+	return utilHCL.WriteDiagnostics(w, e.Files, e.Diagnostics, opts...)
+}
+
+// Load fetches the configuration file from the given URI, applies the HCL
+// extensions and decodes it into the target value.
+//
+// Included files are read relative to the directory of the configuration
+// file, using the same protocol.
+//
+// If the configuration is invalid, the returned error is a DiagnosticsError
+// containing all diagnostics. Errors returned by the fsutil package are
+// returned as is, wrapped with additional context.
+func Load(ctx context.Context, uri string, target any, opts ...Option) error {
+	o := newOptions(opts)
+	return load(ctx, newProtocols(ctx, o), []string{uri}, target, o)
+}
+
+// LoadLayers works like Load, but it loads multiple configuration files and
+// merges them into a single configuration before decoding it. Files are
+// merged in the given order, so later files override earlier ones. See the
+// package documentation for the precedence rules.
+//
+// Includes are resolved separately for every file, relative to its own
+// directory.
+func LoadLayers(ctx context.Context, uris []string, target any, opts ...Option) error {
+	o := newOptions(opts)
+	return load(ctx, newProtocols(ctx, o), uris, target, o)
+}
+
+// DryRun loads and merges the configuration files the same way as LoadLayers,
+// but instead of decoding them into the target value, it writes the effective
+// configuration to w, encoded as HCL. Secrets are replaced by a placeholder,
+// so the output is safe to print or log.
+//
+// The target value is used only to determine the configuration type; it is
+// not modified.
+func DryRun(ctx context.Context, w io.Writer, uris []string, target any, opts ...Option) error {
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	evalCtx := newEvalContext(o)
+	body, files, err := parse(evalCtx, newProtocols(ctx, o).mux(ctx), uris, o)
+	if err != nil {
+		return err
+	}
+	b, diags := pipeline.Render(evalCtx, body, target, o.pipeline()...)
+	if diags.HasErrors() {
+		return errDiagnosticsFn(diags, files)
+	}
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("config: unable to write configuration: %w", err)
+	}
+	return nil
+}
+
+// load loads the configuration using the given protocols. Files checked
+// out for the load, like Git repositories, are removed when it finishes.
+func load(ctx context.Context, protos *protocols, uris []string, target any, o *options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	evalCtx := newEvalContext(o)
+	body, files, err := parse(evalCtx, protos.mux(ctx), uris, o)
+	if err != nil {
+		return err
+	}
+	diags := pipeline.Decode(evalCtx, body, target, o.pipeline()...)
+	if diags.HasErrors() {
+		return errDiagnosticsFn(diags, files)
+	}
+	return nil
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		retryAttempts:   DefaultRetryAttempts,
+		retryDelay:      DefaultRetryDelay,
+		maxIncludeDepth: pipeline.DefaultMaxIncludeDepth,
+		pollInterval:    DefaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// fetch reads the file from the given URI. It returns the contents of the
+// file, the file system it was read from and the directory of the file
+// within that file system.
+func fetch(proto fsutil.Protocol, uri string, o *options) ([]byte, fs.FS, string, error) {
+	if o.requireChecksum && !isLocal(uri) && !hasChecksum(uri) {
+		return nil, nil, "", errConfigLoadFn(uri, errUnverifiedURI)
+	}
+	fsys, name, err := fsutil.ParseURI(proto, uri)
+	if err != nil {
+		return nil, nil, "", errConfigLoadFn(uri, err)
+	}
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, nil, "", errConfigLoadFn(uri, err)
+	}
+	dir, _, _ := strings.Cut(name, "?")
+	return src, fsys, path.Dir(dir), nil
+}
+
+// parse fetches and parses the configuration files from the given URIs,
+// resolves their includes and merges them into a single body. It also
+// returns the parsed files, which are used to print diagnostics.
+func parse(evalCtx *hcl.EvalContext, proto fsutil.Protocol, uris []string, o *options) (hcl.Body, map[string]*hcl.File, error) {
+	if len(uris) == 0 {
+		return nil, nil, errNoFiles
+	}
+	if o.requireChecksum && o.noChecksum {
+		return nil, nil, errRequireChecksumConflict
+	}
+	var (
+		parser = utilHCL.NewParser()
+		bodies = make([]hcl.Body, 0, len(uris))
+		diags  hcl.Diagnostics
+	)
+	for _, uri := range uris {
+		src, fsys, dir, err := fetch(proto, uri, o)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, parseDiags := parser.ParseSource(sourceName(uri), src)
+		if diags = diags.Extend(parseDiags); diags.HasErrors() {
+			return nil, nil, errDiagnosticsFn(diags, parser.Files())
+		}
+		if !o.noIncludes {
+			sub, err := fs.Sub(fsys, dir)
+			if err != nil {
+				return nil, nil, errConfigLoadFn(uri, err)
+			}
+			incOpts := []include.Option{include.WithParser(parser), include.WithDir(sourceDir(uri))}
+			if o.requireChecksum && !isLocal(uri) {
+				incOpts = append(incOpts, include.WithRequireChecksum())
+			}
+			var incDiags hcl.Diagnostics
+			body, incDiags = include.Include(evalCtx, sub, body, o.maxIncludeDepth, incOpts...)
+			if diags = diags.Extend(incDiags); diags.HasErrors() {
+				return nil, nil, errDiagnosticsFn(diags, parser.Files())
+			}
+		}
+		bodies = append(bodies, body)
+	}
+	if len(bodies) == 1 {
+		return bodies[0], parser.Files(), nil
+	}
+	return utilHCL.Merge(bodies...), parser.Files(), nil
+}
+
+// sourceName returns the URI without the query and fragment. The parser
+// chooses the syntax by the file extension, so the parameters, like the
+// checksum, must not be a part of the name.
+func sourceName(uri string) string {
+	name, _, _ := strings.Cut(uri, "?")
+	name, _, _ = strings.Cut(name, "#")
+	return name
+}
+
+// sourceDir returns the directory of the source name of the URI. Unlike
+// path.Dir, it does not clean the URI.
+func sourceDir(uri string) string {
+	name := sourceName(uri)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// isLocal reports whether the URI refers to a local file.
+func isLocal(uri string) bool {
+	u, err := netURL.Parse(uri)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "file", fsutil.EnvScheme, fsutil.MemScheme, fsutil.EmbedScheme, fsutil.EncryptedSchemePrefix + "file":
+		return true
+	}
+	return false
+}
+
+// hasChecksum reports whether the URI has a non-empty checksum parameter.
+// The value is validated by the checksum file system, which refuses to read
+// files with a malformed or zero checksum.
+func hasChecksum(uri string) bool {
+	u, err := netURL.Parse(uri)
+	if err != nil {
+		return false
+	}
+	return u.Query().Get(include.ChecksumParam) != ""
+}
+
+func newEvalContext(o *options) *hcl.EvalContext {
+	if o.evalCtx != nil {
+		return o.evalCtx
+	}
+	return funcs.NewEvalContext()
+}
+
+// pipeline returns the options passed to the pipeline. Options set using
+// WithPipelineOptions take precedence.
+func (o *options) pipeline() []pipeline.Option {
+	if o.logger == nil {
+		return o.pipelineOpts
+	}
+	return append([]pipeline.Option{pipeline.WithLogger(o.logger)}, o.pipelineOpts...)
+}
+
+// protocols are the protocols used to fetch the configuration files. They
+// are created once for every Load or Watch call and shared by all loads, so
+// their state, like the IPNS and DNSLink caches, is reused by reloads.
+type protocols struct {
+	proto  fsutil.Protocol // Set using WithProtocol.
+	protos *fsutil.Protocols
+}
+
+// mux returns the protocol used by a single load. Git repositories are
+// checked out separately for every load and removed when the context is
+// canceled, see fsutil.Protocols.Mux.
+func (p *protocols) mux(ctx context.Context) fsutil.Protocol {
+	if p.proto != nil {
+		return p.proto
+	}
+	return p.protos.Mux(ctx)
+}
+
+// newProtocols returns the protocols used to fetch the configuration files.
+// If a protocol is set using the WithProtocol option, it is used for all
+// schemes.
+func newProtocols(ctx context.Context, o *options) *protocols {
+	if o.proto != nil {
+		return &protocols{proto: o.proto}
+	}
+	return &protocols{protos: fsutil.NewProtocols(ctx, fsutil.ProtocolsConfig{
+		RetryAttempts: o.retryAttempts,
+		RetryDelay:    o.retryDelay,
+		Cache:         o.cache,
+		CacheOptions:  o.cacheOpts,
+		NoChecksum:    o.noChecksum,
+		HTTPOptions:   o.httpOpts,
+		IPFSOptions:   o.ipfsOpts,
+		GitOptions:    o.gitOpts,
+		GitHubOptions: o.githubOpts,
+		MemFS:         o.memFS,
+		EmbedRegistry: o.embedRegistry,
+		DecryptionKey: o.decryptionKey,
+		Logger:        o.logger,
+	})}
+}
+
+var (
+	errNoFiles                 = errutil.WithCode(errors.New("config: no configuration files given"), errutil.CodeConfig)
+	errUnverifiedURI           = errutil.WithCode(errors.New("remote file has no checksum"), errutil.CodeConfig)
+	errRequireChecksumConflict = errutil.WithCode(errors.New("config: WithRequireChecksum cannot be used with WithoutChecksum"), errutil.CodeConfig)
+)
+
+func errConfigLoadFn(uri string, err error) error {
+	return fmt.Errorf("config: unable to load %s: %w", uri, err)
+}
+
+func errDiagnosticsFn(diags hcl.Diagnostics, files map[string]*hcl.File) error {
+	return errutil.WithCode(&DiagnosticsError{Diagnostics: diags, Files: files}, errutil.CodeConfig)
+}
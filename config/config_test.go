@@ -0,0 +1,457 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/chronicleprotocol/go-lib/cryptoutil"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/fsutil"
+	"github.com/chronicleprotocol/go-lib/hcl/funcs"
+	"github.com/chronicleprotocol/go-lib/hcl/pipeline"
+	"github.com/chronicleprotocol/go-lib/testutil"
+)
+
+type config struct {
+	Pair  string `hcl:"pair"`
+	Feeds []feed `hcl:"feed,block"`
+}
+
+type feed struct {
+	Address string `hcl:"address"`
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	src, err := os.ReadFile("testdata/config.hcl")
+	require.NoError(t, err)
+	checksum := sha3.NewLegacyKeccak256()
+	checksum.Write(src)
+	jsonSrc, err := os.ReadFile("testdata/config.json")
+	require.NoError(t, err)
+	jsonChecksum := sha3.NewLegacyKeccak256()
+	jsonChecksum.Write(jsonSrc)
+
+	tests := []struct {
+		name     string
+		uri      string
+		opts     []Option
+		wantErr  string
+		wantCode errutil.Code
+	}{
+		{
+			name: "local file",
+			uri:  "testdata/config.hcl",
+		},
+		{
+			name: "file URI",
+			uri:  "file:///testdata/config.hcl",
+		},
+		{
+			name: "http",
+			uri:  server.URL + "/config.hcl",
+		},
+		{
+			name: "webdav",
+			uri:  strings.Replace(server.URL, "http://", "dav://", 1) + "/config.hcl",
+		},
+		{
+			name: "valid checksum",
+			uri:  server.URL + "/config.hcl?checksum=0x" + hex.EncodeToString(checksum.Sum(nil)),
+		},
+		{
+			name: "JSON with checksum",
+			uri:  server.URL + "/config.json?checksum=0x" + hex.EncodeToString(jsonChecksum.Sum(nil)),
+		},
+		{
+			name:     "invalid checksum",
+			uri:      server.URL + "/config.hcl?checksum=0x" + strings.Repeat("11", 32),
+			wantErr:  "checksum mismatch",
+			wantCode: errutil.CodeIntegrity,
+		},
+		{
+			name:     "without includes",
+			uri:      "testdata/config.hcl",
+			opts:     []Option{WithoutIncludes()},
+			wantErr:  `An argument named "include" is not expected here.`,
+			wantCode: errutil.CodeConfig,
+		},
+		{
+			name:     "invalid config",
+			uri:      "testdata/invalid.hcl",
+			wantErr:  "Unsupported argument",
+			wantCode: errutil.CodeConfig,
+		},
+		{
+			name:     "missing file",
+			uri:      "testdata/missing.hcl",
+			wantErr:  "no such file or directory",
+			wantCode: errutil.CodeNotFound,
+		},
+		{
+			name:     "missing remote file",
+			uri:      server.URL + "/missing.hcl",
+			opts:     []Option{WithRetry(1, 0)},
+			wantErr:  "file does not exist",
+			wantCode: errutil.CodeNotFound,
+		},
+		{
+			name: "require checksum - local file",
+			uri:  "testdata/config.hcl",
+			opts: []Option{WithRequireChecksum()},
+		},
+		{
+			name:     "require checksum - missing checksum",
+			uri:      server.URL + "/config.hcl",
+			opts:     []Option{WithRequireChecksum()},
+			wantErr:  "remote file has no checksum",
+			wantCode: errutil.CodeConfig,
+		},
+		{
+			name:     "require checksum - include without checksum",
+			uri:      server.URL + "/config.hcl?checksum=0x" + hex.EncodeToString(checksum.Sum(nil)),
+			opts:     []Option{WithRequireChecksum()},
+			wantErr:  `Unverified include; Include "feeds/feeds.hcl" has no checksum.`,
+			wantCode: errutil.CodeConfig,
+		},
+		{
+			name:     "require checksum - zero checksum",
+			uri:      server.URL + "/feeds/feeds.hcl?checksum=0x" + strings.Repeat("0", 64),
+			opts:     []Option{WithRequireChecksum()},
+			wantErr:  "invalid checksum parameter: zero hash",
+			wantCode: errutil.CodeConfig,
+		},
+		{
+			name:     "require checksum - malformed checksum",
+			uri:      server.URL + "/feeds/feeds.hcl?checksum=bogus",
+			opts:     []Option{WithRequireChecksum()},
+			wantErr:  "invalid checksum parameter",
+			wantCode: errutil.CodeConfig,
+		},
+		{
+			name:     "require checksum - without checksum",
+			uri:      "testdata/config.hcl",
+			opts:     []Option{WithRequireChecksum(), WithoutChecksum()},
+			wantErr:  "cannot be used with WithoutChecksum",
+			wantCode: errutil.CodeConfig,
+		},
+		{
+			name:     "unknown scheme",
+			uri:      "foo://bar/config.hcl",
+			wantErr:  "unknown scheme",
+			wantCode: errutil.CodeConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			err := Load(ctx, tt.uri, &cfg, tt.opts...)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, config{Pair: "BTC/USD", Feeds: []feed{{Address: "0x1"}, {Address: "0x2"}}}, cfg)
+		})
+	}
+}
+
+func TestLoad_Logger(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	var (
+		buf bytes.Buffer
+		cfg config
+	)
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	require.NoError(t, Load(context.Background(), server.URL+"/config.hcl", &cfg, WithLogger(logger)))
+	assert.Contains(t, buf.String(), `msg="Fetching file" url=`+server.URL+"/config.hcl")
+}
+
+// gitTestRepo creates a Git repository with the contents of the testdata
+// directory, tagged as "v1.0.0".
+func gitTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	repo := t.TempDir()
+	require.NoError(t, os.CopyFS(repo, os.DirFS("testdata")))
+	for _, args := range [][]string{{"init", "--quiet"}, {"add", "."}, {"commit", "--quiet", "-m", "init"}, {"tag", "v1.0.0"}} {
+		gitTestRun(t, repo, args...)
+	}
+	return repo
+}
+
+func gitTestRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// assertEmptyDir asserts that the directory becomes empty.
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		entries, err := os.ReadDir(dir)
+		return err == nil && len(entries) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLoad_Git(t *testing.T) {
+	repo := gitTestRepo(t)
+
+	var cfg config
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	uri := "git+file://" + filepath.ToSlash(repo) + "//config.hcl?ref=v1.0.0"
+	require.NoError(t, Load(ctx, uri, &cfg, WithGitOptions(fsutil.WithGitDir(dir))))
+	assert.Equal(t, config{Pair: "BTC/USD", Feeds: []feed{{Address: "0x1"}, {Address: "0x2"}}}, cfg)
+
+	// The checkout is removed once the configuration is loaded.
+	assertEmptyDir(t, dir)
+}
+
+func TestLoad_GitHub(t *testing.T) {
+	files := http.StripPrefix("/repos/org/repo/contents", http.FileServer(http.Dir("testdata")))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("ref") != "v1.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	apiURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var cfg config
+	err = Load(context.Background(), "github://org/repo/config.hcl?ref=v1.0.0", &cfg, WithGitHubOptions(
+		fsutil.WithGitHubAPIURL(apiURL),
+		fsutil.WithGitHubToken("token"),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, config{Pair: "BTC/USD", Feeds: []feed{{Address: "0x1"}, {Address: "0x2"}}}, cfg)
+}
+
+func TestLoad_IPNS(t *testing.T) {
+	gw := testutil.NewIPFSGateway()
+	defer gw.Close()
+	for _, name := range []string{"config.hcl", "feeds/feeds.hcl"} {
+		b, err := os.ReadFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+		gw.AddFile("QmTest", "config/"+name, b)
+	}
+	// A record with only the value field, which is accepted because the
+	// signature verification is not enabled.
+	value := "/ipfs/QmTest/config"
+	record := append([]byte{0x0a, byte(len(value))}, value...)
+
+	var cfg config
+	err := Load(context.Background(), "ipns://k51Test/config.hcl", &cfg, WithIPFSOptions(
+		fsutil.WithIPFSHTTPClient(gw.Client()),
+		fsutil.WithIPFSGateways(&fsutil.IPFSGateway{Scheme: gw.Scheme(), Host: gw.Host(), ResolveFn: fsutil.IPFSPathResolution}),
+		fsutil.WithIPNSResolver(func(_ context.Context, name string) ([]byte, error) {
+			assert.Equal(t, "k51Test", name)
+			return record, nil
+		}),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, config{Pair: "BTC/USD", Feeds: []feed{{Address: "0x1"}, {Address: "0x2"}}}, cfg)
+}
+
+func TestLoad_Env(t *testing.T) {
+	t.Setenv("CONFIG_TEST_ENV", base64.StdEncoding.EncodeToString([]byte(`pair = "ETH/USD"`)))
+
+	var cfg config
+	err := Load(context.Background(), "env://CONFIG_TEST_ENV?encoding=base64", &cfg, WithRequireChecksum())
+	require.NoError(t, err)
+	assert.Equal(t, config{Pair: "ETH/USD"}, cfg)
+}
+
+func TestLoad_Mem(t *testing.T) {
+	m := fsutil.NewMemFS()
+	require.NoError(t, m.WriteFile("config.hcl", []byte(`include = ["feeds.hcl"]`+"\npair = \"ETH/USD\""), 0o644))
+	require.NoError(t, m.WriteFile("feeds.hcl", []byte(`feed { address = "0x1" }`), 0o644))
+
+	var cfg config
+	require.NoError(t, Load(context.Background(), "mem://config.hcl", &cfg, WithMemFS(m)))
+	assert.Equal(t, config{Pair: "ETH/USD", Feeds: []feed{{Address: "0x1"}}}, cfg)
+
+	// The scheme is not registered without the option.
+	assert.Error(t, Load(context.Background(), "mem://config.hcl", &cfg))
+}
+
+//go:embed testdata
+var testdataFS embed.FS
+
+func TestLoad_Embed(t *testing.T) {
+	r := fsutil.NewEmbedRegistry().Register("app", testdataFS)
+
+	var cfg config
+	require.NoError(t, Load(context.Background(), "embed://app/testdata/config.hcl", &cfg, WithEmbedRegistry(r), WithRequireChecksum()))
+	assert.Equal(t, config{Pair: "BTC/USD", Feeds: []feed{{Address: "0x1"}, {Address: "0x2"}}}, cfg)
+}
+
+func TestLoad_Encrypted(t *testing.T) {
+	key := &ecdsa.PrivateKey{D: big.NewInt(1)}
+	key.X, _ = new(big.Int).SetString("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798", 16)
+	key.Y, _ = new(big.Int).SetString("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8", 16)
+
+	// Encrypt the whole testdata directory, including the included files.
+	dir := t.TempDir()
+	for _, name := range []string{"config.hcl", "feeds/feeds.hcl"} {
+		src, err := os.ReadFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+		ciphertext, err := cryptoutil.Encrypt(&key.PublicKey, src)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), ciphertext, 0o600))
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+	uri := "enc+" + server.URL + "/config.hcl"
+
+	var cfg config
+	require.NoError(t, Load(context.Background(), uri, &cfg, WithDecryptionKey(fsutil.StaticKey(key))))
+	assert.Equal(t, config{Pair: "BTC/USD", Feeds: []feed{{Address: "0x1"}, {Address: "0x2"}}}, cfg)
+
+	err := Load(context.Background(), uri, &cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown scheme")
+}
+
+func TestLoadLayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		uris    []string
+		want    config
+		wantErr string
+	}{
+		{
+			name: "base only",
+			uris: []string{"testdata/layers/base.hcl"},
+			want: config{Pair: "BTC/USD", Feeds: []feed{{Address: "0x1"}}},
+		},
+		{
+			name: "environment override",
+			uris: []string{"testdata/layers/base.hcl", "testdata/layers/prod.hcl"},
+			want: config{Pair: "BTC/EUR", Feeds: []feed{{Address: "0x2"}}},
+		},
+		{
+			name: "host override",
+			uris: []string{"testdata/layers/base.hcl", "testdata/layers/prod.hcl", "testdata/layers/host.hcl"},
+			want: config{Pair: "ETH/EUR", Feeds: []feed{{Address: "0x3"}, {Address: "0x4"}}},
+		},
+		{
+			name:    "missing layer",
+			uris:    []string{"testdata/layers/base.hcl", "testdata/layers/missing.hcl"},
+			wantErr: "no such file or directory",
+		},
+		{
+			name:    "no layers",
+			wantErr: "no configuration files given",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			err := LoadLayers(context.Background(), tt.uris, &cfg)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	evalCtx := funcs.NewEvalContext()
+	evalCtx.Variables["secrets"] = cty.ObjectVal(map[string]cty.Value{
+		"address": cty.StringVal("0xsecret"),
+	})
+
+	var (
+		b   bytes.Buffer
+		cfg config
+	)
+	err := DryRun(
+		context.Background(),
+		&b,
+		[]string{"testdata/layers/base.hcl", "testdata/layers/prod.hcl", "testdata/layers/secret.hcl"},
+		&cfg,
+		WithEvalContext(evalCtx),
+		WithPipelineOptions(pipeline.WithoutSecrets()),
+	)
+	require.NoError(t, err)
+	assert.Contains(t, b.String(), `pair = "BTC/EUR"`)
+	assert.Contains(t, b.String(), `address = "(redacted)"`)
+	assert.NotContains(t, b.String(), "0xsecret")
+	assert.Equal(t, config{}, cfg)
+}
+
+func TestDiagnosticsError_Write(t *testing.T) {
+	for _, uri := range []string{"testdata/invalid.hcl", "testdata/invalid-include.hcl"} {
+		t.Run(uri, func(t *testing.T) {
+			var cfg config
+			err := Load(context.Background(), uri, &cfg)
+
+			var dErr *DiagnosticsError
+			require.True(t, errors.As(err, &dErr))
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, dErr.Write(buf))
+			assert.Contains(t, buf.String(), "Error: Unsupported argument")
+			assert.Contains(t, buf.String(), `feeds = []`)
+		})
+	}
+}
@@ -0,0 +1 @@
+include = ["invalid.hcl"]
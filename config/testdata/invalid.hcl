@@ -0,0 +1,2 @@
+pair  = "BTC/USD"
+feeds = []
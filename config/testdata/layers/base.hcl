@@ -0,0 +1,11 @@
+include = ["common.hcl"]
+
+variables {
+  base = "USD"
+}
+
+pair = "${var.asset}/${var.base}"
+
+feed {
+  address = "0x1"
+}
@@ -0,0 +1,3 @@
+variables {
+  asset = "BTC"
+}
@@ -0,0 +1,9 @@
+pair = "ETH/${var.base}"
+
+feed {
+  address = "0x3"
+}
+
+feed {
+  address = "0x4"
+}
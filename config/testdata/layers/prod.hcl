@@ -0,0 +1,7 @@
+variables {
+  base = "EUR"
+}
+
+feed {
+  address = "0x2"
+}
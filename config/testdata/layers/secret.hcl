@@ -0,0 +1,3 @@
+feed {
+  address = secrets.address
+}
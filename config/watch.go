@@ -0,0 +1,150 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package config
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/fsutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+// DefaultPollInterval is the default interval between checks for
+// configuration changes.
+const DefaultPollInterval = 30 * time.Second
+
+// Snapshot is a valid configuration delivered by Watch.
+type Snapshot[T any] struct {
+	// Config is the decoded configuration.
+	Config T
+
+	// LoadedAt is the time at which the configuration was loaded.
+	LoadedAt time.Time
+}
+
+// WithPollInterval sets the interval between checks for configuration
+// changes. It is used only by Watch.
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = d
+	}
+}
+
+// WithPollJitter randomizes every poll interval by up to the given fraction
+// of it, in either direction, so that many instances watching the same
+// configuration do not fetch it at the same moment. See
+// timeutil.TickerWithJitter. It is used only by Watch.
+func WithPollJitter(jitter float64) Option {
+	return func(o *options) {
+		o.pollJitter = jitter
+	}
+}
+
+// WithClock sets the clock used to schedule reloads and to set the LoadedAt
+// field of snapshots. It is intended for tests, see timeutil.Fake. It is
+// used only by Watch.
+func WithClock(clock timeutil.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithReloadErrorHandler sets a function that is called when the
+// configuration cannot be reloaded. It is used only by Watch.
+func WithReloadErrorHandler(fn func(error)) Option {
+	return func(o *options) {
+		o.onReloadErr = fn
+	}
+}
+
+// Watch loads the configuration from the given URI and then periodically
+// reloads it, sending a new snapshot to the returned channel every time the
+// decoded configuration changes. The first snapshot is sent right away.
+//
+// Every reload fetches the configuration again, including the included
+// files, and decodes it into a new value. The protocols are created once
+// and shared by all reloads, Git repositories are checked out again for
+// every reload and removed once it finishes. Files read over HTTP are
+// fetched using conditional requests, so that unchanged files are not
+// downloaded again, see fsutil.WithHTTPETagCache. If the reload fails, the error
+// is passed to the handler set by the WithReloadErrorHandler option and
+// the previous snapshot remains current, so only valid configurations are
+// ever delivered.
+//
+// If the initial load fails, the error is returned. The channel is closed
+// when the context is canceled.
+//
+// The WithCache option is ignored, because cached files would never change.
+// If an evaluation context is set using WithEvalContext, a child context is
+// created for every reload.
+func Watch[T any](ctx context.Context, uri string, opts ...Option) (<-chan Snapshot[T], error) {
+	o := newOptions(opts)
+	o.cache = false
+	o.httpOpts = append(slices.Clone(o.httpOpts), fsutil.WithHTTPETagCache(fsutil.NewETagCache()))
+	clock := timeutil.OrReal(o.clock)
+	protos := newProtocols(ctx, o)
+	reload := func() (s Snapshot[T], err error) {
+		ro := *o
+		if o.evalCtx != nil {
+			ro.evalCtx = o.evalCtx.NewChild()
+		}
+		if err := load(ctx, protos, []string{uri}, &s.Config, &ro); err != nil {
+			return s, err
+		}
+		s.LoadedAt = clock.Now()
+		return s, nil
+	}
+	current, err := reload()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan Snapshot[T])
+	go func() {
+		defer close(ch)
+		t := timeutil.TickerWithJitter(clock, o.pollInterval, o.pollJitter)
+		defer t.Stop()
+		next := &current
+		for {
+			if next != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- *next:
+					next = nil
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C():
+			}
+			s, err := reload()
+			if err != nil {
+				if ctx.Err() == nil && o.onReloadErr != nil {
+					o.onReloadErr(err)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(s.Config, current.Config) {
+				current = s
+				next = &current
+			}
+		}
+	}()
+	return ch, nil
+}
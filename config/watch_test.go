@@ -0,0 +1,224 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/fsutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.hcl")
+	write := func(src string) {
+		require.NoError(t, os.WriteFile(path, []byte(src), 0600))
+	}
+	errCh := make(chan error, 10)
+	write(`pair = "BTC/USD"`)
+
+	ch, err := Watch[config](
+		ctx,
+		"config.hcl",
+		WithProtocol(fsutil.NewFSProto(os.DirFS(dir))),
+		WithPollInterval(10*time.Millisecond),
+		WithReloadErrorHandler(func(err error) {
+			// Every poll reports the error until the file is fixed, only
+			// the first one is needed.
+			select {
+			case errCh <- err:
+			default:
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	// Initial snapshot.
+	s := <-ch
+	assert.Equal(t, "BTC/USD", s.Config.Pair)
+	assert.False(t, s.LoadedAt.IsZero())
+
+	// Valid change.
+	write(`pair = "ETH/USD"`)
+	s = <-ch
+	assert.Equal(t, "ETH/USD", s.Config.Pair)
+
+	// Invalid change, the previous snapshot remains current.
+	write(`pair = `)
+	select {
+	case err := <-errCh:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("reload error not reported")
+	}
+	select {
+	case s := <-ch:
+		t.Fatalf("unexpected snapshot: %v", s)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Recovery.
+	write(`pair = "ETH/BTC"`)
+	s = <-ch
+	assert.Equal(t, "ETH/BTC", s.Config.Pair)
+
+	// Channel is closed when the context is canceled.
+	cancel()
+	for range ch {
+	}
+}
+
+func TestWatch_HTTP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		content  = `pair = "BTC/USD"`
+		notMod   int
+		received int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received++
+		etag := fmt.Sprintf("%q", content)
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notMod++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	ch, err := Watch[config](ctx, server.URL+"/config.hcl", WithPollInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	s := <-ch
+	assert.Equal(t, "BTC/USD", s.Config.Pair)
+
+	// Unchanged file is not downloaded again.
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return notMod >= 2
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, received-1, notMod)
+	content = `pair = "ETH/USD"`
+	mu.Unlock()
+
+	s = <-ch
+	assert.Equal(t, "ETH/USD", s.Config.Pair)
+}
+
+func TestWatch_Git(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := gitTestRepo(t)
+	dir := t.TempDir()
+	ch, err := Watch[config](
+		ctx,
+		"git+file://"+filepath.ToSlash(repo)+"//config.hcl",
+		WithGitOptions(fsutil.WithGitDir(dir)),
+		WithPollInterval(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+	s := <-ch
+	assert.Equal(t, "BTC/USD", s.Config.Pair)
+
+	// Reloads fetch new commits.
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "feeds/feeds.hcl"), []byte(`variables {
+  asset = "ETH"
+  feeds = ["0x1"]
+}
+`), 0o644))
+	gitTestRun(t, repo, "commit", "--quiet", "-am", "update")
+	s = <-ch
+	assert.Equal(t, "ETH/USD", s.Config.Pair)
+
+	// Checkouts are removed after every reload. They are removed in the
+	// background, so the checkout of the previous reload may still exist.
+	for range 10 {
+		time.Sleep(10 * time.Millisecond)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(entries), 2)
+	}
+	cancel()
+	for range ch {
+	}
+	assertEmptyDir(t, dir)
+}
+
+func TestWatch_InitialError(t *testing.T) {
+	_, err := Watch[config](context.Background(), "testdata/invalid.hcl")
+	assert.Error(t, err)
+}
+
+func TestWatch_Clock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(`pair = "BTC/USD"`), 0600))
+
+	start := time.Unix(0, 0)
+	clock := timeutil.NewFake(start)
+	ch, err := Watch[config](
+		ctx,
+		"config.hcl",
+		WithProtocol(fsutil.NewFSProto(os.DirFS(dir))),
+		WithPollInterval(time.Hour),
+		WithPollJitter(0.1),
+		WithClock(clock),
+	)
+	require.NoError(t, err)
+
+	s := <-ch
+	assert.Equal(t, start, s.LoadedAt)
+
+	// The change is picked up only after the poll interval passes.
+	require.NoError(t, os.WriteFile(path, []byte(`pair = "ETH/USD"`), 0600))
+	clock.BlockUntil(1)
+	clock.Advance(53 * time.Minute)
+	select {
+	case s := <-ch:
+		t.Fatalf("unexpected snapshot: %v", s)
+	case <-time.After(20 * time.Millisecond):
+	}
+	clock.Advance(13 * time.Minute)
+	s = <-ch
+	assert.Equal(t, "ETH/USD", s.Config.Pair)
+	assert.Equal(t, start.Add(66*time.Minute), s.LoadedAt)
+}
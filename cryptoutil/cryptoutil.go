@@ -0,0 +1,148 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package cryptoutil implements the encryption scheme used to store secrets
+// in configuration files, see the hcl/ext/secrets package.
+//
+// A secret is encrypted separately for every recipient using the public key
+// of the recipient's Ethereum account, and stored under the account address.
+// The recipient decrypts it using the private key of the account. Tools that
+// produce secrets for configuration files should use this package to make
+// sure that the ciphertexts can be decrypted by the secrets extension.
+package cryptoutil
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/chronicleprotocol/ecies"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/defiweb/go-eth/hexutil"
+	"github.com/defiweb/go-eth/types"
+	"golang.org/x/crypto/sha3"
+)
+
+// Scheme identifies the encryption scheme of a ciphertext.
+type Scheme string
+
+const (
+	// SchemeECIESv1 is ECIES on the secp256k1 curve, as implemented by the
+	// github.com/chronicleprotocol/ecies package.
+	//
+	// Ciphertexts of this scheme are encoded as hex strings without a scheme
+	// prefix, so that they remain readable by older versions of the secrets
+	// extension.
+	SchemeECIESv1 Scheme = "ecies-v1"
+
+	// DefaultScheme is the scheme used by EncryptForAddress.
+	DefaultScheme = SchemeECIESv1
+)
+
+// ErrUnknownScheme is returned when a ciphertext uses an unknown scheme.
+var ErrUnknownScheme = errors.New("unknown encryption scheme")
+
+// Encrypt encrypts the plaintext for the owner of the given public key using
+// the default scheme and returns the raw ciphertext.
+func Encrypt(pub *ecdsa.PublicKey, plaintext []byte) ([]byte, error) {
+	if pub == nil {
+		return nil, errors.New("public key is nil")
+	}
+	return ecies.Encrypt(eciesKey(pub.X, pub.Y).PublicKey, plaintext)
+}
+
+// Decrypt decrypts a raw ciphertext produced by Encrypt using the given
+// private key.
+func Decrypt(key *ecdsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	if key == nil {
+		return nil, errors.New("private key is nil")
+	}
+	k := eciesKey(key.X, key.Y)
+	k.D = key.D
+	return ecies.Decrypt(k, ciphertext)
+}
+
+// EncryptForAddress encrypts the plaintext for the owner of the given public
+// key. It returns the address of the key and the encoded ciphertext, which
+// are the key and the value of an entry in a secret map:
+//
+//	secrets {
+//	  foo = {
+//	    "<address>" = "<ciphertext>"
+//	  }
+//	}
+func EncryptForAddress(pub *ecdsa.PublicKey, plaintext []byte) (types.Address, string, error) {
+	ciphertext, err := Encrypt(pub, plaintext)
+	if err != nil {
+		return types.Address{}, "", err
+	}
+	return PublicKeyAddress(pub), EncodeCiphertext(DefaultScheme, ciphertext), nil
+}
+
+// DecryptWithKey decrypts a ciphertext encoded by EncodeCiphertext, such as
+// a secret map value, using the given private key.
+func DecryptWithKey(key *ecdsa.PrivateKey, ciphertext string) ([]byte, error) {
+	_, b, err := ParseCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(key, b)
+}
+
+// EncodeCiphertext encodes a raw ciphertext as a hex string. Ciphertexts of
+// schemes other than SchemeECIESv1 are prefixed with the scheme identifier
+// followed by a colon.
+func EncodeCiphertext(scheme Scheme, ciphertext []byte) string {
+	if scheme == SchemeECIESv1 {
+		return hexutil.BytesToHex(ciphertext)
+	}
+	return string(scheme) + ":" + hexutil.BytesToHex(ciphertext)
+}
+
+// ParseCiphertext decodes a ciphertext encoded by EncodeCiphertext and
+// returns its scheme. Ciphertexts without a scheme prefix use
+// SchemeECIESv1.
+func ParseCiphertext(s string) (Scheme, []byte, error) {
+	scheme := SchemeECIESv1
+	if prefix, rest, ok := strings.Cut(s, ":"); ok {
+		scheme, s = Scheme(prefix), rest
+	}
+	if scheme != SchemeECIESv1 {
+		return scheme, nil, fmt.Errorf("%w: %q", ErrUnknownScheme, scheme)
+	}
+	b, err := hexutil.HexToBytes(s)
+	if err != nil {
+		return scheme, nil, fmt.Errorf("ciphertext is not hex encoded: %w", err)
+	}
+	return scheme, b, nil
+}
+
+// PublicKeyAddress returns the Ethereum address of the given public key.
+func PublicKeyAddress(pub *ecdsa.PublicKey) (addr types.Address) {
+	b := make([]byte, 64)
+	pub.X.FillBytes(b[:32])
+	pub.Y.FillBytes(b[32:])
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b)
+	copy(addr[:], h.Sum(nil)[12:])
+	return addr
+}
+
+// eciesKey returns an ECIES key with the given public key coordinates on
+// the secp256k1 curve, the same curve as used by the ecies package.
+func eciesKey(x, y *big.Int) *ecies.PrivateKey {
+	return &ecies.PrivateKey{PublicKey: &ecies.PublicKey{Curve: secp256k1.S256(), X: x, Y: y}}
+}
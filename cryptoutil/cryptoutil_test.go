@@ -0,0 +1,105 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package cryptoutil
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Private keys 1 and 2 on the secp256k1 curve.
+var (
+	key1 = testKey(
+		"1",
+		"79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8",
+	)
+	key2 = testKey(
+		"2",
+		"C6047F9441ED7D6D3045406E95C07CD85C778E4B8CEF3CA7ABAC09B95C709EE5",
+		"1AE168FEA63DC339A3C58419466CEAEEF7F632653266D0E1236431A950CFE52A",
+	)
+)
+
+func testKey(d, x, y string) *ecdsa.PrivateKey {
+	k := &ecdsa.PrivateKey{D: new(big.Int)}
+	k.D.SetString(d, 16)
+	k.X, _ = new(big.Int).SetString(x, 16)
+	k.Y, _ = new(big.Int).SetString(y, 16)
+	return k
+}
+
+func TestEncryptForAddress(t *testing.T) {
+	addr, ciphertext, err := EncryptForAddress(&key1.PublicKey, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, PublicKeyAddress(&key1.PublicKey), addr)
+	assert.Regexp(t, "^0x[0-9a-f]+$", ciphertext)
+
+	plaintext, err := DecryptWithKey(key1, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+
+	_, err = DecryptWithKey(key2, ciphertext)
+	assert.Error(t, err)
+}
+
+func TestPublicKeyAddress(t *testing.T) {
+	assert.Equal(t, "7e5f4552091a69125d5dfcb7b8c2659029395bdf", hex.EncodeToString(PublicKeyAddress(&key1.PublicKey).Bytes()))
+	assert.Equal(t, "2b5ad5c4795c026514f8317c7a215e218dccd6cf", hex.EncodeToString(PublicKeyAddress(&key2.PublicKey).Bytes()))
+}
+
+func TestParseCiphertext(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantScheme Scheme
+		wantBytes  []byte
+		wantErr    string
+	}{
+		{input: "0x0102", wantScheme: SchemeECIESv1, wantBytes: []byte{1, 2}},
+		{input: "0102", wantScheme: SchemeECIESv1, wantBytes: []byte{1, 2}},
+		{input: "ecies-v1:0x0102", wantScheme: SchemeECIESv1, wantBytes: []byte{1, 2}},
+		{input: "0xzz", wantScheme: SchemeECIESv1, wantErr: "ciphertext is not hex encoded"},
+		{input: "foo:0x0102", wantScheme: "foo", wantErr: `unknown encryption scheme: "foo"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			scheme, b, err := ParseCiphertext(tt.input)
+			assert.Equal(t, tt.wantScheme, scheme)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBytes, b)
+		})
+	}
+}
+
+func TestEncodeCiphertext(t *testing.T) {
+	assert.Equal(t, "0x0102", EncodeCiphertext(SchemeECIESv1, []byte{1, 2}))
+	assert.Equal(t, "foo:0x0102", EncodeCiphertext("foo", []byte{1, 2}))
+}
+
+func TestNilKeys(t *testing.T) {
+	_, err := Encrypt(nil, []byte("secret"))
+	assert.Error(t, err)
+	_, err = Decrypt(nil, []byte{1})
+	assert.Error(t, err)
+}
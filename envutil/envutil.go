@@ -0,0 +1,160 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package envutil provides typed access to environment variables.
+//
+// Get reads a single optional variable. To read a set of variables that
+// must be present, use Require with a Collector, which gathers all missing
+// and invalid variables into one error, so all problems can be reported at
+// once:
+//
+//	var c envutil.Collector
+//	endpoint := envutil.Require[*url.URL](&c, "RPC_URL")
+//	token := envutil.Require[string](&c, "RPC_TOKEN")
+//	timeout := envutil.Optional(&c, "RPC_TIMEOUT", 10*time.Second)
+//	if err := c.Err(); err != nil {
+//		return err
+//	}
+//
+// Variables that are set to an empty string are treated as not set.
+package envutil
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/ethutil"
+)
+
+// ErrMissing is returned when a required environment variable is not set.
+var ErrMissing = errors.New("environment variable is not set")
+
+// Value is the set of types that can be parsed from environment variables.
+type Value interface {
+	string | bool | int | int64 | uint64 | float64 | time.Duration | *url.URL | types.Address
+}
+
+// Get returns the value of the environment variable with the given name,
+// parsed as T. If the variable is not set, def is returned. If the value
+// cannot be parsed, def is returned along with an error. The error names the
+// variable, but not its value, which may contain credentials.
+//
+// Addresses are parsed using ethutil.ParseAddress.
+func Get[T Value](name string, def T) (T, error) {
+	s, ok := lookup(name)
+	if !ok {
+		return def, nil
+	}
+	v, err := parse[T](s)
+	if err != nil {
+		return def, fmt.Errorf("invalid environment variable %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// Collector collects errors from the Require and Optional functions. The
+// zero value is ready to use.
+type Collector struct {
+	err error
+}
+
+// Err returns all collected errors, or nil if there were none.
+func (c *Collector) Err() error {
+	return c.err
+}
+
+// Require returns the value of the environment variable with the given
+// name, parsed as T. If the variable is not set or its value cannot be
+// parsed, an error is added to the collector and the zero value of T is
+// returned.
+func Require[T Value](c *Collector, name string) T {
+	var zero T
+	if _, ok := lookup(name); !ok {
+		c.err = errutil.Append(c.err, fmt.Errorf("%s: %w", name, ErrMissing))
+		return zero
+	}
+	return Optional(c, name, zero)
+}
+
+// Optional works like Get, but errors are added to the collector instead
+// of being returned.
+func Optional[T Value](c *Collector, name string, def T) T {
+	v, err := Get(name, def)
+	if err != nil {
+		c.err = errutil.Append(c.err, err)
+	}
+	return v
+}
+
+func lookup(name string) (string, bool) {
+	s, ok := os.LookupEnv(name)
+	return s, ok && s != ""
+}
+
+// parse parses the value of a variable. Values may contain credentials, so
+// the returned errors never include the value itself.
+func parse[T Value](s string) (res T, err error) {
+	switch p := any(&res).(type) {
+	case *string:
+		*p = s
+	case *bool:
+		if *p, err = strconv.ParseBool(s); err != nil {
+			return res, errors.New("must be a boolean")
+		}
+	case *int:
+		*p, err = strconv.Atoi(s)
+		return res, numError(err)
+	case *int64:
+		*p, err = strconv.ParseInt(s, 10, 64)
+		return res, numError(err)
+	case *uint64:
+		*p, err = strconv.ParseUint(s, 10, 64)
+		return res, numError(err)
+	case *float64:
+		*p, err = strconv.ParseFloat(s, 64)
+		return res, numError(err)
+	case *time.Duration:
+		if *p, err = time.ParseDuration(s); err != nil {
+			return res, errors.New(`must be a duration, e.g. "10s"`)
+		}
+	case **url.URL:
+		u, err := url.Parse(s)
+		if err != nil || !u.IsAbs() || u.Host == "" {
+			return res, errors.New("must be an absolute URL")
+		}
+		*p = u
+	case *types.Address:
+		if *p, err = ethutil.ParseAddress(s); err != nil {
+			return res, errors.New("must be an Ethereum address")
+		}
+	}
+	return res, nil
+}
+
+// numError returns an error without the parsed value, which is included in
+// errors returned by the strconv package.
+func numError(err error) error {
+	var nErr *strconv.NumError
+	if errors.As(err, &nErr) {
+		return fmt.Errorf("must be a number: %w", nErr.Err)
+	}
+	return err
+}
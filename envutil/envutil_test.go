@@ -0,0 +1,150 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package envutil
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	t.Setenv("ENVUTIL_STRING", "foo")
+	t.Setenv("ENVUTIL_EMPTY", "")
+	t.Setenv("ENVUTIL_BOOL", "true")
+	t.Setenv("ENVUTIL_INT", "-42")
+	t.Setenv("ENVUTIL_UINT", "42")
+	t.Setenv("ENVUTIL_FLOAT", "1.5")
+	t.Setenv("ENVUTIL_DURATION", "1m30s")
+	t.Setenv("ENVUTIL_URL", "https://example.com/rpc")
+	t.Setenv("ENVUTIL_ADDRESS", "0x2d800d93b065ce011af83f316cef9f0d005b0aa4")
+
+	assertGet(t, "ENVUTIL_STRING", "default", "foo")
+	assertGet(t, "ENVUTIL_EMPTY", "default", "default")
+	assertGet(t, "ENVUTIL_UNSET", "default", "default")
+	assertGet(t, "ENVUTIL_BOOL", false, true)
+	assertGet(t, "ENVUTIL_INT", 0, -42)
+	assertGet(t, "ENVUTIL_INT", int64(0), int64(-42))
+	assertGet(t, "ENVUTIL_UINT", uint64(0), uint64(42))
+	assertGet(t, "ENVUTIL_FLOAT", 0.0, 1.5)
+	assertGet(t, "ENVUTIL_DURATION", time.Second, 90*time.Second)
+	assertGet(t, "ENVUTIL_URL", nil, &url.URL{Scheme: "https", Host: "example.com", Path: "/rpc"})
+	assertGet(t, "ENVUTIL_ADDRESS", types.ZeroAddress, types.MustAddressFromHex("0x2d800d93b065ce011af83f316cef9f0d005b0aa4"))
+}
+
+func TestGet_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		get     func() error
+		wantErr string
+	}{
+		{
+			name:    "bool",
+			value:   "maybe",
+			get:     func() error { _, err := Get("ENVUTIL_VAR", false); return err },
+			wantErr: `invalid environment variable ENVUTIL_VAR: must be a boolean`,
+		},
+		{
+			name:    "uint",
+			value:   "-1",
+			get:     func() error { _, err := Get("ENVUTIL_VAR", uint64(0)); return err },
+			wantErr: `invalid environment variable ENVUTIL_VAR: must be a number: invalid syntax`,
+		},
+		{
+			name:    "duration",
+			value:   "5",
+			get:     func() error { _, err := Get("ENVUTIL_VAR", time.Duration(0)); return err },
+			wantErr: `invalid environment variable ENVUTIL_VAR: must be a duration, e.g. "10s"`,
+		},
+		{
+			name:    "url",
+			value:   "example.com/rpc",
+			get:     func() error { _, err := Get[*url.URL]("ENVUTIL_VAR", nil); return err },
+			wantErr: `invalid environment variable ENVUTIL_VAR: must be an absolute URL`,
+		},
+		{
+			name:    "address",
+			value:   "0x1234",
+			get:     func() error { _, err := Get("ENVUTIL_VAR", types.ZeroAddress); return err },
+			wantErr: `invalid environment variable ENVUTIL_VAR: must be an Ethereum address`,
+		},
+		{
+			name:    "address checksum",
+			value:   "0x2D800d93b065ce011af83f316cef9f0d005b0aa4",
+			get:     func() error { _, err := Get("ENVUTIL_VAR", types.ZeroAddress); return err },
+			wantErr: `invalid environment variable ENVUTIL_VAR: must be an Ethereum address`,
+		},
+		{
+			name:    "int range",
+			value:   "99999999999999999999",
+			get:     func() error { _, err := Get("ENVUTIL_VAR", int64(0)); return err },
+			wantErr: `invalid environment variable ENVUTIL_VAR: must be a number: value out of range`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENVUTIL_VAR", tt.value)
+			err := tt.get()
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	t.Setenv("ENVUTIL_URL", "https://example.com")
+	t.Setenv("ENVUTIL_TOKEN", "secret")
+	t.Setenv("ENVUTIL_TIMEOUT", "5s")
+
+	var c Collector
+	endpoint := Require[*url.URL](&c, "ENVUTIL_URL")
+	token := Require[string](&c, "ENVUTIL_TOKEN")
+	timeout := Optional(&c, "ENVUTIL_TIMEOUT", time.Second)
+	retries := Optional(&c, "ENVUTIL_RETRIES", 3)
+	require.NoError(t, c.Err())
+	assert.Equal(t, "https://example.com", endpoint.String())
+	assert.Equal(t, "secret", token)
+	assert.Equal(t, 5*time.Second, timeout)
+	assert.Equal(t, 3, retries)
+}
+
+func TestRequire_Errors(t *testing.T) {
+	t.Setenv("ENVUTIL_URL", "not a url")
+	t.Setenv("ENVUTIL_TIMEOUT", "soon")
+
+	var c Collector
+	assert.Nil(t, Require[*url.URL](&c, "ENVUTIL_URL"))
+	assert.Equal(t, "", Require[string](&c, "ENVUTIL_TOKEN"))
+	assert.Equal(t, time.Second, Optional(&c, "ENVUTIL_TIMEOUT", time.Second))
+
+	err := c.Err()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrMissing)
+	assert.EqualError(t, err, `following errors occurred: [`+
+		`invalid environment variable ENVUTIL_URL: must be an absolute URL, `+
+		`ENVUTIL_TOKEN: environment variable is not set, `+
+		`invalid environment variable ENVUTIL_TIMEOUT: must be a duration, e.g. "10s"]`)
+}
+
+func assertGet[T Value](t *testing.T, name string, def, want T) {
+	t.Helper()
+	got, err := Get(name, def)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
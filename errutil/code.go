@@ -0,0 +1,110 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// Code classifies an error by its cause, so callers can decide how to handle
+// it without inspecting error messages.
+type Code uint8
+
+const (
+	// CodeUnknown is returned by CodeOf for errors without a code.
+	CodeUnknown Code = iota
+
+	// CodeNotFound indicates that the requested resource does not exist.
+	CodeNotFound
+
+	// CodePermission indicates that access to the resource was denied.
+	CodePermission
+
+	// CodeTransient indicates a temporary failure, the operation may
+	// succeed if retried.
+	CodeTransient
+
+	// CodeIntegrity indicates that the data is corrupted or does not match
+	// the expected checksum.
+	CodeIntegrity
+
+	// CodeConfig indicates an invalid configuration or input, the operation
+	// will not succeed until it is fixed.
+	CodeConfig
+)
+
+// String implements the fmt.Stringer interface.
+func (c Code) String() string {
+	switch c {
+	case CodeNotFound:
+		return "not found"
+	case CodePermission:
+		return "permission"
+	case CodeTransient:
+		return "transient"
+	case CodeIntegrity:
+		return "integrity"
+	case CodeConfig:
+		return "config"
+	default:
+		return "unknown"
+	}
+}
+
+// WithCode tags the error with the given code. The returned error wraps the
+// original one and has the same message. If err is nil, WithCode returns nil.
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	return &codeError{err: err, code: code}
+}
+
+// CodeOf returns the code of the error. If the error chain contains multiple
+// codes, the outermost one is returned.
+//
+// Errors without a code that wrap fs.ErrNotExist or fs.ErrPermission are
+// classified as CodeNotFound and CodePermission respectively. For other
+// errors, CodeUnknown is returned.
+func CodeOf(err error) Code {
+	if err == nil {
+		return CodeUnknown
+	}
+	var c *codeError
+	if errors.As(err, &c) {
+		return c.code
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return CodeNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return CodePermission
+	}
+	return CodeUnknown
+}
+
+type codeError struct {
+	err  error
+	code Code
+}
+
+func (e *codeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codeError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	err := errors.New("error")
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{name: "nil", err: nil, want: CodeUnknown},
+		{name: "no code", err: err, want: CodeUnknown},
+		{name: "code", err: WithCode(err, CodeTransient), want: CodeTransient},
+		{name: "wrapped", err: fmt.Errorf("wrapped: %w", WithCode(err, CodeIntegrity)), want: CodeIntegrity},
+		{name: "outermost", err: WithCode(fmt.Errorf("wrapped: %w", WithCode(err, CodeIntegrity)), CodeConfig), want: CodeConfig},
+		{name: "multi error", err: Append(err, WithCode(err, CodePermission)), want: CodePermission},
+		{name: "not exist", err: fmt.Errorf("wrapped: %w", fs.ErrNotExist), want: CodeNotFound},
+		{name: "permission", err: fmt.Errorf("wrapped: %w", fs.ErrPermission), want: CodePermission},
+		{name: "code overrides fs error", err: WithCode(fs.ErrNotExist, CodeTransient), want: CodeTransient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CodeOf(tt.err))
+		})
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	assert.Nil(t, WithCode(nil, CodeConfig))
+
+	err := errors.New("error")
+	cErr := WithCode(err, CodeConfig)
+	assert.Equal(t, "error", cErr.Error())
+	assert.ErrorIs(t, cErr, err)
+}
+
+func TestCode_String(t *testing.T) {
+	assert.Equal(t, "unknown", CodeUnknown.String())
+	assert.Equal(t, "not found", CodeNotFound.String())
+	assert.Equal(t, "config", CodeConfig.String())
+	assert.Equal(t, "unknown", Code(255).String())
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollect(t *testing.T) {
+	err1 := errors.New("error1")
+	err2 := errors.New("error2")
+	tests := []struct {
+		name string
+		errs []error
+		want error
+	}{
+		{name: "empty", errs: nil, want: nil},
+		{name: "nil errors", errs: []error{nil, nil}, want: nil},
+		{name: "single error", errs: []error{nil, err1}, want: err1},
+		{name: "multiple errors", errs: []error{err1, nil, err2}, want: MultiError{err1, err2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan error)
+			go func() {
+				defer close(ch)
+				for _, err := range tt.errs {
+					ch <- err
+				}
+			}()
+			assert.Equal(t, tt.want, Collect(ch))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"fmt"
+	"io"
+)
+
+// RepeatedError represents an error that occurred multiple times. It is
+// created by MultiError.Dedup.
+type RepeatedError struct {
+	Err   error
+	Count int
+}
+
+// Error implements the error interface.
+func (e *RepeatedError) Error() string {
+	return fmt.Sprintf("%s (repeated %d times)", e.Err.Error(), e.Count)
+}
+
+// Unwrap returns the repeated error.
+func (e *RepeatedError) Unwrap() error {
+	return e.Err
+}
+
+// Format implements the fmt.Formatter interface. The %+v verb formats the
+// repeated error using %+v.
+func (e *RepeatedError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = fmt.Fprintf(s, "%+v (repeated %d times)", e.Err, e.Count)
+	case verb == 'v' || verb == 's':
+		_, _ = io.WriteString(s, e.Error())
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// Dedup returns a new MultiError in which errors with identical messages are
+// collapsed into a single RepeatedError. Only the first of the identical
+// errors is kept. The order of the errors is preserved.
+//
+// Errors that are already RepeatedError are merged with identical errors, and
+// their counts are summed.
+func (m MultiError) Dedup() MultiError {
+	if len(m) < 2 {
+		return m
+	}
+	type entry struct {
+		err   error
+		count int
+	}
+	var (
+		entries []*entry
+		index   = make(map[string]*entry, len(m))
+	)
+	for _, err := range m {
+		count := 1
+		// Using type casting instead of errors.As is intentional.
+		if r, ok := err.(*RepeatedError); ok {
+			err, count = r.Err, r.Count
+		}
+		msg := err.Error()
+		if e, ok := index[msg]; ok {
+			e.count += count
+			continue
+		}
+		e := &entry{err: err, count: count}
+		index[msg] = e
+		entries = append(entries, e)
+	}
+	res := make(MultiError, len(entries))
+	for i, e := range entries {
+		if e.count > 1 {
+			res[i] = &RepeatedError{Err: e.err, Count: e.count}
+		} else {
+			res[i] = e.err
+		}
+	}
+	return res
+}
+
+// AppendDedup works like Append, but identical errors are collapsed using
+// MultiError.Dedup.
+func AppendDedup(err error, errs ...error) error {
+	err = Append(err, errs...)
+	// Using type casting instead of errors.As is intentional.
+	if m, ok := err.(MultiError); ok {
+		if m = m.Dedup(); len(m) == 1 {
+			return m[0]
+		}
+		return m
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiError_Dedup(t *testing.T) {
+	err1 := errors.New("error1")
+	err2 := errors.New("error2")
+	tests := []struct {
+		name string
+		errs MultiError
+		want MultiError
+	}{
+		{
+			name: "empty",
+			errs: MultiError{},
+			want: MultiError{},
+		},
+		{
+			name: "unique",
+			errs: MultiError{err1, err2},
+			want: MultiError{err1, err2},
+		},
+		{
+			name: "repeated",
+			errs: MultiError{err1, err2, err1, errors.New("error1")},
+			want: MultiError{&RepeatedError{Err: err1, Count: 3}, err2},
+		},
+		{
+			name: "merge repeated",
+			errs: MultiError{&RepeatedError{Err: err1, Count: 2}, err2, err1},
+			want: MultiError{&RepeatedError{Err: err1, Count: 3}, err2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.errs.Dedup())
+		})
+	}
+}
+
+func TestAppendDedup(t *testing.T) {
+	var err error
+	for range 3 {
+		err = AppendDedup(err, context.DeadlineExceeded)
+	}
+	assert.EqualError(t, err, "context deadline exceeded (repeated 3 times)")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = AppendDedup(err, errors.New("error"))
+	assert.EqualError(t, err, "following errors occurred: [context deadline exceeded (repeated 3 times), error]")
+
+	assert.Nil(t, AppendDedup(nil))
+}
@@ -18,6 +18,7 @@ package errutil
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 )
 
@@ -50,14 +51,17 @@ func Join(vs ...any) (err error) {
 }
 
 // Append combines the provided error with a list of errors.
+//
+// Errors that are MultiError or implement the Unwrap() []error method, like
+// errors created by errors.Join, are flattened, so the result contains the
+// errors they hold rather than the errors themselves.
 func Append(err error, errs ...error) error {
 	if err == nil && len(errs) == 0 {
 		return nil
 	}
-	// Using type casting instead of errors.As is intentional.
 	var mErr MultiError
-	if e, ok := err.(MultiError); ok {
-		mErr = e
+	if es, ok := splitErrors(err); ok {
+		mErr = append(mErr, es...)
 	} else if err != nil {
 		mErr = MultiError{err}
 	}
@@ -65,8 +69,8 @@ func Append(err error, errs ...error) error {
 		if e == nil {
 			continue
 		}
-		if m, ok := e.(MultiError); ok {
-			mErr = append(mErr, m...)
+		if es, ok := splitErrors(e); ok {
+			mErr = append(mErr, es...)
 		} else {
 			mErr = append(mErr, e)
 		}
@@ -101,6 +105,28 @@ func (m MultiError) Error() string {
 	return b.String()
 }
 
+// Format implements the fmt.Formatter interface. The %+v verb prints every
+// error on its own indented line. Errors are formatted using %+v as well, so
+// stack traces are printed when present.
+func (m MultiError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		if len(m) == 0 {
+			return
+		}
+		_, _ = io.WriteString(s, "following errors occurred:")
+		for _, err := range m {
+			_, _ = io.WriteString(s, "\n  - ")
+			msg := strings.TrimRight(fmt.Sprintf("%+v", err), "\n")
+			_, _ = io.WriteString(s, strings.ReplaceAll(msg, "\n", "\n    "))
+		}
+	case verb == 'v' || verb == 's':
+		_, _ = io.WriteString(s, m.Error())
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", m.Error())
+	}
+}
+
 // Unwrap unwraps all errors.
 func (m MultiError) Unwrap() []error {
 	return m
@@ -112,6 +138,11 @@ func Ignore[T any](v T, _ error) T {
 	return v
 }
 
+// Ignore2 works like Ignore, but for functions that return two values.
+func Ignore2[T1, T2 any](v1 T1, v2 T2, _ error) (T1, T2) {
+	return v1, v2
+}
+
 // Must is a helper function that panics when the error is not nil. Otherwise,
 // it returns the first argument. It is intended for use with functions that
 // should never return an error when called.
@@ -122,6 +153,21 @@ func Must[T any](v T, err error) T {
 	return v
 }
 
+// Must0 works like Must, but for functions that return only an error.
+func Must0(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Must2 works like Must, but for functions that return two values.
+func Must2[T1, T2 any](v1 T1, v2 T2, err error) (T1, T2) {
+	if err != nil {
+		panic(err)
+	}
+	return v1, v2
+}
+
 // As is a helper function that attempts to extract a target type from the error
 // and returns it. It returns false if the error does not contain the target
 // type.
@@ -129,3 +175,26 @@ func As[T error](err error) (target T, ok bool) {
 	ok = errors.As(err, &target)
 	return
 }
+
+// First returns the first target type found in the provided errors, checking
+// them in order using errors.As. It returns false if none of the errors
+// contains the target type.
+func First[T error](errs ...error) (target T, ok bool) {
+	for _, err := range errs {
+		if target, ok = As[T](err); ok {
+			return target, true
+		}
+	}
+	return target, false
+}
+
+// AnyIs reports whether any error in the error chain matches any of the
+// targets, as reported by errors.Is.
+func AnyIs(err error, targets ...error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
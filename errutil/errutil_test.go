@@ -88,6 +88,24 @@ func TestAppend(t *testing.T) {
 		assert.Contains(t, result.(MultiError), err2)
 		assert.Len(t, result.(MultiError), 4) // It should have 4 errors since we appended the same multiError.
 	})
+
+	t.Run("append joined errors", func(t *testing.T) {
+		result := Append(errors.Join(err1, err2), errors.Join(err2, err1))
+		assert.Equal(t, MultiError{err1, err2, err2, err1}, result)
+	})
+
+	t.Run("append errors wrapped by fmt.Errorf", func(t *testing.T) {
+		wrapped := fmt.Errorf("%w: %w", err1, err2)
+		result := Append(err1, wrapped)
+		assert.Equal(t, MultiError{err1, err1, err2}, result)
+	})
+
+	t.Run("does not modify MultiError", func(t *testing.T) {
+		mErr := make(MultiError, 1, 2)
+		mErr[0] = err1
+		assert.Equal(t, MultiError{err1, err2}, Append(mErr, err2))
+		assert.Nil(t, mErr[:cap(mErr)][1])
+	})
 }
 
 func TestMultiError(t *testing.T) {
@@ -108,6 +126,24 @@ func TestMultiError(t *testing.T) {
 		multiErr := MultiError{err1, err2}
 		assert.Equal(t, "following errors occurred: [error1, error2]", multiErr.Error())
 	})
+
+	t.Run("Format", func(t *testing.T) {
+		multiErr := MultiError{err1, MultiError{err2, &PanicError{Value: "boom", Stack: []byte("stack1\nstack2\n")}}}
+		assert.Equal(t, multiErr.Error(), fmt.Sprintf("%v", multiErr))
+		assert.Equal(t, multiErr.Error(), fmt.Sprintf("%s", multiErr))
+		assert.Equal(t, fmt.Sprintf("%q", multiErr.Error()), fmt.Sprintf("%q", multiErr))
+		assert.Equal(t, ""+
+			"following errors occurred:\n"+
+			"  - error1\n"+
+			"  - following errors occurred:\n"+
+			"      - error2\n"+
+			"      - panic: boom\n"+
+			"        stack1\n"+
+			"        stack2",
+			fmt.Sprintf("%+v", multiErr),
+		)
+		assert.Empty(t, fmt.Sprintf("%+v", MultiError{}))
+	})
 }
 
 func TestIgnore(t *testing.T) {
@@ -166,6 +202,26 @@ func TestMust(t *testing.T) {
 	}
 }
 
+func TestIgnore2(t *testing.T) {
+	v1, v2 := Ignore2(1, "a", fmt.Errorf("error"))
+	assert.Equal(t, 1, v1)
+	assert.Equal(t, "a", v2)
+}
+
+func TestMust0(t *testing.T) {
+	assert.NotPanics(t, func() { Must0(nil) })
+	assert.Panics(t, func() { Must0(fmt.Errorf("error")) })
+}
+
+func TestMust2(t *testing.T) {
+	assert.NotPanics(t, func() {
+		v1, v2 := Must2(1, "a", nil)
+		assert.Equal(t, 1, v1)
+		assert.Equal(t, "a", v2)
+	})
+	assert.Panics(t, func() { Must2(1, "a", fmt.Errorf("error")) })
+}
+
 func TestAs(t *testing.T) {
 	t.Run("error contains target type", func(t *testing.T) {
 		err := fmt.Errorf("wrapped error: %w", testErr{})
@@ -195,3 +251,27 @@ func TestAs(t *testing.T) {
 type testErr struct{}
 
 func (e testErr) Error() string { return "test error" }
+
+func TestFirst(t *testing.T) {
+	pErr := &PanicError{Value: "boom"}
+	err1 := errors.New("error1")
+
+	target, ok := First[*PanicError](err1, fmt.Errorf("wrapped: %w", pErr), &PanicError{Value: "other"})
+	assert.True(t, ok)
+	assert.Same(t, pErr, target)
+
+	target, ok = First[*PanicError](err1, nil)
+	assert.False(t, ok)
+	assert.Nil(t, target)
+}
+
+func TestAnyIs(t *testing.T) {
+	err1 := errors.New("error1")
+	err2 := errors.New("error2")
+	err3 := errors.New("error3")
+
+	assert.True(t, AnyIs(fmt.Errorf("wrapped: %w", err2), err1, err2))
+	assert.True(t, AnyIs(errors.Join(err3, err1), err1))
+	assert.False(t, AnyIs(err3, err1, err2))
+	assert.False(t, AnyIs(err1))
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import "fmt"
+
+// badKey is used as a key for values without a key, it is the same as the
+// one used by the log/slog package.
+const badKey = "!BADKEY"
+
+// WithFields attaches key/value pairs to the error, so they can be extracted
+// later using the Fields function. The kv arguments are alternating keys and
+// values, keys that are not strings are converted using fmt.Sprint. A value
+// without a key is stored under the "!BADKEY" key.
+//
+// The returned error wraps the original one and has the same message. If err
+// is nil, WithFields returns nil.
+func WithFields(err error, kv ...any) error {
+	if err == nil {
+		return nil
+	}
+	e := &fieldsError{err: err}
+	for len(kv) > 0 {
+		if len(kv) == 1 {
+			e.fields = append(e.fields, field{key: badKey, value: kv[0]})
+			break
+		}
+		key, ok := kv[0].(string)
+		if !ok {
+			key = fmt.Sprint(kv[0])
+		}
+		e.fields = append(e.fields, field{key: key, value: kv[1]})
+		kv = kv[2:]
+	}
+	return e
+}
+
+// Fields returns all fields attached to the error chain using WithFields,
+// including errors in a MultiError. If the same key is used more than once,
+// the outermost value is returned, and for errors in a MultiError, the value
+// of the first error is returned. If there are no fields, nil is returned.
+func Fields(err error) map[string]any {
+	var m map[string]any
+	collectFields(err, &m)
+	return m
+}
+
+// collectFields adds the fields of err to m. Wrapped errors are visited
+// first, so values of outer errors take precedence.
+func collectFields(err error, m *map[string]any) {
+	switch e := err.(type) {
+	case nil:
+		return
+	case interface{ Unwrap() error }:
+		collectFields(e.Unwrap(), m)
+	case interface{ Unwrap() []error }:
+		errs := e.Unwrap()
+		for i := len(errs) - 1; i >= 0; i-- {
+			collectFields(errs[i], m)
+		}
+	}
+	if e, ok := err.(*fieldsError); ok && len(e.fields) > 0 {
+		if *m == nil {
+			*m = make(map[string]any, len(e.fields))
+		}
+		for _, f := range e.fields {
+			(*m)[f.key] = f.value
+		}
+	}
+}
+
+type field struct {
+	key   string
+	value any
+}
+
+type fieldsError struct {
+	err    error
+	fields []field
+}
+
+func (e *fieldsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fieldsError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields(t *testing.T) {
+	err := errors.New("error")
+	tests := []struct {
+		name string
+		err  error
+		want map[string]any
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "no fields",
+			err:  err,
+			want: nil,
+		},
+		{
+			name: "fields",
+			err:  WithFields(err, "uri", "ipfs://cid", "attempt", 2),
+			want: map[string]any{"uri": "ipfs://cid", "attempt": 2},
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("wrapped: %w", WithFields(err, "uri", "ipfs://cid")),
+			want: map[string]any{"uri": "ipfs://cid"},
+		},
+		{
+			name: "nested",
+			err:  WithFields(fmt.Errorf("wrapped: %w", WithFields(err, "uri", "ipfs://cid", "attempt", 1)), "attempt", 2),
+			want: map[string]any{"uri": "ipfs://cid", "attempt": 2},
+		},
+		{
+			name: "multi error",
+			err:  Append(WithFields(err, "gateway", "a"), WithFields(err, "gateway", "b", "attempt", 1)),
+			want: map[string]any{"gateway": "a", "attempt": 1},
+		},
+		{
+			name: "non-string key",
+			err:  WithFields(err, 1, "a"),
+			want: map[string]any{"1": "a"},
+		},
+		{
+			name: "missing value",
+			err:  WithFields(err, "uri", "ipfs://cid", "attempt"),
+			want: map[string]any{"uri": "ipfs://cid", "!BADKEY": "attempt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Fields(tt.err))
+		})
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	assert.Nil(t, WithFields(nil, "key", "value"))
+
+	err := errors.New("error")
+	fErr := WithFields(err, "key", "value")
+	assert.Equal(t, "error", fErr.Error())
+	assert.ErrorIs(t, fErr, err)
+}
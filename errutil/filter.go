@@ -0,0 +1,93 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import "errors"
+
+// FilterErrors returns an error containing only the errors for which keep
+// returns true. If err is a MultiError or implements the Unwrap() []error
+// method, like errors created by errors.Join, the filter is applied to
+// every error it contains, recursively. Otherwise, the filter is applied to
+// err itself.
+//
+// If no errors are kept, nil is returned.
+func FilterErrors(err error, keep func(error) bool) error {
+	return MapErrors(err, func(err error) error {
+		if keep(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// MapErrors returns an error in which every error is replaced with the result
+// of fn. If err is a MultiError or implements the Unwrap() []error method,
+// like errors created by errors.Join, fn is applied to every error it
+// contains, recursively. Otherwise, fn is applied to err itself. Errors for
+// which fn returns nil are removed.
+//
+// If no errors remain, nil is returned.
+func MapErrors(err error, fn func(error) error) error {
+	if err == nil {
+		return nil
+	}
+	errs, ok := splitErrors(err)
+	if !ok {
+		return fn(err)
+	}
+	var res []error
+	for _, e := range errs {
+		if e = MapErrors(e, fn); e != nil {
+			res = append(res, e)
+		}
+	}
+	switch {
+	case len(res) == 0:
+		return nil
+	case len(res) == 1:
+		return res[0]
+	case isMultiError(err):
+		return MultiError(res)
+	default:
+		return errors.Join(res...)
+	}
+}
+
+// splitErrors returns the errors contained in a MultiError or in an error
+// that implements the Unwrap() []error method, such as those created by
+// errors.Join or by fmt.Errorf with multiple %w verbs. Nil errors are
+// skipped.
+func splitErrors(err error) ([]error, bool) {
+	// Using type casting instead of errors.As is intentional.
+	if m, ok := err.(MultiError); ok {
+		return m, true
+	}
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil, false
+	}
+	var errs []error
+	for _, e := range u.Unwrap() {
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
+	return errs, len(errs) > 0
+}
+
+func isMultiError(err error) bool {
+	_, ok := err.(MultiError)
+	return ok
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterErrors(t *testing.T) {
+	err1 := errors.New("error1")
+	err2 := errors.New("error2")
+	notExist := fmt.Errorf("file: %w", fs.ErrNotExist)
+	keep := func(err error) bool { return !errors.Is(err, fs.ErrNotExist) }
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "single kept",
+			err:  err1,
+			want: err1,
+		},
+		{
+			name: "single removed",
+			err:  notExist,
+			want: nil,
+		},
+		{
+			name: "multi error",
+			err:  MultiError{err1, notExist, err2},
+			want: MultiError{err1, err2},
+		},
+		{
+			name: "multi error single left",
+			err:  MultiError{err1, notExist},
+			want: err1,
+		},
+		{
+			name: "multi error all removed",
+			err:  MultiError{notExist, notExist},
+			want: nil,
+		},
+		{
+			name: "nested",
+			err:  MultiError{err1, MultiError{notExist, err2}},
+			want: MultiError{err1, err2},
+		},
+		{
+			name: "join",
+			err:  errors.Join(err1, notExist, err2),
+			want: errors.Join(err1, err2),
+		},
+		{
+			name: "join in multi error",
+			err:  MultiError{notExist, errors.Join(err1, notExist)},
+			want: err1,
+		},
+		{
+			name: "fmt with multiple wraps",
+			err:  fmt.Errorf("%w: %w", err1, notExist),
+			want: err1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FilterErrors(tt.err, keep))
+		})
+	}
+}
+
+func TestMapErrors(t *testing.T) {
+	err1 := errors.New("error1")
+	err2 := errors.New("error2")
+	wrap := func(err error) error { return fmt.Errorf("wrapped: %w", err) }
+
+	err := MapErrors(MultiError{err1, errors.Join(err2, err1)}, wrap)
+	assert.EqualError(t, err, "following errors occurred: [wrapped: error1, wrapped: error2\nwrapped: error1]")
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+
+	assert.Nil(t, MapErrors(err1, func(error) error { return nil }))
+}
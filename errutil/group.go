@@ -0,0 +1,85 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs functions concurrently and collects all errors they return.
+//
+// Unlike errgroup.Group, Wait returns all errors, not only the first one.
+type Group struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup returns a new Group and a context derived from ctx. The context is
+// canceled when any function returns an error or when Wait returns.
+//
+// If limit is greater than zero, at most limit functions run concurrently.
+func NewGroup(ctx context.Context, limit int) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{cancel: cancel}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g, ctx
+}
+
+// Go calls fn in a new goroutine. If the concurrency limit is reached, Go
+// blocks until one of the running functions returns. Panics in fn are
+// recovered and reported as a PanicError.
+func (g *Group) Go(fn func() error) {
+	g.mu.Lock()
+	idx := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		var err error
+		defer func() {
+			if err == nil {
+				return
+			}
+			g.mu.Lock()
+			g.errs[idx] = err
+			g.mu.Unlock()
+			g.cancel()
+		}()
+		defer Recover(&err)
+		err = fn()
+	}()
+}
+
+// Wait blocks until all functions have returned and returns their errors
+// combined using Append, in the order in which the functions were added.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return Append(nil, g.errs...)
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("no errors", func(t *testing.T) {
+		g, ctx := NewGroup(context.Background(), 0)
+		for range 3 {
+			g.Go(func() error { return nil })
+		}
+		assert.NoError(t, g.Wait())
+		assert.Error(t, ctx.Err())
+	})
+	t.Run("all errors", func(t *testing.T) {
+		err1 := errors.New("error1")
+		err2 := errors.New("error2")
+		g, _ := NewGroup(context.Background(), 0)
+		g.Go(func() error {
+			time.Sleep(10 * time.Millisecond)
+			return err1
+		})
+		g.Go(func() error { return nil })
+		g.Go(func() error { return err2 })
+		err := g.Wait()
+		require.IsType(t, MultiError{}, err)
+		assert.Equal(t, MultiError{err1, err2}, err)
+	})
+	t.Run("cancel on error", func(t *testing.T) {
+		g, ctx := NewGroup(context.Background(), 0)
+		g.Go(func() error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		g.Go(func() error { return errors.New("error") })
+		err := g.Wait()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorContains(t, err, "error")
+	})
+	t.Run("panic", func(t *testing.T) {
+		g, _ := NewGroup(context.Background(), 0)
+		g.Go(func() error { panic("boom") })
+		var pErr *PanicError
+		assert.ErrorAs(t, g.Wait(), &pErr)
+	})
+	t.Run("limit", func(t *testing.T) {
+		var running, peak atomic.Int32
+		g, _ := NewGroup(context.Background(), 2)
+		for range 10 {
+			g.Go(func() error {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}
+		require.NoError(t, g.Wait())
+		assert.LessOrEqual(t, peak.Load(), int32(2))
+	})
+}
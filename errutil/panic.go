@@ -0,0 +1,88 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// PanicError is an error created from a recovered panic.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Format implements the fmt.Formatter interface. The %+v verb prints the
+// error message followed by the stack trace.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, e.Error())
+		_, _ = io.WriteString(s, "\n")
+		_, _ = s.Write(e.Stack)
+	case verb == 'v' || verb == 's':
+		_, _ = io.WriteString(s, e.Error())
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// Recover recovers from a panic and stores it in err as a PanicError. If err
+// already holds an error, both errors are combined using Append. It must be
+// called directly by defer:
+//
+//	func f() (err error) {
+//		defer errutil.Recover(&err)
+//		...
+//	}
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		*err = Append(*err, &PanicError{Value: r, Stack: debug.Stack()})
+	}
+}
+
+// SafeGo runs fn in a new goroutine. If fn returns an error or panics, onErr
+// is called with the error or a PanicError respectively. If onErr is nil,
+// errors are discarded.
+func SafeGo(fn func() error, onErr func(error)) {
+	go func() {
+		var err error
+		defer func() {
+			if err != nil && onErr != nil {
+				onErr(err)
+			}
+		}()
+		defer Recover(&err)
+		err = fn()
+	}()
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package errutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	errTest := errors.New("error")
+	tests := []struct {
+		name     string
+		fn       func() error
+		wantMsg  string
+		wantIs   error
+		wantNone bool
+	}{
+		{
+			name:     "no panic",
+			fn:       func() error { return nil },
+			wantNone: true,
+		},
+		{
+			name:    "panic with value",
+			fn:      func() error { panic("boom") },
+			wantMsg: "panic: boom",
+		},
+		{
+			name:    "panic with error",
+			fn:      func() error { panic(errTest) },
+			wantMsg: "panic: error",
+			wantIs:  errTest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer Recover(&err)
+				return tt.fn()
+			}()
+			if tt.wantNone {
+				assert.NoError(t, err)
+				return
+			}
+			var pErr *PanicError
+			require.ErrorAs(t, err, &pErr)
+			assert.Equal(t, tt.wantMsg, err.Error())
+			assert.NotEmpty(t, pErr.Stack)
+			assert.Contains(t, fmt.Sprintf("%+v", pErr), "goroutine")
+			assert.NotContains(t, fmt.Sprintf("%v", pErr), "goroutine")
+			if tt.wantIs != nil {
+				assert.ErrorIs(t, err, tt.wantIs)
+			}
+		})
+	}
+}
+
+func TestSafeGo(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		ch := make(chan error, 1)
+		SafeGo(func() error { return errors.New("error") }, func(err error) { ch <- err })
+		assert.EqualError(t, <-ch, "error")
+	})
+	t.Run("panic", func(t *testing.T) {
+		ch := make(chan error, 1)
+		SafeGo(func() error { panic("boom") }, func(err error) { ch <- err })
+		var pErr *PanicError
+		assert.ErrorAs(t, <-ch, &pErr)
+	})
+	t.Run("success", func(t *testing.T) {
+		ch := make(chan error, 1)
+		done := make(chan struct{})
+		SafeGo(func() error { defer close(done); return nil }, func(err error) { ch <- err })
+		<-done
+		select {
+		case err := <-ch:
+			t.Fatalf("unexpected error: %v", err)
+		default:
+		}
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package ethutil provides helpers for parsing and formatting Ethereum
+// addresses and hashes, using the go-eth types.Address and types.Hash types.
+package ethutil
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/defiweb/go-eth/types"
+	"golang.org/x/crypto/sha3"
+)
+
+// ParseAddress parses a hex encoded Ethereum address.
+//
+// The address must be a 20 bytes long hex string, optionally prefixed with
+// "0x". If the address contains both upper and lower case letters, it is
+// treated as checksummed, and the checksum must be valid.
+func ParseAddress(s string) (addr types.Address, err error) {
+	addr, err = ParseAddressUnchecked(s)
+	if err != nil {
+		return addr, err
+	}
+	if hexAddr := trimHexPrefix(s); isMixedCase(hexAddr) {
+		if checksummed := Checksum(addr); hexAddr != checksummed[2:] {
+			return addr, fmt.Errorf("invalid address %q: invalid checksum, expected %s", s, checksummed)
+		}
+	}
+	return addr, nil
+}
+
+// ParseAddressUnchecked works like ParseAddress, but it does not verify the
+// EIP-55 checksum of mixed case addresses. It should be used only where
+// addresses were accepted regardless of the letter case before.
+func ParseAddressUnchecked(s string) (addr types.Address, err error) {
+	hexAddr := trimHexPrefix(s)
+	if len(hexAddr) != types.AddressLength*2 {
+		return addr, fmt.Errorf("invalid address %q: must be %d bytes long", s, types.AddressLength)
+	}
+	if _, err := hex.Decode(addr[:], []byte(hexAddr)); err != nil {
+		return addr, fmt.Errorf("invalid address %q: must be a hex string", s)
+	}
+	return addr, nil
+}
+
+// MustParseAddress works like ParseAddress, but panics on error.
+func MustParseAddress(s string) types.Address {
+	addr, err := ParseAddress(s)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
+// ParseHash parses a hex encoded 32 bytes long hash, optionally prefixed
+// with "0x".
+func ParseHash(s string) (hash types.Hash, err error) {
+	hexHash := trimHexPrefix(s)
+	if len(hexHash) != types.HashLength*2 {
+		return hash, fmt.Errorf("invalid hash %q: must be %d bytes long", s, types.HashLength)
+	}
+	if _, err := hex.Decode(hash[:], []byte(hexHash)); err != nil {
+		return hash, fmt.Errorf("invalid hash %q: must be a hex string", s)
+	}
+	return hash, nil
+}
+
+// MustParseHash works like ParseHash, but panics on error.
+func MustParseHash(s string) types.Hash {
+	hash, err := ParseHash(s)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// Checksum returns the address in the EIP-55 checksummed form, prefixed
+// with "0x".
+func Checksum(addr types.Address) string {
+	b := []byte(hex.EncodeToString(addr[:]))
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b)
+	hash := hex.EncodeToString(h.Sum(nil))
+	for i, c := range b {
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(b)
+}
+
+func trimHexPrefix(s string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+}
+
+func isMixedCase(s string) bool {
+	return strings.ToLower(s) != s && strings.ToUpper(s) != s
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package ethutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{input: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", want: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{input: "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", want: "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"},
+		{input: "0XD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb", want: "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb"},
+		{input: "dbf03b407c01e7cd3cbea99509d93f8dddc8c6fb", want: "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"},
+		{
+			input:   "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d35A",
+			wantErr: `invalid address "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d35A": invalid checksum, expected 0xFb6916095CA1dF60bB79cE92Ce3Ea74c37C5D35a`,
+		},
+		{
+			input:   "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea",
+			wantErr: `invalid address "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea": must be 20 bytes long`,
+		},
+		{
+			input:   "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaxx",
+			wantErr: `invalid address "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaxx": must be a hex string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			addr, err := ParseAddress(tt.input)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Checksum(addr))
+		})
+	}
+}
+
+func TestParseAddressUnchecked(t *testing.T) {
+	addr, err := ParseAddressUnchecked("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d35A")
+	require.NoError(t, err)
+	assert.Equal(t, "0xFb6916095CA1dF60bB79cE92Ce3Ea74c37C5D35a", Checksum(addr))
+
+	_, err = ParseAddressUnchecked("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaxx")
+	require.EqualError(t, err, `invalid address "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaxx": must be a hex string`)
+}
+
+func TestParseHash(t *testing.T) {
+	const hash = "0x512227571b4b801d3bbe8f01e3b651e6c4462eb9780ee5b9fb9ea4fb6899a5c4"
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{input: hash},
+		{input: hash[2:]},
+		{input: "0x512227571B4B801D3BBE8F01E3B651E6C4462EB9780EE5B9FB9EA4FB6899A5C4"},
+		{input: "0x1234", wantErr: `invalid hash "0x1234": must be 32 bytes long`},
+		{input: hash[:64] + "zz", wantErr: `invalid hash "` + hash[:64] + `zz": must be a hex string`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			h, err := ParseHash(tt.input)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, hash, h.String())
+		})
+	}
+}
@@ -16,6 +16,7 @@
 package fsutil
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
@@ -24,6 +25,12 @@ import (
 	netURL "net/url"
 	"os"
 	"path"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
 )
 
 type CacheFSOption func(*cacheFS)
@@ -43,6 +50,39 @@ func WithCacheNamespace(ns string) CacheFSOption {
 	}
 }
 
+// WithCacheTTL sets the time after which cached files expire and are read
+// again from the underlying file system. By default, cached files never
+// expire.
+func WithCacheTTL(ttl time.Duration) CacheFSOption {
+	return func(c *cacheFS) {
+		c.ttl = ttl
+	}
+}
+
+// WithCacheClock sets the clock used to check whether cached files have
+// expired. It is intended for tests, see timeutil.Fake.
+func WithCacheClock(clock timeutil.Clock) CacheFSOption {
+	return func(c *cacheFS) {
+		c.clock = clock
+	}
+}
+
+// WithCacheLogger sets the logger used to log cache hits and misses.
+func WithCacheLogger(logger logutil.Logger) CacheFSOption {
+	return func(c *cacheFS) {
+		c.logger = logger
+	}
+}
+
+// WithCacheMaxBytes sets the maximum size of cached files. Larger files are
+// read from the underlying file system every time. By default, the size is
+// not limited.
+func WithCacheMaxBytes(limit bytesize.Size) CacheFSOption {
+	return func(c *cacheFS) {
+		c.maxBytes = limit
+	}
+}
+
 func withCacheURL(url *netURL.URL) CacheFSOption {
 	return func(c *cacheFS) {
 		if url == nil {
@@ -92,6 +132,8 @@ func NewCacheFS(fs fs.FS, opts ...CacheFSOption) (fs.FS, error) {
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.clock = timeutil.OrReal(c.clock)
+	c.logger = logutil.OrNop(c.logger)
 	if c.dir == "" {
 		dir, err := os.UserCacheDir()
 		if err != nil {
@@ -106,9 +148,13 @@ func NewCacheFS(fs fs.FS, opts ...CacheFSOption) (fs.FS, error) {
 }
 
 type cacheFS struct {
-	fs  fs.FS
-	dir string
-	ns  string
+	fs       fs.FS
+	dir      string
+	ns       string
+	ttl      time.Duration
+	maxBytes bytesize.Size
+	clock    timeutil.Clock
+	logger   logutil.Logger
 }
 
 // Open implements the fs.Open interface.
@@ -117,16 +163,27 @@ func (c *cacheFS) Open(name string) (fs.File, error) {
 		return nil, errCacheFSFn(err)
 	}
 	if f, err := c.cacheOpen(name); err == nil {
+		c.logger.Debug("Cache hit", "name", redactName(name))
 		return f, nil
 	}
+	c.logger.Debug("Cache miss", "name", redactName(name))
 	f, err := c.fs.Open(name)
 	if err != nil {
 		return nil, errCacheFSFn(err)
 	}
-	b, err := io.ReadAll(f)
+	var r io.Reader = f
+	if c.maxBytes > 0 {
+		// Read one byte more than allowed to detect if the limit is exceeded.
+		r = io.LimitReader(f, int64(c.maxBytes)+1)
+	}
+	b, err := io.ReadAll(r)
 	if err != nil {
+		_ = f.Close()
 		return nil, errCacheFSFn(err)
 	}
+	if c.tooLarge(name, b) {
+		return &bufferedFile{File: f, r: io.MultiReader(bytes.NewReader(b), f)}, nil
+	}
 	if err := f.Close(); err != nil {
 		return nil, errCacheFSFn(err)
 	}
@@ -162,16 +219,21 @@ func (c *cacheFS) ReadFile(name string) ([]byte, error) {
 		return nil, errCacheFSFn(err)
 	}
 	if f, err := c.cacheOpen(name); err == nil {
+		c.logger.Debug("Cache hit", "name", redactName(name))
 		b, err := io.ReadAll(f)
 		if err != nil {
 			return nil, errCacheFSFn(err)
 		}
 		return b, nil
 	}
+	c.logger.Debug("Cache miss", "name", redactName(name))
 	b, err := fs.ReadFile(c.fs, name)
 	if err != nil {
 		return nil, errCacheFSFn(err)
 	}
+	if c.tooLarge(name, b) {
+		return b, nil
+	}
 	if err := c.cacheWrite(name, b); err != nil {
 		return nil, errCacheFSFn(err)
 	}
@@ -198,12 +260,34 @@ func (c *cacheFS) Sub(name string) (fs.FS, error) {
 	return fs.Sub(c.fs, name)
 }
 
-// cacheOpen opens a file in the cache directory.
+// tooLarge reports whether the file content exceeds the size limit and
+// must not be cached.
+func (c *cacheFS) tooLarge(name string, b []byte) bool {
+	if c.maxBytes <= 0 || int64(len(b)) <= int64(c.maxBytes) {
+		return false
+	}
+	c.logger.Debug("File too large to cache", "name", redactName(name), "limit", c.maxBytes)
+	return true
+}
+
+// cacheOpen opens a file in the cache directory. Expired files are treated
+// as missing.
 func (c *cacheFS) cacheOpen(name string) (fs.File, error) {
 	f, err := os.Open(c.cachePath(name))
 	if err != nil {
 		return nil, err
 	}
+	if c.ttl > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if c.clock.Now().Sub(info.ModTime()) >= c.ttl {
+			f.Close()
+			return nil, fs.ErrNotExist
+		}
+	}
 	return f, nil
 }
 
@@ -212,14 +296,14 @@ func (c *cacheFS) cacheRead(name string) ([]byte, error) {
 	return os.ReadFile(c.cachePath(name))
 }
 
+// cacheWrite writes a file to the cache directory. The modification time of
+// the file is set using the clock, so it can be compared with the TTL.
 func (c *cacheFS) cacheWrite(name string, content []byte) error {
-	f, err := os.Create(c.cachePath(name))
-	if err != nil {
+	if err := os.WriteFile(c.cachePath(name), content, 0666); err != nil {
 		return err
 	}
-	defer f.Close()
-	_, err = f.Write(content)
-	return err
+	now := c.clock.Now()
+	return os.Chtimes(c.cachePath(name), now, now)
 }
 
 func (c *cacheFS) cachePath(name string) string {
@@ -230,7 +314,18 @@ func (c *cacheFS) cachePath(name string) string {
 	return path.Join(c.dir, hex.EncodeToString(hash.Sum(nil)))
 }
 
-var errCacheProtoNilURI = fmt.Errorf("fsutil.cacheProto: nil URI")
+// bufferedFile is a file whose beginning has already been read into
+// a buffer. Reads return the buffered data first.
+type bufferedFile struct {
+	fs.File
+	r io.Reader
+}
+
+func (f *bufferedFile) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+var errCacheProtoNilURI = errutil.WithCode(fmt.Errorf("fsutil.cacheProto: nil URI"), errutil.CodeConfig)
 
 func errCacheProtoFn(err error) error {
 	return fmt.Errorf("fsutil.cacheProto: %w", err)
@@ -21,9 +21,12 @@ import (
 	"os"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/timeutil"
 )
 
 func TestCacheProto(t *testing.T) {
@@ -144,3 +147,66 @@ func TestCacheFS(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheFS_TTL(t *testing.T) {
+	clock := timeutil.NewFake(time.Now())
+	testFS := fstest.MapFS{"file.txt": &fstest.MapFile{Data: []byte("data")}}
+	cacheFS, err := NewCacheFS(testFS, WithCacheDir(t.TempDir()), WithCacheTTL(time.Minute), WithCacheClock(clock))
+	require.NoError(t, err)
+
+	data, err := fs.ReadFile(cacheFS, "file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	// The file is read from the cache until it expires.
+	testFS["file.txt"].Data = []byte("new data")
+	clock.Advance(59 * time.Second)
+	data, err = fs.ReadFile(cacheFS, "file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	// After expiration, the file is read from the source again.
+	clock.Advance(time.Second)
+	data, err = fs.ReadFile(cacheFS, "file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "new data", string(data))
+
+	f, err := cacheFS.Open("file.txt")
+	require.NoError(t, err)
+	data, err = io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, "new data", string(data))
+}
+
+func TestCacheFS_MaxBytes(t *testing.T) {
+	testFS := fstest.MapFS{
+		"small.txt": &fstest.MapFile{Data: []byte("data")},
+		"large.txt": &fstest.MapFile{Data: []byte("large data")},
+	}
+	cacheFS, err := NewCacheFS(testFS, WithCacheDir(t.TempDir()), WithCacheMaxBytes(4))
+	require.NoError(t, err)
+
+	for _, name := range []string{"small.txt", "large.txt"} {
+		data, err := fs.ReadFile(cacheFS, name)
+		require.NoError(t, err)
+		assert.Equal(t, string(testFS[name].Data), string(data))
+
+		f, err := cacheFS.Open(name)
+		require.NoError(t, err)
+		data, err = io.ReadAll(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		assert.Equal(t, string(testFS[name].Data), string(data))
+	}
+
+	// Only the small file is read from the cache.
+	testFS["small.txt"].Data = []byte("new")
+	testFS["large.txt"].Data = []byte("new large data")
+	data, err := fs.ReadFile(cacheFS, "small.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+	data, err = fs.ReadFile(cacheFS, "large.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "new large data", string(data))
+}
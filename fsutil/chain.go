@@ -18,11 +18,11 @@ package fsutil
 import (
 	"fmt"
 	"io/fs"
-	"math/rand/v2"
 	netURL "net/url"
 	"strings"
 
 	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/logutil"
 	"github.com/chronicleprotocol/go-lib/sliceutil"
 )
 
@@ -42,6 +42,14 @@ func WithChainRandOrder() ChainFSOption {
 	}
 }
 
+// WithChainLogger sets the logger used to log failures of the chained file
+// systems that cause the next file system to be tried.
+func WithChainLogger(logger logutil.Logger) ChainFSOption {
+	return func(c *chainFS) {
+		c.logger = logger
+	}
+}
+
 // NewChainProto creates a new chain protocol.
 func NewChainProto(opts ...ChainFSOption) Protocol {
 	return &chainProto{opts: opts}
@@ -73,8 +81,9 @@ func NewChainFS(opts ...ChainFSOption) fs.FS {
 }
 
 type chainFS struct {
-	fs   []fs.FS
-	rand bool
+	fs     []fs.FS
+	rand   bool
+	logger logutil.Logger
 }
 
 // Open implements the fs.Open interface.
@@ -88,6 +97,7 @@ func (c *chainFS) Open(name string) (fs.File, error) {
 		if fErr == nil {
 			return f, nil
 		}
+		c.logFailure("open", name, i, fErr)
 		err = errutil.Append(err, fErr)
 	}
 	return nil, errChainFSFn(err)
@@ -119,6 +129,7 @@ func (c *chainFS) Stat(name string) (fs.FileInfo, error) {
 		if fErr == nil {
 			return f, nil
 		}
+		c.logFailure("stat", name, i, fErr)
 		err = errutil.Append(err, fErr)
 	}
 	return nil, errChainFSFn(err)
@@ -135,6 +146,7 @@ func (c *chainFS) ReadFile(name string) ([]byte, error) {
 		if fErr == nil {
 			return f, nil
 		}
+		c.logFailure("readFile", name, i, fErr)
 		err = errutil.Append(err, fErr)
 	}
 	return nil, errChainFSFn(err)
@@ -148,6 +160,7 @@ func (c *chainFS) ReadDir(name string) (s []fs.DirEntry, err error) {
 	for i := range c.iter() {
 		f, fErr := fs.ReadDir(c.fs[i], name)
 		if fErr != nil {
+			c.logFailure("readDir", name, i, fErr)
 			err = errutil.Append(err, fErr)
 			continue
 		}
@@ -172,23 +185,29 @@ func (c *chainFS) Sub(name string) (fs.FS, error) {
 		if fErr == nil {
 			return f, nil
 		}
+		c.logFailure("sub", name, i, fErr)
 		err = errutil.Append(err, fErr)
 	}
 	return nil, errChainFSFn(err)
 }
 
+// logFailure logs a failure of the i-th file system.
+func (c *chainFS) logFailure(op, name string, i int, err error) {
+	logutil.OrNop(c.logger).Debug("Chained file system failed", "op", op, "name", redactName(name), "index", i, "error", err)
+}
+
 func (c *chainFS) iter() []int {
-	if c.rand {
-		return rand.Perm(len(c.fs))
-	}
 	i := make([]int, len(c.fs))
 	for n := range c.fs {
 		i[n] = n
 	}
+	if c.rand {
+		return sliceutil.Shuffle(i, nil)
+	}
 	return i
 }
 
-var errChainProtoNilURI = fmt.Errorf("fsutil.chainProto: nil URI")
+var errChainProtoNilURI = errutil.WithCode(fmt.Errorf("fsutil.chainProto: nil URI"), errutil.CodeConfig)
 
 func errChainFSFn(err error) error {
 	return fmt.Errorf("fsutil.chainFS: %w", err)
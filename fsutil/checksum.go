@@ -17,6 +17,7 @@ package fsutil
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"hash"
@@ -25,6 +26,9 @@ import (
 	netURL "net/url"
 	"strings"
 
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/hashutil"
 	"github.com/defiweb/go-eth/types"
 	"golang.org/x/crypto/sha3"
 )
@@ -37,7 +41,9 @@ const (
 	ChecksumFSVerifyAfterRead ChecksumFSVerifyMode = iota
 
 	// ChecksumFSVerifyAfterOpen verifies the checksum immediately after
-	// opening the file.
+	// opening the file. The file is read into memory to compute the
+	// checksum, and the buffered data is returned as the file contents, so
+	// the file is read from the underlying file system only once.
 	ChecksumFSVerifyAfterOpen
 )
 
@@ -72,6 +78,16 @@ func WithChecksumVerifyMode(mode ChecksumFSVerifyMode) ChecksumFSOption {
 	}
 }
 
+// WithChecksumMaxBytes sets the maximum size of files read into memory in
+// the ChecksumFSVerifyAfterOpen mode. Reading a larger file fails with
+// ErrFileTooLarge, so a misbehaving source cannot make the file system
+// buffer an unbounded amount of data. By default, the size is not limited.
+func WithChecksumMaxBytes(limit bytesize.Size) ChecksumFSOption {
+	return func(c *checksumFS) {
+		c.maxBytes = limit
+	}
+}
+
 type checksumProto struct {
 	proto Protocol
 	opts  []ChecksumFSOption
@@ -100,8 +116,15 @@ func (c *checksumProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err
 // be provided in the file name as a query parameter, e.g.,
 // "file?checksum=0x1234...".
 //
+// The checksum may also be given in the Subresource Integrity format, e.g.,
+// "file?checksum=sha256-<base64 digest>", in which case the hash function
+// is selected by the digest instead of the WithChecksumHash option. The
+// "+" characters in the digest may be left unescaped.
+//
 // If the checksum does not match, the file system returns an error when
-// reading the file.
+// reading the file. If the parameter is present but is not a valid
+// checksum, e.g. it is empty, malformed or the zero hash, the file is not
+// read at all, so a mistyped checksum cannot disable the verification.
 func NewChecksumFS(fs fs.FS, opts ...ChecksumFSOption) (fs.FS, error) {
 	c := &checksumFS{fs: fs}
 	for _, opt := range opts {
@@ -120,40 +143,39 @@ func NewChecksumFS(fs fs.FS, opts ...ChecksumFSOption) (fs.FS, error) {
 }
 
 type checksumFS struct {
-	fs    fs.FS
-	hash  func() hash.Hash
-	param string
-	mode  ChecksumFSVerifyMode
+	fs       fs.FS
+	hash     func() hash.Hash
+	param    string
+	mode     ChecksumFSVerifyMode
+	maxBytes bytesize.Size
 }
 
 func (c *checksumFS) Open(name string) (fs.File, error) {
 	if err := validPath("open", name); err != nil {
 		return nil, errChecksumFSFn(err)
 	}
-	name, hash := c.checksumParam(name)
+	name, sum, err := c.checksumParam(name)
+	if err != nil {
+		return nil, errChecksumFSFn(err)
+	}
 	f, err := c.fs.Open(name)
 	if err != nil {
 		return nil, errChecksumFSFn(err)
 	}
-	if hash == types.ZeroHash {
+	if sum == nil {
 		return f, nil
 	}
 	switch c.mode {
 	case ChecksumFSVerifyAfterRead:
-		return checksumFile{file: f, checksum: hash, hash: c.hash()}, nil
+		return newChecksumFile(f, sum), nil
 	case ChecksumFSVerifyAfterOpen:
-		stat, err := f.Stat()
-		if err != nil {
-			return nil, errChecksumFSFn(err)
-		}
-		cfile := checksumFile{file: f, checksum: hash, hash: c.hash()}
-		data, err := io.ReadAll(cfile)
+		data, info, err := c.readVerified(f, sum)
 		if err != nil {
 			return nil, errChecksumFSFn(err)
 		}
 		return &file{
 			reader: io.NopCloser(bytes.NewReader(data)),
-			info:   stat,
+			info:   info,
 		}, nil
 	default:
 		return nil, errChecksumFSUnsupportedMode
@@ -165,7 +187,7 @@ func (c *checksumFS) Glob(pattern string) ([]string, error) {
 	if err := validPattern("glob", pattern); err != nil {
 		return nil, errChecksumFSFn(err)
 	}
-	return fs.Glob(c, pattern)
+	return fs.Glob(c.fs, pattern)
 }
 
 // Stat implements the fs.FS interface.
@@ -173,7 +195,7 @@ func (c *checksumFS) Stat(name string) (fs.FileInfo, error) {
 	if err := validPath("stat", name); err != nil {
 		return nil, errChecksumFSFn(err)
 	}
-	return fs.Stat(c, name)
+	return fs.Stat(c.fs, name)
 }
 
 // ReadFile implements the fs.ReadFileFS interface.
@@ -181,11 +203,28 @@ func (c *checksumFS) ReadFile(name string) ([]byte, error) {
 	if err := validPath("readFile", name); err != nil {
 		return nil, errChecksumFSFn(err)
 	}
-	f, err := c.Open(name)
+	name, sum, err := c.checksumParam(name)
 	if err != nil {
-		return nil, err
+		return nil, errChecksumFSFn(err)
+	}
+	if sum == nil {
+		b, err := fs.ReadFile(c.fs, name)
+		if err != nil {
+			return nil, errChecksumFSFn(err)
+		}
+		return b, nil
+	}
+	f, err := c.fs.Open(name)
+	if err != nil {
+		return nil, errChecksumFSFn(err)
 	}
-	return io.ReadAll(f)
+	// The file is read in both modes, so the data read for verification
+	// is returned directly, without copying it into another buffer.
+	data, _, err := c.readVerified(f, sum)
+	if err != nil {
+		return nil, errChecksumFSFn(err)
+	}
+	return data, nil
 }
 
 // ReadDir implements the fs.ReadDirFS interface.
@@ -193,29 +232,100 @@ func (c *checksumFS) ReadDir(name string) ([]fs.DirEntry, error) {
 	if err := validPath("readDir", name); err != nil {
 		return nil, errChecksumFSFn(err)
 	}
-	return fs.ReadDir(c, name)
+	return fs.ReadDir(c.fs, name)
+}
+
+// readVerified reads the whole file, verifies its checksum and closes it.
+// The file is stat-ed only after successful verification, because the
+// result is not needed if the checksum does not match.
+//
+// If the size limit is set, at most limit+1 bytes are read, so a failed
+// attempt does not download more data than a successful one would.
+func (c *checksumFS) readVerified(f fs.File, sum *checksum) ([]byte, fs.FileInfo, error) {
+	defer f.Close()
+	var r io.Reader = newChecksumFile(f, sum)
+	if c.maxBytes > 0 {
+		r = newMaxBytesReader(io.NopCloser(r), int64(c.maxBytes))
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, info, nil
+}
+
+// checksum is the expected checksum of a file together with the hash
+// function used to compute it.
+type checksum struct {
+	sum  []byte
+	hash func() hash.Hash
 }
 
 // checksumParam extracts the checksum value from the file name and returns the
-// file name without the checksum parameter.
-func (c *checksumFS) checksumParam(name string) (string, types.Hash) {
+// file name without the checksum parameter. If the name does not contain the
+// checksum parameter, the returned checksum is nil. If the parameter is
+// present but invalid, an error is returned.
+func (c *checksumFS) checksumParam(name string) (string, *checksum, error) {
 	q := strings.Index(name, "?")
 	if q == -1 {
-		return name, types.ZeroHash
+		return name, nil, nil
 	}
 	v, err := netURL.ParseQuery(name[q+1:])
-	if err != nil {
-		return name, types.ZeroHash
+	if err != nil && hasQueryKey(name[q+1:], c.param) {
+		// Pairs that cannot be decoded are skipped by the parser, so
+		// the parameter would be silently ignored.
+		return "", nil, errChecksumFSInvalidChecksumFn(c.param, err)
 	}
-	h, err := types.HashFromHex(v.Get(c.param), types.PadNone)
+	if !v.Has(c.param) {
+		return name, nil, nil
+	}
+	sum, err := c.parseChecksum(v.Get(c.param))
 	if err != nil {
-		return name, types.ZeroHash
+		return "", nil, errChecksumFSInvalidChecksumFn(c.param, err)
 	}
 	v.Del(c.param)
 	if len(v) == 0 {
-		return name[:q], h
+		return name[:q], sum, nil
 	}
-	return name[:q] + "?" + v.Encode(), h
+	return name[:q] + "?" + v.Encode(), sum, nil
+}
+
+// hasQueryKey reports whether the raw query contains the given key,
+// without decoding it.
+func hasQueryKey(query, key string) bool {
+	for pair := range strings.SplitSeq(query, "&") {
+		if k, _, _ := strings.Cut(pair, "="); k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// parseChecksum parses a checksum given either as a hex encoded hash or as
+// an SRI digest. The zero hash is rejected, as it is never a real checksum.
+func (c *checksumFS) parseChecksum(s string) (*checksum, error) {
+	if h, err := types.HashFromHex(s, types.PadNone); err == nil {
+		if h == types.ZeroHash {
+			return nil, errChecksumFSZeroHash
+		}
+		return &checksum{sum: h.Bytes(), hash: c.hash}, nil
+	}
+	// The query parser decodes unescaped "+" characters as spaces. Spaces
+	// never appear in a single SRI digest, so they can be restored.
+	d, err := hashutil.ParseDigest(strings.ReplaceAll(s, " ", "+"))
+	if err != nil {
+		if !strings.Contains(s, "-") {
+			// The value does not look like an SRI digest, so the error
+			// about the digest format would be misleading.
+			return nil, errChecksumFSMalformed
+		}
+		return nil, err
+	}
+	return &checksum{sum: d.Sum, hash: d.Algorithm.New}, nil
 }
 
 // checksumFile computes the checksum of the file contents and
@@ -224,8 +334,16 @@ func (c *checksumFS) checksumParam(name string) (string, types.Hash) {
 // the read operation is complete.
 type checksumFile struct {
 	file     fs.File
-	hash     hash.Hash
-	checksum types.Hash
+	hasher   *hashutil.TeeHasher
+	checksum []byte
+}
+
+func newChecksumFile(f fs.File, sum *checksum) checksumFile {
+	return checksumFile{
+		file:     f,
+		hasher:   hashutil.NewTeeHasher(f, sum.hash()),
+		checksum: sum.sum,
+	}
 }
 
 // Stat implements the fs.File interface.
@@ -235,17 +353,18 @@ func (c checksumFile) Stat() (fs.FileInfo, error) {
 
 // Read implements the fs.File interface.
 func (c checksumFile) Read(b []byte) (int, error) {
-	n, err := c.file.Read(b)
+	n, err := c.hasher.Read(b)
 	if errors.Is(err, io.EOF) {
-		if c.checksum != c.calcChecksum() {
+		// Readers may return the last chunk of data together with io.EOF,
+		// so the checksum must be verified after hashing it.
+		if subtle.ConstantTimeCompare(c.checksum, c.hasher.Sum(0)) != 1 {
 			return 0, errChecksumFSMismatch
 		}
-		return 0, io.EOF
+		return n, io.EOF
 	}
 	if err != nil {
 		return 0, err
 	}
-	c.hash.Write(b[:n])
 	return n, nil
 }
 
@@ -254,14 +373,12 @@ func (c checksumFile) Close() error {
 	return c.file.Close()
 }
 
-func (c checksumFile) calcChecksum() types.Hash {
-	return types.Hash(c.hash.Sum(nil))
-}
-
 var (
-	errChecksumProtoNilURI       = errors.New("fsutil.checksumProto: nil URI")
-	errChecksumFSUnsupportedMode = errors.New("fsutil.checksumFS: unsupported verify mode")
-	errChecksumFSMismatch        = errors.New("fsutil.checksumFS: checksum mismatch")
+	errChecksumProtoNilURI       = errutil.WithCode(errors.New("fsutil.checksumProto: nil URI"), errutil.CodeConfig)
+	errChecksumFSUnsupportedMode = errutil.WithCode(errors.New("fsutil.checksumFS: unsupported verify mode"), errutil.CodeConfig)
+	errChecksumFSMismatch        = errutil.WithCode(errors.New("fsutil.checksumFS: checksum mismatch"), errutil.CodeIntegrity)
+	errChecksumFSZeroHash        = errors.New("zero hash")
+	errChecksumFSMalformed       = errors.New("expected a hex encoded hash or an SRI digest")
 )
 
 func errChecksumProtoFn(err error) error {
@@ -271,3 +388,7 @@ func errChecksumProtoFn(err error) error {
 func errChecksumFSFn(err error) error {
 	return fmt.Errorf("fsutil.checksumFS: %w", err)
 }
+
+func errChecksumFSInvalidChecksumFn(param string, err error) error {
+	return errutil.WithCode(fmt.Errorf("invalid %s parameter: %w", param, err), errutil.CodeConfig)
+}
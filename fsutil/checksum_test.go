@@ -18,6 +18,8 @@ package fsutil
 import (
 	"io"
 	"io/fs"
+	netURL "net/url"
+	"strings"
 	"testing"
 	"testing/fstest"
 
@@ -25,11 +27,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/sha3"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/hashutil"
 )
 
 func TestChecksumFS(t *testing.T) {
 	testFS := fstest.MapFS{
 		"file.txt": &fstest.MapFile{Data: []byte("data")},
+		"foo.txt":  &fstest.MapFile{Data: []byte("foo")},
 	}
 	tc := []struct {
 		name      string
@@ -61,6 +68,27 @@ func TestChecksumFS(t *testing.T) {
 			file:    "file.txt?checksum=" + calculateKeccak256([]byte("data2")).String(),
 			wantErr: true,
 		},
+		{
+			name:     "open - with SRI checksum",
+			method:   "Open",
+			fs:       testFS,
+			file:     "file.txt?checksum=" + sriDigest(hashutil.SHA384, []byte("data")),
+			wantData: "data",
+		},
+		{
+			name:     "open - with unescaped SRI checksum",
+			method:   "Open",
+			fs:       testFS,
+			file:     "foo.txt?checksum=sha256-LCa0a2j/xo/5m0U8HTBBNBNCLXBkg7+g+YpeiGJm564=",
+			wantData: "foo",
+		},
+		{
+			name:    "open - with SRI checksum mismatch",
+			method:  "Open",
+			fs:      testFS,
+			file:    "file.txt?checksum=" + sriDigest(hashutil.SHA256, []byte("data2")),
+			wantErr: true,
+		},
 		{
 			name:     "readFile - without checksum",
 			method:   "ReadFile",
@@ -82,6 +110,20 @@ func TestChecksumFS(t *testing.T) {
 			file:    "file.txt?checksum=" + calculateKeccak256([]byte("data2")).String(),
 			wantErr: true,
 		},
+		{
+			name:     "readFile - with SRI checksum",
+			method:   "ReadFile",
+			fs:       testFS,
+			file:     "file.txt?checksum=" + sriDigest(hashutil.SHA512, []byte("data")),
+			wantData: "data",
+		},
+		{
+			name:    "readFile - with SRI checksum mismatch",
+			method:  "ReadFile",
+			fs:      testFS,
+			file:    "file.txt?checksum=" + sriDigest(hashutil.SHA512, []byte("data2")),
+			wantErr: true,
+		},
 	}
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
@@ -112,8 +154,144 @@ func TestChecksumFS(t *testing.T) {
 	}
 }
 
+func TestChecksumFS_InvalidChecksum(t *testing.T) {
+	testFS := fstest.MapFS{"file.txt": &fstest.MapFile{Data: []byte("data")}}
+	tc := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{name: "zero hash", file: "file.txt?checksum=0x" + strings.Repeat("0", 64), wantErr: "zero hash"},
+		{name: "malformed", file: "file.txt?checksum=bogus", wantErr: "expected a hex encoded hash or an SRI digest"},
+		{name: "short hash", file: "file.txt?checksum=0x1234", wantErr: "expected a hex encoded hash or an SRI digest"},
+		{name: "malformed SRI", file: "file.txt?checksum=sha256-AAAA", wantErr: "invalid SRI digest"},
+		{name: "empty", file: "file.txt?checksum=", wantErr: "invalid checksum parameter"},
+		{name: "malformed query", file: "file.txt?checksum=0x%zz", wantErr: "invalid checksum parameter"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			checksumFS, err := NewChecksumFS(testFS)
+			require.NoError(t, err)
+
+			_, err = checksumFS.Open(tt.file)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+
+			_, err = fs.ReadFile(checksumFS, tt.file)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+		})
+	}
+}
+
+func sriDigest(alg hashutil.Algorithm, data []byte) string {
+	d, err := hashutil.Sum(alg, data)
+	if err != nil {
+		panic(err)
+	}
+	return netURL.QueryEscape(d.String())
+}
+
 func calculateKeccak256(data []byte) types.Hash {
 	h := sha3.NewLegacyKeccak256()
 	h.Write(data)
 	return types.Hash(h.Sum(nil))
 }
+
+func TestChecksumFS_GlobStatReadDir(t *testing.T) {
+	cfs, err := NewChecksumFS(fstest.MapFS{
+		"dir/file.txt": &fstest.MapFile{Data: []byte("data")},
+	})
+	require.NoError(t, err)
+
+	matches, err := fs.Glob(cfs, "dir/*.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dir/file.txt"}, matches)
+
+	info, err := fs.Stat(cfs, "dir/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), info.Size())
+
+	entries, err := fs.ReadDir(cfs, "dir")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "file.txt", entries[0].Name())
+}
+
+// countingFS counts the files opened and closed by the wrapped file system.
+type countingFS struct {
+	fs     fs.FS
+	opened int
+	closed int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	f, err := c.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	c.opened++
+	return &countingFile{File: f, fs: c}, nil
+}
+
+type countingFile struct {
+	fs.File
+	fs *countingFS
+}
+
+func (f *countingFile) Close() error {
+	f.fs.closed++
+	return f.File.Close()
+}
+
+func TestChecksumFS_VerifyAfterOpen(t *testing.T) {
+	data := []byte("data")
+	tc := []struct {
+		name     string
+		method   string
+		checksum types.Hash
+		maxBytes bytesize.Size
+		wantErr  error
+	}{
+		{name: "open", method: "Open", checksum: calculateKeccak256(data)},
+		{name: "readFile", method: "ReadFile", checksum: calculateKeccak256(data)},
+		{name: "open - mismatch", method: "Open", checksum: calculateKeccak256([]byte("data2")), wantErr: errChecksumFSMismatch},
+		{name: "readFile - mismatch", method: "ReadFile", checksum: calculateKeccak256([]byte("data2")), wantErr: errChecksumFSMismatch},
+		{name: "open - within limit", method: "Open", checksum: calculateKeccak256(data), maxBytes: 4},
+		{name: "open - over limit", method: "Open", checksum: calculateKeccak256(data), maxBytes: 3, wantErr: ErrFileTooLarge},
+		{name: "readFile - over limit", method: "ReadFile", checksum: calculateKeccak256(data), maxBytes: 3, wantErr: ErrFileTooLarge},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			cfs := &countingFS{fs: fstest.MapFS{"file.txt": &fstest.MapFile{Data: data}}}
+			checksumFS, err := NewChecksumFS(cfs, WithChecksumVerifyMode(ChecksumFSVerifyAfterOpen), WithChecksumMaxBytes(tt.maxBytes))
+			require.NoError(t, err)
+
+			var got []byte
+			name := "file.txt?checksum=" + tt.checksum.String()
+			switch tt.method {
+			case "Open":
+				var f fs.File
+				f, err = checksumFS.Open(name)
+				if err == nil {
+					got, err = io.ReadAll(f)
+					require.NoError(t, err)
+					require.NoError(t, f.Close())
+				}
+			case "ReadFile":
+				got, err = fs.ReadFile(checksumFS, name)
+			}
+
+			// The underlying file is opened once and closed before the
+			// result is returned, whether the verification succeeds or not.
+			assert.Equal(t, 1, cfs.opened)
+			assert.Equal(t, 1, cfs.closed)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package main
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	netURL "net/url"
+	"strings"
+	"sync"
+
+	"github.com/defiweb/go-eth/hexutil"
+
+	"github.com/chronicleprotocol/go-lib/fsutil"
+	"github.com/chronicleprotocol/go-lib/hashutil"
+	"github.com/chronicleprotocol/go-lib/httputil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/urlutil"
+)
+
+// result is a fetched file.
+type result struct {
+	data     []byte
+	source   string // URL of the server that sent the file, or its location.
+	checksum string // Keccak-256 hash of the data.
+}
+
+// fetch reads the file at cfg.uri using a protocol multiplexer configured
+// from the flags.
+func fetch(ctx context.Context, cfg *config, stderr io.Writer) (*result, error) {
+	logger := logutil.Nop()
+	if cfg.verbose {
+		logger = logutil.Slog(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	rec := &sourceRecorder{}
+	client := httputil.NewClient(
+		httputil.WithTimeout(cfg.timeout),
+		httputil.WithMiddleware(rec.middleware),
+	)
+	uri := cfg.uri
+	if cfg.checksum != "" {
+		sep := "?"
+		if strings.Contains(uri, "?") {
+			sep = "&"
+		}
+		uri += sep + "checksum=" + netURL.QueryEscape(cfg.checksum)
+	}
+	// Canceling the context removes Git checkouts, see fsutil.Protocols.Mux.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fsys, name, err := fsutil.ParseURI(newProtocols(ctx, cfg, client, logger).Mux(ctx), uri)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := hashutil.Sum(hashutil.Keccak256, data)
+	if err != nil {
+		return nil, err
+	}
+	res := &result{
+		data:     data,
+		source:   rec.last(),
+		checksum: hexutil.BytesToHex(sum.Sum),
+	}
+	if res.source == "" {
+		// No HTTP request succeeded, so the file was read from the disk.
+		res.source = "local"
+		if cfg.cacheDir != "" && !isLocal(cfg.uri) {
+			res.source = "cache"
+		}
+	}
+	return res, nil
+}
+
+// newProtocols returns the protocols used by the config package, configured
+// from the flags.
+func newProtocols(ctx context.Context, cfg *config, client *http.Client, logger logutil.Logger) *fsutil.Protocols {
+	ipfsOpts := []fsutil.IPFSOption{fsutil.WithIPFSHTTPClient(client)}
+	if gws := gateways(cfg); len(gws) > 0 {
+		ipfsOpts = append(ipfsOpts, fsutil.WithIPFSGateways(gws...))
+	}
+	return fsutil.NewProtocols(ctx, fsutil.ProtocolsConfig{
+		RetryAttempts: cfg.attempts,
+		RetryDelay:    cfg.retryDelay,
+		Cache:         cfg.cacheDir != "",
+		CacheOptions:  []fsutil.CacheFSOption{fsutil.WithCacheDir(cfg.cacheDir), fsutil.WithCacheTTL(cfg.cacheTTL)},
+		HTTPOptions:   []fsutil.HTTPFSOption{fsutil.WithHTTPClient(client)},
+		IPFSOptions:   ipfsOpts,
+		GitHubOptions: []fsutil.GitHubOption{fsutil.WithGitHubHTTPClient(client)},
+		Logger:        logger,
+	})
+}
+
+// gateways returns the IPFS gateways set using flags.
+func gateways(cfg *config) []*fsutil.IPFSGateway {
+	var gws []*fsutil.IPFSGateway
+	for _, u := range cfg.pathGWs {
+		gws = append(gws, &fsutil.IPFSGateway{Scheme: u.Scheme, Host: u.Host, ResolveFn: fsutil.IPFSPathResolution})
+	}
+	for _, u := range cfg.subdomainGWs {
+		gws = append(gws, &fsutil.IPFSGateway{Scheme: u.Scheme, Host: u.Host, ResolveFn: fsutil.IPFSSubdomainResolution})
+	}
+	return gws
+}
+
+// isLocal reports whether the URI refers to a local file.
+func isLocal(uri string) bool {
+	return !strings.Contains(uri, "://") || strings.HasPrefix(uri, "file://")
+}
+
+// sourceRecorder records the URL of the last successful HTTP response.
+// Remote file systems try their sources one by one and stop at the first
+// one that succeeds, so it is the source of the fetched file.
+type sourceRecorder struct {
+	mu  sync.Mutex
+	url string
+}
+
+func (r *sourceRecorder) middleware(next http.RoundTripper) http.RoundTripper {
+	return httputil.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		res, err := next.RoundTrip(req)
+		if err == nil && res.StatusCode >= 200 && res.StatusCode < 300 {
+			r.mu.Lock()
+			r.url = urlutil.Redact(req.URL)
+			r.mu.Unlock()
+		}
+		return res, err
+	})
+}
+
+func (r *sourceRecorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.url
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	netURL "net/url"
+	"strings"
+	"time"
+)
+
+// config holds the parsed command line flags.
+type config struct {
+	uri          string
+	output       string
+	checksum     string
+	cacheDir     string
+	cacheTTL     time.Duration
+	warm         bool
+	timeout      time.Duration
+	attempts     int
+	retryDelay   time.Duration
+	pathGWs      urlList
+	subdomainGWs urlList
+	verbose      bool
+}
+
+// urlList is a flag.Value that collects URLs from repeated flags.
+type urlList []*netURL.URL
+
+func (l *urlList) String() string {
+	s := make([]string, len(*l))
+	for i, u := range *l {
+		s[i] = u.String()
+	}
+	return strings.Join(s, ",")
+}
+
+func (l *urlList) Set(v string) error {
+	u, err := netURL.Parse(v)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid gateway URL %q: scheme and host are required", v)
+	}
+	*l = append(*l, u)
+	return nil
+}
+
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	var cfg config
+	f := flag.NewFlagSet("fsfetch", flag.ContinueOnError)
+	f.SetOutput(stderr)
+	f.Usage = func() {
+		_, _ = fmt.Fprintln(f.Output(), "Usage: fsfetch [flags] URI")
+		_, _ = fmt.Fprintln(f.Output(), "\nFlags:")
+		f.PrintDefaults()
+	}
+	f.StringVar(&cfg.output, "o", "", "write the file to `path` instead of the standard output")
+	f.StringVar(&cfg.checksum, "checksum", "", "verify the Keccak-256 `hash` of the file")
+	f.StringVar(&cfg.cacheDir, "cache-dir", "", "cache remote files in `dir`")
+	f.DurationVar(&cfg.cacheTTL, "cache-ttl", 0, "expire cached files after `duration` (default: never)")
+	f.BoolVar(&cfg.warm, "warm", false, "only store the file in the cache, requires -cache-dir")
+	f.DurationVar(&cfg.timeout, "timeout", 30*time.Second, "timeout of a single HTTP request")
+	f.IntVar(&cfg.attempts, "attempts", 3, "number of attempts to fetch a remote file")
+	f.DurationVar(&cfg.retryDelay, "retry-delay", time.Second, "delay between attempts")
+	f.Var(&cfg.pathGWs, "gateway", "use the IPFS gateway at `url` with path resolution, can be repeated")
+	f.Var(&cfg.subdomainGWs, "subdomain-gateway", "use the IPFS gateway at `url` with subdomain resolution, can be repeated")
+	f.BoolVar(&cfg.verbose, "v", false, "log requests and print the source, size and checksum of the file to stderr")
+	if err := f.Parse(args); err != nil {
+		return nil, err
+	}
+	if f.NArg() != 1 {
+		f.Usage()
+		return nil, errors.New("exactly one URI is required")
+	}
+	if cfg.warm && cfg.cacheDir == "" {
+		return nil, errors.New("the -warm flag requires -cache-dir")
+	}
+	if cfg.attempts < 1 {
+		return nil, errors.New("the -attempts flag must be at least 1")
+	}
+	cfg.uri = f.Arg(0)
+	return &cfg, nil
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Command fsfetch fetches a file using the fsutil protocols and writes it to
+// the standard output or to a file.
+//
+// It supports the same URI schemes as the config package, see
+// fsutil.Protocols, and verifies the "checksum" parameter in the same way.
+// Remote files are fetched with retries and can be cached on disk. It is
+// intended for debugging problems with remote configuration files, such as
+// misbehaving IPFS gateways.
+//
+// Usage:
+//
+//	fsfetch [flags] URI
+//
+// Examples:
+//
+//	# Print a file served by IPFS and verify its checksum.
+//	fsfetch -checksum 0x1234... ipfs://QmTest/config.hcl
+//
+//	# Show which gateway served the file.
+//	fsfetch -v -o /dev/null ipfs://QmTest/config.hcl
+//
+//	# Store a file in the cache without printing it.
+//	fsfetch -cache-dir /var/cache/app -warm https://example.com/config.hcl
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		_, _ = fmt.Fprintln(os.Stderr, "fsfetch:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command with the given arguments, without the program
+// name. It is separated from main so that it can be tested.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+	res, err := fetch(ctx, cfg, stderr)
+	if err != nil {
+		return err
+	}
+	if !cfg.warm {
+		if err := write(cfg.output, stdout, res.data); err != nil {
+			return err
+		}
+	}
+	if cfg.verbose {
+		_, _ = fmt.Fprintf(stderr, "source:   %s\nsize:     %d\nchecksum: %s\n", res.source, len(res.data), res.checksum)
+	}
+	return nil
+}
+
+// write writes data to the file at path, or to w if path is empty.
+func write(path string, w io.Writer, data []byte) error {
+	if path == "" {
+		_, err := w.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/chronicleprotocol/go-lib/testutil"
+)
+
+func TestRun(t *testing.T) {
+	gw := testutil.NewIPFSGateway()
+	defer gw.Close()
+	gw.AddFile("QmTest", "file.txt", []byte("content"))
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), []string{"-v", "-gateway", gw.URL(), "ipfs://QmTest/file.txt"}, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, "content", stdout.String())
+	assert.Contains(t, stderr.String(), "source:   "+gw.URL()+"/ipfs/QmTest/file.txt\n")
+	assert.Contains(t, stderr.String(), "size:     7\n")
+}
+
+func TestRun_Fallback(t *testing.T) {
+	bad := testutil.NewIPFSGateway(testutil.WithIPFSCorruption())
+	defer bad.Close()
+	good := testutil.NewIPFSGateway()
+	defer good.Close()
+	bad.AddFile("QmTest", "file.txt", []byte("content"))
+	good.AddFile("QmTest", "file.txt", []byte("content"))
+
+	checksum := checksumOf(t, "content")
+	for range 10 {
+		// The gateways are tried in random order, so the good one must
+		// serve the file regardless of the order.
+		var stdout, stderr bytes.Buffer
+		args := []string{"-v", "-checksum", checksum, "-gateway", bad.URL(), "-gateway", good.URL(), "ipfs://QmTest/file.txt"}
+		require.NoError(t, run(context.Background(), args, &stdout, &stderr))
+		assert.Equal(t, "content", stdout.String())
+		assert.Contains(t, stderr.String(), "source:   "+good.URL())
+	}
+}
+
+func TestRun_ChecksumMismatch(t *testing.T) {
+	gw := testutil.NewIPFSGateway(testutil.WithIPFSCorruption())
+	defer gw.Close()
+	gw.AddFile("QmTest", "file.txt", []byte("content"))
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-attempts", "1", "-checksum", checksumOf(t, "content"), "-gateway", gw.URL(), "ipfs://QmTest/file.txt"}
+	assert.Error(t, run(context.Background(), args, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+}
+
+func TestRun_WarmCache(t *testing.T) {
+	gw := testutil.NewIPFSGateway()
+	defer gw.Close()
+	gw.AddFile("QmTest", "file.txt", []byte("content"))
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-cache-dir", dir, "-warm", "-gateway", gw.URL(), "ipfs://QmTest/file.txt"}
+	require.NoError(t, run(context.Background(), args, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, 1, gw.Requests())
+
+	// The second run is served from the cache.
+	stdout.Reset()
+	stderr.Reset()
+	args = []string{"-v", "-cache-dir", dir, "-gateway", gw.URL(), "ipfs://QmTest/file.txt"}
+	require.NoError(t, run(context.Background(), args, &stdout, &stderr))
+	assert.Equal(t, "content", stdout.String())
+	assert.Contains(t, stderr.String(), "source:   cache\n")
+	assert.Equal(t, 1, gw.Requests())
+}
+
+func TestRun_OutputFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	dst := filepath.Join(dir, "dst.txt")
+	require.NoError(t, os.WriteFile("src.txt", []byte("content"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, run(context.Background(), []string{"-v", "-o", dst, "src.txt"}, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "source:   local\n")
+	b, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(b))
+}
+
+func TestRun_InvalidFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no uri", args: nil, wantErr: "exactly one URI is required"},
+		{name: "warm without cache", args: []string{"-warm", "file.txt"}, wantErr: "the -warm flag requires -cache-dir"},
+		{name: "invalid gateway", args: []string{"-gateway", "localhost", "file.txt"}, wantErr: "scheme and host are required"},
+		{name: "invalid attempts", args: []string{"-attempts", "0", "file.txt"}, wantErr: "the -attempts flag must be at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.ErrorContains(t, run(context.Background(), tt.args, &stdout, &stderr), tt.wantErr)
+		})
+	}
+	var stdout, stderr bytes.Buffer
+	assert.ErrorIs(t, run(context.Background(), []string{"-h"}, &stdout, &stderr), flag.ErrHelp)
+}
+
+func checksumOf(t *testing.T, s string) string {
+	t.Helper()
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(s))
+	return "0x" + hex.EncodeToString(h.Sum(nil))
+}
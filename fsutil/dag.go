@@ -0,0 +1,481 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// IPFSDAGFormat is an IPLD representation of IPFS content that can be
+// requested from IPFS gateways.
+type IPFSDAGFormat string
+
+const (
+	// IPFSDAGJSON requests the DAG-JSON representation of an IPLD node.
+	IPFSDAGJSON IPFSDAGFormat = "dag-json"
+
+	// IPFSDAGCBOR requests the DAG-CBOR representation of an IPLD node.
+	IPFSDAGCBOR IPFSDAGFormat = "dag-cbor"
+)
+
+// contentType returns the media type used in the Accept header.
+func (f IPFSDAGFormat) contentType() string {
+	return "application/vnd.ipld." + string(f)
+}
+
+// dagMaxDepth is the maximum nesting level of decoded IPLD nodes.
+const dagMaxDepth = 256
+
+// dagFS decodes files that contain DAG-JSON or DAG-CBOR encoded IPLD nodes
+// and exposes them as canonical JSON documents.
+//
+// In the canonical form, map keys are sorted, insignificant whitespace is
+// removed, links are represented as {"/": "<cid>"} and bytes as
+// {"/": {"bytes": "<base64>"}}, as in DAG-JSON. Because the representation
+// does not depend on the codec used by a gateway, checksums can be computed
+// over the decoded files.
+type dagFS struct {
+	fs     fs.FS
+	format IPFSDAGFormat
+}
+
+// Open implements the fs.FS interface.
+func (d *dagFS) Open(name string) (fs.File, error) {
+	f, err := d.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errDAGFSFn(err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errDAGFSFn(err)
+	}
+	var v any
+	switch d.format {
+	case IPFSDAGJSON:
+		v, err = decodeDAGJSON(b)
+	case IPFSDAGCBOR:
+		v, err = decodeDAGCBOR(b)
+	default:
+		err = errDAGUnsupportedFormatFn(d.format)
+	}
+	if err != nil {
+		return nil, errDAGFSDecodeFn(name, err)
+	}
+	j, err := canonicalJSON(v)
+	if err != nil {
+		return nil, errDAGFSDecodeFn(name, err)
+	}
+	return &file{
+		reader: io.NopCloser(bytes.NewReader(j)),
+		info: &fileInfo{
+			name:    info.Name(),
+			size:    int64(len(j)),
+			mode:    info.Mode(),
+			modTime: info.ModTime(),
+			isDir:   false,
+			sys:     info.Sys(),
+		},
+	}, nil
+}
+
+// decodeDAGJSON decodes a DAG-JSON document. Numbers are kept as they
+// are, so large integers do not lose precision.
+func decodeDAGJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errDAGTrailingData
+	}
+	return v, nil
+}
+
+// decodeDAGCBOR decodes a DAG-CBOR document into values that can be
+// encoded as JSON.
+func decodeDAGCBOR(b []byte) (any, error) {
+	d := &cborDecoder{b: b}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(d.b) {
+		return nil, errDAGTrailingData
+	}
+	return v, nil
+}
+
+// canonicalJSON encodes v as compact JSON with sorted map keys.
+func canonicalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// cborDecoder decodes the subset of CBOR allowed by the DAG-CBOR codec.
+//
+// Indefinite-length items, tags other than 42 (CID) and simple values
+// other than false, true and null are rejected, as they are not allowed
+// in DAG-CBOR.
+type cborDecoder struct {
+	b   []byte
+	off int
+}
+
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+
+	cborTagCID = 42
+)
+
+func (d *cborDecoder) decode(depth int) (any, error) {
+	if depth > dagMaxDepth {
+		return nil, errDAGTooDeep
+	}
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case cborNegInt:
+		// The value is -1 - arg, which may not fit in int64.
+		n := new(big.Int).SetUint64(arg)
+		n.Neg(n).Sub(n, big.NewInt(1))
+		return json.Number(n.String()), nil
+	case cborBytes:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"/": map[string]any{"bytes": base64.RawStdEncoding.EncodeToString(b)}}, nil
+	case cborText:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, errDAGInvalidUTF8
+		}
+		return string(b), nil
+	case cborArray:
+		// Every item takes at least one byte.
+		if arg > uint64(len(d.b)-d.off) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		a := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	case cborMap:
+		// Every entry takes at least two bytes.
+		if arg > uint64(len(d.b)-d.off)/2 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		m := make(map[string]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			ks, ok := k.(string)
+			if !ok {
+				return nil, errDAGInvalidMapKey
+			}
+			if _, ok := m[ks]; ok {
+				return nil, errDAGDuplicateMapKeyFn(ks)
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[ks] = v
+		}
+		return m, nil
+	case cborTag:
+		if arg != cborTagCID {
+			return nil, errDAGUnsupportedTagFn(arg)
+		}
+		major, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if major != cborBytes {
+			return nil, errDAGInvalidCID
+		}
+		b, err := d.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		cid, err := formatCID(b)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"/": cid}, nil
+	default: // cborSimple
+		return d.simple()
+	}
+}
+
+// head reads the initial byte of a data item and its argument.
+func (d *cborDecoder) head() (major byte, arg uint64, err error) {
+	if d.off >= len(d.b) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	ib := d.b[d.off]
+	d.off++
+	major, info := ib>>5, ib&0x1f
+	if major == cborSimple {
+		// Simple values and floats are decoded by the simple method.
+		d.off--
+		return major, 0, nil
+	}
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		b, err := d.next(1)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(b[0]), nil
+	case info == 25:
+		b, err := d.next(2)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.next(4)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.next(8)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, binary.BigEndian.Uint64(b), nil
+	case info == 31:
+		return 0, 0, errDAGIndefiniteLength
+	default:
+		return 0, 0, errDAGInvalidHeaderFn(ib)
+	}
+}
+
+// simple decodes a simple value or a floating-point number.
+func (d *cborDecoder) simple() (any, error) {
+	ib := d.b[d.off]
+	d.off++
+	switch ib & 0x1f {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22:
+		return nil, nil
+	case 25:
+		b, err := d.next(2)
+		if err != nil {
+			return nil, err
+		}
+		return checkFloat(float16(binary.BigEndian.Uint16(b)))
+	case 26:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return checkFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(b))))
+	case 27:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return checkFloat(math.Float64frombits(binary.BigEndian.Uint64(b)))
+	case 31:
+		return nil, errDAGIndefiniteLength
+	default:
+		return nil, errDAGInvalidHeaderFn(ib)
+	}
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.b)-d.off) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return d.next(int(n))
+}
+
+func (d *cborDecoder) next(n int) ([]byte, error) {
+	if n > len(d.b)-d.off {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.b[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+// float16 converts an IEEE 754 half-precision number to float64.
+func float16(h uint16) float64 {
+	var (
+		sign = 1.0
+		exp  = int(h>>10) & 0x1f
+		frac = float64(h & 0x3ff)
+	)
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(frac+1024, exp-25)
+	}
+}
+
+// checkFloat rejects NaN and infinities, which cannot be represented in
+// the IPLD data model.
+func checkFloat(f float64) (any, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errDAGInvalidFloat
+	}
+	return f, nil
+}
+
+// formatCID formats the content of a DAG-CBOR link as a CID string.
+//
+// The content starts with the identity multibase prefix followed by the
+// binary CID. CIDv0 is formatted using base58btc and CIDv1 using base32,
+// which are the default string encodings for these versions.
+func formatCID(b []byte) (string, error) {
+	if len(b) < 2 || b[0] != 0x00 {
+		return "", errDAGInvalidCID
+	}
+	b = b[1:]
+	if len(b) == 34 && b[0] == 0x12 && b[1] == 0x20 {
+		// CIDv0 is a bare SHA2-256 multihash.
+		return base58Encode(b), nil
+	}
+	return "b" + cidBase32.EncodeToString(b), nil
+}
+
+var cidBase32 = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode encodes b using the Bitcoin base58 alphabet.
+func base58Encode(b []byte) string {
+	var (
+		n    = new(big.Int).SetBytes(b)
+		base = big.NewInt(58)
+		mod  = new(big.Int)
+		out  []byte
+	)
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+var (
+	errDAGTrailingData     = errors.New("unexpected data after the top-level value")
+	errDAGTooDeep          = errors.New("maximum nesting depth exceeded")
+	errDAGInvalidUTF8      = errors.New("invalid UTF-8 string")
+	errDAGInvalidMapKey    = errors.New("map keys must be strings")
+	errDAGInvalidCID       = errors.New("invalid CID")
+	errDAGInvalidFloat     = errors.New("NaN and infinity are not allowed")
+	errDAGIndefiniteLength = errors.New("indefinite-length items are not allowed")
+)
+
+func errDAGFSFn(err error) error {
+	return fmt.Errorf("fsutil.dagFS: %w", err)
+}
+
+func errDAGFSDecodeFn(name string, err error) error {
+	return errutil.WithCode(
+		fmt.Errorf("fsutil.dagFS: failed to decode %s: %w", redactName(name), err),
+		errutil.CodeIntegrity,
+	)
+}
+
+func errDAGUnsupportedFormatFn(format IPFSDAGFormat) error {
+	return errutil.WithCode(fmt.Errorf("unsupported format: %q", format), errutil.CodeConfig)
+}
+
+func errDAGUnsupportedTagFn(tag uint64) error {
+	return fmt.Errorf("unsupported tag: %d", tag)
+}
+
+func errDAGDuplicateMapKeyFn(key string) error {
+	return fmt.Errorf("duplicate map key: %q", key)
+}
+
+func errDAGInvalidHeaderFn(ib byte) error {
+	return fmt.Errorf("invalid initial byte: 0x%02x", ib)
+}
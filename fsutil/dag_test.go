@@ -0,0 +1,190 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptySHA256 is the SHA2-256 multihash of empty data.
+const emptySHA256 = "1220e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestDecodeDAGCBOR(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string // Hex encoded.
+		want    string
+		wantErr bool
+	}{
+		{name: "map with sorted keys", data: "a2616201616182f5f6", want: `{"a":[true,null],"b":1}`},
+		{name: "negative integer", data: "20", want: `-1`},
+		{name: "large negative integer", data: "3bffffffffffffffff", want: `-18446744073709551616`},
+		{name: "large integer", data: "1bffffffffffffffff", want: `18446744073709551615`},
+		{name: "text", data: "6a3c68656c6c6f2026203e", want: `"<hello & >"`},
+		{name: "bytes", data: "43010203", want: `{"/":{"bytes":"AQID"}}`},
+		{name: "float64", data: "fb3ff8000000000000", want: `1.5`},
+		{name: "float16", data: "f93c00", want: `1`},
+		{name: "CIDv0 link", data: "d82a582300" + emptySHA256, want: `{"/":"QmdfTbBqBPQ7VNxZEYEj14VmRuZBkqFbiwReogJgS1zR1n"}`},
+		{name: "CIDv1 link", data: "d82a5825000170" + emptySHA256, want: `{"/":"bafybeihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"}`},
+		{name: "empty", data: "", wantErr: true},
+		{name: "trailing data", data: "0101", wantErr: true},
+		{name: "truncated text", data: "6261", wantErr: true},
+		{name: "indefinite-length array", data: "9fff", wantErr: true},
+		{name: "non-string map key", data: "a10101", wantErr: true},
+		{name: "duplicate map key", data: "a2616101616102", wantErr: true},
+		{name: "invalid UTF-8", data: "61ff", wantErr: true},
+		{name: "unsupported tag", data: "c100", wantErr: true},
+		{name: "NaN", data: "f97e00", wantErr: true},
+		{name: "CID without prefix", data: "d82a5822" + emptySHA256, wantErr: true},
+		{name: "oversized array length", data: "9b00000000ffffffff", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			v, err := decodeDAGCBOR(b)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			j, err := canonicalJSON(v)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(j))
+		})
+	}
+}
+
+func TestDecodeDAGCBOR_Limits(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string // Hex encoded.
+		wantErr error
+	}{
+		{name: "max depth of arrays", data: strings.Repeat("81", dagMaxDepth) + "00"},
+		{name: "too deep arrays", data: strings.Repeat("81", dagMaxDepth+1) + "00", wantErr: errDAGTooDeep},
+		{name: "max depth of maps", data: strings.Repeat("a16161", dagMaxDepth) + "00"},
+		{name: "too deep maps", data: strings.Repeat("a16161", dagMaxDepth+1) + "00", wantErr: errDAGTooDeep},
+		{name: "array length over input size", data: "9a0000000200", wantErr: io.ErrUnexpectedEOF},
+		{name: "map length over input size", data: "a2616100", wantErr: io.ErrUnexpectedEOF},
+		{name: "max map length", data: "bbffffffffffffffff", wantErr: io.ErrUnexpectedEOF},
+		{name: "bytes length over input size", data: "5a0000000200", wantErr: io.ErrUnexpectedEOF},
+		{name: "max text length", data: "7bffffffffffffffff", wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated argument", data: "1b0000", wantErr: io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			_, err = decodeDAGCBOR(b)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// FuzzDecodeDAGCBOR checks that the decoder does not panic or allocate
+// memory for lengths not backed by the input, and that every decoded
+// document can be encoded as JSON.
+func FuzzDecodeDAGCBOR(f *testing.F) {
+	for _, s := range []string{
+		"a2616201616182f5f6",
+		"3bffffffffffffffff",
+		"6a3c68656c6c6f2026203e",
+		"43010203",
+		"fb3ff8000000000000",
+		"f93c00",
+		"d82a582300" + emptySHA256,
+		"d82a5825000170" + emptySHA256,
+		"9b00000000ffffffff",
+		"bbffffffffffffffff",
+		strings.Repeat("81", dagMaxDepth) + "00",
+		strings.Repeat("a16161", dagMaxDepth+1) + "00",
+	} {
+		b, err := hex.DecodeString(s)
+		require.NoError(f, err)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		v, err := decodeDAGCBOR(b)
+		if err != nil {
+			return
+		}
+		_, err = canonicalJSON(v)
+		require.NoError(t, err)
+
+		// A valid document followed by any data is invalid.
+		_, err = decodeDAGCBOR(append(b[:len(b):len(b)], 0))
+		require.ErrorIs(t, err, errDAGTrailingData)
+	})
+}
+
+// FuzzDecodeDAGJSON checks that the decoder does not panic and that every
+// decoded document can be encoded as JSON.
+func FuzzDecodeDAGJSON(f *testing.F) {
+	for _, s := range []string{
+		`{ "b": 1, "a": [true, null] }`,
+		`{"n": 123456789012345678901234567890}`,
+		`{"/": "bafybeihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"}`,
+		`{} {}`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		v, err := decodeDAGJSON(b)
+		if err != nil {
+			return
+		}
+		_, err = canonicalJSON(v)
+		require.NoError(t, err)
+	})
+}
+
+func TestDecodeDAGJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{name: "sorted keys", data: `{ "b": 1, "a": [true, null] }`, want: `{"a":[true,null],"b":1}`},
+		{name: "large integer", data: `{"n": 123456789012345678901234567890}`, want: `{"n":123456789012345678901234567890}`},
+		{name: "link", data: `{"/": "bafybeihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"}`, want: `{"/":"bafybeihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"}`},
+		{name: "invalid", data: `{"a":`, wantErr: true},
+		{name: "trailing data", data: `{} {}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := decodeDAGJSON([]byte(tt.data))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			j, err := canonicalJSON(v)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(j))
+		})
+	}
+}
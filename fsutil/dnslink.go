@@ -0,0 +1,93 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// DNSResolver looks up the TXT records of the given domain name. The
+// LookupTXT method of net.Resolver can be used as a DNSResolver.
+type DNSResolver func(ctx context.Context, name string) ([]string, error)
+
+// WithDNSResolver sets the resolver used to look up DNSLink records. By
+// default, net.DefaultResolver is used.
+//
+// DNSLink records are not signed, so the resolved content is only as
+// trustworthy as the DNS responses. A validating resolver or checksums
+// should be used if this is a concern.
+func WithDNSResolver(resolver DNSResolver) IPFSOption {
+	return func(c *ipfsFS) {
+		c.dnsResolver = resolver
+	}
+}
+
+// isDNSLinkName reports whether the name is a domain name rather than a
+// CID or an IPNS key. CIDs and keys never contain dots.
+func isDNSLinkName(name string) bool {
+	return strings.Contains(name, ".")
+}
+
+// resolveDNSLink looks up the "_dnslink" TXT record of the domain and
+// returns the IPFS or IPNS path it points to. If there are multiple
+// DNSLink records, the lexicographically first one is used, so that the
+// result does not depend on the order of the DNS response.
+func (i *ipfsFS) resolveDNSLink(ctx context.Context, domain string) (string, error) {
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" || strings.ContainsAny(domain, ":/@") {
+		return "", errDNSLinkInvalidDomain
+	}
+	txts, err := i.dnsResolver(ctx, "_dnslink."+domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "", errDNSLinkNotFound
+		}
+		return "", errutil.WithCode(err, errutil.CodeTransient)
+	}
+	var links []string
+	for _, txt := range txts {
+		link, ok := strings.CutPrefix(strings.TrimSpace(txt), "dnslink=")
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(link, "/ipfs/") && !strings.HasPrefix(link, "/ipns/") {
+			continue
+		}
+		links = append(links, link)
+	}
+	if len(links) == 0 {
+		return "", errDNSLinkNotFound
+	}
+	slices.Sort(links)
+	return links[0], nil
+}
+
+var (
+	errDNSLinkInvalidDomain = errutil.WithCode(errors.New("invalid DNSLink domain"), errutil.CodeConfig)
+	errDNSLinkNotFound      = errutil.WithCode(errors.New("no DNSLink record found"), errutil.CodeNotFound)
+)
+
+func errDNSLinkResolveFn(domain string, err error) error {
+	return fmt.Errorf("failed to resolve DNSLink of %s: %w", domain, err)
+}
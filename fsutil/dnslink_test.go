@@ -0,0 +1,199 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io/fs"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/testutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+func TestDNSLink(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, key := testIPNSKey(t)
+	name := testIPNSName(key.Public().(ed25519.PublicKey))
+	tc := []struct {
+		name     string
+		txt      map[string][]string
+		uri      string
+		wantData string
+		wantCode errutil.Code
+	}{
+		{
+			name:     "ipfs scheme",
+			txt:      map[string][]string{"_dnslink.example.com": {"dnslink=/ipfs/QmTest"}},
+			uri:      "ipfs://example.com/dir/file.txt",
+			wantData: "file",
+		},
+		{
+			name:     "ipns scheme",
+			txt:      map[string][]string{"_dnslink.example.com": {"dnslink=/ipfs/QmTest"}},
+			uri:      "ipns://example.com",
+			wantData: "root",
+		},
+		{
+			name:     "path in record",
+			txt:      map[string][]string{"_dnslink.example.com": {"dnslink=/ipfs/QmTest/dir"}},
+			uri:      "ipfs://example.com/file.txt",
+			wantData: "file",
+		},
+		{
+			name:     "checksum",
+			txt:      map[string][]string{"_dnslink.example.com": {"dnslink=/ipfs/QmTest"}},
+			uri:      "ipfs://example.com/dir/file.txt?checksum=" + calculateKeccak256([]byte("file")).String(),
+			wantData: "file",
+		},
+		{
+			name:     "IPNS name",
+			txt:      map[string][]string{"_dnslink.example.com": {"dnslink=/ipns/" + name + "/dir"}},
+			uri:      "ipfs://example.com/file.txt",
+			wantData: "file",
+		},
+		{
+			name: "other DNSLink name",
+			txt: map[string][]string{
+				"_dnslink.example.com": {"dnslink=/ipns/example.org"},
+				"_dnslink.example.org": {"dnslink=/ipfs/QmTest"},
+			},
+			uri:      "ipfs://example.com",
+			wantData: "root",
+		},
+		{
+			name: "other records",
+			txt: map[string][]string{"_dnslink.example.com": {
+				"v=spf1 -all",
+				"dnslink=invalid",
+				" dnslink=/ipfs/QmTest ",
+			}},
+			uri:      "ipfs://example.com",
+			wantData: "root",
+		},
+		{
+			name: "multiple records",
+			txt: map[string][]string{"_dnslink.example.com": {
+				"dnslink=/ipfs/QmTest/dir",
+				"dnslink=/ipfs/QmOther",
+			}},
+			uri:      "ipfs://example.com",
+			wantCode: errutil.CodeNotFound, // QmOther does not exist.
+		},
+		{
+			name:     "no DNSLink record",
+			txt:      map[string][]string{"_dnslink.example.com": {"v=spf1 -all"}},
+			uri:      "ipfs://example.com",
+			wantCode: errutil.CodeNotFound,
+		},
+		{
+			name:     "no TXT record",
+			uri:      "ipfs://example.com",
+			wantCode: errutil.CodeNotFound,
+		},
+		{
+			name:     "loop",
+			txt:      map[string][]string{"_dnslink.example.com": {"dnslink=/ipns/example.com"}},
+			uri:      "ipfs://example.com",
+			wantCode: errutil.CodeConfig,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := testutil.NewIPFSGateway()
+			defer gw.Close()
+			gw.AddFile("QmTest", "", []byte("root"))
+			gw.AddFile("QmTest", "dir/file.txt", []byte("file"))
+			gw.AddIPNSRecord(name, testIPNSRecord(key, "/ipfs/QmTest", now.Add(time.Hour), 0))
+			proto := NewIPFSProto(
+				ctx,
+				WithIPFSHTTPClient(gw.Client()),
+				WithIPFSGateways(&IPFSGateway{
+					Scheme:        gw.Scheme(),
+					Host:          gw.Host(),
+					ResolveFn:     IPFSPathResolution,
+					IPNSResolveFn: IPNSPathResolution,
+				}),
+				WithIPNSRecordVerification(),
+				WithIPFSClock(timeutil.NewFake(now)),
+				WithDNSResolver(func(_ context.Context, name string) ([]string, error) {
+					if txt, ok := tt.txt[name]; ok {
+						return txt, nil
+					}
+					return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+				}),
+			)
+			fsys, path, err := ParseURI(proto, tt.uri)
+			if err == nil {
+				var data []byte
+				data, err = fs.ReadFile(fsys, path)
+				if tt.wantCode == errutil.CodeUnknown {
+					require.NoError(t, err)
+					assert.Equal(t, tt.wantData, string(data))
+					return
+				}
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+		})
+	}
+}
+
+func TestDNSLink_Cache(t *testing.T) {
+	ctx := context.Background()
+	clock := timeutil.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	calls := 0
+	proto := NewIPFSProto(
+		ctx,
+		WithIPFSClock(clock),
+		WithIPNSCacheTTL(time.Minute),
+		WithDNSResolver(func(context.Context, string) ([]string, error) {
+			calls++
+			return []string{"dnslink=/ipfs/QmTest"}, nil
+		}),
+	)
+	for range 2 {
+		_, _, err := ParseURI(proto, "ipfs://example.com/file.txt")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+
+	clock.Advance(time.Minute)
+	_, _, err := ParseURI(proto, "ipfs://example.com/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDNSLink_ResolverError(t *testing.T) {
+	proto := NewIPFSProto(
+		context.Background(),
+		WithDNSResolver(func(context.Context, string) ([]string, error) {
+			return nil, errors.New("resolver error")
+		}),
+	)
+	_, _, err := ParseURI(proto, "ipfs://example.com")
+	assert.ErrorContains(t, err, "resolver error")
+	assert.Equal(t, errutil.CodeTransient, errutil.CodeOf(err))
+}
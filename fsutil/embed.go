@@ -0,0 +1,143 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	netURL "net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// EmbedScheme is the URI scheme of the embed protocol.
+const EmbedScheme = "embed"
+
+// embedNameRx matches valid names of embedded file systems. Names are used
+// as the host of URIs, so they are restricted to host name characters.
+var embedNameRx = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+
+// EmbedRegistry holds named embedded file systems, so that files compiled
+// into a binary can be loaded using "embed://name/path" URIs, see
+// NewEmbedProto. It is safe for concurrent use.
+//
+// Example:
+//
+//	//go:embed defaults
+//	var defaults embed.FS
+//
+//	r := fsutil.NewEmbedRegistry().Register("app", defaults)
+//	fs, path, err := fsutil.ParseURI(fsutil.NewEmbedProto(r), "embed://app/defaults/config.hcl")
+//
+// Paths are the same as in the embed.FS, so they include the directories
+// named in the go:embed directive.
+type EmbedRegistry struct {
+	mu  sync.RWMutex
+	fss map[string]embed.FS
+}
+
+// NewEmbedRegistry creates a new, empty registry.
+func NewEmbedRegistry() *EmbedRegistry {
+	return &EmbedRegistry{fss: make(map[string]embed.FS)}
+}
+
+// Register adds the file system to the registry under the given name.
+// Names are case-insensitive and may contain letters, digits, dots, dashes
+// and underscores.
+//
+// Register panics if the name is invalid or already registered. It is
+// intended to be called during initialization.
+func (r *EmbedRegistry) Register(name string, fs embed.FS) *EmbedRegistry {
+	key := strings.ToLower(name)
+	if !embedNameRx.MatchString(key) {
+		panic(fmt.Sprintf("fsutil.EmbedRegistry: invalid name: %q", name))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.fss[key]; ok {
+		panic(fmt.Sprintf("fsutil.EmbedRegistry: name already registered: %q", name))
+	}
+	r.fss[key] = fs
+	return r
+}
+
+// Lookup returns the file system registered under the given name. It can
+// be used to chain an embedded file system with others, e.g. to fall back
+// to compiled-in defaults, see NewChainFS.
+func (r *EmbedRegistry) Lookup(name string) (fs.FS, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.fss[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return f, true
+}
+
+// NewEmbedProto creates a new protocol that serves files from the file
+// systems in the given registry. The host of the URI is the name of the
+// file system and the path is the path within it, e.g.
+// "embed://app/defaults/config.hcl".
+func NewEmbedProto(r *EmbedRegistry) Protocol {
+	return &embedProto{registry: r}
+}
+
+type embedProto struct {
+	registry *EmbedRegistry
+}
+
+// FileSystem implements the Protocol interface.
+func (e *embedProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err error) {
+	if uri == nil {
+		return nil, "", errEmbedProtoNilURI
+	}
+	if uri.Scheme != EmbedScheme {
+		return nil, "", errEmbedProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	if uri.Opaque != "" {
+		return nil, "", errEmbedProtoOpaqueNotAllowed
+	}
+	if uri.Fragment != "" || uri.RawFragment != "" {
+		return nil, "", errEmbedProtoFragmentNotAllowed
+	}
+	if uri.Host == "" {
+		return nil, "", errEmbedProtoEmptyHost
+	}
+	fs, ok := e.registry.Lookup(uri.Host)
+	if !ok {
+		return nil, "", errEmbedProtoNotRegisteredFn(uri.Host)
+	}
+	return fs, uriPath(uri, true), nil
+}
+
+var (
+	errEmbedProtoNilURI             = errutil.WithCode(errors.New("fsutil.embedProto: nil URI"), errutil.CodeConfig)
+	errEmbedProtoOpaqueNotAllowed   = errutil.WithCode(errors.New("fsutil.embedProto: opaque not allowed"), errutil.CodeConfig)
+	errEmbedProtoFragmentNotAllowed = errutil.WithCode(errors.New("fsutil.embedProto: fragment not allowed"), errutil.CodeConfig)
+	errEmbedProtoEmptyHost          = errutil.WithCode(errors.New("fsutil.embedProto: empty host"), errutil.CodeConfig)
+)
+
+func errEmbedProtoUnexpectedSchemeFn(scheme string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.embedProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
+}
+
+func errEmbedProtoNotRegisteredFn(name string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.embedProto: file system not registered: %s", name), errutil.CodeConfig)
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"embed"
+	"io/fs"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+//go:embed testdata
+var embedTestFS embed.FS
+
+func TestEmbedProto(t *testing.T) {
+	r := NewEmbedRegistry().Register("test", embedTestFS)
+
+	tc := []struct {
+		name     string
+		uri      string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "nil URL", wantErr: true},
+		{name: "unexpected scheme", uri: "file://test/testdata/test.txt", wantErr: true},
+		{name: "empty host", uri: "embed:///testdata/test.txt", wantErr: true},
+		{name: "fragment", uri: "embed://test/testdata/test.txt#x", wantErr: true},
+		{name: "not registered", uri: "embed://other/testdata/test.txt", wantErr: true},
+		{name: "file", uri: "embed://test/testdata/test.txt", wantPath: "testdata/test.txt"},
+		{name: "case insensitive", uri: "embed://TEST/testdata/test.txt", wantPath: "testdata/test.txt"},
+		{name: "query", uri: "embed://test/testdata/test.txt?checksum=x", wantPath: "testdata/test.txt?checksum=x"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var u *url.URL
+			if tt.uri != "" {
+				var err error
+				u, err = url.Parse(tt.uri)
+				require.NoError(t, err)
+			}
+			fsys, path, err := NewEmbedProto(r).FileSystem(u)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, embedTestFS, fsys)
+		})
+	}
+}
+
+func TestEmbedRegistry(t *testing.T) {
+	r := NewEmbedRegistry().Register("test", embedTestFS)
+
+	t.Run("lookup", func(t *testing.T) {
+		fsys, ok := r.Lookup("Test")
+		require.True(t, ok)
+		b, err := fs.ReadFile(fsys, "testdata/test.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "test content", string(b))
+		_, ok = r.Lookup("other")
+		assert.False(t, ok)
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		assert.Panics(t, func() { r.Register("TEST", embedTestFS) })
+	})
+	t.Run("invalid name", func(t *testing.T) {
+		for _, name := range []string{"", "a/b", "a:1", "-a", "a b"} {
+			assert.Panics(t, func() { r.Register(name, embedTestFS) }, name)
+		}
+	})
+	t.Run("fallback", func(t *testing.T) {
+		// Files missing from the first file system are read from the
+		// embedded defaults.
+		overrides := NewMemFS()
+		defaults, _ := r.Lookup("test")
+		fsys := NewChainFS(WithChainFilesystems(overrides, defaults))
+		b, err := fs.ReadFile(fsys, "testdata/test.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "test content", string(b))
+
+		require.NoError(t, overrides.WriteFile("testdata/test.txt", []byte("override"), 0o644))
+		b, err = fs.ReadFile(fsys, "testdata/test.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "override", string(b))
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	netURL "net/url"
+	"strings"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/cryptoutil"
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+const (
+	// EncryptedSchemePrefix is the prefix of URI schemes handled by the
+	// encrypted protocol, e.g. "enc+https".
+	EncryptedSchemePrefix = "enc+"
+
+	defaultEncryptedMaxBytes = 128 * bytesize.MiB
+)
+
+// KeyProvider returns the private key of the Ethereum account used to
+// decrypt files. It is called every time an encrypted file is opened, so
+// the key can be loaded lazily.
+type KeyProvider func() (*ecdsa.PrivateKey, error)
+
+// StaticKey returns a KeyProvider that always returns the given key.
+func StaticKey(key *ecdsa.PrivateKey) KeyProvider {
+	return func() (*ecdsa.PrivateKey, error) {
+		return key, nil
+	}
+}
+
+type EncryptedFSOption func(*encryptedFS)
+
+// WithEncryptedMaxBytes sets the maximum size of encrypted files. Files are
+// read into memory to be decrypted, so the size must be limited. Reading
+// a larger file fails with ErrFileTooLarge. The default limit is 128MiB.
+// If the limit is zero or negative, the size is not limited.
+func WithEncryptedMaxBytes(limit bytesize.Size) EncryptedFSOption {
+	return func(c *encryptedFS) {
+		c.maxBytes = limit
+	}
+}
+
+// NewEncryptedProto creates a new encrypted protocol.
+//
+// The encrypted protocol handles URIs with the "enc+" scheme prefix, such
+// as "enc+https://example.com/config.hcl.ecies". The prefix is removed
+// before the URI is passed to the given protocol, and the returned file
+// system is wrapped with an encrypted file system that decrypts files
+// using the key returned by the key provider.
+func NewEncryptedProto(proto Protocol, key KeyProvider, opts ...EncryptedFSOption) Protocol {
+	return &encryptedProto{proto: proto, key: key, opts: opts}
+}
+
+type encryptedProto struct {
+	proto Protocol
+	key   KeyProvider
+	opts  []EncryptedFSOption
+}
+
+// FileSystem implements the Protocol interface.
+func (e *encryptedProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err error) {
+	if uri == nil {
+		return nil, "", errEncryptedProtoNilURI
+	}
+	scheme, ok := strings.CutPrefix(uri.Scheme, EncryptedSchemePrefix)
+	if !ok || scheme == "" {
+		return nil, "", errEncryptedProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	inner := *uri
+	inner.Scheme = scheme
+	fs, path, err = e.proto.FileSystem(&inner)
+	if err != nil {
+		return nil, "", errEncryptedProtoFn(err)
+	}
+	fs, err = NewEncryptedFS(fs, e.key, e.opts...)
+	if err != nil {
+		return nil, "", errEncryptedProtoFn(err)
+	}
+	return
+}
+
+// NewEncryptedFS creates a new encrypted file system.
+//
+// The file system wraps an existing file system and decrypts the contents
+// of every file using the key returned by the key provider. Files are
+// encrypted using the same scheme as secrets handled by the
+// hcl/ext/secrets package, see the cryptoutil package. A file may contain
+// either the raw ciphertext returned by cryptoutil.Encrypt or its text
+// encoding returned by cryptoutil.EncodeCiphertext.
+//
+// Decryption authenticates the ciphertext, so a modified file fails to
+// decrypt rather than returning corrupted data.
+func NewEncryptedFS(fs fs.FS, key KeyProvider, opts ...EncryptedFSOption) (fs.FS, error) {
+	if key == nil {
+		return nil, errEncryptedFSNilKeyProvider
+	}
+	e := &encryptedFS{fs: fs, key: key, maxBytes: defaultEncryptedMaxBytes}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+type encryptedFS struct {
+	fs       fs.FS
+	key      KeyProvider
+	maxBytes bytesize.Size
+}
+
+// Open implements the fs.FS interface.
+func (e *encryptedFS) Open(name string) (fs.File, error) {
+	if err := validPath("open", name); err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	f, err := e.fs.Open(name)
+	if err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	var r io.Reader = f
+	if e.maxBytes > 0 {
+		r = newMaxBytesReader(f, int64(e.maxBytes))
+	}
+	ciphertext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	key, err := e.key()
+	if err != nil {
+		return nil, errEncryptedFSKeyFn(err)
+	}
+	plaintext, err := decryptFile(key, ciphertext)
+	if err != nil {
+		return nil, errEncryptedFSDecryptFn(name, err)
+	}
+	return &file{
+		reader: io.NopCloser(bytes.NewReader(plaintext)),
+		info: &fileInfo{
+			name:    info.Name(),
+			size:    int64(len(plaintext)),
+			mode:    info.Mode(),
+			modTime: info.ModTime(),
+			isDir:   false,
+			sys:     info.Sys(),
+		},
+	}, nil
+}
+
+// Stat implements the fs.StatFS interface. The size of the returned file
+// info is the size of the decrypted file, so the file is decrypted.
+func (e *encryptedFS) Stat(name string) (fs.FileInfo, error) {
+	if err := validPath("stat", name); err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	f, err := e.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+// ReadFile implements the fs.ReadFileFS interface.
+func (e *encryptedFS) ReadFile(name string) ([]byte, error) {
+	if err := validPath("readFile", name); err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	f, err := e.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// Glob implements the fs.GlobFS interface.
+func (e *encryptedFS) Glob(pattern string) ([]string, error) {
+	if err := validPattern("glob", pattern); err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	return fs.Glob(e.fs, pattern)
+}
+
+// ReadDir implements the fs.ReadDirFS interface.
+func (e *encryptedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := validPath("readDir", name); err != nil {
+		return nil, errEncryptedFSFn(err)
+	}
+	return fs.ReadDir(e.fs, name)
+}
+
+// decryptFile decrypts the contents of an encrypted file.
+func decryptFile(key *ecdsa.PrivateKey, ciphertext []byte) (_ []byte, err error) {
+	// Raw ciphertexts start with an uncompressed public key, whose first
+	// byte is 0x04. Text encoded ciphertexts are hex strings, so they never
+	// start with that byte.
+	if len(ciphertext) > 0 && ciphertext[0] != 0x04 {
+		_, ciphertext, err = cryptoutil.ParseCiphertext(string(bytes.TrimSpace(ciphertext)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cryptoutil.Decrypt(key, ciphertext)
+}
+
+var (
+	errEncryptedProtoNilURI      = errutil.WithCode(errors.New("fsutil.encryptedProto: nil URI"), errutil.CodeConfig)
+	errEncryptedFSNilKeyProvider = errutil.WithCode(errors.New("fsutil.encryptedFS: nil key provider"), errutil.CodeConfig)
+)
+
+func errEncryptedProtoFn(err error) error {
+	return fmt.Errorf("fsutil.encryptedProto: %w", err)
+}
+
+func errEncryptedProtoUnexpectedSchemeFn(scheme string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.encryptedProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
+}
+
+func errEncryptedFSFn(err error) error {
+	return fmt.Errorf("fsutil.encryptedFS: %w", err)
+}
+
+func errEncryptedFSKeyFn(err error) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.encryptedFS: unable to get key: %w", err), errutil.CodeConfig)
+}
+
+func errEncryptedFSDecryptFn(name string, err error) error {
+	return errutil.WithCode(
+		fmt.Errorf("fsutil.encryptedFS: failed to decrypt %s: %w", redactName(name), err),
+		errutil.CodeIntegrity,
+	)
+}
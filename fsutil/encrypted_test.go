@@ -0,0 +1,163 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"io/fs"
+	"math/big"
+	"net/url"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/cryptoutil"
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// Private keys 1 and 2 on the secp256k1 curve.
+var (
+	encryptedTestKey1 = encryptedTestKey(
+		"1",
+		"79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8",
+	)
+	encryptedTestKey2 = encryptedTestKey(
+		"2",
+		"C6047F9441ED7D6D3045406E95C07CD85C778E4B8CEF3CA7ABAC09B95C709EE5",
+		"1AE168FEA63DC339A3C58419466CEAEEF7F632653266D0E1236431A950CFE52A",
+	)
+)
+
+func encryptedTestKey(d, x, y string) *ecdsa.PrivateKey {
+	k := &ecdsa.PrivateKey{D: new(big.Int)}
+	k.D.SetString(d, 16)
+	k.X, _ = new(big.Int).SetString(x, 16)
+	k.Y, _ = new(big.Int).SetString(y, 16)
+	return k
+}
+
+func TestEncryptedProto(t *testing.T) {
+	plaintext := []byte(`foo = "bar"`)
+	raw, err := cryptoutil.Encrypt(&encryptedTestKey1.PublicKey, plaintext)
+	require.NoError(t, err)
+	_, encoded, err := cryptoutil.EncryptForAddress(&encryptedTestKey1.PublicKey, plaintext)
+	require.NoError(t, err)
+
+	files := fstest.MapFS{
+		"raw.hcl.ecies":     &fstest.MapFile{Data: raw},
+		"encoded.hcl.ecies": &fstest.MapFile{Data: []byte(encoded + "\n")},
+		"plain.hcl":         &fstest.MapFile{Data: plaintext},
+	}
+	inner := NewMux(map[string]ProtoFunc{
+		"file": func(*url.URL) (Protocol, error) { return &mockProto{fs: files}, nil },
+	})
+	tests := []struct {
+		name        string
+		key         KeyProvider
+		opts        []EncryptedFSOption
+		uri         string
+		wantData    []byte
+		wantErr     bool
+		wantErrFS   bool
+		wantErrCode errutil.Code
+	}{
+		{
+			name:     "raw ciphertext",
+			key:      StaticKey(encryptedTestKey1),
+			uri:      "enc+file:///raw.hcl.ecies",
+			wantData: plaintext,
+		},
+		{
+			name:     "encoded ciphertext",
+			key:      StaticKey(encryptedTestKey1),
+			uri:      "enc+file:///encoded.hcl.ecies",
+			wantData: plaintext,
+		},
+		{
+			name:        "wrong key",
+			key:         StaticKey(encryptedTestKey2),
+			uri:         "enc+file:///raw.hcl.ecies",
+			wantErr:     true,
+			wantErrCode: errutil.CodeIntegrity,
+		},
+		{
+			name:        "plaintext file",
+			key:         StaticKey(encryptedTestKey1),
+			uri:         "enc+file:///plain.hcl",
+			wantErr:     true,
+			wantErrCode: errutil.CodeIntegrity,
+		},
+		{
+			name:        "key provider error",
+			key:         func() (*ecdsa.PrivateKey, error) { return nil, errors.New("locked") },
+			uri:         "enc+file:///raw.hcl.ecies",
+			wantErr:     true,
+			wantErrCode: errutil.CodeConfig,
+		},
+		{
+			name:    "file too large",
+			key:     StaticKey(encryptedTestKey1),
+			opts:    []EncryptedFSOption{WithEncryptedMaxBytes(8 * bytesize.B)},
+			uri:     "enc+file:///raw.hcl.ecies",
+			wantErr: true,
+		},
+		{
+			name:      "missing prefix",
+			key:       StaticKey(encryptedTestKey1),
+			uri:       "file:///raw.hcl.ecies",
+			wantErrFS: true,
+		},
+		{
+			name:      "missing inner scheme",
+			key:       StaticKey(encryptedTestKey1),
+			uri:       "enc+:///raw.hcl.ecies",
+			wantErrFS: true,
+		},
+		{
+			name:      "nil key provider",
+			uri:       "enc+file:///raw.hcl.ecies",
+			wantErrFS: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys, path, err := ParseURI(NewEncryptedProto(inner, tt.key, tt.opts...), tt.uri)
+			if tt.wantErrFS {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			data, err := fs.ReadFile(fsys, path)
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.wantErrCode != errutil.CodeUnknown {
+					assert.Equal(t, tt.wantErrCode, errutil.CodeOf(err))
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, data)
+
+			info, err := fs.Stat(fsys, path)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.wantData)), info.Size())
+		})
+	}
+}
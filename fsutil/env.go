@@ -0,0 +1,303 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	netURL "net/url"
+	"os"
+	"strings"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// EnvScheme is the URI scheme of the environment variable protocol.
+const EnvScheme = "env"
+
+// EnvEncodingParam is the name of the query parameter that lists the
+// encodings of the environment variable value.
+const EnvEncodingParam = "encoding"
+
+// EnvEncoding is an encoding of an environment variable value.
+type EnvEncoding string
+
+const (
+	// EnvEncodingBase64 decodes base64 encoded values. Both the standard
+	// and the URL alphabet are accepted, padding is optional.
+	EnvEncodingBase64 EnvEncoding = "base64"
+
+	// EnvEncodingGzip decompresses gzip compressed values. Since
+	// environment variables cannot hold arbitrary bytes, it is usually
+	// preceded by EnvEncodingBase64.
+	EnvEncodingGzip EnvEncoding = "gzip"
+)
+
+type EnvOption func(*envFS)
+
+// WithEnvLookup sets the function used to look up environment variables.
+// The default is os.LookupEnv.
+func WithEnvLookup(lookup func(name string) (string, bool)) EnvOption {
+	return func(e *envFS) {
+		e.lookup = lookup
+	}
+}
+
+// WithEnvEncoding sets the encodings of the value, applied in the given
+// order. For example, EnvEncodingBase64 followed by EnvEncodingGzip
+// decodes a base64 encoded gzip archive.
+func WithEnvEncoding(encs ...EnvEncoding) EnvOption {
+	return func(e *envFS) {
+		e.encs = encs
+	}
+}
+
+// WithEnvReadLimit sets the maximum size of the decompressed value. If the
+// limit is exceeded, ErrFileTooLarge is returned. The default limit is
+// 128MiB.
+func WithEnvReadLimit(limit bytesize.Size) EnvOption {
+	return func(e *envFS) {
+		e.readLimit = limit
+	}
+}
+
+// NewEnvProto creates a new environment variable protocol for URIs of the
+// form "env://MY_CONFIG". The returned file system contains a single file
+// named after the variable, whose content is the value of the variable.
+//
+// The "encoding" parameter lists the encodings of the value, separated by
+// commas, e.g. "env://MY_CONFIG?encoding=base64,gzip". If present, it
+// replaces the encodings set using WithEnvEncoding, an empty parameter
+// disables them. Other parameters, such as "checksum", are kept in the
+// returned path.
+func NewEnvProto(opts ...EnvOption) Protocol {
+	return &envProto{opts: opts}
+}
+
+type envProto struct {
+	opts []EnvOption
+}
+
+// FileSystem implements the Protocol interface.
+func (e *envProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err error) {
+	if uri == nil {
+		return nil, "", errEnvProtoNilURI
+	}
+	if uri.Scheme != EnvScheme {
+		return nil, "", errEnvProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	if uri.Opaque != "" {
+		return nil, "", errEnvProtoOpaqueNotAllowed
+	}
+	if uri.Fragment != "" || uri.RawFragment != "" {
+		return nil, "", errEnvProtoFragmentNotAllowed
+	}
+	if uri.User != nil || uri.Port() != "" {
+		return nil, "", errEnvProtoInvalidName
+	}
+	if uri.Path != "" && uri.Path != "/" {
+		return nil, "", errEnvProtoPathNotAllowed
+	}
+	query := uri.Query()
+	opts := e.opts
+	if query.Has(EnvEncodingParam) {
+		var encs []EnvEncoding
+		if v := query.Get(EnvEncodingParam); v != "" {
+			for _, enc := range strings.Split(v, ",") {
+				encs = append(encs, EnvEncoding(strings.TrimSpace(enc)))
+			}
+		}
+		opts = append(opts[:len(opts):len(opts)], WithEnvEncoding(encs...))
+		query.Del(EnvEncodingParam)
+	}
+	fs, err = NewEnvFS(uri.Host, opts...)
+	if err != nil {
+		return nil, "", errEnvProtoFn(err)
+	}
+	return fs, uriPath(&netURL.URL{Path: uri.Host, RawQuery: query.Encode()}, true), nil
+}
+
+// NewEnvFS creates a file system that contains a single file with the
+// given name, whose content is the value of the environment variable with
+// the same name. The variable is read every time the file is opened.
+//
+// If the variable is not set, fs.ErrNotExist is returned. Error messages
+// never include the value of the variable.
+func NewEnvFS(name string, opts ...EnvOption) (fs.FS, error) {
+	if name == "" || !fs.ValidPath(name) || strings.Contains(name, "/") {
+		return nil, errEnvFSInvalidNameFn(name)
+	}
+	e := &envFS{name: name, readLimit: defaultGzipReadLimit}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.lookup == nil {
+		e.lookup = os.LookupEnv
+	}
+	for _, enc := range e.encs {
+		if enc != EnvEncodingBase64 && enc != EnvEncodingGzip {
+			return nil, errEnvFSUnsupportedEncodingFn(enc)
+		}
+	}
+	return e, nil
+}
+
+type envFS struct {
+	name      string
+	lookup    func(string) (string, bool)
+	encs      []EnvEncoding
+	readLimit bytesize.Size
+}
+
+// Open implements the fs.FS interface.
+func (e *envFS) Open(name string) (fs.File, error) {
+	b, err := e.read("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &file{
+		reader: io.NopCloser(bytes.NewReader(b)),
+		info:   &fileInfo{name: e.name, size: int64(len(b))},
+	}, nil
+}
+
+// ReadFile implements the fs.ReadFileFS interface.
+func (e *envFS) ReadFile(name string) ([]byte, error) {
+	return e.read("readFile", name)
+}
+
+// Stat implements the fs.StatFS interface.
+func (e *envFS) Stat(name string) (fs.FileInfo, error) {
+	if name == "." {
+		return &fileInfo{name: ".", mode: fs.ModeDir, isDir: true}, nil
+	}
+	b, err := e.read("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{name: e.name, size: int64(len(b))}, nil
+}
+
+// ReadDir implements the fs.ReadDirFS interface. The root directory
+// contains the file if the variable is set.
+func (e *envFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := validPath("readDir", name); err != nil {
+		return nil, errEnvFSFn(err)
+	}
+	if name != "." {
+		return nil, errEnvFSFn(&fs.PathError{Op: "readDir", Path: name, Err: fs.ErrNotExist})
+	}
+	info, err := e.Stat(e.name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []fs.DirEntry{fs.FileInfoToDirEntry(info)}, nil
+}
+
+// read returns the decoded value of the variable.
+func (e *envFS) read(op, name string) ([]byte, error) {
+	if err := validPath(op, name); err != nil {
+		return nil, errEnvFSFn(err)
+	}
+	v, ok := "", false
+	if name == e.name {
+		v, ok = e.lookup(e.name)
+	}
+	if !ok {
+		return nil, errEnvFSFn(&fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist})
+	}
+	b := []byte(v)
+	for _, enc := range e.encs {
+		var err error
+		switch enc {
+		case EnvEncodingBase64:
+			b, err = decodeEnvBase64(b)
+		case EnvEncodingGzip:
+			b, err = e.gunzip(b)
+		}
+		if err != nil {
+			return nil, errEnvFSDecodeFn(e.name, enc, err)
+		}
+	}
+	return b, nil
+}
+
+func (e *envFS) gunzip(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, int64(e.readLimit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(out)) > int64(e.readLimit) {
+		return nil, ErrFileTooLarge
+	}
+	return out, nil
+}
+
+// decodeEnvBase64 decodes base64 using the standard or the URL alphabet,
+// with or without padding. Whitespace is ignored, so values wrapped by the
+// base64 command can be used.
+func decodeEnvBase64(b []byte) ([]byte, error) {
+	s := strings.TrimRight(strings.Join(strings.Fields(string(b)), ""), "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+var (
+	errEnvProtoNilURI             = errutil.WithCode(errors.New("fsutil.envProto: nil URI"), errutil.CodeConfig)
+	errEnvProtoOpaqueNotAllowed   = errutil.WithCode(errors.New("fsutil.envProto: opaque not allowed"), errutil.CodeConfig)
+	errEnvProtoFragmentNotAllowed = errutil.WithCode(errors.New("fsutil.envProto: fragment not allowed"), errutil.CodeConfig)
+	errEnvProtoPathNotAllowed     = errutil.WithCode(errors.New("fsutil.envProto: path not allowed"), errutil.CodeConfig)
+	errEnvProtoInvalidName        = errutil.WithCode(errors.New("fsutil.envProto: invalid variable name"), errutil.CodeConfig)
+)
+
+func errEnvProtoFn(err error) error {
+	return fmt.Errorf("fsutil.envProto: %w", err)
+}
+
+func errEnvProtoUnexpectedSchemeFn(scheme string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.envProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
+}
+
+func errEnvFSFn(err error) error {
+	return fmt.Errorf("fsutil.envFS: %w", err)
+}
+
+func errEnvFSInvalidNameFn(name string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.envFS: invalid variable name: %q", name), errutil.CodeConfig)
+}
+
+func errEnvFSUnsupportedEncodingFn(enc EnvEncoding) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.envFS: unsupported encoding: %q", enc), errutil.CodeConfig)
+}
+
+func errEnvFSDecodeFn(name string, enc EnvEncoding, err error) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.envFS: %s: invalid %s value: %w", name, enc, err), errutil.CodeConfig)
+}
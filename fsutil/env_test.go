@@ -0,0 +1,189 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/fs"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+func TestEnvProto(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte("compressed"))
+	require.NoError(t, w.Close())
+
+	env := map[string]string{
+		"PLAIN":   "plain",
+		"BASE64":  base64.StdEncoding.EncodeToString([]byte("encoded")),
+		"WRAPPED": "ZW5j\nb2Rl\nZA==\n",
+		"URL":     base64.RawURLEncoding.EncodeToString([]byte{0xfb, 0xff}),
+		"GZIP":    base64.StdEncoding.EncodeToString(gz.Bytes()),
+		"INVALID": "not base64!",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tc := []struct {
+		name     string
+		uri      string
+		wantPath string
+		wantData string
+		wantErr  error
+		wantCode errutil.Code
+	}{
+		{name: "nil URL", wantCode: errutil.CodeConfig},
+		{name: "unexpected scheme", uri: "file://PLAIN", wantCode: errutil.CodeConfig},
+		{name: "empty name", uri: "env://", wantCode: errutil.CodeConfig},
+		{name: "path", uri: "env://PLAIN/file.hcl", wantCode: errutil.CodeConfig},
+		{name: "fragment", uri: "env://PLAIN#x", wantCode: errutil.CodeConfig},
+		{name: "unsupported encoding", uri: "env://PLAIN?encoding=hex", wantCode: errutil.CodeConfig},
+		{name: "plain", uri: "env://PLAIN", wantPath: "PLAIN", wantData: "plain"},
+		{name: "base64", uri: "env://BASE64?encoding=base64", wantPath: "BASE64", wantData: "encoded"},
+		{name: "wrapped base64", uri: "env://WRAPPED?encoding=base64", wantPath: "WRAPPED", wantData: "encoded"},
+		{name: "URL base64", uri: "env://URL?encoding=base64", wantPath: "URL", wantData: "\xfb\xff"},
+		{name: "gzip", uri: "env://GZIP?encoding=base64,gzip", wantPath: "GZIP", wantData: "compressed"},
+		{name: "other parameters", uri: "env://PLAIN?checksum=x", wantPath: "PLAIN?checksum=x"},
+		{name: "not set", uri: "env://MISSING", wantPath: "MISSING", wantErr: fs.ErrNotExist, wantCode: errutil.CodeNotFound},
+		{name: "invalid base64", uri: "env://INVALID?encoding=base64", wantPath: "INVALID", wantCode: errutil.CodeConfig},
+		{name: "invalid gzip", uri: "env://PLAIN?encoding=gzip", wantPath: "PLAIN", wantCode: errutil.CodeConfig},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var u *url.URL
+			if tt.uri != "" {
+				var err error
+				u, err = url.Parse(tt.uri)
+				require.NoError(t, err)
+			}
+			fsys, path, err := NewEnvProto(WithEnvLookup(lookup)).FileSystem(u)
+			if tt.wantPath == "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			if strings.Contains(path, "?") {
+				return
+			}
+			b, err := fs.ReadFile(fsys, path)
+			if tt.wantCode != errutil.CodeUnknown {
+				require.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+				if v := env[path]; v != "" {
+					assert.NotContains(t, err.Error(), v)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, string(b))
+		})
+	}
+}
+
+func TestEnvProto_Encoding(t *testing.T) {
+	env := map[string]string{
+		"PLAIN":  "plain",
+		"BASE64": base64.StdEncoding.EncodeToString([]byte("encoded")),
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	proto := NewEnvProto(WithEnvLookup(lookup), WithEnvEncoding(EnvEncodingBase64))
+	tc := []struct {
+		name     string
+		uri      string
+		wantData string
+	}{
+		{name: "proto encoding", uri: "env://BASE64", wantData: "encoded"},
+		{name: "URI encoding", uri: "env://BASE64?encoding=base64", wantData: "encoded"},
+		{name: "empty URI encoding", uri: "env://PLAIN?encoding=", wantData: "plain"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys, path, err := ParseURI(proto, tt.uri)
+			require.NoError(t, err)
+			b, err := fs.ReadFile(fsys, path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, string(b))
+		})
+	}
+}
+
+func TestEnvFS(t *testing.T) {
+	t.Setenv("FSUTIL_TEST_CONFIG", "config")
+	fsys, err := NewEnvFS("FSUTIL_TEST_CONFIG")
+	require.NoError(t, err)
+
+	t.Run("open", func(t *testing.T) {
+		f, err := fsys.Open("FSUTIL_TEST_CONFIG")
+		require.NoError(t, err)
+		defer f.Close()
+		info, err := f.Stat()
+		require.NoError(t, err)
+		assert.Equal(t, "FSUTIL_TEST_CONFIG", info.Name())
+		assert.Equal(t, int64(6), info.Size())
+	})
+	t.Run("read directory", func(t *testing.T) {
+		entries, err := fs.ReadDir(fsys, ".")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "FSUTIL_TEST_CONFIG", entries[0].Name())
+	})
+	t.Run("other variables are not exposed", func(t *testing.T) {
+		t.Setenv("FSUTIL_TEST_OTHER", "other")
+		_, err := fs.ReadFile(fsys, "FSUTIL_TEST_OTHER")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+	t.Run("value is read on open", func(t *testing.T) {
+		t.Setenv("FSUTIL_TEST_CONFIG", "changed")
+		b, err := fs.ReadFile(fsys, "FSUTIL_TEST_CONFIG")
+		require.NoError(t, err)
+		assert.Equal(t, "changed", string(b))
+	})
+	t.Run("read limit", func(t *testing.T) {
+		var gz bytes.Buffer
+		w := gzip.NewWriter(&gz)
+		_, _ = w.Write(bytes.Repeat([]byte("a"), 100))
+		require.NoError(t, w.Close())
+		t.Setenv("FSUTIL_TEST_CONFIG", base64.StdEncoding.EncodeToString(gz.Bytes()))
+		fsys, err := NewEnvFS("FSUTIL_TEST_CONFIG", WithEnvEncoding(EnvEncodingBase64, EnvEncodingGzip), WithEnvReadLimit(bytesize.Size(10)))
+		require.NoError(t, err)
+		_, err = fs.ReadFile(fsys, "FSUTIL_TEST_CONFIG")
+		assert.ErrorIs(t, err, ErrFileTooLarge)
+	})
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := NewEnvFS("A/B")
+		assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+	})
+}
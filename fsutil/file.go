@@ -21,6 +21,8 @@ import (
 	"io/fs"
 	netURL "net/url"
 	"os"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
 )
 
 type FileOption func(*fileProto)
@@ -64,12 +66,12 @@ func (m *fileProto) FileSystem(url *netURL.URL) (fs fs.FS, path string, err erro
 	return os.DirFS(m.wd), uriPath(url, true), nil
 }
 
-var errFileNilURI = errors.New("fsutil.fileProto: nil URI")
+var errFileNilURI = errutil.WithCode(errors.New("fsutil.fileProto: nil URI"), errutil.CodeConfig)
 
 func errFileUnexpectedSchemeFn(scheme string) error {
-	return fmt.Errorf("fsutil.fileProto: unexpected scheme: %s", scheme)
+	return errutil.WithCode(fmt.Errorf("fsutil.fileProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
 }
 
 func errFileUnexpectedHostFn(host string) error {
-	return fmt.Errorf("fsutil.fileProto: unexpected host: %s, must be empty or 'localhost'", host)
+	return errutil.WithCode(fmt.Errorf("fsutil.fileProto: unexpected host: %s, must be empty or 'localhost'", host), errutil.CodeConfig)
 }
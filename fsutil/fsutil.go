@@ -21,7 +21,11 @@ import (
 	"io/fs"
 	netURL "net/url"
 	"path"
+	"strings"
 	"time"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/urlutil"
 )
 
 // Protocol defines a file system protocol. It provides a file system instance
@@ -77,13 +81,57 @@ func (f *file) Read(p []byte) (n int, err error)     { return f.reader.Read(p) }
 func (f *file) Close() error                         { return f.reader.Close() }
 func (f *file) ReadDir(_ int) ([]fs.DirEntry, error) { return nil, errFileReadDirUnsupported }
 
+// ErrFileTooLarge is returned when a file exceeds the size limit set using
+// WithHTTPMaxBytes, WithChecksumMaxBytes or WithIPFSMaxBytes.
+var ErrFileTooLarge = errutil.WithCode(errors.New("fsutil: file exceeds the size limit"), errutil.CodeConfig)
+
+// maxBytesReader reads from r and returns ErrFileTooLarge if it contains
+// more than n bytes. It works like http.MaxBytesReader, but the error does
+// not refer to a request body.
+type maxBytesReader struct {
+	r   io.ReadCloser
+	n   int64 // Bytes remaining.
+	err error
+}
+
+func newMaxBytesReader(r io.ReadCloser, n int64) *maxBytesReader {
+	return &maxBytesReader{r: r, n: n}
+}
+
+func (m *maxBytesReader) Read(p []byte) (n int, err error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// Read one byte more than allowed to detect if the limit is exceeded.
+	if int64(len(p)) > m.n+1 {
+		p = p[:m.n+1]
+	}
+	n, err = m.r.Read(p)
+	if int64(n) <= m.n {
+		m.n -= int64(n)
+		m.err = err
+		return n, err
+	}
+	n = int(m.n)
+	m.n = 0
+	m.err = ErrFileTooLarge
+	return n, m.err
+}
+
+func (m *maxBytesReader) Close() error {
+	return m.r.Close()
+}
+
 func isPathError(err error) bool {
 	var e *fs.PathError
 	return errors.As(err, &e)
 }
 
 var (
-	errFSProtoNilURI          = errors.New("fsutil.fsProto: nil URI")
+	errFSProtoNilURI          = errutil.WithCode(errors.New("fsutil.fsProto: nil URI"), errutil.CodeConfig)
 	errFileReadDirUnsupported = errors.New("fsutil.file: ReadDir not supported")
 )
 
@@ -101,6 +149,16 @@ func validPattern(operation, pattern string) error { //nolint:unparam
 	return nil
 }
 
+// redactName redacts credentials from file names that contain a query
+// string, such as names used by the HTTP file system. It should be used
+// whenever a name is included in an error message or a log entry.
+func redactName(name string) string {
+	if !strings.Contains(name, "?") {
+		return name
+	}
+	return urlutil.RedactString(name)
+}
+
 func errInvalidPathFn(operation string, path string) error {
-	return &fs.PathError{Op: operation, Path: path, Err: fs.ErrInvalid}
+	return &fs.PathError{Op: operation, Path: redactName(path), Err: fs.ErrInvalid}
 }
@@ -0,0 +1,331 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	netURL "net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/syncutil"
+	"github.com/chronicleprotocol/go-lib/urlutil"
+)
+
+// GitSchemePrefix is the prefix of the URI schemes supported by the Git
+// protocol. The rest of the scheme is the transport used to fetch the
+// repository, e.g. "git+https".
+const GitSchemePrefix = "git+"
+
+// GitRefParam is the name of the query parameter that pins the revision
+// of the repository.
+const GitRefParam = "ref"
+
+type GitOption func(*gitProto)
+
+// WithGitBinary sets the path to the git executable. By default, "git" is
+// looked up in the PATH.
+func WithGitBinary(bin string) GitOption {
+	return func(g *gitProto) {
+		g.bin = bin
+	}
+}
+
+// WithGitDir sets the directory in which repositories are checked out.
+// By default, a temporary directory is created on first use and removed
+// when the context is canceled. A directory set with this option is owned
+// by the caller and is not removed, only the checkouts created in it are
+// removed when the context is canceled.
+func WithGitDir(dir string) GitOption {
+	return func(g *gitProto) {
+		g.dir = dir
+	}
+}
+
+// WithGitLogger sets the logger used to log fetched repositories.
+func WithGitLogger(logger logutil.Logger) GitOption {
+	return func(g *gitProto) {
+		g.logger = logger
+	}
+}
+
+// NewGitProto creates a new Git protocol for the "git+https", "git+http",
+// "git+ssh" and "git+file" schemes.
+//
+// The URI has the form "git+https://host/org/repo//path?ref=v1.0.0". The
+// double slash separates the repository from the path of the file within
+// it. If there is no double slash, the root of the repository is used.
+// The "ref" parameter may be a tag, a branch or a full commit hash. If it
+// is omitted, the default branch is used.
+//
+// Only the requested revision is fetched, using a shallow fetch with the
+// git executable, which uses the smart HTTP protocol for HTTP remotes.
+// Checkouts are cached for the lifetime of the protocol, so every URI
+// with the same repository and ref is served from the same tree. To load
+// configuration reproducibly, a tag or a commit hash should be used. If
+// the ref is a commit hash, the fetched commit is verified to match it.
+// Different repositories and refs are fetched concurrently. Checkouts are
+// removed when the context is canceled.
+//
+// The returned file system is read-only and cannot access files outside
+// the checked out tree, even through symbolic links. The ".git"
+// directory is not included.
+//
+// Git runs with terminal prompts disabled, so credentials must be
+// provided in the URI, by a credential helper or by an SSH agent.
+func NewGitProto(ctx context.Context, opts ...GitOption) Protocol {
+	g := &gitProto{ctx: ctx, repos: make(map[string]*gitRepo)}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.bin == "" {
+		g.bin = "git"
+	}
+	g.logger = logutil.OrNop(g.logger)
+	return g
+}
+
+type gitProto struct {
+	mu     sync.Mutex                  // Guards dir and repos.
+	keys   syncutil.KeyedMutex[string] // Serializes fetches of the same key.
+	ctx    context.Context
+	bin    string
+	dir    string
+	logger logutil.Logger
+	repos  map[string]*gitRepo // Key is the repository URL and the ref.
+}
+
+type gitRepo struct {
+	fs     fs.FS
+	commit string
+}
+
+// gitRefRx matches refs that are safe to pass to git. Refs must not start
+// with a dash, so they are not interpreted as options.
+var gitRefRx = regexp.MustCompile(`^[A-Za-z0-9._/][A-Za-z0-9._/-]*$`)
+
+// gitHashRx matches full SHA-1 and SHA-256 commit hashes.
+var gitHashRx = regexp.MustCompile(`^([0-9a-fA-F]{40}|[0-9a-fA-F]{64})$`)
+
+// FileSystem implements the Protocol interface.
+func (g *gitProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err error) {
+	repo, ref, file, err := parseGitURI(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := g.checkout(repo, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	return r.fs, uriPath(file, true), nil
+}
+
+// parseGitURI splits the URI into the repository URL, the ref and a URI
+// whose path and query refer to the file within the repository.
+func parseGitURI(uri *netURL.URL) (repo *netURL.URL, ref string, file *netURL.URL, err error) {
+	if uri == nil {
+		return nil, "", nil, errGitProtoNilURI
+	}
+	transport, ok := strings.CutPrefix(uri.Scheme, GitSchemePrefix)
+	if !ok {
+		return nil, "", nil, errGitProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	switch transport {
+	case "http", "https", "ssh", "file":
+	default:
+		return nil, "", nil, errGitProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	if uri.Opaque != "" {
+		return nil, "", nil, errGitProtoOpaqueNotAllowed
+	}
+	if uri.Fragment != "" || uri.RawFragment != "" {
+		return nil, "", nil, errGitProtoFragmentNotAllowed
+	}
+	if transport != "file" && uri.Host == "" {
+		return nil, "", nil, errGitProtoEmptyHost
+	}
+	query := uri.Query()
+	ref = query.Get(GitRefParam)
+	query.Del(GitRefParam)
+	if ref != "" && !gitRefRx.MatchString(ref) {
+		return nil, "", nil, errGitProtoInvalidRefFn(ref)
+	}
+	repoPath, filePath, _ := strings.Cut(uri.Path, "//")
+	if strings.Trim(repoPath, "/") == "" {
+		return nil, "", nil, errGitProtoEmptyRepo
+	}
+	repo = uriCopy(uri)
+	repo.Scheme = transport
+	repo.Path = repoPath
+	repo.RawPath = ""
+	repo.ForceQuery = false
+	repo.RawQuery = ""
+	file = &netURL.URL{Path: filePath, RawQuery: query.Encode()}
+	return repo, ref, file, nil
+}
+
+// checkout returns the checked out tree of the repository at the given
+// ref, fetching it if it is not cached yet. Failed fetches are not cached.
+//
+// Only fetches of the same repository and ref wait for each other, so a
+// slow remote does not block the others.
+func (g *gitProto) checkout(repo *netURL.URL, ref string) (*gitRepo, error) {
+	key := repo.String() + "\x00" + ref
+	g.keys.Lock(key)
+	defer g.keys.Unlock(key)
+	base, r, err := g.cached(key)
+	if err != nil || r != nil {
+		return r, err
+	}
+	dir, err := os.MkdirTemp(base, "repo-")
+	if err != nil {
+		return nil, errGitProtoFn(err)
+	}
+	r, err = g.fetch(dir, repo, ref)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	g.logger.Info("Git repository fetched", "url", urlutil.Redact(repo), "ref", ref, "commit", r.commit)
+	g.mu.Lock()
+	g.repos[key] = r
+	g.mu.Unlock()
+	return r, nil
+}
+
+// cached returns the cached checkout for the key, if any, and the
+// directory in which new checkouts are created.
+func (g *gitProto) cached(key string) (string, *gitRepo, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if r, ok := g.repos[key]; ok {
+		return "", r, nil
+	}
+	if g.dir == "" {
+		dir, err := os.MkdirTemp("", "fsutil-git-")
+		if err != nil {
+			return "", nil, errGitProtoFn(err)
+		}
+		context.AfterFunc(g.ctx, func() { _ = os.RemoveAll(dir) })
+		g.dir = dir
+	}
+	return g.dir, nil, nil
+}
+
+// fetch performs a shallow fetch of the ref into dir and checks it out.
+func (g *gitProto) fetch(dir string, repo *netURL.URL, ref string) (*gitRepo, error) {
+	rev := ref
+	if rev == "" {
+		rev = "HEAD"
+	}
+	if _, err := g.git(repo, "init", "--quiet", dir); err != nil {
+		return nil, err
+	}
+	if _, err := g.git(repo, "-C", dir, "fetch", "--quiet", "--depth", "1", "--no-tags", "--", repo.String(), rev); err != nil {
+		return nil, err
+	}
+	if _, err := g.git(repo, "-C", dir, "checkout", "--quiet", "--detach", "FETCH_HEAD"); err != nil {
+		return nil, err
+	}
+	out, err := g.git(repo, "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	commit := strings.TrimSpace(string(out))
+	if gitHashRx.MatchString(ref) && !strings.EqualFold(ref, commit) {
+		return nil, errGitProtoCommitMismatchFn(ref, commit)
+	}
+	// Only the tree at the pinned ref is exposed.
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		return nil, errGitProtoFn(err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, errGitProtoFn(err)
+	}
+	context.AfterFunc(g.ctx, func() {
+		_ = root.Close()
+		_ = os.RemoveAll(dir)
+	})
+	return &gitRepo{fs: root.FS(), commit: commit}, nil
+}
+
+// git runs the git executable with the given arguments and returns its
+// standard output. Credentials of the repository URL are redacted from
+// the error message.
+func (g *gitProto) git(repo *netURL.URL, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(g.ctx, g.bin, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		msg = strings.ReplaceAll(msg, repo.String(), urlutil.Redact(repo))
+		if p, ok := repo.User.Password(); ok && p != "" {
+			msg = strings.ReplaceAll(msg, p, urlutil.Mask)
+		}
+		return nil, errGitProtoCommandFn(args, repo, msg, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+var (
+	errGitProtoNilURI             = errutil.WithCode(errors.New("fsutil.gitProto: nil URI"), errutil.CodeConfig)
+	errGitProtoOpaqueNotAllowed   = errutil.WithCode(errors.New("fsutil.gitProto: opaque not allowed"), errutil.CodeConfig)
+	errGitProtoFragmentNotAllowed = errutil.WithCode(errors.New("fsutil.gitProto: fragment not allowed"), errutil.CodeConfig)
+	errGitProtoEmptyHost          = errutil.WithCode(errors.New("fsutil.gitProto: empty host"), errutil.CodeConfig)
+	errGitProtoEmptyRepo          = errutil.WithCode(errors.New("fsutil.gitProto: empty repository path"), errutil.CodeConfig)
+)
+
+func errGitProtoFn(err error) error {
+	return fmt.Errorf("fsutil.gitProto: %w", err)
+}
+
+func errGitProtoUnexpectedSchemeFn(scheme string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.gitProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
+}
+
+func errGitProtoInvalidRefFn(ref string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.gitProto: invalid ref: %q", ref), errutil.CodeConfig)
+}
+
+func errGitProtoCommitMismatchFn(ref, commit string) error {
+	return errutil.WithCode(
+		fmt.Errorf("fsutil.gitProto: fetched commit %s does not match ref %s", commit, ref),
+		errutil.CodeIntegrity,
+	)
+}
+
+func errGitProtoCommandFn(args []string, repo *netURL.URL, stderr string, err error) error {
+	cmd := args[0]
+	if cmd == "-C" && len(args) > 2 {
+		cmd = args[2]
+	}
+	if stderr == "" {
+		return fmt.Errorf("fsutil.gitProto: git %s %s failed: %w", cmd, urlutil.Redact(repo), err)
+	}
+	return fmt.Errorf("fsutil.gitProto: git %s %s failed: %w: %s", cmd, urlutil.Redact(repo), err, stderr)
+}
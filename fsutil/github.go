@@ -0,0 +1,490 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	netURL "net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/httputil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/retry"
+	"github.com/chronicleprotocol/go-lib/urlutil"
+)
+
+// GitHubScheme is the URI scheme of the GitHub protocol.
+const GitHubScheme = "github"
+
+// ErrGitHubRateLimit is returned when the GitHub API rate limit is
+// exceeded. The error suggests the time to wait before the next attempt
+// using the retry.RetryAfter interface.
+var ErrGitHubRateLimit = errors.New("GitHub API rate limit exceeded")
+
+// githubAPIURL is the URL of the public GitHub REST API.
+var githubAPIURL = &netURL.URL{Scheme: "https", Host: "api.github.com"}
+
+type GitHubOption func(*githubFS)
+
+// WithGitHubToken sets the token used to authenticate requests, such as a
+// personal access token or an installation token of a GitHub App. It is
+// required to read private repositories and increases the rate limit.
+func WithGitHubToken(token string) GitHubOption {
+	return func(g *githubFS) {
+		g.token = token
+	}
+}
+
+// WithGitHubAPIURL sets the URL of the GitHub REST API. It can be used
+// for GitHub Enterprise Server, e.g. "https://github.example.com/api/v3".
+func WithGitHubAPIURL(url *netURL.URL) GitHubOption {
+	return func(g *githubFS) {
+		g.apiURL = url
+	}
+}
+
+// WithGitHubHTTPClient sets the HTTP client used to make requests.
+func WithGitHubHTTPClient(client *http.Client) GitHubOption {
+	return func(g *githubFS) {
+		g.client = client
+	}
+}
+
+// WithGitHubLogger sets the logger used to log requests.
+func WithGitHubLogger(logger logutil.Logger) GitHubOption {
+	return func(g *githubFS) {
+		g.logger = logger
+	}
+}
+
+// WithGitHubMaxBytes sets the maximum size of files and API responses.
+// If the limit is exceeded, ErrFileTooLarge is returned. Zero means no
+// limit.
+func WithGitHubMaxBytes(limit bytesize.Size) GitHubOption {
+	return func(g *githubFS) {
+		g.maxBytes = limit
+	}
+}
+
+// WithGitHubMaxRateLimitWait sets the longest wait for the rate limit reset
+// suggested to retries, see retry.RetryAfter. If the rate limit resets
+// later, the error is marked as permanent, see retry.Permanent, so retries
+// fail fast instead of blocking until the reset. The default is one minute.
+func WithGitHubMaxRateLimitWait(d time.Duration) GitHubOption {
+	return func(g *githubFS) {
+		g.maxWait = d
+	}
+}
+
+// NewGitHubProto creates a new GitHub protocol for URIs of the form
+// "github://owner/repo/path?ref=v1.0.0". The "ref" parameter may be a
+// tag, a branch or a commit hash. If it is omitted, the default branch of
+// the repository is used. Other parameters, such as "checksum", are kept
+// in the returned path.
+//
+// The rate limit state is shared by all file systems created by the
+// protocol, see NewGitHubFS.
+func NewGitHubProto(ctx context.Context, opts ...GitHubOption) Protocol {
+	return &githubProto{ctx: ctx, opts: opts, limit: &githubRateLimit{}}
+}
+
+type githubProto struct {
+	ctx   context.Context
+	opts  []GitHubOption
+	limit *githubRateLimit
+}
+
+// FileSystem implements the Protocol interface.
+func (g *githubProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err error) {
+	if uri == nil {
+		return nil, "", errGitHubProtoNilURI
+	}
+	if uri.Scheme != GitHubScheme {
+		return nil, "", errGitHubProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	if uri.Opaque != "" {
+		return nil, "", errGitHubProtoOpaqueNotAllowed
+	}
+	if uri.Fragment != "" || uri.RawFragment != "" {
+		return nil, "", errGitHubProtoFragmentNotAllowed
+	}
+	repo, filePath, _ := strings.Cut(strings.TrimPrefix(uri.Path, "/"), "/")
+	if uri.Host == "" || repo == "" {
+		return nil, "", errGitHubProtoEmptyRepo
+	}
+	query := uri.Query()
+	ref := query.Get(GitRefParam)
+	query.Del(GitRefParam)
+	f, err := newGitHubFS(g.ctx, uri.Host, repo, ref, g.opts...)
+	if err != nil {
+		return nil, "", errGitHubProtoFn(err)
+	}
+	f.limit = g.limit
+	return f, uriPath(&netURL.URL{Path: filePath, RawQuery: query.Encode()}, true), nil
+}
+
+// NewGitHubFS creates a file system that reads the repository at the given
+// ref using the GitHub contents API. If ref is empty, the default branch
+// is used.
+//
+// Files are downloaded using the raw media type, so files up to 100 MB
+// can be read. Directories can be listed using ReadDir.
+//
+// GitHub responds with 404 Not Found to unauthenticated requests for
+// private repositories, so fs.ErrNotExist is returned in that case as
+// well. Requests with an invalid token, or denied for other reasons,
+// return fs.ErrPermission.
+//
+// When the rate limit is exceeded, ErrGitHubRateLimit is returned, and no
+// further requests are made until the limit is reset. The error suggests
+// the delay before the next attempt, see retry.RetryAfter, unless it is
+// longer than the limit set using WithGitHubMaxRateLimitWait.
+//
+// Directories cannot be opened, use ReadDir to list them.
+func NewGitHubFS(ctx context.Context, owner, repo, ref string, opts ...GitHubOption) (fs.FS, error) {
+	f, err := newGitHubFS(ctx, owner, repo, ref, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newGitHubFS(ctx context.Context, owner, repo, ref string, opts ...GitHubOption) (*githubFS, error) {
+	if owner == "" || repo == "" || strings.Contains(owner, "/") || strings.Contains(repo, "/") {
+		return nil, errGitHubFSInvalidRepoFn(owner, repo)
+	}
+	f := &githubFS{ctx: ctx, owner: owner, repo: repo, ref: ref, limit: &githubRateLimit{}, maxWait: time.Minute}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.client == nil {
+		f.client = httputil.Default()
+	}
+	if f.apiURL == nil {
+		f.apiURL = githubAPIURL
+	}
+	f.logger = logutil.OrNop(f.logger)
+	return f, nil
+}
+
+type githubFS struct {
+	ctx      context.Context
+	client   *http.Client
+	apiURL   *netURL.URL
+	token    string
+	owner    string
+	repo     string
+	ref      string
+	logger   logutil.Logger
+	maxBytes bytesize.Size
+	maxWait  time.Duration
+	limit    *githubRateLimit
+}
+
+// githubRateLimit holds the time until which requests must not be made,
+// because the rate limit was exceeded.
+type githubRateLimit struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// wait returns the time remaining until the rate limit is reset.
+func (l *githubRateLimit) wait() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return max(time.Until(l.until), 0)
+}
+
+func (l *githubRateLimit) set(until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.until) {
+		l.until = until
+	}
+}
+
+// githubContent is an entry returned by the contents API.
+type githubContent struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	SHA  string `json:"sha"`
+}
+
+// Open implements the fs.FS interface. Only files can be opened.
+func (g *githubFS) Open(name string) (fs.File, error) {
+	if err := validPath("open", name); err != nil {
+		return nil, errGitHubFSFn(err)
+	}
+	url, res, err := g.get(name, "application/vnd.github.raw+json")
+	if err != nil {
+		return nil, err
+	}
+	// For directories, the raw media type is ignored and the directory
+	// listing is returned as JSON.
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == "application/json" {
+		_ = res.Body.Close()
+		return nil, errGitHubFSFn(&fs.PathError{Op: "open", Path: name, Err: errGitHubFSIsDir})
+	}
+	body := res.Body
+	if g.maxBytes > 0 {
+		if res.ContentLength > int64(g.maxBytes) {
+			_ = res.Body.Close()
+			return nil, errGitHubFSRequestErrorFn(url, ErrFileTooLarge)
+		}
+		body = newMaxBytesReader(body, int64(g.maxBytes))
+	}
+	meta := newHTTPMetadata(url, res)
+	modTime := meta.LastModified
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	return &file{
+		reader: body,
+		info: &fileInfo{
+			name:    path.Base(name),
+			size:    res.ContentLength,
+			modTime: modTime,
+			sys:     meta,
+		},
+	}, nil
+}
+
+// Stat implements the fs.StatFS interface.
+func (g *githubFS) Stat(name string) (fs.FileInfo, error) {
+	if err := validPath("stat", name); err != nil {
+		return nil, errGitHubFSFn(err)
+	}
+	url, raw, err := g.getJSON(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > 0 && raw[0] == '[' {
+		return &fileInfo{name: path.Base(name), mode: fs.ModeDir, isDir: true}, nil
+	}
+	var c githubContent
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, errGitHubFSInvalidResponseFn(url, err)
+	}
+	info := githubFileInfo(c)
+	info.name = path.Base(name)
+	return info, nil
+}
+
+// ReadDir implements the fs.ReadDirFS interface. Entries are sorted by
+// name.
+func (g *githubFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := validPath("readDir", name); err != nil {
+		return nil, errGitHubFSFn(err)
+	}
+	url, raw, err := g.getJSON(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || raw[0] != '[' {
+		return nil, errGitHubFSRequestErrorFn(url, errGitHubFSNotDir)
+	}
+	var cs []githubContent
+	if err := json.Unmarshal(raw, &cs); err != nil {
+		return nil, errGitHubFSInvalidResponseFn(url, err)
+	}
+	entries := make([]fs.DirEntry, 0, len(cs))
+	for _, c := range cs {
+		entries = append(entries, fs.FileInfoToDirEntry(githubFileInfo(c)))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// githubFileInfo converts an entry returned by the contents API to a
+// fs.FileInfo. The modification time is not known.
+func githubFileInfo(c githubContent) *fileInfo {
+	info := &fileInfo{name: c.Name, size: c.Size}
+	switch c.Type {
+	case "dir":
+		info.mode = fs.ModeDir
+		info.isDir = true
+	case "symlink":
+		info.mode = fs.ModeSymlink
+	case "submodule":
+		info.mode = fs.ModeIrregular
+	}
+	return info
+}
+
+// getJSON requests the JSON representation of the content at the given
+// path and returns the response body.
+func (g *githubFS) getJSON(name string) (*netURL.URL, []byte, error) {
+	url, res, err := g.get(name, "application/vnd.github+json")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	var body io.Reader = res.Body
+	if g.maxBytes > 0 {
+		body = newMaxBytesReader(res.Body, int64(g.maxBytes))
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, errGitHubFSRequestErrorFn(url, err)
+	}
+	return url, raw, nil
+}
+
+// get requests the content at the given path using the given media type.
+// The caller must close the response body.
+func (g *githubFS) get(name string, accept string) (*netURL.URL, *http.Response, error) {
+	url := g.contentsURL(name)
+	if d := g.limit.wait(); d > 0 {
+		return nil, nil, errGitHubFSRateLimitFn(url, d, g.maxWait)
+	}
+	req, err := http.NewRequestWithContext(g.ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return nil, nil, errGitHubFSRequestErrorFn(url, err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	g.logger.Debug("Fetching file", "url", urlutil.Redact(url))
+	res, err := g.client.Do(req)
+	if err != nil {
+		return nil, nil, errutil.WithCode(errGitHubFSRequestErrorFn(url, err), errutil.CodeTransient)
+	}
+	if res.StatusCode == http.StatusOK {
+		return url, res, nil
+	}
+	_ = res.Body.Close()
+	if d, ok := githubRateLimited(res); ok {
+		g.limit.set(time.Now().Add(d))
+		return nil, nil, errGitHubFSRateLimitFn(url, d, g.maxWait)
+	}
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return nil, nil, errGitHubFSRequestErrorFn(url, fs.ErrNotExist)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, nil, errGitHubFSRequestErrorFn(url, fs.ErrPermission)
+	}
+	return nil, nil, errGitHubFSRequestErrorCodeFn(url, res.StatusCode)
+}
+
+// contentsURL returns the URL of the contents API for the given path.
+func (g *githubFS) contentsURL(name string) *netURL.URL {
+	elems := []string{"repos", g.owner, g.repo, "contents"}
+	if name != "." {
+		elems = append(elems, strings.Split(name, "/")...)
+	}
+	url := g.apiURL.JoinPath(elems...)
+	if g.ref != "" {
+		url.RawQuery = netURL.Values{"ref": {g.ref}}.Encode()
+	}
+	return url
+}
+
+// githubRateLimited reports whether the response indicates that the
+// primary or a secondary rate limit was exceeded, and returns the time to
+// wait before the next request.
+//
+// See: https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api
+func githubRateLimited(res *http.Response) (time.Duration, bool) {
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if res.Header.Get("X-RateLimit-Remaining") == "0" {
+		if s, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Until(time.Unix(s, 0)), 0), true
+		}
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		// Secondary rate limits without a Retry-After header require
+		// waiting at least one minute.
+		return time.Minute, true
+	}
+	return 0, false
+}
+
+var (
+	errGitHubProtoNilURI             = errutil.WithCode(errors.New("fsutil.githubProto: nil URI"), errutil.CodeConfig)
+	errGitHubProtoOpaqueNotAllowed   = errutil.WithCode(errors.New("fsutil.githubProto: opaque not allowed"), errutil.CodeConfig)
+	errGitHubProtoFragmentNotAllowed = errutil.WithCode(errors.New("fsutil.githubProto: fragment not allowed"), errutil.CodeConfig)
+	errGitHubProtoEmptyRepo          = errutil.WithCode(errors.New("fsutil.githubProto: owner and repository must be set"), errutil.CodeConfig)
+	errGitHubFSNotDir                = errors.New("not a directory")
+	errGitHubFSIsDir                 = errors.New("is a directory")
+)
+
+func errGitHubProtoFn(err error) error {
+	return fmt.Errorf("fsutil.githubProto: %w", err)
+}
+
+func errGitHubProtoUnexpectedSchemeFn(scheme string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.githubProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
+}
+
+func errGitHubFSFn(err error) error {
+	return fmt.Errorf("fsutil.githubFS: %w", err)
+}
+
+func errGitHubFSInvalidRepoFn(owner, repo string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.githubFS: invalid repository: %q/%q", owner, repo), errutil.CodeConfig)
+}
+
+func errGitHubFSRequestErrorFn(url *netURL.URL, err error) error {
+	return fmt.Errorf("fsutil.githubFS: %s: %w", urlutil.Redact(url), urlutil.RedactError(err))
+}
+
+func errGitHubFSInvalidResponseFn(url *netURL.URL, err error) error {
+	return errutil.WithCode(
+		fmt.Errorf("fsutil.githubFS: %s: invalid response: %w", urlutil.Redact(url), err),
+		errutil.CodeIntegrity,
+	)
+}
+
+func errGitHubFSRateLimitFn(url *netURL.URL, wait, maxWait time.Duration) error {
+	err := fmt.Errorf("fsutil.githubFS: %s: %w, retry in %s", urlutil.Redact(url), ErrGitHubRateLimit, wait.Round(time.Second))
+	err = errutil.WithCode(err, errutil.CodeTransient)
+	if wait > maxWait {
+		return retry.Permanent(err)
+	}
+	return retry.WithDelay(err, wait)
+}
+
+func errGitHubFSRequestErrorCodeFn(url *netURL.URL, code int) error {
+	err := fmt.Errorf("fsutil.githubFS: %s: unexpected status code: %d %s", urlutil.Redact(url), code, http.StatusText(code))
+	if code >= http.StatusInternalServerError || code == http.StatusRequestTimeout {
+		return errutil.WithCode(err, errutil.CodeTransient)
+	}
+	return err
+}
@@ -0,0 +1,301 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/retry"
+)
+
+// githubTestFiles is the content of the test repository at the "v1" ref.
+// Directories have names ending with a slash.
+var githubTestFiles = map[string]string{
+	"":           "",
+	"config.hcl": "config",
+	"sub/":       "",
+	"sub/b.hcl":  "b",
+	"sub/a.hcl":  "a",
+}
+
+// githubTestHandler emulates the contents API for the "owner/repo"
+// repository. Requests must use the "token" token.
+func githubTestHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2022-11-28", r.Header.Get("X-GitHub-Api-Version"))
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		name, ok := strings.CutPrefix(r.URL.Path, "/repos/owner/repo/contents")
+		if !ok || r.URL.Query().Get("ref") != "v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		name = strings.TrimPrefix(name, "/")
+		isDir := false
+		if _, ok := githubTestFiles[name]; !ok {
+			if _, ok := githubTestFiles[name+"/"]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			isDir = true
+		}
+		if !isDir && r.Header.Get("Accept") == "application/vnd.github.raw+json" {
+			_, _ = w.Write([]byte(githubTestFiles[name]))
+			return
+		}
+		content := func(n string) githubContent {
+			if strings.HasSuffix(n, "/") {
+				n = strings.TrimSuffix(n, "/")
+				return githubContent{Type: "dir", Name: n[strings.LastIndex(n, "/")+1:], Path: n}
+			}
+			return githubContent{Type: "file", Name: n[strings.LastIndex(n, "/")+1:], Path: n, Size: int64(len(githubTestFiles[n]))}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !isDir && name != "" {
+			require.NoError(t, json.NewEncoder(w).Encode(content(name)))
+			return
+		}
+		prefix := name
+		if prefix != "" {
+			prefix += "/"
+		}
+		list := []githubContent{}
+		for n := range githubTestFiles {
+			rest, ok := strings.CutPrefix(n, prefix)
+			if ok && rest != "" && !strings.Contains(strings.TrimSuffix(rest, "/"), "/") {
+				list = append(list, content(n))
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(list))
+	})
+}
+
+func TestGitHubProto(t *testing.T) {
+	tc := []struct {
+		name     string
+		uri      string
+		wantRef  string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "nil URL", wantErr: true},
+		{name: "unexpected scheme", uri: "https://github.com/owner/repo", wantErr: true},
+		{name: "missing repository", uri: "github://owner", wantErr: true},
+		{name: "missing owner", uri: "github:///repo/config.hcl", wantErr: true},
+		{name: "fragment", uri: "github://owner/repo/config.hcl#x", wantErr: true},
+		{name: "root", uri: "github://owner/repo", wantPath: "."},
+		{name: "file", uri: "github://owner/repo/sub/a.hcl?ref=v1", wantRef: "v1", wantPath: "sub/a.hcl"},
+		{name: "other parameters", uri: "github://owner/repo/a.hcl?ref=v1&checksum=x", wantRef: "v1", wantPath: "a.hcl?checksum=x"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var u *url.URL
+			if tt.uri != "" {
+				var err error
+				u, err = url.Parse(tt.uri)
+				require.NoError(t, err)
+			}
+			fsys, path, err := NewGitHubProto(context.Background()).FileSystem(u)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, "owner", fsys.(*githubFS).owner)
+			assert.Equal(t, "repo", fsys.(*githubFS).repo)
+			assert.Equal(t, tt.wantRef, fsys.(*githubFS).ref)
+		})
+	}
+}
+
+func TestGitHubFS(t *testing.T) {
+	server := httptest.NewServer(githubTestHandler(t))
+	defer server.Close()
+	apiURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	fsys, err := NewGitHubFS(context.Background(), "owner", "repo", "v1", WithGitHubAPIURL(apiURL), WithGitHubToken("token"))
+	require.NoError(t, err)
+
+	t.Run("open", func(t *testing.T) {
+		b, err := fs.ReadFile(fsys, "sub/a.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(b))
+	})
+	t.Run("stat file", func(t *testing.T) {
+		info, err := fs.Stat(fsys, "config.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, "config.hcl", info.Name())
+		assert.Equal(t, int64(6), info.Size())
+		assert.False(t, info.IsDir())
+	})
+	t.Run("stat directory", func(t *testing.T) {
+		info, err := fs.Stat(fsys, "sub")
+		require.NoError(t, err)
+		assert.Equal(t, "sub", info.Name())
+		assert.True(t, info.IsDir())
+	})
+	t.Run("read directory", func(t *testing.T) {
+		entries, err := fs.ReadDir(fsys, ".")
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "config.hcl", entries[0].Name())
+		assert.Equal(t, "sub", entries[1].Name())
+		assert.True(t, entries[1].IsDir())
+	})
+	t.Run("glob", func(t *testing.T) {
+		matches, err := fs.Glob(fsys, "sub/*.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sub/a.hcl", "sub/b.hcl"}, matches)
+	})
+	t.Run("open directory", func(t *testing.T) {
+		_, err := fsys.Open("sub")
+		var pErr *fs.PathError
+		require.ErrorAs(t, err, &pErr)
+		assert.Equal(t, "open", pErr.Op)
+		assert.Equal(t, "sub", pErr.Path)
+	})
+	t.Run("read file as directory", func(t *testing.T) {
+		_, err := fs.ReadDir(fsys, "config.hcl")
+		assert.Error(t, err)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := fs.ReadFile(fsys, "missing.hcl")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		assert.Equal(t, errutil.CodeNotFound, errutil.CodeOf(err))
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := fsys.Open("../config.hcl")
+		assert.ErrorIs(t, err, fs.ErrInvalid)
+	})
+	t.Run("too large", func(t *testing.T) {
+		fsys, err := NewGitHubFS(context.Background(), "owner", "repo", "v1", WithGitHubAPIURL(apiURL), WithGitHubToken("token"), WithGitHubMaxBytes(bytesize.Size(2)))
+		require.NoError(t, err)
+		_, err = fs.ReadFile(fsys, "config.hcl")
+		assert.ErrorIs(t, err, ErrFileTooLarge)
+	})
+	t.Run("private repository without token", func(t *testing.T) {
+		fsys, err := NewGitHubFS(context.Background(), "owner", "repo", "v1", WithGitHubAPIURL(apiURL))
+		require.NoError(t, err)
+		_, err = fs.ReadFile(fsys, "config.hcl")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
+
+func TestGitHubFS_Errors(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	tc := []struct {
+		name          string
+		status        int
+		header        http.Header
+		opts          []GitHubOption
+		wantErr       error
+		wantCode      errutil.Code
+		wantDelay     time.Duration
+		wantPermanent bool
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: fs.ErrPermission, wantCode: errutil.CodePermission},
+		{name: "forbidden", status: http.StatusForbidden, wantErr: fs.ErrPermission, wantCode: errutil.CodePermission},
+		{
+			name:          "primary rate limit",
+			status:        http.StatusForbidden,
+			header:        http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {strconv.FormatInt(reset, 10)}},
+			wantErr:       ErrGitHubRateLimit,
+			wantCode:      errutil.CodeTransient,
+			wantPermanent: true,
+		},
+		{
+			name:      "primary rate limit with longer wait",
+			status:    http.StatusForbidden,
+			header:    http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {strconv.FormatInt(reset, 10)}},
+			opts:      []GitHubOption{WithGitHubMaxRateLimitWait(2 * time.Hour)},
+			wantErr:   ErrGitHubRateLimit,
+			wantCode:  errutil.CodeTransient,
+			wantDelay: time.Hour,
+		},
+		{
+			name:      "secondary rate limit",
+			status:    http.StatusTooManyRequests,
+			header:    http.Header{"Retry-After": {"30"}},
+			wantErr:   ErrGitHubRateLimit,
+			wantCode:  errutil.CodeTransient,
+			wantDelay: 30 * time.Second,
+		},
+		{name: "server error", status: http.StatusBadGateway, wantCode: errutil.CodeTransient},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			apiURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			opts := append([]GitHubOption{WithGitHubAPIURL(apiURL), WithGitHubToken("secret")}, tt.opts...)
+			proto := NewGitHubProto(context.Background(), opts...)
+			u, err := url.Parse("github://owner/repo/config.hcl")
+			require.NoError(t, err)
+
+			fsys, path, err := proto.FileSystem(u)
+			require.NoError(t, err)
+			_, err = fs.ReadFile(fsys, path)
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), "secret")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+			assert.Equal(t, tt.wantPermanent, retry.IsPermanent(err))
+			if tt.wantDelay == 0 {
+				return
+			}
+			var ra retry.RetryAfter
+			require.True(t, errors.As(err, &ra))
+			assert.InDelta(t, tt.wantDelay, ra.RetryAfter(), float64(5*time.Second))
+
+			// No requests are made until the rate limit is reset, also
+			// by other file systems created by the same protocol.
+			fsys, path, err = proto.FileSystem(u)
+			require.NoError(t, err)
+			_, err = fs.ReadFile(fsys, path)
+			assert.ErrorIs(t, err, ErrGitHubRateLimit)
+			assert.Equal(t, int32(1), requests.Load())
+		})
+	}
+}
@@ -3,16 +3,17 @@ module github.com/chronicleprotocol/go-lib/fsutil
 go 1.24.0
 
 require (
-	github.com/chronicleprotocol/go-lib v0.57.1
+	github.com/chronicleprotocol/go-lib v0.57.2-0.20261016130621-f1c58982d4e9
 	github.com/defiweb/go-eth v0.7.0
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/crypto v0.37.0
 )
 
 require (
+	github.com/chronicleprotocol/ecies v0.0.0-20241017151548-381690fa1131 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.3.0 // indirect
 	github.com/defiweb/go-rlp v0.3.0 // indirect
-	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
@@ -24,12 +24,13 @@ import (
 	netURL "net/url"
 	"strings"
 
+	"github.com/chronicleprotocol/go-lib/bytesize"
 	"github.com/chronicleprotocol/go-lib/errutil"
 )
 
 const (
 	defaultGzipExt       = "gz"
-	defaultGzipReadLimit = 1024 * 1024 * 128 // 128MiB
+	defaultGzipReadLimit = 128 * bytesize.MiB
 )
 
 type GzipFSOption func(*gzipFS)
@@ -38,6 +39,15 @@ type GzipFSOption func(*gzipFS)
 // If the decompressed data exceeds the limit, the io.ErrUnexpectedEOF error
 // will be returned. The default limit is 128MiB.
 func WithGzipReadLimit(limit int64) GzipFSOption {
+	return func(c *gzipFS) {
+		c.readLimit = bytesize.Size(limit)
+	}
+}
+
+// WithGzipMaxBytes works like WithGzipReadLimit, but takes the limit as
+// a bytesize.Size, so that limits given as strings, such as "128MiB", can
+// be used after parsing them with bytesize.Parse.
+func WithGzipMaxBytes(limit bytesize.Size) GzipFSOption {
 	return func(c *gzipFS) {
 		c.readLimit = limit
 	}
@@ -106,7 +116,7 @@ func NewGzipFS(fs fs.FS, opts ...GzipFSOption) fs.FS {
 
 type gzipFS struct {
 	fs        fs.FS
-	readLimit int64
+	readLimit bytesize.Size
 	checkExt  bool
 	exts      []string
 }
@@ -123,7 +133,7 @@ func (c *gzipFS) Open(name string) (fs.File, error) {
 	if !c.shouldDecompress(name) {
 		return c.fs.Open(name)
 	}
-	return newGzipFile(f, c.readLimit)
+	return newGzipFile(f, int64(c.readLimit))
 }
 
 // Glob implements the fs.GlobFS interface.
@@ -214,7 +224,8 @@ func (c *gzipFile) Read(p []byte) (n int, err error) {
 		return 0, c.err
 	}
 	if c.n <= 0 {
-		if _, err := c.g.Read(make([]byte, 1)); errors.Is(err, io.EOF) {
+		// The reader may return the last byte together with io.EOF.
+		if n, err := c.g.Read(make([]byte, 1)); n == 0 && errors.Is(err, io.EOF) {
 			c.err = io.EOF
 			return 0, c.err
 		}
@@ -247,7 +258,7 @@ func (c *gzipFile) Close() error {
 	return nil
 }
 
-var errGzipProtoNilURI = errors.New("fsutil.gzipProto: nil URI")
+var errGzipProtoNilURI = errutil.WithCode(errors.New("fsutil.gzipProto: nil URI"), errutil.CodeConfig)
 
 func errGzipProtoFn(err error) error {
 	return fmt.Errorf("fsutil.gzipProto: %w", err)
@@ -24,6 +24,8 @@ import (
 	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
 )
 
 func TestGzipProto(t *testing.T) {
@@ -154,6 +156,28 @@ func TestGzipFS(t *testing.T) {
 			file:    "bigfile.txt.gz",
 			wantErr: true,
 		},
+		{
+			name: "max bytes",
+			files: map[string][]byte{
+				"bigfile.txt.gz": gzipData(bytes.Repeat([]byte("a"), 1024)),
+			},
+			opts: []GzipFSOption{
+				WithGzipMaxBytes(bytesize.KiB - 1),
+			},
+			file:    "bigfile.txt.gz",
+			wantErr: true,
+		},
+		{
+			name: "max bytes within limit",
+			files: map[string][]byte{
+				"bigfile.txt.gz": gzipData(bytes.Repeat([]byte("a"), 1024)),
+			},
+			opts: []GzipFSOption{
+				WithGzipMaxBytes(bytesize.KiB),
+			},
+			file:     "bigfile.txt.gz",
+			wantData: bytes.Repeat([]byte("a"), 1024),
+		},
 	}
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
@@ -16,24 +16,106 @@
 package fsutil
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	netURL "net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/httputil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/urlutil"
 )
 
 type HTTPFSOption func(*httpFS)
 
-// WithHTTPClient sets the HTTP client used to perform HTTP requests.
+// WithHTTPClient sets the HTTP client used to perform HTTP requests. By
+// default, the client returned by httputil.Default is used.
 func WithHTTPClient(client *http.Client) HTTPFSOption {
 	return func(f *httpFS) {
 		f.client = client
 	}
 }
 
+// WithHTTPLogger sets the logger used to log HTTP requests.
+func WithHTTPLogger(logger logutil.Logger) HTTPFSOption {
+	return func(f *httpFS) {
+		f.logger = logger
+	}
+}
+
+// WithHTTPMaxBytes sets the maximum size of files read over HTTP. Reading
+// a larger file fails with ErrFileTooLarge. By default, the size is not
+// limited.
+func WithHTTPMaxBytes(limit bytesize.Size) HTTPFSOption {
+	return func(f *httpFS) {
+		f.maxBytes = limit
+	}
+}
+
+// WithHTTPETagCache makes the HTTP file system use conditional requests
+// for files stored in the given cache. Files are sent with the
+// If-None-Match header, and if the server responds with 304 Not Modified,
+// the cached file is returned. Files with an ETag header are added to the
+// cache when read.
+//
+// It is useful when the same files are read repeatedly, e.g. when a
+// configuration is polled for changes. The cache may be shared by many file
+// systems.
+func WithHTTPETagCache(cache *ETagCache) HTTPFSOption {
+	return func(f *httpFS) {
+		f.etags = cache
+	}
+}
+
+// ETagCache stores files read over HTTP along with their ETag, see
+// WithHTTPETagCache. It is safe for concurrent use.
+//
+// Files are never removed from the cache, so it should be used only for
+// a limited set of files.
+type ETagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagEntry
+}
+
+type etagEntry struct {
+	data []byte
+	meta *HTTPMetadata
+}
+
+// NewETagCache creates a new, empty ETag cache.
+func NewETagCache() *ETagCache {
+	return &ETagCache{entries: make(map[string]etagEntry)}
+}
+
+func (c *ETagCache) get(url string) (etagEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+func (c *ETagCache) set(url string, e etagEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = e
+}
+
+func (c *ETagCache) remove(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, url)
+}
+
 // NewHTTPProto creates a new HTTP protocol.
 
 // The HTTP protocol is used to create an HTTP file system.
@@ -62,6 +144,14 @@ func (m *httpProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err erro
 
 // NewHTTPFS creates a new HTTP file system.
 func NewHTTPFS(ctx context.Context, baseURI *netURL.URL, opts ...HTTPFSOption) (fs.FS, error) {
+	f, err := newHTTPFS(ctx, baseURI, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newHTTPFS(ctx context.Context, baseURI *netURL.URL, opts ...HTTPFSOption) (*httpFS, error) {
 	if err := validHTTPURI(baseURI); err != nil {
 		return nil, errHTTPFSFn(err)
 	}
@@ -70,16 +160,22 @@ func NewHTTPFS(ctx context.Context, baseURI *netURL.URL, opts ...HTTPFSOption) (
 		opt(fs)
 	}
 	if fs.client == nil {
-		fs.client = http.DefaultClient
+		fs.client = httputil.Default()
 	}
 	fs.baseURI = baseURI
 	return fs, nil
 }
 
 type httpFS struct {
-	ctx     context.Context
-	client  *http.Client
-	baseURI *netURL.URL
+	ctx      context.Context
+	client   *http.Client
+	baseURI  *netURL.URL
+	logger   logutil.Logger
+	maxBytes bytesize.Size
+	etags    *ETagCache
+
+	// accept is the value of the Accept header sent with requests.
+	accept string
 
 	// parseFn allows to define a custom name parsing function.
 	parseFn func(fs *httpFS, name string) (*netURL.URL, error)
@@ -98,9 +194,26 @@ func (f *httpFS) Open(name string) (fs.File, error) {
 	if err != nil {
 		return nil, errHTTPFSRequestErrorFn(url, err)
 	}
+	if f.accept != "" {
+		req.Header.Set("Accept", f.accept)
+	}
+	var (
+		cached    etagEntry
+		hasCached bool
+	)
+	if f.etags != nil {
+		if cached, hasCached = f.etags.get(url.String()); hasCached {
+			req.Header.Set("If-None-Match", cached.meta.ETag)
+		}
+	}
+	logutil.OrNop(f.logger).Debug("Fetching file", "url", urlutil.Redact(url))
 	res, err := f.client.Do(req)
 	if err != nil {
-		return nil, errHTTPFSRequestErrorFn(url, err)
+		return nil, errutil.WithCode(errHTTPFSRequestErrorFn(url, err), errutil.CodeTransient)
+	}
+	if res.StatusCode == http.StatusNotModified && hasCached {
+		_ = res.Body.Close()
+		return newHTTPFile(name, cached.data, cached.meta), nil
 	}
 	if res.StatusCode != http.StatusOK {
 		// Use fs package errors when possible to increase compatibility.
@@ -112,18 +225,149 @@ func (f *httpFS) Open(name string) (fs.File, error) {
 		}
 		return nil, errHTTPFSRequestErrorCodeFn(url, res.StatusCode)
 	}
+	body := res.Body
+	if f.maxBytes > 0 {
+		if res.ContentLength > int64(f.maxBytes) {
+			_ = res.Body.Close()
+			return nil, errHTTPFSRequestErrorFn(url, ErrFileTooLarge)
+		}
+		body = newMaxBytesReader(body, int64(f.maxBytes))
+	}
+	meta := newHTTPMetadata(url, res)
+	if f.etags != nil {
+		if meta.ETag == "" || meta.CacheControl.NoStore {
+			f.etags.remove(url.String())
+		} else {
+			data, err := io.ReadAll(body)
+			_ = body.Close()
+			if err != nil {
+				return nil, errHTTPFSRequestErrorFn(url, err)
+			}
+			f.etags.set(url.String(), etagEntry{data: data, meta: meta})
+			return newHTTPFile(name, data, meta), nil
+		}
+	}
+	modTime := meta.LastModified
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
 	return &file{
-		reader: res.Body,
+		reader: body,
 		info: &fileInfo{
 			name:    name,
 			size:    res.ContentLength,
 			mode:    0,
-			modTime: lastModTime(res.Header),
+			modTime: modTime,
 			isDir:   false,
+			sys:     meta,
 		},
 	}, nil
 }
 
+// newHTTPFile returns a file with the given contents and HTTP metadata.
+func newHTTPFile(name string, data []byte, meta *HTTPMetadata) fs.File {
+	modTime := meta.LastModified
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	return &file{
+		reader: io.NopCloser(bytes.NewReader(data)),
+		info: &fileInfo{
+			name:    name,
+			size:    int64(len(data)),
+			mode:    0,
+			modTime: modTime,
+			isDir:   false,
+			sys:     meta,
+		},
+	}
+}
+
+// HTTPMetadata holds the metadata of the HTTP response a file was read
+// from. It is returned by the Sys method of the fs.FileInfo of files opened
+// using the HTTP file system, see HTTPMetadataOf.
+type HTTPMetadata struct {
+	// URL is the URL of the file, with credentials redacted.
+	URL string
+
+	// ETag is the value of the ETag header, including quotes and the weak
+	// validator prefix, if present. It can be used in the If-None-Match
+	// header of a conditional request.
+	ETag string
+
+	// LastModified is the value of the Last-Modified header, or the zero
+	// time if the header is missing or invalid.
+	LastModified time.Time
+
+	// ContentType is the value of the Content-Type header.
+	ContentType string
+
+	// CacheControl holds the parsed Cache-Control header.
+	CacheControl CacheControl
+
+	// Header holds all response headers.
+	Header http.Header
+}
+
+// CacheControl holds the directives of a Cache-Control header that are
+// relevant to clients.
+type CacheControl struct {
+	MaxAge         time.Duration // Value of the max-age directive.
+	HasMaxAge      bool          // Whether the max-age directive is present.
+	NoCache        bool
+	NoStore        bool
+	MustRevalidate bool
+	Immutable      bool
+}
+
+// HTTPMetadataOf returns the HTTP metadata of a file. It returns false if
+// the file was not read over HTTP.
+func HTTPMetadataOf(info fs.FileInfo) (*HTTPMetadata, bool) {
+	if info == nil {
+		return nil, false
+	}
+	meta, ok := info.Sys().(*HTTPMetadata)
+	return meta, ok
+}
+
+func newHTTPMetadata(url *netURL.URL, res *http.Response) *HTTPMetadata {
+	meta := &HTTPMetadata{
+		URL:          urlutil.Redact(url),
+		ETag:         res.Header.Get("ETag"),
+		ContentType:  res.Header.Get("Content-Type"),
+		CacheControl: parseCacheControl(res.Header.Get("Cache-Control")),
+		Header:       res.Header.Clone(),
+	}
+	if t, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		meta.LastModified = t
+	}
+	return meta
+}
+
+// parseCacheControl parses a Cache-Control header. Unknown and invalid
+// directives are ignored.
+func parseCacheControl(header string) (cc CacheControl) {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "max-age":
+			if s, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil && s >= 0 {
+				cc.MaxAge = time.Duration(s) * time.Second
+				cc.HasMaxAge = true
+			}
+		case "no-cache":
+			cc.NoCache = true
+		case "no-store":
+			cc.NoStore = true
+		case "must-revalidate":
+			cc.MustRevalidate = true
+		case "immutable":
+			cc.Immutable = true
+		}
+	}
+	return cc
+}
+
 func (f *httpFS) parse(name string) (*netURL.URL, error) {
 	if f.parseFn != nil {
 		return f.parseFn(f, name)
@@ -141,13 +385,6 @@ func (f *httpFS) parse(name string) (*netURL.URL, error) {
 	return uri, nil
 }
 
-func lastModTime(headers http.Header) time.Time {
-	if t, err := time.Parse(time.RFC1123, headers.Get("Last-Modified")); err == nil {
-		return t
-	}
-	return time.Now()
-}
-
 func validHTTPURI(uri *netURL.URL) error {
 	if uri == nil {
 		return errHTTPProtoNilURI
@@ -171,11 +408,11 @@ func validHTTPURI(uri *netURL.URL) error {
 }
 
 var (
-	errHTTPProtoNilURI             = errors.New("fsutil.httpProto: nil URI")
-	errHTTPProtoOpaqueNotAllowed   = errors.New("fsutil.httpProto: opaque not allowed")
-	errHTTPProtoEmptyHost          = errors.New("fsutil.httpProto: empty host")
-	errHTTPProtoOmitHost           = errors.New("fsutil.httpProto: omit host must be false")
-	errHTTPProtoFragmentNotAllowed = errors.New("fsutil.httpProto: fragment not allowed")
+	errHTTPProtoNilURI             = errutil.WithCode(errors.New("fsutil.httpProto: nil URI"), errutil.CodeConfig)
+	errHTTPProtoOpaqueNotAllowed   = errutil.WithCode(errors.New("fsutil.httpProto: opaque not allowed"), errutil.CodeConfig)
+	errHTTPProtoEmptyHost          = errutil.WithCode(errors.New("fsutil.httpProto: empty host"), errutil.CodeConfig)
+	errHTTPProtoOmitHost           = errutil.WithCode(errors.New("fsutil.httpProto: omit host must be false"), errutil.CodeConfig)
+	errHTTPProtoFragmentNotAllowed = errutil.WithCode(errors.New("fsutil.httpProto: fragment not allowed"), errutil.CodeConfig)
 )
 
 func errHTTPProtoFn(err error) error {
@@ -183,7 +420,7 @@ func errHTTPProtoFn(err error) error {
 }
 
 func errHTTPProtoUnexpectedSchemeFn(scheme string) error {
-	return fmt.Errorf("fsutil.httpProto: unexpected scheme: %s", scheme)
+	return errutil.WithCode(fmt.Errorf("fsutil.httpProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
 }
 
 func errHTTPFSFn(err error) error {
@@ -191,9 +428,13 @@ func errHTTPFSFn(err error) error {
 }
 
 func errHTTPFSRequestErrorFn(url *netURL.URL, err error) error {
-	return fmt.Errorf("fsutil.httpFS: %s: %w", url.String(), err)
+	return fmt.Errorf("fsutil.httpFS: %s: %w", urlutil.Redact(url), urlutil.RedactError(err))
 }
 
 func errHTTPFSRequestErrorCodeFn(url *netURL.URL, code int) error {
-	return fmt.Errorf("fsutil.httpFS: %s: unexpected status code: %d %s", url.String(), code, http.StatusText(code))
+	err := fmt.Errorf("fsutil.httpFS: %s: unexpected status code: %d %s", urlutil.Redact(url), code, http.StatusText(code))
+	if code >= http.StatusInternalServerError || code == http.StatusTooManyRequests || code == http.StatusRequestTimeout {
+		return errutil.WithCode(err, errutil.CodeTransient)
+	}
+	return err
 }
@@ -19,12 +19,17 @@ import (
 	"context"
 	"errors"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/httputil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -115,6 +120,8 @@ func TestHTTPFS(t *testing.T) {
 			w.WriteHeader(http.StatusPaymentRequired)
 		case "/forbidden.txt":
 			w.WriteHeader(http.StatusForbidden)
+		case "/unavailable.txt":
+			w.WriteHeader(http.StatusServiceUnavailable)
 		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
@@ -126,6 +133,7 @@ func TestHTTPFS(t *testing.T) {
 		wantErr           bool
 		wantNotExistsErr  bool
 		wantPermissionErr bool
+		wantCode          errutil.Code
 	}{
 		{
 			name:    "valid request",
@@ -169,6 +177,7 @@ func TestHTTPFS(t *testing.T) {
 			file:             "notfound.txt",
 			wantErr:          true,
 			wantNotExistsErr: true,
+			wantCode:         errutil.CodeNotFound,
 		},
 		{
 			name:              "unauthorized",
@@ -176,6 +185,7 @@ func TestHTTPFS(t *testing.T) {
 			file:              "unauthorized.txt",
 			wantErr:           true,
 			wantPermissionErr: true,
+			wantCode:          errutil.CodePermission,
 		},
 		{
 			name:              "payment required",
@@ -183,6 +193,7 @@ func TestHTTPFS(t *testing.T) {
 			file:              "paymentrequired.txt",
 			wantErr:           true,
 			wantPermissionErr: true,
+			wantCode:          errutil.CodePermission,
 		},
 		{
 			name:              "forbidden",
@@ -190,6 +201,14 @@ func TestHTTPFS(t *testing.T) {
 			file:              "forbidden.txt",
 			wantErr:           true,
 			wantPermissionErr: true,
+			wantCode:          errutil.CodePermission,
+		},
+		{
+			name:     "service unavailable",
+			baseURL:  "http://localhost",
+			file:     "unavailable.txt",
+			wantErr:  true,
+			wantCode: errutil.CodeTransient,
 		},
 	}
 	for _, tt := range tc {
@@ -214,6 +233,7 @@ func TestHTTPFS(t *testing.T) {
 			}
 			if tt.wantErr {
 				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
 				return
 			}
 			require.NoError(t, err)
@@ -224,3 +244,198 @@ func TestHTTPFS(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPFS_RedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tc := []struct {
+		name   string
+		client *http.Client
+	}{
+		{
+			name:   "status code",
+			client: server.Client(),
+		},
+		{
+			name: "transport error",
+			client: &http.Client{Transport: httputil.RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			})},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, err := url.Parse("http://user:password@" + server.Listener.Addr().String())
+			require.NoError(t, err)
+
+			httpFS, err := NewHTTPFS(context.Background(), baseURL, WithHTTPClient(tt.client))
+			require.NoError(t, err)
+
+			_, err = httpFS.Open("file.json?apikey=secret")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "apikey=xxxxx")
+			assert.NotContains(t, err.Error(), "password")
+			assert.NotContains(t, err.Error(), "secret")
+		})
+	}
+}
+
+func TestHTTPFS_MaxBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked.txt" {
+			// Flushing before writing the body forces chunked encoding, so
+			// the content length is unknown.
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte("test content"))
+	}))
+	defer server.Close()
+
+	tc := []struct {
+		name     string
+		file     string
+		maxBytes bytesize.Size
+		wantErr  bool
+	}{
+		{name: "within limit", file: "file.txt", maxBytes: 12},
+		{name: "content length over limit", file: "file.txt", maxBytes: 11, wantErr: true},
+		{name: "chunked within limit", file: "chunked.txt", maxBytes: 12},
+		{name: "chunked over limit", file: "chunked.txt", maxBytes: 11, wantErr: true},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			httpFS, err := NewHTTPFS(context.Background(), baseURL, WithHTTPMaxBytes(tt.maxBytes))
+			require.NoError(t, err)
+
+			data, err := fs.ReadFile(httpFS, tt.file)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrFileTooLarge)
+				assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "test content", string(data))
+		})
+	}
+}
+
+func TestHTTPFS_Metadata(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"abc"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300, must-revalidate")
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	baseURL.User = url.UserPassword("user", "password")
+	httpFS, err := NewHTTPFS(context.Background(), baseURL)
+	require.NoError(t, err)
+
+	info, err := fs.Stat(httpFS, "file.json")
+	require.NoError(t, err)
+	assert.Equal(t, lastModified, info.ModTime().UTC())
+
+	meta, ok := HTTPMetadataOf(info)
+	require.True(t, ok)
+	assert.Equal(t, "http://xxxxx@"+baseURL.Host+"/file.json", meta.URL)
+	assert.Equal(t, `W/"abc"`, meta.ETag)
+	assert.Equal(t, "application/json", meta.ContentType)
+	assert.Equal(t, lastModified, meta.LastModified.UTC())
+	assert.Equal(t, CacheControl{MaxAge: 5 * time.Minute, HasMaxAge: true, MustRevalidate: true}, meta.CacheControl)
+	assert.Equal(t, "2", meta.Header.Get("Content-Length"))
+
+	_, ok = HTTPMetadataOf(nil)
+	assert.False(t, ok)
+}
+
+func TestHTTPFS_ETagCache(t *testing.T) {
+	var (
+		content  = "v1"
+		etag     = `"1"`
+		requests []string // If-None-Match headers of the requests.
+		notMod   int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("If-None-Match"))
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				notMod++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	cache := NewETagCache()
+	httpFS, err := NewHTTPFS(context.Background(), baseURL, WithHTTPETagCache(cache))
+	require.NoError(t, err)
+	read := func() string {
+		data, err := fs.ReadFile(httpFS, "file.txt")
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	// The first request is unconditional.
+	assert.Equal(t, "v1", read())
+
+	// Unchanged file is returned from the cache.
+	assert.Equal(t, "v1", read())
+	assert.Equal(t, 1, notMod)
+
+	// Changed file is fetched again.
+	content, etag = "v2", `"2"`
+	assert.Equal(t, "v2", read())
+	assert.Equal(t, "v2", read())
+	assert.Equal(t, 2, notMod)
+
+	// Files without an ETag are removed from the cache.
+	content, etag = "v3", ""
+	assert.Equal(t, "v3", read())
+	assert.Equal(t, "v3", read())
+	assert.Equal(t, []string{"", `"1"`, `"1"`, `"2"`, `"2"`, ""}, requests)
+
+	// The cache is shared by file systems.
+	content, etag = "v4", `"4"`
+	assert.Equal(t, "v4", read())
+	otherFS, err := NewHTTPFS(context.Background(), baseURL, WithHTTPETagCache(cache))
+	require.NoError(t, err)
+	data, err := fs.ReadFile(otherFS, "file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "v4", string(data))
+	assert.Equal(t, 3, notMod)
+}
+
+func TestParseCacheControl(t *testing.T) {
+	tc := []struct {
+		header string
+		want   CacheControl
+	}{
+		{header: "", want: CacheControl{}},
+		{header: "no-cache", want: CacheControl{NoCache: true}},
+		{header: "No-Store, max-age=0", want: CacheControl{NoStore: true, HasMaxAge: true}},
+		{header: `max-age="60", immutable`, want: CacheControl{MaxAge: time.Minute, HasMaxAge: true, Immutable: true}},
+		{header: "max-age=-1, private", want: CacheControl{}},
+		{header: "max-age=soon", want: CacheControl{}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseCacheControl(tt.header))
+		})
+	}
+}
@@ -21,21 +21,39 @@ import (
 	"fmt"
 	"hash"
 	"io/fs"
+	"net"
 	"net/http"
 	netURL "net/url"
+	"time"
 
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/httputil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
 	"golang.org/x/crypto/sha3"
 )
 
+// defaultIPFSMaxBytes is the default maximum size of files read from IPFS
+// gateways. Files are buffered in memory to verify their checksums, so the
+// size must be limited.
+const defaultIPFSMaxBytes = 128 * bytesize.MiB
+
 type IPFSOption func(*ipfsFS)
 
 type IPFSGateway struct {
 	Scheme    string
 	Host      string
 	ResolveFn func(cid string) func(f *httpFS, name string) (*netURL.URL, error)
+
+	// IPNSResolveFn returns the function used to build the URL of the IPNS
+	// record of the given name, see IPNSPathResolution. If nil, the gateway
+	// is not used to resolve IPNS names.
+	IPNSResolveFn func(name string) func(f *httpFS, name string) (*netURL.URL, error)
 }
 
-// WithIPFSHTTPClient sets the HTTP client used to perform HTTP requests.
+// WithIPFSHTTPClient sets the HTTP client used to perform HTTP requests. By
+// default, the client returned by httputil.Default is used.
 func WithIPFSHTTPClient(client *http.Client) IPFSOption {
 	return func(c *ipfsFS) {
 		c.client = client
@@ -56,16 +74,61 @@ func WithIPFSChecksumHash(hash func() hash.Hash) IPFSOption {
 	}
 }
 
+// WithIPFSMaxBytes sets the maximum size of files read from IPFS gateways.
+// Reading a larger file fails with ErrFileTooLarge, and the gateway stops
+// being read as soon as the limit is exceeded. The default limit is 128MiB.
+// If the limit is zero or negative, the size is not limited.
+func WithIPFSMaxBytes(limit bytesize.Size) IPFSOption {
+	return func(c *ipfsFS) {
+		c.maxBytes = limit
+	}
+}
+
+// WithIPFSDAGFormat makes the file system request the given IPLD
+// representation of IPFS content instead of raw files. It is intended for
+// content published as IPLD nodes rather than UnixFS files.
+//
+// Decoded nodes are exposed as canonical JSON documents, regardless of the
+// format requested from gateways. Links are represented as {"/": "<cid>"}
+// and bytes as {"/": {"bytes": "<base64>"}}, as in DAG-JSON. Checksums are
+// verified against the decoded documents.
+func WithIPFSDAGFormat(format IPFSDAGFormat) IPFSOption {
+	return func(c *ipfsFS) {
+		c.dagFormat = format
+	}
+}
+
+// WithIPFSLogger sets the logger used to log requests to the gateways and
+// gateway failures.
+func WithIPFSLogger(logger logutil.Logger) IPFSOption {
+	return func(c *ipfsFS) {
+		c.logger = logger
+	}
+}
+
 // NewIPFSProto creates a new IPFS protocol.
 //
-// The IPFS protocol is used to create an IPFS file system.
+// The IPFS protocol is used to create an IPFS file system. Besides the
+// "ipfs" scheme, it accepts the "ipns" scheme, in which case the host is
+// an IPNS name that is resolved to a CID before the file system is
+// created, see WithIPNSResolver.
+//
+// If the host is a domain name, as in "ipfs://example.com/file.json", it
+// is resolved using DNSLink, see WithDNSResolver.
 func NewIPFSProto(ctx context.Context, opts ...IPFSOption) Protocol {
-	return &ipfsProto{ctx: ctx, opts: opts}
+	return &ipfsProto{
+		ctx:  ctx,
+		opts: opts,
+		cfg:  newIPFSConfig(opts...),
+		ipns: &ipnsCache{entries: make(map[string]ipnsCacheEntry)},
+	}
 }
 
 type ipfsProto struct {
 	ctx  context.Context
 	opts []IPFSOption
+	cfg  *ipfsFS
+	ipns *ipnsCache
 }
 
 // FileSystem implements the Protocol interface.
@@ -73,7 +136,14 @@ func (m *ipfsProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err erro
 	if err := validIPFSURI(uri); err != nil {
 		return nil, "", err
 	}
-	fs, err = NewIPFSFS(m.ctx, uri.Host, m.opts...)
+	cid := uri.Host
+	if uri.Scheme == IPNSScheme || isDNSLinkName(uri.Host) {
+		cid, uri, err = m.resolveIPNSURI(uri)
+		if err != nil {
+			return nil, "", errIPFSProtoFn(err)
+		}
+	}
+	fs, err = NewIPFSFS(m.ctx, cid, m.opts...)
 	if err != nil {
 		return nil, "", errIPFSProtoFn(err)
 	}
@@ -98,12 +168,48 @@ func NewIPFSFS(ctx context.Context, cid string, opts ...IPFSOption) (fs.FS, erro
 	if cid == "" {
 		return nil, errIPFSFSEmptyCID
 	}
-	i := &ipfsFS{}
+	i := newIPFSConfig(opts...)
+	switch i.dagFormat {
+	case "", IPFSDAGJSON, IPFSDAGCBOR:
+	default:
+		return nil, errIPFSFSFn(errDAGUnsupportedFormatFn(i.dagFormat))
+	}
+	cfs := &chainFS{rand: true, logger: i.logger}
+	for _, gw := range i.gateways {
+		hfs := &httpFS{
+			ctx:      ctx,
+			client:   i.client,
+			baseURI:  &netURL.URL{Scheme: gw.Scheme, Host: gw.Host},
+			parseFn:  gw.ResolveFn(cid),
+			logger:   i.logger,
+			maxBytes: i.maxBytes,
+		}
+		var gfs fs.FS = hfs
+		if i.dagFormat != "" {
+			hfs.parseFn = ipfsFormatResolution(hfs.parseFn, string(i.dagFormat))
+			hfs.accept = i.dagFormat.contentType()
+			gfs = &dagFS{fs: hfs, format: i.dagFormat}
+		}
+		cfs.fs = append(cfs.fs, &checksumFS{
+			fs:    gfs,
+			hash:  i.checksumHash,
+			param: "checksum",
+			mode:  ChecksumFSVerifyAfterOpen,
+		})
+	}
+	i.cfs = cfs
+	return i, nil
+}
+
+// newIPFSConfig returns an ipfsFS with the options applied and the
+// defaults set. The chain file system is not created.
+func newIPFSConfig(opts ...IPFSOption) *ipfsFS {
+	i := &ipfsFS{maxBytes: defaultIPFSMaxBytes, ipnsCacheTTL: defaultIPNSCacheTTL}
 	for _, opt := range opts {
 		opt(i)
 	}
 	if i.client == nil {
-		i.client = http.DefaultClient
+		i.client = httputil.Default()
 	}
 	if len(i.gateways) == 0 {
 		i.gateways = ipfsGateways
@@ -111,29 +217,27 @@ func NewIPFSFS(ctx context.Context, cid string, opts ...IPFSOption) (fs.FS, erro
 	if i.checksumHash == nil {
 		i.checksumHash = sha3.NewLegacyKeccak256
 	}
-	cfs := &chainFS{rand: true}
-	for _, gw := range i.gateways {
-		cfs.fs = append(cfs.fs, &checksumFS{
-			fs: &httpFS{
-				ctx:     ctx,
-				client:  i.client,
-				baseURI: &netURL.URL{Scheme: gw.Scheme, Host: gw.Host},
-				parseFn: gw.ResolveFn(cid),
-			},
-			hash:  i.checksumHash,
-			param: "checksum",
-			mode:  ChecksumFSVerifyAfterOpen,
-		})
+	if i.dnsResolver == nil {
+		i.dnsResolver = net.DefaultResolver.LookupTXT
 	}
-	i.cfs = cfs
-	return i, nil
+	i.clock = timeutil.OrReal(i.clock)
+	return i
 }
 
 type ipfsFS struct {
 	client       *http.Client
 	gateways     []*IPFSGateway
 	checksumHash func() hash.Hash
+	maxBytes     bytesize.Size
+	dagFormat    IPFSDAGFormat
+	logger       logutil.Logger
 	cfs          *chainFS
+
+	ipnsResolver IPNSResolver
+	ipnsVerify   bool
+	ipnsCacheTTL time.Duration
+	dnsResolver  DNSResolver
+	clock        timeutil.Clock
 }
 
 func (h *ipfsFS) Open(name string) (fs.File, error) {
@@ -143,6 +247,15 @@ func (h *ipfsFS) Open(name string) (fs.File, error) {
 	return h.cfs.Open(name)
 }
 
+// ReadFile implements the fs.ReadFileFS interface. The data verified by
+// the checksum file system is returned without being copied.
+func (h *ipfsFS) ReadFile(name string) ([]byte, error) {
+	if err := validPath("readFile", name); err != nil {
+		return nil, errIPFSFSFn(err)
+	}
+	return h.cfs.ReadFile(name)
+}
+
 func IPFSPathResolution(cid string) func(f *httpFS, name string) (*netURL.URL, error) {
 	return func(f *httpFS, name string) (*netURL.URL, error) {
 		httpPath := "/ipfs/" + cid
@@ -174,24 +287,40 @@ func IPFSSubdomainResolution(cid string) func(f *httpFS, name string) (*netURL.U
 	}
 }
 
+// ipfsFormatResolution adds the "format" query parameter, which tells
+// gateways to return the given representation, such as an IPLD codec or
+// an IPNS record, to URLs returned by the resolve function.
+func ipfsFormatResolution(resolve func(f *httpFS, name string) (*netURL.URL, error), format string) func(f *httpFS, name string) (*netURL.URL, error) {
+	return func(f *httpFS, name string) (*netURL.URL, error) {
+		url, err := resolve(f, name)
+		if err != nil {
+			return nil, err
+		}
+		query := url.Query()
+		query.Set("format", format)
+		url.RawQuery = query.Encode()
+		return url, nil
+	}
+}
+
 var ipfsGateways = []*IPFSGateway{
-	{Scheme: "https", Host: "ipfs.io", ResolveFn: IPFSPathResolution},
-	{Scheme: "https", Host: "gateway.pinata.cloud", ResolveFn: IPFSPathResolution},
-	{Scheme: "https", Host: "trustless-gateway.link", ResolveFn: IPFSPathResolution},
+	{Scheme: "https", Host: "ipfs.io", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
+	{Scheme: "https", Host: "gateway.pinata.cloud", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
+	{Scheme: "https", Host: "trustless-gateway.link", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
 	{Scheme: "https", Host: "dweb.link", ResolveFn: IPFSSubdomainResolution},
-	{Scheme: "https", Host: "storry.tv", ResolveFn: IPFSPathResolution},
-	{Scheme: "https", Host: "w3s.link", ResolveFn: IPFSPathResolution},
-	{Scheme: "https", Host: "4everland.io", ResolveFn: IPFSPathResolution},
-	{Scheme: "https", Host: "flk-ipfs.xyz", ResolveFn: IPFSPathResolution},
-	{Scheme: "https", Host: "ipfs.cyou", ResolveFn: IPFSPathResolution},
-	{Scheme: "https", Host: "nftstorage.link", ResolveFn: IPFSPathResolution},
+	{Scheme: "https", Host: "storry.tv", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
+	{Scheme: "https", Host: "w3s.link", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
+	{Scheme: "https", Host: "4everland.io", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
+	{Scheme: "https", Host: "flk-ipfs.xyz", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
+	{Scheme: "https", Host: "ipfs.cyou", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
+	{Scheme: "https", Host: "nftstorage.link", ResolveFn: IPFSPathResolution, IPNSResolveFn: IPNSPathResolution},
 }
 
 func validIPFSURI(uri *netURL.URL) error {
 	if uri == nil {
 		return errIPFSProtoNilURI
 	}
-	if uri.Scheme != "ipfs" && uri.Scheme != "ipfs+gateway" {
+	if uri.Scheme != "ipfs" && uri.Scheme != "ipfs+gateway" && uri.Scheme != IPNSScheme {
 		return errIPFSProtoUnexpectedSchemeFn(uri.Scheme)
 	}
 	if uri.Opaque != "" {
@@ -210,12 +339,12 @@ func validIPFSURI(uri *netURL.URL) error {
 }
 
 var (
-	errIPFSProtoNilURI             = errors.New("fsutil.ipfsProto: nil URI")
-	errIPFSProtoOpaqueNotAllowed   = errors.New("fsutil.ipfsProto: opaque not allowed")
-	errIPFSProtoEmptyHost          = errors.New("fsutil.ipfsProto: empty host")
-	errIPFSProtoOmitHost           = errors.New("fsutil.ipfsProto: omit host must be false")
-	errIPFSProtoFragmentNotAllowed = errors.New("fsutil.ipfsProto: fragment not allowed")
-	errIPFSFSEmptyCID              = fmt.Errorf("fsutil.ipfsFS: empty CID")
+	errIPFSProtoNilURI             = errutil.WithCode(errors.New("fsutil.ipfsProto: nil URI"), errutil.CodeConfig)
+	errIPFSProtoOpaqueNotAllowed   = errutil.WithCode(errors.New("fsutil.ipfsProto: opaque not allowed"), errutil.CodeConfig)
+	errIPFSProtoEmptyHost          = errutil.WithCode(errors.New("fsutil.ipfsProto: empty host"), errutil.CodeConfig)
+	errIPFSProtoOmitHost           = errutil.WithCode(errors.New("fsutil.ipfsProto: omit host must be false"), errutil.CodeConfig)
+	errIPFSProtoFragmentNotAllowed = errutil.WithCode(errors.New("fsutil.ipfsProto: fragment not allowed"), errutil.CodeConfig)
+	errIPFSFSEmptyCID              = errutil.WithCode(fmt.Errorf("fsutil.ipfsFS: empty CID"), errutil.CodeConfig)
 )
 
 func errIPFSProtoFn(err error) error {
@@ -227,5 +356,5 @@ func errIPFSFSFn(err error) error {
 }
 
 func errIPFSProtoUnexpectedSchemeFn(scheme string) error {
-	return fmt.Errorf("fsutil.ipfsProto: unexpected scheme: %s", scheme)
+	return errutil.WithCode(fmt.Errorf("fsutil.ipfsProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
 }
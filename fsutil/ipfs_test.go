@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"strings"
@@ -26,6 +27,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/testutil"
 )
 
 type roundTripFunc func(req *http.Request) (*http.Response, error)
@@ -136,6 +140,162 @@ func TestIPFSFS(t *testing.T) {
 	}
 }
 
+func TestIPFSFS_Gateway(t *testing.T) {
+	ctx := context.Background()
+	content := []byte("ipfs content")
+	tc := []struct {
+		name       string
+		opts       []testutil.IPFSGatewayOption
+		wantSecond int // Requests expected to reach the fallback gateway.
+	}{
+		{name: "healthy gateway", wantSecond: 0},
+		{name: "failing gateway", opts: []testutil.IPFSGatewayOption{testutil.WithIPFSFailures(-1)}, wantSecond: 1},
+		{name: "corrupting gateway", opts: []testutil.IPFSGatewayOption{testutil.WithIPFSCorruption()}, wantSecond: 1},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			first := testutil.NewIPFSGateway(tt.opts...)
+			defer first.Close()
+			second := testutil.NewIPFSGateway()
+			defer second.Close()
+			first.AddFile("QmTest", "test.txt", content)
+			second.AddFile("QmTest", "test.txt", content)
+
+			// The client of each gateway connects only to that gateway, so
+			// requests are routed by the host.
+			client := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if strings.HasSuffix(req.URL.Host, first.Host()) {
+						return first.Client().Transport.RoundTrip(req)
+					}
+					return second.Client().Transport.RoundTrip(req)
+				}),
+			}
+			proto := NewIPFSProto(
+				ctx,
+				WithIPFSHTTPClient(client),
+				WithIPFSGateways(
+					&IPFSGateway{Scheme: first.Scheme(), Host: first.Host(), ResolveFn: IPFSPathResolution},
+					&IPFSGateway{Scheme: second.Scheme(), Host: second.Host(), ResolveFn: IPFSSubdomainResolution},
+				),
+			)
+			fs, path, err := ParseURI(proto, fmt.Sprintf("ipfs://QmTest/test.txt?checksum=%s", calculateKeccak256(content)))
+			require.NoError(t, err)
+			fs.(*ipfsFS).cfs.rand = false
+
+			file, err := fs.Open(path)
+			require.NoError(t, err)
+			defer file.Close()
+			data, err := io.ReadAll(file)
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+			assert.Equal(t, 1, first.Requests())
+			assert.Equal(t, tt.wantSecond, second.Requests())
+		})
+	}
+}
+
+func TestIPFSFS_DAGFormat(t *testing.T) {
+	ctx := context.Background()
+	// {"b": 1, "a": "x"} encoded as DAG-CBOR.
+	cbor := []byte{0xa2, 0x61, 0x62, 0x01, 0x61, 0x61, 0x61, 0x78}
+	canonical := `{"a":"x","b":1}`
+	tc := []struct {
+		name       string
+		format     IPFSDAGFormat
+		body       []byte
+		uri        string
+		wantQuery  string
+		wantAccept string
+		wantData   string
+		wantErr    bool
+	}{
+		{
+			name:       "dag-cbor",
+			format:     IPFSDAGCBOR,
+			body:       cbor,
+			uri:        "ipfs://QmTest/meta",
+			wantQuery:  "format=dag-cbor",
+			wantAccept: "application/vnd.ipld.dag-cbor",
+			wantData:   canonical,
+		},
+		{
+			name:       "dag-json",
+			format:     IPFSDAGJSON,
+			body:       []byte(`{"b": 1, "a": "x"}`),
+			uri:        "ipfs://QmTest/meta",
+			wantQuery:  "format=dag-json",
+			wantAccept: "application/vnd.ipld.dag-json",
+			wantData:   canonical,
+		},
+		{
+			name:       "checksum of decoded document",
+			format:     IPFSDAGCBOR,
+			body:       cbor,
+			uri:        fmt.Sprintf("ipfs://QmTest/meta?checksum=%s", calculateKeccak256([]byte(canonical))),
+			wantQuery:  "format=dag-cbor",
+			wantAccept: "application/vnd.ipld.dag-cbor",
+			wantData:   canonical,
+		},
+		{
+			name:       "checksum of raw response",
+			format:     IPFSDAGCBOR,
+			body:       cbor,
+			uri:        fmt.Sprintf("ipfs://QmTest/meta?checksum=%s", calculateKeccak256(cbor)),
+			wantQuery:  "format=dag-cbor",
+			wantAccept: "application/vnd.ipld.dag-cbor",
+			wantErr:    true,
+		},
+		{
+			name:       "invalid node",
+			format:     IPFSDAGCBOR,
+			body:       []byte{0x9f, 0xff},
+			uri:        "ipfs://QmTest/meta",
+			wantQuery:  "format=dag-cbor",
+			wantAccept: "application/vnd.ipld.dag-cbor",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotQuery, gotAccept string
+			client := &http.Client{
+				Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					gotQuery = req.URL.RawQuery
+					gotAccept = req.Header.Get("Accept")
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader(string(tt.body))),
+					}, nil
+				}),
+			}
+			proto := NewIPFSProto(
+				ctx,
+				WithIPFSHTTPClient(client),
+				WithIPFSGateways(&IPFSGateway{Scheme: "https", Host: "ipfs-path.io", ResolveFn: IPFSPathResolution}),
+				WithIPFSDAGFormat(tt.format),
+			)
+			fsys, path, err := ParseURI(proto, tt.uri)
+			require.NoError(t, err)
+
+			data, err := fs.ReadFile(fsys, path)
+			assert.Equal(t, tt.wantQuery, gotQuery)
+			assert.Equal(t, tt.wantAccept, gotAccept)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, string(data))
+		})
+	}
+}
+
+func TestIPFSFS_UnsupportedDAGFormat(t *testing.T) {
+	_, err := NewIPFSFS(context.Background(), "QmTest", WithIPFSDAGFormat("dag-pb"))
+	require.Error(t, err)
+}
+
 func TestIPFSPathResolution(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -317,3 +477,39 @@ func TestIPFSSubdomainResolution(t *testing.T) {
 		})
 	}
 }
+
+func TestIPFSFS_MaxBytes(t *testing.T) {
+	content := []byte("ipfs content")
+	tc := []struct {
+		name    string
+		opts    []IPFSOption
+		wantErr bool
+	}{
+		{name: "default limit"},
+		{name: "within limit", opts: []IPFSOption{WithIPFSMaxBytes(bytesize.Size(len(content)))}},
+		{name: "over limit", opts: []IPFSOption{WithIPFSMaxBytes(bytesize.Size(len(content) - 1))}, wantErr: true},
+		{name: "no limit", opts: []IPFSOption{WithIPFSMaxBytes(0)}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := testutil.NewIPFSGateway()
+			defer gw.Close()
+			gw.AddFile("QmTest", "test.txt", content)
+
+			opts := append([]IPFSOption{
+				WithIPFSHTTPClient(gw.Client()),
+				WithIPFSGateways(&IPFSGateway{Scheme: gw.Scheme(), Host: gw.Host(), ResolveFn: IPFSPathResolution}),
+			}, tt.opts...)
+			fsys, path, err := ParseURI(NewIPFSProto(context.Background(), opts...), fmt.Sprintf("ipfs://QmTest/test.txt?checksum=%s", calculateKeccak256(content)))
+			require.NoError(t, err)
+
+			data, err := fs.ReadFile(fsys, path)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrFileTooLarge)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+		})
+	}
+}
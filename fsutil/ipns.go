@@ -0,0 +1,296 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	netURL "net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+// IPNSScheme is the URI scheme of IPNS names, as in "ipns://<name>/path".
+const IPNSScheme = "ipns"
+
+const (
+	// defaultIPNSCacheTTL is the default maximum time for which resolved
+	// IPNS names are cached.
+	defaultIPNSCacheTTL = time.Minute
+
+	// ipnsMaxRecordSize is the maximum size of IPNS records, as defined by
+	// the IPNS specification.
+	ipnsMaxRecordSize = 10 * bytesize.KiB
+
+	// ipnsMaxDepth is the maximum number of IPNS names resolved to reach
+	// an IPFS path, as IPNS records may point to other IPNS names.
+	ipnsMaxDepth = 32
+
+	ipnsRecordContentType = "application/vnd.ipfs.ipns-record"
+)
+
+// IPNSResolver fetches the IPNS record of the given name. The record must
+// be returned in its protobuf serialization, as defined by the IPNS
+// specification, for example as returned by the delegated routing API
+// ("/routing/v1/ipns/<name>") or by a gateway.
+type IPNSResolver func(ctx context.Context, name string) ([]byte, error)
+
+// WithIPNSResolver sets the resolver used to fetch IPNS records. By
+// default, records are fetched from the gateways that have the
+// IPNSResolveFn field set, trying them in order until a valid record is
+// returned.
+func WithIPNSResolver(resolver IPNSResolver) IPFSOption {
+	return func(c *ipfsFS) {
+		c.ipnsResolver = resolver
+	}
+}
+
+// WithIPNSRecordVerification enables the verification of IPNS record
+// signatures. The public key is obtained from the IPNS name or, if the
+// name contains only a hash of the key, from the record itself.
+//
+// Without the verification, a misconfigured or malicious gateway could
+// resolve a name to arbitrary content, so it should be enabled unless
+// records are fetched from a trusted resolver or the content is verified
+// using checksums. Ed25519, RSA and ECDSA keys are supported.
+func WithIPNSRecordVerification() IPFSOption {
+	return func(c *ipfsFS) {
+		c.ipnsVerify = true
+	}
+}
+
+// WithIPNSCacheTTL sets the maximum time for which resolved IPNS and
+// DNSLink names are cached. IPNS names are cached for the TTL given in the
+// record if it is shorter, and never past the expiration of the record.
+// The default is one minute. If the TTL is zero or negative, names are not
+// cached.
+func WithIPNSCacheTTL(ttl time.Duration) IPFSOption {
+	return func(c *ipfsFS) {
+		c.ipnsCacheTTL = ttl
+	}
+}
+
+// WithIPFSClock sets the clock used to check the expiration of IPNS
+// records and cached names. It is intended for tests, see timeutil.Fake.
+func WithIPFSClock(clock timeutil.Clock) IPFSOption {
+	return func(c *ipfsFS) {
+		c.clock = clock
+	}
+}
+
+// IPNSPathResolution builds the URL of the IPNS record of the given name
+// in the form of "/ipns/<name>?format=ipns-record".
+func IPNSPathResolution(name string) func(f *httpFS, _ string) (*netURL.URL, error) {
+	return ipfsFormatResolution(func(f *httpFS, _ string) (*netURL.URL, error) {
+		url := &netURL.URL{
+			Scheme: f.baseURI.Scheme,
+			User:   f.baseURI.User,
+			Host:   f.baseURI.Host,
+			Path:   "/ipns/" + name,
+		}
+		return url, nil
+	}, "ipns-record")
+}
+
+// resolveIPNSURI resolves the IPNS name in the URI host. It returns the
+// CID the name resolves to and the URI with its path prefixed by the
+// path the name resolves to.
+func (m *ipfsProto) resolveIPNSURI(uri *netURL.URL) (string, *netURL.URL, error) {
+	cid, dir, err := m.resolveIPNS(uri.Host)
+	if err != nil {
+		return "", nil, err
+	}
+	if dir == "" {
+		return cid, uri, nil
+	}
+	uri = uriCopy(uri)
+	uri.Path = path.Join("/", dir, uri.Path)
+	uri.RawPath = ""
+	return cid, uri, nil
+}
+
+// resolveIPNS resolves the IPNS or DNSLink name to a CID and a path
+// within it, following records that point to other names.
+func (m *ipfsProto) resolveIPNS(name string) (cid, dir string, err error) {
+	for range ipnsMaxDepth {
+		value, err := m.ipns.resolve(m.ctx, m.cfg, name)
+		if err != nil {
+			return "", "", err
+		}
+		ns, rest, _ := strings.Cut(strings.TrimPrefix(value, "/"), "/")
+		id, sub, _ := strings.Cut(rest, "/")
+		if id == "" {
+			return "", "", errIPNSInvalidValueFn(name)
+		}
+		if sub != "" {
+			dir = path.Join(sub, dir)
+		}
+		switch ns {
+		case "ipfs":
+			return id, dir, nil
+		case "ipns":
+			name = id
+		default:
+			return "", "", errIPNSInvalidValueFn(name)
+		}
+	}
+	return "", "", errIPNSTooDeep
+}
+
+// ipnsCache caches the values of resolved IPNS and DNSLink names.
+type ipnsCache struct {
+	mu      sync.Mutex
+	entries map[string]ipnsCacheEntry
+}
+
+type ipnsCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// resolve returns the value of the IPNS record or the DNSLink of the name,
+// which is an IPFS or IPNS path, using the cached value if it has not
+// expired.
+func (c *ipnsCache) resolve(ctx context.Context, cfg *ipfsFS, name string) (string, error) {
+	now := cfg.clock.Now()
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.value, nil
+	}
+	var (
+		value string
+		ttl   = cfg.ipnsCacheTTL
+	)
+	if isDNSLinkName(name) {
+		var err error
+		if value, err = cfg.resolveDNSLink(ctx, name); err != nil {
+			return "", errDNSLinkResolveFn(name, err)
+		}
+		logutil.OrNop(cfg.logger).Debug("Resolved DNSLink name", "name", name, "value", value)
+	} else {
+		rec, err := cfg.resolveIPNSRecord(ctx, name)
+		if err != nil {
+			return "", errIPNSResolveFn(name, err)
+		}
+		logutil.OrNop(cfg.logger).Debug("Resolved IPNS name", "name", name, "value", rec.value, "sequence", rec.sequence)
+		value = rec.value
+		if rec.ttl > 0 && rec.ttl < ttl {
+			ttl = rec.ttl
+		}
+		if !rec.validity.IsZero() {
+			ttl = min(ttl, rec.validity.Sub(now))
+		}
+	}
+	c.mu.Lock()
+	if ttl > 0 {
+		c.entries[name] = ipnsCacheEntry{value: value, expires: now.Add(ttl)}
+	} else {
+		delete(c.entries, name)
+	}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// resolveIPNSRecord fetches, parses and validates the IPNS record of the
+// name, using either the configured resolver or the gateways.
+func (i *ipfsFS) resolveIPNSRecord(ctx context.Context, name string) (*ipnsRecord, error) {
+	if i.ipnsResolver != nil {
+		b, err := i.ipnsResolver(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return i.validIPNSRecord(name, b)
+	}
+	var (
+		errs  error
+		tried bool
+	)
+	for _, gw := range i.gateways {
+		if gw.IPNSResolveFn == nil {
+			continue
+		}
+		tried = true
+		hfs := &httpFS{
+			ctx:      ctx,
+			client:   i.client,
+			baseURI:  &netURL.URL{Scheme: gw.Scheme, Host: gw.Host},
+			parseFn:  gw.IPNSResolveFn(name),
+			logger:   i.logger,
+			maxBytes: ipnsMaxRecordSize,
+			accept:   ipnsRecordContentType,
+		}
+		b, err := fs.ReadFile(hfs, ".")
+		if err == nil {
+			var rec *ipnsRecord
+			if rec, err = i.validIPNSRecord(name, b); err == nil {
+				return rec, nil
+			}
+		}
+		logutil.OrNop(i.logger).Debug("Failed to resolve IPNS name using gateway", "name", name, "gateway", gw.Host, "error", err)
+		errs = errutil.Append(errs, err)
+	}
+	if !tried {
+		return nil, errIPNSNoGateways
+	}
+	return nil, errs
+}
+
+// validIPNSRecord parses the IPNS record and checks that it has not
+// expired. If enabled, the signature of the record is verified.
+func (i *ipfsFS) validIPNSRecord(name string, b []byte) (*ipnsRecord, error) {
+	e, err := parseIPNSEntry(b)
+	if err != nil {
+		return nil, err
+	}
+	if i.ipnsVerify {
+		if err := e.verify(name); err != nil {
+			return nil, err
+		}
+	}
+	rec, err := e.record()
+	if err != nil {
+		return nil, err
+	}
+	if !rec.validity.IsZero() && !i.clock.Now().Before(rec.validity) {
+		return nil, errIPNSRecordExpired
+	}
+	return rec, nil
+}
+
+var (
+	errIPNSTooDeep       = errutil.WithCode(errors.New("maximum IPNS resolution depth exceeded"), errutil.CodeConfig)
+	errIPNSNoGateways    = errutil.WithCode(errors.New("no gateways support IPNS resolution"), errutil.CodeConfig)
+	errIPNSRecordExpired = errutil.WithCode(errors.New("IPNS record expired"), errutil.CodeIntegrity)
+)
+
+func errIPNSResolveFn(name string, err error) error {
+	return fmt.Errorf("failed to resolve IPNS name %s: %w", name, err)
+}
+
+func errIPNSInvalidValueFn(name string) error {
+	return errutil.WithCode(fmt.Errorf("IPNS name %s does not resolve to an IPFS or IPNS path", name), errutil.CodeIntegrity)
+}
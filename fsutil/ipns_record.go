@@ -0,0 +1,441 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// ipnsRecord holds the fields of an IPNS record used for resolution.
+type ipnsRecord struct {
+	value    string
+	validity time.Time // Zero if the record does not expire.
+	sequence uint64
+	ttl      time.Duration
+}
+
+// ipnsEntry is a parsed, but not interpreted, IPNS record protobuf
+// message.
+type ipnsEntry struct {
+	value       []byte // 1
+	signatureV1 []byte // 2
+	validity    []byte // 4
+	sequence    uint64 // 5
+	ttl         uint64 // 6
+	pubKey      []byte // 7
+	signatureV2 []byte // 8
+	data        []byte // 9
+}
+
+// parseIPNSEntry parses the protobuf serialization of an IPNS record.
+func parseIPNSEntry(b []byte) (*ipnsEntry, error) {
+	e := &ipnsEntry{}
+	err := protoFields(b, func(num, v uint64, data []byte) error {
+		switch num {
+		case 1:
+			e.value = data
+		case 2:
+			e.signatureV1 = data
+		case 4:
+			e.validity = data
+		case 5:
+			e.sequence = v
+		case 6:
+			e.ttl = v
+		case 7:
+			e.pubKey = data
+		case 8:
+			e.signatureV2 = data
+		case 9:
+			e.data = data
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errIPNSInvalidRecordFn(err)
+	}
+	return e, nil
+}
+
+// record interprets the entry. The values are read from the signed
+// DAG-CBOR data if present, otherwise from the legacy protobuf fields,
+// which are not covered by the V2 signature.
+func (e *ipnsEntry) record() (*ipnsRecord, error) {
+	var (
+		value, validity []byte
+		validityType    uint64
+		rec             = &ipnsRecord{}
+	)
+	if len(e.data) > 0 {
+		v, err := decodeDAGCBOR(e.data)
+		if err != nil {
+			return nil, errIPNSInvalidRecordFn(err)
+		}
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, errIPNSInvalidRecordFn(errors.New("data is not a map"))
+		}
+		var ttl uint64
+		if value, ok = cborBytesValue(m["Value"]); !ok {
+			return nil, errIPNSInvalidRecordFn(errors.New("invalid Value field"))
+		}
+		if validity, ok = cborBytesValue(m["Validity"]); !ok {
+			return nil, errIPNSInvalidRecordFn(errors.New("invalid Validity field"))
+		}
+		if validityType, ok = cborUintValue(m["ValidityType"]); !ok {
+			return nil, errIPNSInvalidRecordFn(errors.New("invalid ValidityType field"))
+		}
+		if rec.sequence, ok = cborUintValue(m["Sequence"]); !ok {
+			return nil, errIPNSInvalidRecordFn(errors.New("invalid Sequence field"))
+		}
+		if ttl, ok = cborUintValue(m["TTL"]); !ok {
+			return nil, errIPNSInvalidRecordFn(errors.New("invalid TTL field"))
+		}
+		rec.ttl = ipnsDuration(ttl)
+	} else {
+		value, validity = e.value, e.validity
+		rec.sequence, rec.ttl = e.sequence, ipnsDuration(e.ttl)
+	}
+	if validityType != 0 {
+		// 0 is the only validity type, which means that the validity
+		// field holds the expiration time.
+		return nil, errIPNSInvalidRecordFn(fmt.Errorf("unsupported validity type: %d", validityType))
+	}
+	if len(validity) > 0 {
+		t, err := time.Parse(time.RFC3339Nano, string(validity))
+		if err != nil {
+			return nil, errIPNSInvalidRecordFn(fmt.Errorf("invalid validity: %w", err))
+		}
+		rec.validity = t
+	}
+	rec.value = string(value)
+	if !strings.HasPrefix(rec.value, "/ipfs/") && !strings.HasPrefix(rec.value, "/ipns/") {
+		return nil, errIPNSInvalidRecordFn(errors.New("value is not an IPFS or IPNS path"))
+	}
+	return rec, nil
+}
+
+// verify verifies the V2 signature of the entry using the public key of
+// the IPNS name. Entries without a V2 signature are rejected, as V1
+// signatures are deprecated and do not cover all fields.
+func (e *ipnsEntry) verify(name string) error {
+	key, err := ipnsPublicKey(name, e.pubKey)
+	if err != nil {
+		return err
+	}
+	if len(e.signatureV2) == 0 || len(e.data) == 0 {
+		return errIPNSMissingSignature
+	}
+	msg := append([]byte("ipns-signature:"), e.data...)
+	hash := sha256.Sum256(msg)
+	var ok bool
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, msg, e.signatureV2)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], e.signatureV2) == nil
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, hash[:], e.signatureV2)
+	}
+	if !ok {
+		return errIPNSInvalidSignature
+	}
+	return nil
+}
+
+const (
+	multicodecLibp2pKey = 0x72
+	multihashIdentity   = 0x00
+	multihashSHA256     = 0x12
+
+	libp2pKeyRSA     = 0
+	libp2pKeyEd25519 = 1
+	libp2pKeyECDSA   = 3
+)
+
+// ipnsPublicKey returns the public key of the IPNS name. The name is a
+// CIDv1 with the libp2p-key codec, encoded using base36 or base32, or a
+// base58 encoded multihash (legacy peer ID). If the multihash is not an
+// identity hash, the key is taken from the record and must match the hash.
+func ipnsPublicKey(name string, recordKey []byte) (crypto.PublicKey, error) {
+	mh, err := ipnsNameMultihash(name)
+	if err != nil {
+		return nil, errIPNSInvalidNameFn(name, err)
+	}
+	code, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return nil, errIPNSInvalidNameFn(name, errors.New("invalid multihash"))
+	}
+	size, m := binary.Uvarint(mh[n:])
+	if m <= 0 || size != uint64(len(mh)-n-m) {
+		return nil, errIPNSInvalidNameFn(name, errors.New("invalid multihash"))
+	}
+	digest := mh[n+m:]
+	var keyData []byte
+	switch code {
+	case multihashIdentity:
+		keyData = digest
+	case multihashSHA256:
+		if len(recordKey) == 0 {
+			return nil, errIPNSMissingPublicKey
+		}
+		if sum := sha256.Sum256(recordKey); !bytes.Equal(sum[:], digest) {
+			return nil, errIPNSPublicKeyMismatch
+		}
+		keyData = recordKey
+	default:
+		return nil, errIPNSInvalidNameFn(name, fmt.Errorf("unsupported multihash: 0x%x", code))
+	}
+	return parseLibp2pPublicKey(keyData)
+}
+
+// ipnsNameMultihash decodes the IPNS name and returns its multihash.
+func ipnsNameMultihash(name string) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch {
+	case strings.HasPrefix(name, "k"):
+		b, err = baseDecode(base36Alphabet, name[1:])
+	case strings.HasPrefix(name, "b"):
+		b, err = cidBase32.DecodeString(name[1:])
+	case strings.HasPrefix(name, "1"), strings.HasPrefix(name, "Qm"):
+		// Legacy peer IDs are bare base58 encoded multihashes.
+		return baseDecode(base58Alphabet, name)
+	default:
+		return nil, errors.New("unsupported encoding")
+	}
+	if err != nil {
+		return nil, err
+	}
+	version, n := binary.Uvarint(b)
+	if n <= 0 || version != 1 {
+		return nil, errors.New("not a CIDv1")
+	}
+	codec, m := binary.Uvarint(b[n:])
+	if m <= 0 || codec != multicodecLibp2pKey {
+		return nil, errors.New("not a libp2p-key CID")
+	}
+	return b[n+m:], nil
+}
+
+// parseLibp2pPublicKey parses a libp2p PublicKey protobuf message.
+func parseLibp2pPublicKey(b []byte) (crypto.PublicKey, error) {
+	var (
+		typ  uint64
+		data []byte
+	)
+	err := protoFields(b, func(num, v uint64, d []byte) error {
+		switch num {
+		case 1:
+			typ = v
+		case 2:
+			data = d
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errIPNSInvalidPublicKeyFn(err)
+	}
+	switch typ {
+	case libp2pKeyEd25519:
+		if len(data) != ed25519.PublicKeySize {
+			return nil, errIPNSInvalidPublicKeyFn(errors.New("invalid Ed25519 key size"))
+		}
+		return ed25519.PublicKey(data), nil
+	case libp2pKeyRSA, libp2pKeyECDSA:
+		key, err := x509.ParsePKIXPublicKey(data)
+		if err != nil {
+			return nil, errIPNSInvalidPublicKeyFn(err)
+		}
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if typ == libp2pKeyRSA {
+				return key, nil
+			}
+		case *ecdsa.PublicKey:
+			if typ == libp2pKeyECDSA {
+				return key, nil
+			}
+		}
+		return nil, errIPNSInvalidPublicKeyFn(errors.New("key does not match its type"))
+	default:
+		return nil, errIPNSUnsupportedKeyTypeFn(typ)
+	}
+}
+
+// protoFields calls fn for every field of the protobuf message b. The
+// value of varint fields is passed as v and the content of length-delimited
+// fields as data. Fixed-size fields are skipped.
+func protoFields(b []byte, fn func(num, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errProtoInvalidVarint
+		}
+		b = b[n:]
+		var (
+			v    uint64
+			data []byte
+		)
+		switch key & 7 {
+		case 0: // Varint.
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errProtoInvalidVarint
+			}
+			b = b[n:]
+		case 1: // 64-bit.
+			if len(b) < 8 {
+				return errProtoTruncated
+			}
+			b = b[8:]
+			continue
+		case 2: // Length-delimited.
+			l, n := binary.Uvarint(b)
+			if n <= 0 {
+				return errProtoInvalidVarint
+			}
+			if l > uint64(len(b)-n) {
+				return errProtoTruncated
+			}
+			data, b = b[n:n+int(l)], b[n+int(l):]
+		case 5: // 32-bit.
+			if len(b) < 4 {
+				return errProtoTruncated
+			}
+			b = b[4:]
+			continue
+		default:
+			return fmt.Errorf("unsupported wire type: %d", key&7)
+		}
+		if err := fn(key>>3, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cborBytesValue returns the content of bytes decoded by decodeDAGCBOR.
+func cborBytesValue(v any) ([]byte, bool) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	inner, ok := m["/"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	s, ok := inner["bytes"].(string)
+	if !ok {
+		return nil, false
+	}
+	b, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// cborUintValue returns the value of an unsigned integer decoded by
+// decodeDAGCBOR.
+func cborUintValue(v any) (uint64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	u, err := strconv.ParseUint(string(n), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return u, true
+}
+
+// ipnsDuration converts the TTL of an IPNS record, given in nanoseconds,
+// to a duration, saturating on overflow.
+func ipnsDuration(ns uint64) time.Duration {
+	if ns > uint64(1<<63-1) {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(ns)
+}
+
+const base36Alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// baseDecode decodes s using the given alphabet, where the first
+// character of the alphabet represents zero. Leading zeros are preserved,
+// as in base58.
+func baseDecode(alphabet, s string) ([]byte, error) {
+	var (
+		n    = new(big.Int)
+		base = big.NewInt(int64(len(alphabet)))
+	)
+	for _, c := range s {
+		d := strings.IndexRune(alphabet, c)
+		if d < 0 {
+			return nil, fmt.Errorf("invalid character: %q", c)
+		}
+		n.Mul(n, base).Add(n, big.NewInt(int64(d)))
+	}
+	var zeros int
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+var (
+	errProtoInvalidVarint = errors.New("invalid varint")
+	errProtoTruncated     = errors.New("truncated message")
+
+	errIPNSMissingSignature  = errutil.WithCode(errors.New("IPNS record is not signed"), errutil.CodeIntegrity)
+	errIPNSInvalidSignature  = errutil.WithCode(errors.New("invalid IPNS record signature"), errutil.CodeIntegrity)
+	errIPNSMissingPublicKey  = errutil.WithCode(errors.New("IPNS record does not contain the public key"), errutil.CodeIntegrity)
+	errIPNSPublicKeyMismatch = errutil.WithCode(errors.New("IPNS record public key does not match the name"), errutil.CodeIntegrity)
+)
+
+func errIPNSInvalidRecordFn(err error) error {
+	return errutil.WithCode(fmt.Errorf("invalid IPNS record: %w", err), errutil.CodeIntegrity)
+}
+
+func errIPNSInvalidNameFn(name string, err error) error {
+	return errutil.WithCode(fmt.Errorf("invalid IPNS name %s: %w", name, err), errutil.CodeConfig)
+}
+
+func errIPNSInvalidPublicKeyFn(err error) error {
+	return errutil.WithCode(fmt.Errorf("invalid IPNS public key: %w", err), errutil.CodeIntegrity)
+}
+
+func errIPNSUnsupportedKeyTypeFn(typ uint64) error {
+	return errutil.WithCode(fmt.Errorf("unsupported IPNS key type: %d", typ), errutil.CodeConfig)
+}
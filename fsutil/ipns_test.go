@@ -0,0 +1,460 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/ptrutil"
+	"github.com/chronicleprotocol/go-lib/testutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+func TestIPNSProto(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, key := testIPNSKey(t)
+	_, otherKey := testIPNSKey(t)
+	name := testIPNSName(key.Public().(ed25519.PublicKey))
+	tc := []struct {
+		name     string
+		record   []byte
+		records  map[string][]byte // Additional records.
+		verify   bool
+		uri      string
+		wantData string
+		wantCode errutil.Code
+	}{
+		{
+			name:     "root",
+			record:   testIPNSRecord(key, "/ipfs/QmTest", now.Add(time.Hour), 0),
+			verify:   true,
+			uri:      "ipns://" + name,
+			wantData: "root",
+		},
+		{
+			name:     "file",
+			record:   testIPNSRecord(key, "/ipfs/QmTest", now.Add(time.Hour), 0),
+			verify:   true,
+			uri:      "ipns://" + name + "/dir/file.txt",
+			wantData: "file",
+		},
+		{
+			name:     "path in record",
+			record:   testIPNSRecord(key, "/ipfs/QmTest/dir", now.Add(time.Hour), 0),
+			verify:   true,
+			uri:      "ipns://" + name + "/file.txt",
+			wantData: "file",
+		},
+		{
+			name:     "checksum",
+			record:   testIPNSRecord(key, "/ipfs/QmTest", now.Add(time.Hour), 0),
+			verify:   true,
+			uri:      "ipns://" + name + "/dir/file.txt?checksum=" + calculateKeccak256([]byte("file")).String(),
+			wantData: "file",
+		},
+		{
+			name:   "nested name",
+			record: testIPNSRecord(key, "/ipns/k51Other/dir", now.Add(time.Hour), 0),
+			records: map[string][]byte{
+				"k51Other": testIPNSRecord(otherKey, "/ipfs/QmTest", now.Add(time.Hour), 0),
+			},
+			uri:      "ipns://" + name + "/file.txt",
+			wantData: "file",
+		},
+		{
+			name:     "invalid signature",
+			record:   testIPNSRecord(otherKey, "/ipfs/QmTest", now.Add(time.Hour), 0),
+			verify:   true,
+			uri:      "ipns://" + name,
+			wantCode: errutil.CodeIntegrity,
+		},
+		{
+			name:     "invalid signature without verification",
+			record:   testIPNSRecord(otherKey, "/ipfs/QmTest", now.Add(time.Hour), 0),
+			uri:      "ipns://" + name,
+			wantData: "root",
+		},
+		{
+			name:     "expired record",
+			record:   testIPNSRecord(key, "/ipfs/QmTest", now.Add(-time.Second), 0),
+			verify:   true,
+			uri:      "ipns://" + name,
+			wantCode: errutil.CodeIntegrity,
+		},
+		{
+			name:     "invalid value",
+			record:   testIPNSRecord(key, "QmTest", now.Add(time.Hour), 0),
+			verify:   true,
+			uri:      "ipns://" + name,
+			wantCode: errutil.CodeIntegrity,
+		},
+		{
+			name:     "malformed record",
+			record:   []byte{0x0a, 0xff},
+			uri:      "ipns://" + name,
+			wantCode: errutil.CodeIntegrity,
+		},
+		{
+			name:     "missing record",
+			uri:      "ipns://" + name,
+			wantCode: errutil.CodeNotFound,
+		},
+		{
+			name:     "invalid name",
+			record:   testIPNSRecord(key, "/ipfs/QmTest", now.Add(time.Hour), 0),
+			verify:   true,
+			uri:      "ipns://zTest",
+			wantCode: errutil.CodeConfig,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := testutil.NewIPFSGateway()
+			defer gw.Close()
+			gw.AddFile("QmTest", "", []byte("root"))
+			gw.AddFile("QmTest", "dir/file.txt", []byte("file"))
+			if tt.record != nil {
+				gw.AddIPNSRecord(name, tt.record)
+				gw.AddIPNSRecord("zTest", tt.record)
+			}
+			for n, r := range tt.records {
+				gw.AddIPNSRecord(n, r)
+			}
+			opts := []IPFSOption{
+				WithIPFSHTTPClient(gw.Client()),
+				WithIPFSGateways(&IPFSGateway{
+					Scheme:        gw.Scheme(),
+					Host:          gw.Host(),
+					ResolveFn:     IPFSPathResolution,
+					IPNSResolveFn: IPNSPathResolution,
+				}),
+				WithIPFSClock(timeutil.NewFake(now)),
+			}
+			if tt.verify {
+				opts = append(opts, WithIPNSRecordVerification())
+			}
+			fsys, path, err := ParseURI(NewIPFSProto(ctx, opts...), tt.uri)
+			if tt.wantCode != errutil.CodeUnknown {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			data, err := fs.ReadFile(fsys, path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, string(data))
+		})
+	}
+}
+
+func TestIPNSProto_Cache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, key := testIPNSKey(t)
+	name := testIPNSName(key.Public().(ed25519.PublicKey))
+	tc := []struct {
+		name      string
+		recordTTL time.Duration
+		validity  time.Duration
+		cacheTTL  *time.Duration
+		advance   []time.Duration
+		wantCalls int
+		wantErr   bool // Whether the last resolution fails.
+	}{
+		{
+			name:      "default TTL",
+			advance:   []time.Duration{30 * time.Second, 29 * time.Second},
+			wantCalls: 1,
+		},
+		{
+			name:      "default TTL expired",
+			advance:   []time.Duration{30 * time.Second, 30 * time.Second},
+			wantCalls: 2,
+		},
+		{
+			name:      "record TTL",
+			recordTTL: 10 * time.Second,
+			advance:   []time.Duration{5 * time.Second, 5 * time.Second},
+			wantCalls: 2,
+		},
+		{
+			name:      "record TTL longer than maximum",
+			recordTTL: time.Hour,
+			cacheTTL:  ptrutil.Ptr(time.Minute),
+			advance:   []time.Duration{time.Minute},
+			wantCalls: 2,
+		},
+		{
+			// The name must not be resolved from the cache after the
+			// record expires.
+			name:      "record validity",
+			validity:  10 * time.Second,
+			advance:   []time.Duration{5 * time.Second, 5 * time.Second},
+			wantCalls: 2,
+			wantErr:   true,
+		},
+		{
+			name:      "disabled",
+			cacheTTL:  ptrutil.Ptr(time.Duration(0)),
+			advance:   []time.Duration{0, 0},
+			wantCalls: 3,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := timeutil.NewFake(now)
+			validity := tt.validity
+			if validity == 0 {
+				validity = 24 * time.Hour
+			}
+			record := testIPNSRecord(key, "/ipfs/QmTest", now.Add(validity), tt.recordTTL)
+			calls := 0
+			opts := []IPFSOption{
+				WithIPNSResolver(func(_ context.Context, n string) ([]byte, error) {
+					calls++
+					if n != name {
+						return nil, fs.ErrNotExist
+					}
+					return record, nil
+				}),
+				WithIPNSRecordVerification(),
+				WithIPFSClock(clock),
+			}
+			if tt.cacheTTL != nil {
+				opts = append(opts, WithIPNSCacheTTL(*tt.cacheTTL))
+			}
+			proto := NewIPFSProto(ctx, opts...)
+			_, _, err := ParseURI(proto, "ipns://"+name)
+			require.NoError(t, err)
+			for _, d := range tt.advance {
+				clock.Advance(d)
+				_, _, err = ParseURI(proto, "ipns://"+name)
+			}
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errIPNSRecordExpired)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIPNSProto_Resolver(t *testing.T) {
+	ctx := context.Background()
+	proto := NewIPFSProto(
+		ctx,
+		WithIPNSResolver(func(context.Context, string) ([]byte, error) {
+			return nil, errors.New("resolver error")
+		}),
+	)
+	_, _, err := ParseURI(proto, "ipns://k51Test")
+	assert.ErrorContains(t, err, "resolver error")
+
+	proto = NewIPFSProto(ctx, WithIPFSGateways(&IPFSGateway{Scheme: "https", Host: "example.com", ResolveFn: IPFSPathResolution}))
+	_, _, err = ParseURI(proto, "ipns://k51Test")
+	assert.ErrorIs(t, err, errIPNSNoGateways)
+}
+
+func TestIPNSEntry_Verify(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	edPub, edKey := testIPNSKey(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+	ecPubKey := testLibp2pPublicKey(libp2pKeyECDSA, ecDER)
+	ecHash := sha256.Sum256(ecPubKey)
+	ecName := "k" + testBaseEncode(base36Alphabet, testLibp2pCID(append([]byte{multihashSHA256, 32}, ecHash[:]...)))
+
+	edRecord := testIPNSRecord(edKey, "/ipfs/QmTest", now, 0)
+	ecRecord := testIPNSRecordSigned(func(msg []byte) []byte {
+		h := sha256.Sum256(msg)
+		sig, err := ecdsa.SignASN1(rand.Reader, ecKey, h[:])
+		require.NoError(t, err)
+		return sig
+	}, ecPubKey, "/ipfs/QmTest", now, 0)
+
+	edIdentity := append([]byte{multihashIdentity, byte(len(edPub))}, edPub...)
+	tc := []struct {
+		name     string
+		ipnsName string
+		record   []byte
+		wantErr  error
+		wantCode errutil.Code
+	}{
+		{name: "base36", ipnsName: testIPNSName(edKey.Public().(ed25519.PublicKey)), record: edRecord},
+		{name: "base32", ipnsName: "b" + cidBase32.EncodeToString(testLibp2pCID(edIdentity)), record: edRecord},
+		{name: "peer ID", ipnsName: base58Encode(edIdentity), record: edRecord},
+		{name: "hashed key", ipnsName: ecName, record: ecRecord},
+		{name: "hashed key mismatch", ipnsName: ecName, record: testIPNSRecordSigned(func([]byte) []byte { return []byte{1} }, edPub, "/ipfs/QmTest", now, 0), wantErr: errIPNSPublicKeyMismatch},
+		{name: "hashed key missing", ipnsName: ecName, record: testIPNSRecordSigned(func([]byte) []byte { return []byte{1} }, nil, "/ipfs/QmTest", now, 0), wantErr: errIPNSMissingPublicKey},
+		{name: "wrong key", ipnsName: ecName, record: testIPNSRecordSigned(func(msg []byte) []byte { return ed25519.Sign(edKey, msg) }, ecPubKey, "/ipfs/QmTest", now, 0), wantErr: errIPNSInvalidSignature},
+		{name: "unsigned", ipnsName: testIPNSName(edKey.Public().(ed25519.PublicKey)), record: testProtoBytes(1, []byte("/ipfs/QmTest")), wantErr: errIPNSMissingSignature},
+		{name: "invalid encoding", ipnsName: "zTest", record: edRecord, wantCode: errutil.CodeConfig},
+		{name: "not a libp2p key", ipnsName: "b" + cidBase32.EncodeToString([]byte{0x01, 0x55, 0x00, 0x00}), record: edRecord, wantCode: errutil.CodeConfig},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseIPNSEntry(tt.record)
+			require.NoError(t, err)
+			err = e.verify(tt.ipnsName)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantCode != errutil.CodeUnknown:
+				assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// testIPNSKey returns the libp2p public key and the private key of a new
+// Ed25519 key pair.
+func testIPNSKey(t *testing.T) ([]byte, ed25519.PrivateKey) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testLibp2pPublicKey(libp2pKeyEd25519, pub), key
+}
+
+// testIPNSName returns the base36 encoded IPNS name of the Ed25519 key.
+func testIPNSName(pub ed25519.PublicKey) string {
+	key := testLibp2pPublicKey(libp2pKeyEd25519, pub)
+	return "k" + testBaseEncode(base36Alphabet, testLibp2pCID(append([]byte{multihashIdentity, byte(len(key))}, key...)))
+}
+
+func testLibp2pPublicKey(typ uint64, data []byte) []byte {
+	return append(testProtoVarint(1, typ), testProtoBytes(2, data)...)
+}
+
+func testLibp2pCID(multihash []byte) []byte {
+	return append([]byte{0x01, multicodecLibp2pKey}, multihash...)
+}
+
+// testIPNSRecord returns an IPNS record signed with the Ed25519 key.
+func testIPNSRecord(key ed25519.PrivateKey, value string, validity time.Time, ttl time.Duration) []byte {
+	return testIPNSRecordSigned(func(msg []byte) []byte {
+		return ed25519.Sign(key, msg)
+	}, nil, value, validity, ttl)
+}
+
+// testIPNSRecordSigned returns an IPNS record with both V1 and V2 fields,
+// signed using the sign function. If pubKey is not nil, it is included in
+// the record.
+func testIPNSRecordSigned(sign func([]byte) []byte, pubKey []byte, value string, validity time.Time, ttl time.Duration) []byte {
+	validityStr := []byte(validity.UTC().Format(time.RFC3339Nano))
+	// DAG-CBOR map with keys sorted by length, then bytewise.
+	var data []byte
+	data = append(data, 0xa5)
+	data = append(data, testCBORText("TTL")...)
+	data = append(data, testCBORHead(0, uint64(ttl))...)
+	data = append(data, testCBORText("Value")...)
+	data = append(data, testCBORBytes([]byte(value))...)
+	data = append(data, testCBORText("Sequence")...)
+	data = append(data, testCBORHead(0, 1)...)
+	data = append(data, testCBORText("Validity")...)
+	data = append(data, testCBORBytes(validityStr)...)
+	data = append(data, testCBORText("ValidityType")...)
+	data = append(data, testCBORHead(0, 0)...)
+
+	var b []byte
+	b = append(b, testProtoBytes(1, []byte(value))...)
+	b = append(b, testProtoVarint(3, 0)...)
+	b = append(b, testProtoBytes(4, validityStr)...)
+	b = append(b, testProtoVarint(5, 1)...)
+	b = append(b, testProtoVarint(6, uint64(ttl))...)
+	if pubKey != nil {
+		b = append(b, testProtoBytes(7, pubKey)...)
+	}
+	b = append(b, testProtoBytes(8, sign(append([]byte("ipns-signature:"), data...)))...)
+	b = append(b, testProtoBytes(9, data)...)
+	return b
+}
+
+func testProtoVarint(num, v uint64) []byte {
+	b := binary.AppendUvarint(nil, num<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+func testProtoBytes(num uint64, data []byte) []byte {
+	b := binary.AppendUvarint(nil, num<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func testCBORHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, n)
+	}
+}
+
+func testCBORText(s string) []byte {
+	return append(testCBORHead(3, uint64(len(s))), s...)
+}
+
+func testCBORBytes(b []byte) []byte {
+	return append(testCBORHead(2, uint64(len(b))), b...)
+}
+
+// testBaseEncode encodes b using the given alphabet, as in base58Encode.
+func testBaseEncode(alphabet string, b []byte) string {
+	var (
+		n    = new(big.Int).SetBytes(b)
+		base = big.NewInt(int64(len(alphabet)))
+		mod  = new(big.Int)
+		out  []byte
+	)
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		out = append([]byte{alphabet[mod.Int64()]}, out...)
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append([]byte{alphabet[0]}, out...)
+	}
+	return string(out)
+}
@@ -0,0 +1,305 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	netURL "net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// MemScheme is the URI scheme of the in-memory protocol.
+const MemScheme = "mem"
+
+// NewMemProto creates a new protocol that serves files from the given
+// in-memory file system. The host and the path of the URI are joined, so
+// "mem://dir/config.hcl" and "mem:///dir/config.hcl" refer to the same
+// file.
+func NewMemProto(m *MemFS) Protocol {
+	return &memProto{fs: m}
+}
+
+type memProto struct {
+	fs *MemFS
+}
+
+// FileSystem implements the Protocol interface.
+func (m *memProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err error) {
+	if uri == nil {
+		return nil, "", errMemProtoNilURI
+	}
+	if uri.Scheme != MemScheme {
+		return nil, "", errMemProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	if uri.Opaque != "" {
+		return nil, "", errMemProtoOpaqueNotAllowed
+	}
+	if uri.Fragment != "" || uri.RawFragment != "" {
+		return nil, "", errMemProtoFragmentNotAllowed
+	}
+	u := uriCopy(uri)
+	u.Path = "/" + strings.TrimPrefix(uri.Host+uri.Path, "/")
+	u.RawPath = ""
+	return m.fs, uriPath(u, true), nil
+}
+
+// MemFS is a writable in-memory file system. It is safe for concurrent
+// use. The zero value is an empty file system ready to use.
+//
+// Directories are created implicitly when a file is written and exist as
+// long as they contain files.
+type MemFS struct {
+	mu    sync.RWMutex
+	files map[string]*memFile
+}
+
+type memFile struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemFS creates a new empty in-memory file system.
+func NewMemFS() *MemFS {
+	return &MemFS{}
+}
+
+// WriteFile writes data to the named file, creating it and its parent
+// directories if necessary. If the file exists, it is replaced. The data
+// is copied, so the caller may modify it afterwards.
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := validPath("writeFile", name); err != nil {
+		return errMemFSFn(err)
+	}
+	if name == "." {
+		return errMemFSFn(&fs.PathError{Op: "writeFile", Path: name, Err: errMemFSIsDir})
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.isDir(name) {
+		return errMemFSFn(&fs.PathError{Op: "writeFile", Path: name, Err: errMemFSIsDir})
+	}
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if _, ok := m.files[dir]; ok {
+			return errMemFSFn(&fs.PathError{Op: "writeFile", Path: name, Err: errMemFSNotDir})
+		}
+	}
+	if m.files == nil {
+		m.files = make(map[string]*memFile)
+	}
+	m.files[name] = &memFile{
+		data:    bytes.Clone(data),
+		mode:    perm.Perm(),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// Remove removes the named file. Directories are removed when their last
+// file is removed.
+func (m *MemFS) Remove(name string) error {
+	if err := validPath("remove", name); err != nil {
+		return errMemFSFn(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; !ok {
+		return errMemFSFn(&fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist})
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// Open implements the fs.FS interface.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	if err := validPath("open", name); err != nil {
+		return nil, errMemFSFn(err)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if f, ok := m.files[name]; ok {
+		return &file{
+			reader: io.NopCloser(bytes.NewReader(f.data)),
+			info:   f.info(name),
+		}, nil
+	}
+	if m.isDir(name) {
+		return &memDir{info: memDirInfo(name), entries: m.readDir(name)}, nil
+	}
+	return nil, errMemFSFn(&fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist})
+}
+
+// ReadFile implements the fs.ReadFileFS interface.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	if err := validPath("readFile", name); err != nil {
+		return nil, errMemFSFn(err)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.files[name]
+	if !ok {
+		if m.isDir(name) {
+			return nil, errMemFSFn(&fs.PathError{Op: "readFile", Path: name, Err: errMemFSIsDir})
+		}
+		return nil, errMemFSFn(&fs.PathError{Op: "readFile", Path: name, Err: fs.ErrNotExist})
+	}
+	return bytes.Clone(f.data), nil
+}
+
+// Stat implements the fs.StatFS interface.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	if err := validPath("stat", name); err != nil {
+		return nil, errMemFSFn(err)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if f, ok := m.files[name]; ok {
+		return f.info(name), nil
+	}
+	if m.isDir(name) {
+		return memDirInfo(name), nil
+	}
+	return nil, errMemFSFn(&fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist})
+}
+
+// ReadDir implements the fs.ReadDirFS interface. Entries are sorted by
+// name.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := validPath("readDir", name); err != nil {
+		return nil, errMemFSFn(err)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.isDir(name) {
+		if _, ok := m.files[name]; ok {
+			return nil, errMemFSFn(&fs.PathError{Op: "readDir", Path: name, Err: errMemFSNotDir})
+		}
+		return nil, errMemFSFn(&fs.PathError{Op: "readDir", Path: name, Err: fs.ErrNotExist})
+	}
+	return m.readDir(name), nil
+}
+
+// isDir reports whether the named directory exists, i.e. whether it
+// contains any files. The root directory always exists.
+func (m *MemFS) isDir(name string) bool {
+	if name == "." {
+		return true
+	}
+	prefix := name + "/"
+	for n := range m.files {
+		if strings.HasPrefix(n, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readDir returns the sorted entries of the named directory.
+func (m *MemFS) readDir(name string) []fs.DirEntry {
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	var entries []fs.DirEntry
+	seen := make(map[string]bool)
+	for n, f := range m.files {
+		rest, ok := strings.CutPrefix(n, prefix)
+		if !ok {
+			continue
+		}
+		child, _, isDir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		if isDir {
+			entries = append(entries, fs.FileInfoToDirEntry(memDirInfo(prefix+child)))
+		} else {
+			entries = append(entries, fs.FileInfoToDirEntry(f.info(n)))
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries
+}
+
+func (f *memFile) info(name string) *fileInfo {
+	return &fileInfo{
+		name:    path.Base(name),
+		size:    int64(len(f.data)),
+		mode:    f.mode,
+		modTime: f.modTime,
+	}
+}
+
+func memDirInfo(name string) *fileInfo {
+	return &fileInfo{name: path.Base(name), mode: fs.ModeDir | 0o555, isDir: true}
+}
+
+// memDir implements the fs.ReadDirFile interface for directories of
+// MemFS. Entries are captured when the directory is opened.
+type memDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read(_ []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errMemFSIsDir}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
+
+var (
+	errMemProtoNilURI             = errutil.WithCode(errors.New("fsutil.memProto: nil URI"), errutil.CodeConfig)
+	errMemProtoOpaqueNotAllowed   = errutil.WithCode(errors.New("fsutil.memProto: opaque not allowed"), errutil.CodeConfig)
+	errMemProtoFragmentNotAllowed = errutil.WithCode(errors.New("fsutil.memProto: fragment not allowed"), errutil.CodeConfig)
+	errMemFSIsDir                 = errors.New("is a directory")
+	errMemFSNotDir                = errors.New("not a directory")
+)
+
+func errMemProtoUnexpectedSchemeFn(scheme string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.memProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
+}
+
+func errMemFSFn(err error) error {
+	return fmt.Errorf("fsutil.memFS: %w", err)
+}
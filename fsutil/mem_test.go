@@ -0,0 +1,162 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package fsutil
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+func TestMemProto(t *testing.T) {
+	m := NewMemFS()
+	require.NoError(t, m.WriteFile("dir/config.hcl", []byte("config"), 0o644))
+
+	tc := []struct {
+		name     string
+		uri      string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "nil URL", wantErr: true},
+		{name: "unexpected scheme", uri: "file:///dir/config.hcl", wantErr: true},
+		{name: "fragment", uri: "mem:///dir/config.hcl#x", wantErr: true},
+		{name: "empty host", uri: "mem:///dir/config.hcl", wantPath: "dir/config.hcl"},
+		{name: "host", uri: "mem://dir/config.hcl", wantPath: "dir/config.hcl"},
+		{name: "root", uri: "mem://", wantPath: "."},
+		{name: "query", uri: "mem://dir/config.hcl?checksum=x", wantPath: "dir/config.hcl?checksum=x"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var u *url.URL
+			if tt.uri != "" {
+				var err error
+				u, err = url.Parse(tt.uri)
+				require.NoError(t, err)
+			}
+			fsys, path, err := NewMemProto(m).FileSystem(u)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Same(t, m, fsys)
+		})
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS()
+	require.NoError(t, m.WriteFile("a.hcl", []byte("a"), 0o644))
+	require.NoError(t, m.WriteFile("dir/b.hcl", []byte("b"), 0o600))
+	require.NoError(t, m.WriteFile("dir/sub/c.hcl", []byte("c"), 0o644))
+	require.NoError(t, fstest.TestFS(m, "a.hcl", "dir/b.hcl", "dir/sub/c.hcl"))
+
+	t.Run("read file", func(t *testing.T) {
+		b, err := m.ReadFile("dir/b.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, "b", string(b))
+		info, err := m.Stat("dir/b.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0o600), info.Mode())
+	})
+	t.Run("read directory", func(t *testing.T) {
+		entries, err := m.ReadDir("dir")
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "b.hcl", entries[0].Name())
+		assert.Equal(t, "sub", entries[1].Name())
+		assert.True(t, entries[1].IsDir())
+	})
+	t.Run("overwrite", func(t *testing.T) {
+		m := NewMemFS()
+		data := []byte("old")
+		require.NoError(t, m.WriteFile("a.hcl", data, 0o644))
+		data[0] = 'x'
+		b, err := m.ReadFile("a.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(b))
+		require.NoError(t, m.WriteFile("a.hcl", []byte("new"), 0o644))
+		b, err = m.ReadFile("a.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(b))
+	})
+	t.Run("remove", func(t *testing.T) {
+		m := NewMemFS()
+		require.NoError(t, m.WriteFile("dir/a.hcl", []byte("a"), 0o644))
+		require.NoError(t, m.Remove("dir/a.hcl"))
+		_, err := m.Stat("dir")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		assert.ErrorIs(t, m.Remove("dir/a.hcl"), fs.ErrNotExist)
+	})
+	t.Run("write over directory", func(t *testing.T) {
+		assert.Error(t, m.WriteFile("dir", []byte("x"), 0o644))
+		assert.Error(t, m.WriteFile(".", []byte("x"), 0o644))
+	})
+	t.Run("write below file", func(t *testing.T) {
+		assert.Error(t, m.WriteFile("a.hcl/x", []byte("x"), 0o644))
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		assert.ErrorIs(t, m.WriteFile("../a.hcl", []byte("x"), 0o644), fs.ErrInvalid)
+		_, err := m.Open("/a.hcl")
+		assert.ErrorIs(t, err, fs.ErrInvalid)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := m.ReadFile("missing.hcl")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		assert.Equal(t, errutil.CodeNotFound, errutil.CodeOf(err))
+	})
+	t.Run("zero value", func(t *testing.T) {
+		var m MemFS
+		entries, err := m.ReadDir(".")
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		require.NoError(t, m.WriteFile("a.hcl", []byte("a"), 0o644))
+	})
+}
+
+func TestMemFS_Concurrent(t *testing.T) {
+	m := NewMemFS()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("dir/%d.hcl", i)
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, m.WriteFile(name, []byte(name), 0o644))
+				b, err := m.ReadFile(name)
+				assert.NoError(t, err)
+				assert.Equal(t, name, string(b))
+				_, err = m.ReadDir("dir")
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+	entries, err := m.ReadDir("dir")
+	require.NoError(t, err)
+	assert.Len(t, entries, 10)
+}
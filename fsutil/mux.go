@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"io/fs"
 	netURL "net/url"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
 )
 
 // ProtoFunc is a function that creates a Protocol from a URL.
@@ -53,8 +55,8 @@ func (m *mux) FileSystem(uri *netURL.URL) (fs.FS, string, error) {
 }
 
 var (
-	errMuxNilURI        = fmt.Errorf("fsutil.mux: nil URI")
-	errMuxUnknownScheme = fmt.Errorf("fsutil.mux: unknown scheme")
+	errMuxNilURI        = errutil.WithCode(fmt.Errorf("fsutil.mux: nil URI"), errutil.CodeConfig)
+	errMuxUnknownScheme = errutil.WithCode(fmt.Errorf("fsutil.mux: unknown scheme"), errutil.CodeConfig)
 )
 
 func errMuxUnknownSchemeFn(scheme string) error {
@@ -0,0 +1,157 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/logutil"
+)
+
+// ProtocolsConfig configures the protocols created by NewProtocols.
+type ProtocolsConfig struct {
+	// RetryAttempts and RetryDelay configure retries of remote protocols,
+	// see NewRetryProto.
+	RetryAttempts int
+	RetryDelay    time.Duration
+
+	// Cache enables caching of remote files, see NewCacheProto.
+	Cache        bool
+	CacheOptions []CacheFSOption
+
+	// NoChecksum disables the verification of the "checksum" parameter,
+	// see NewChecksumProto. The IPFS protocol verifies checksums itself,
+	// regardless of this setting.
+	NoChecksum bool
+
+	HTTPOptions   []HTTPFSOption
+	IPFSOptions   []IPFSOption
+	GitOptions    []GitOption
+	GitHubOptions []GitHubOption
+
+	// MemFS, EmbedRegistry and DecryptionKey enable the mem, embed and
+	// encrypted schemes, respectively.
+	MemFS         *MemFS
+	EmbedRegistry *EmbedRegistry
+	DecryptionKey KeyProvider
+
+	// Logger is passed to all protocols. Options given in the fields above
+	// take precedence over it.
+	Logger logutil.Logger
+}
+
+// Protocols is the set of protocols used to read configuration files, as
+// used by the config package. It supports the file, env, http, https, ipfs,
+// ipns, dav, davs, github and git+<transport> schemes, and optionally the
+// mem, embed and encrypted+<scheme> schemes, see ProtocolsConfig.
+//
+// Remote protocols retry failed requests and may cache files. The protocols
+// are meant to be created once and shared by many reads, so that their
+// state, like the IPNS and DNSLink caches, is reused.
+type Protocols struct {
+	protos  map[string]ProtoFunc
+	gitOpts []GitOption
+	verify  func(Protocol) Protocol
+}
+
+// NewProtocols creates the protocols using the given configuration. The
+// context is used by remote protocols, see NewHTTPProto.
+func NewProtocols(ctx context.Context, cfg ProtocolsConfig) *Protocols {
+	var (
+		retryOpts []RetryFSOption
+		cacheOpts = cfg.CacheOptions
+		httpOpts  = cfg.HTTPOptions
+		ipfsOpts  = cfg.IPFSOptions
+		gitOpts   = cfg.GitOptions
+		ghOpts    = cfg.GitHubOptions
+	)
+	if cfg.Logger != nil {
+		// Options set by the user are applied last, so they take precedence.
+		retryOpts = append(retryOpts, WithRetryLogger(cfg.Logger))
+		cacheOpts = append([]CacheFSOption{WithCacheLogger(cfg.Logger)}, cacheOpts...)
+		httpOpts = append([]HTTPFSOption{WithHTTPLogger(cfg.Logger)}, httpOpts...)
+		ipfsOpts = append([]IPFSOption{WithIPFSLogger(cfg.Logger)}, ipfsOpts...)
+		gitOpts = append([]GitOption{WithGitLogger(cfg.Logger)}, gitOpts...)
+		ghOpts = append([]GitHubOption{WithGitHubLogger(cfg.Logger)}, ghOpts...)
+	}
+	remote := func(proto Protocol) Protocol {
+		proto = NewRetryProto(ctx, proto, cfg.RetryAttempts, cfg.RetryDelay, retryOpts...)
+		if cfg.Cache {
+			proto = NewCacheProto(proto, cacheOpts...)
+		}
+		return proto
+	}
+	verify := func(proto Protocol) Protocol {
+		if cfg.NoChecksum {
+			return proto
+		}
+		return NewChecksumProto(proto)
+	}
+	file := verify(NewFileProto())
+	env := verify(NewEnvProto())
+	web := verify(remote(NewHTTPProto(ctx, httpOpts...)))
+	// The IPFS file system verifies the checksum of the response of every
+	// gateway, so that a corrupted response makes it try the next gateway
+	// instead of failing.
+	ipfs := remote(NewIPFSProto(ctx, ipfsOpts...))
+	dav := verify(remote(NewWebDAVProto(ctx, httpOpts...)))
+	github := verify(remote(NewGitHubProto(ctx, ghOpts...)))
+	protos := map[string]ProtoFunc{
+		"file":   func(*url.URL) (Protocol, error) { return file, nil },
+		"env":    func(*url.URL) (Protocol, error) { return env, nil },
+		"http":   func(*url.URL) (Protocol, error) { return web, nil },
+		"https":  func(*url.URL) (Protocol, error) { return web, nil },
+		"ipfs":   func(*url.URL) (Protocol, error) { return ipfs, nil },
+		"ipns":   func(*url.URL) (Protocol, error) { return ipfs, nil },
+		"dav":    func(*url.URL) (Protocol, error) { return dav, nil },
+		"davs":   func(*url.URL) (Protocol, error) { return dav, nil },
+		"github": func(*url.URL) (Protocol, error) { return github, nil },
+	}
+	if cfg.MemFS != nil {
+		mem := verify(NewMemProto(cfg.MemFS))
+		protos[MemScheme] = func(*url.URL) (Protocol, error) { return mem, nil }
+	}
+	if cfg.EmbedRegistry != nil {
+		embed := verify(NewEmbedProto(cfg.EmbedRegistry))
+		protos[EmbedScheme] = func(*url.URL) (Protocol, error) { return embed, nil }
+	}
+	if cfg.DecryptionKey != nil {
+		for scheme, proto := range map[string]Protocol{"file": file, "http": web, "https": web, "ipfs": ipfs, "ipns": ipfs, "dav": dav, "davs": dav} {
+			enc := NewEncryptedProto(proto, cfg.DecryptionKey)
+			protos[EncryptedSchemePrefix+scheme] = func(*url.URL) (Protocol, error) { return enc, nil }
+		}
+	}
+	// Repositories are fetched once per Mux call and read from a local
+	// checkout, so retries and caching are not needed.
+	return &Protocols{protos: protos, gitOpts: gitOpts, verify: verify}
+}
+
+// Mux returns a protocol multiplexer for the supported schemes. Git
+// repositories are checked out separately for every Mux call and removed
+// when the context is canceled, so that every call sees new commits and
+// checkouts do not pile up. The context should be canceled once the files
+// are read.
+func (p *Protocols) Mux(ctx context.Context) Protocol {
+	protos := maps.Clone(p.protos)
+	git := p.verify(NewGitProto(ctx, p.gitOpts...))
+	for _, transport := range []string{"http", "https", "ssh", "file"} {
+		protos[GitSchemePrefix+transport] = func(*url.URL) (Protocol, error) { return git, nil }
+	}
+	return NewMux(protos)
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocols(t *testing.T) {
+	m := NewMemFS()
+	require.NoError(t, m.WriteFile("config.hcl", []byte("data"), 0o644))
+	checksum := calculateKeccak256([]byte("data")).String()
+
+	tests := []struct {
+		name    string
+		cfg     ProtocolsConfig
+		uri     string
+		wantErr bool
+	}{
+		{name: "mem", cfg: ProtocolsConfig{MemFS: m}, uri: "mem://config.hcl"},
+		{name: "checksum", cfg: ProtocolsConfig{MemFS: m}, uri: "mem://config.hcl?checksum=" + checksum},
+		{name: "checksum mismatch", cfg: ProtocolsConfig{MemFS: m}, uri: "mem://config.hcl?checksum=0x1234", wantErr: true},
+		{name: "mem disabled", uri: "mem://config.hcl", wantErr: true},
+		{name: "unknown scheme", uri: "foo://config.hcl", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fsys, name, err := ParseURI(NewProtocols(ctx, tt.cfg).Mux(ctx), tt.uri)
+			if err == nil {
+				var data []byte
+				data, err = fs.ReadFile(fsys, name)
+				if err == nil {
+					assert.Equal(t, "data", string(data))
+				}
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -25,15 +25,26 @@ import (
 	"path"
 	"time"
 
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/logutil"
 	"github.com/chronicleprotocol/go-lib/retry"
 )
 
+type RetryFSOption func(*retryFS)
+
+// WithRetryLogger sets the logger used to log failed attempts.
+func WithRetryLogger(logger logutil.Logger) RetryFSOption {
+	return func(r *retryFS) {
+		r.logger = logger
+	}
+}
+
 // NewRetryProto creates a new retry protocol.
 //
 // The retry protocol will wrap the filesystem returned by a given protocol
 // with a retry filesystem.
-func NewRetryProto(ctx context.Context, proto Protocol, attempts int, delay time.Duration) Protocol {
-	return &retryProto{ctx: ctx, proto: proto, attempts: attempts, delay: delay}
+func NewRetryProto(ctx context.Context, proto Protocol, attempts int, delay time.Duration, opts ...RetryFSOption) Protocol {
+	return &retryProto{ctx: ctx, proto: proto, attempts: attempts, delay: delay, opts: opts}
 }
 
 type retryProto struct {
@@ -41,6 +52,7 @@ type retryProto struct {
 	proto    Protocol
 	attempts int
 	delay    time.Duration
+	opts     []RetryFSOption
 }
 
 // FileSystem implements the Protocol interface.
@@ -52,7 +64,7 @@ func (m *retryProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err err
 	if err != nil {
 		return nil, "", errRetryProtoFn(err)
 	}
-	fs = NewRetryFS(m.ctx, fs, m.attempts, m.delay)
+	fs = NewRetryFS(m.ctx, fs, m.attempts, m.delay, m.opts...)
 	return
 }
 
@@ -61,11 +73,17 @@ type retryFS struct {
 	fs       fs.FS
 	attempts int
 	delay    time.Duration
+	logger   logutil.Logger
 }
 
 // NewRetryFS wraps the given FS to add retry functionality.
-func NewRetryFS(ctx context.Context, fs fs.FS, attempts int, delay time.Duration) fs.FS {
-	return &retryFS{ctx: ctx, fs: fs, attempts: attempts, delay: delay}
+func NewRetryFS(ctx context.Context, fs fs.FS, attempts int, delay time.Duration, opts ...RetryFSOption) fs.FS {
+	r := &retryFS{ctx: ctx, fs: fs, attempts: attempts, delay: delay}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.logger = logutil.OrNop(r.logger)
+	return r
 }
 
 // Open implements the fs.Open interface.
@@ -73,12 +91,12 @@ func (r *retryFS) Open(name string) (f fs.File, err error) {
 	if err := validPath("open", name); err != nil {
 		return nil, errRetryFSFn(err)
 	}
-	return retry.Try2(r.ctx, func(_ context.Context) (fs.File, error, bool) {
+	return retry.Try2(r.ctx, func(ctx context.Context) (fs.File, error, bool) {
 		f, err = r.fs.Open(name)
 		if err == nil {
 			return f, nil, retry.Stop
 		}
-		if !isRetryable(err) {
+		if !r.retryable(ctx, "open", name, err) {
 			return nil, errRetryFSFn(err), retry.Stop
 		}
 		return f, errRetryFSFn(err), retry.TryAgain
@@ -90,12 +108,12 @@ func (r *retryFS) Glob(pattern string) ([]string, error) {
 	if err := validPattern("glob", pattern); err != nil {
 		return nil, errRetryFSFn(err)
 	}
-	return retry.Try2(r.ctx, func(_ context.Context) (f []string, err error, ok bool) {
+	return retry.Try2(r.ctx, func(ctx context.Context) (f []string, err error, ok bool) {
 		f, err = fs.Glob(r.fs, pattern)
 		if err == nil {
 			return f, nil, retry.Stop
 		}
-		if !isRetryable(err) {
+		if !r.retryable(ctx, "glob", pattern, err) {
 			return nil, errRetryFSFn(err), retry.Stop
 		}
 		return f, errRetryFSFn(err), retry.TryAgain
@@ -107,12 +125,12 @@ func (r *retryFS) Stat(name string) (fs.FileInfo, error) {
 	if err := validPath("stat", name); err != nil {
 		return nil, errRetryFSFn(err)
 	}
-	return retry.Try2(r.ctx, func(_ context.Context) (f fs.FileInfo, err error, ok bool) {
+	return retry.Try2(r.ctx, func(ctx context.Context) (f fs.FileInfo, err error, ok bool) {
 		f, err = fs.Stat(r.fs, name)
 		if err == nil {
 			return f, nil, retry.Stop
 		}
-		if !isRetryable(err) {
+		if !r.retryable(ctx, "stat", name, err) {
 			return nil, errRetryFSFn(err), retry.Stop
 		}
 		return f, errRetryFSFn(err), retry.TryAgain
@@ -124,12 +142,12 @@ func (r *retryFS) ReadFile(name string) ([]byte, error) {
 	if err := validPath("readFile", name); err != nil {
 		return nil, errRetryFSFn(err)
 	}
-	return retry.Try2(r.ctx, func(_ context.Context) (b []byte, err error, ok bool) {
+	return retry.Try2(r.ctx, func(ctx context.Context) (b []byte, err error, ok bool) {
 		b, err = fs.ReadFile(r.fs, name)
 		if err == nil {
 			return b, nil, retry.Stop
 		}
-		if !isRetryable(err) {
+		if !r.retryable(ctx, "readFile", name, err) {
 			return nil, errRetryFSFn(err), retry.Stop
 		}
 		return b, errRetryFSFn(err), retry.TryAgain
@@ -141,12 +159,12 @@ func (r *retryFS) ReadDir(name string) ([]fs.DirEntry, error) {
 	if err := validPath("readDir", name); err != nil {
 		return nil, errRetryFSFn(err)
 	}
-	return retry.Try2(r.ctx, func(_ context.Context) (e []fs.DirEntry, err error, ok bool) {
+	return retry.Try2(r.ctx, func(ctx context.Context) (e []fs.DirEntry, err error, ok bool) {
 		e, err = fs.ReadDir(r.fs, name)
 		if err == nil {
 			return e, nil, retry.Stop
 		}
-		if !isRetryable(err) {
+		if !r.retryable(ctx, "readDir", name, err) {
 			return nil, errRetryFSFn(err), retry.Stop
 		}
 		return e, errRetryFSFn(err), retry.TryAgain
@@ -161,11 +179,22 @@ func (r *retryFS) Sub(name string) (fs.FS, error) {
 	return fs.Sub(r.fs, name)
 }
 
+// retryable reports whether the operation should be retried after the
+// error. Retryable errors are logged.
+func (r *retryFS) retryable(ctx context.Context, op, name string, err error) bool {
+	if !isRetryable(err) {
+		return false
+	}
+	a, _ := retry.AttemptFromContext(ctx)
+	r.logger.Debug("File system operation failed", "op", op, "name", redactName(name), "attempt", a.Number, "error", err)
+	return true
+}
+
 func isRetryable(err error) bool {
-	return !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrPermission) && !errors.Is(err, path.ErrBadPattern) && !isPathError(err)
+	return !errutil.AnyIs(err, os.ErrNotExist, os.ErrPermission, path.ErrBadPattern, ErrFileTooLarge) && !isPathError(err)
 }
 
-var errRetryProtoNilURI = errors.New("fsutil.retryProto: nil URI")
+var errRetryProtoNilURI = errutil.WithCode(errors.New("fsutil.retryProto: nil URI"), errutil.CodeConfig)
 
 func errRetryProtoFn(err error) error {
 	return fmt.Errorf("fsutil.retryProto: %w", err)
@@ -21,6 +21,9 @@ import (
 	"net/url"
 	"path"
 	"strings"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/urlutil"
 )
 
 // ParseURI is a helper function that parses a URI for a given protocol and returns
@@ -94,5 +97,5 @@ func uriPath(uri *url.URL, inclQueryAndFragment bool) string {
 }
 
 func errParseURIFn(err error) error {
-	return fmt.Errorf("fsutil.ParseURI: %w", err)
+	return errutil.WithCode(fmt.Errorf("fsutil.ParseURI: %w", urlutil.RedactError(err)), errutil.CodeConfig)
 }
@@ -0,0 +1,360 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	netURL "net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/urlutil"
+)
+
+// WebDAV URI schemes. They are mapped to the "http" and "https" schemes
+// respectively.
+const (
+	WebDAVScheme       = "dav"
+	WebDAVSecureScheme = "davs"
+)
+
+// NewWebDAVProto creates a new WebDAV protocol for the "dav" and "davs"
+// schemes, e.g. "davs://cloud.example.com/remote.php/dav/files/user/app.hcl".
+//
+// Credentials can be provided in the URI, in which case they are sent using
+// basic authentication. For Nextcloud, an app password should be used.
+func NewWebDAVProto(ctx context.Context, opts ...HTTPFSOption) Protocol {
+	return &webdavProto{ctx: ctx, opts: opts}
+}
+
+type webdavProto struct {
+	ctx  context.Context
+	opts []HTTPFSOption
+}
+
+// FileSystem implements the Protocol interface.
+func (w *webdavProto) FileSystem(uri *netURL.URL) (fs fs.FS, path string, err error) {
+	if uri == nil {
+		return nil, "", errWebDAVProtoNilURI
+	}
+	uri = uriCopy(uri)
+	switch uri.Scheme {
+	case WebDAVScheme:
+		uri.Scheme = "http"
+	case WebDAVSecureScheme:
+		uri.Scheme = "https"
+	default:
+		return nil, "", errWebDAVProtoUnexpectedSchemeFn(uri.Scheme)
+	}
+	if err := validHTTPURI(uri); err != nil {
+		return nil, "", errWebDAVProtoFn(err)
+	}
+	var base *netURL.URL
+	base, path = uriSplit(uri)
+	fs, err = NewWebDAVFS(w.ctx, base, w.opts...)
+	if err != nil {
+		return nil, "", errWebDAVProtoFn(err)
+	}
+	return fs, path, nil
+}
+
+// NewWebDAVFS creates a new WebDAV file system. The base URI must use the
+// "http" or "https" scheme.
+//
+// Files are read using GET requests, the same as in the HTTP file system.
+// The Stat and ReadDir methods use PROPFIND requests, so directories can be
+// listed and walked using fs.ReadDir, fs.Glob and fs.WalkDir. The size
+// limit set using WithHTTPMaxBytes also applies to PROPFIND responses.
+//
+// The Sys method of the returned fs.FileInfo values returns *HTTPMetadata
+// with the URL, ETag, LastModified and ContentType fields set from the
+// properties of the resource.
+func NewWebDAVFS(ctx context.Context, baseURI *netURL.URL, opts ...HTTPFSOption) (fs.FS, error) {
+	h, err := newHTTPFS(ctx, baseURI, opts...)
+	if err != nil {
+		return nil, errWebDAVFSFn(err)
+	}
+	return &webdavFS{http: h}, nil
+}
+
+type webdavFS struct {
+	http *httpFS
+}
+
+// Open implements the fs.FS interface.
+func (w *webdavFS) Open(name string) (fs.File, error) {
+	f, err := w.http.Open(name)
+	if err != nil {
+		return nil, errWebDAVFSFn(err)
+	}
+	return f, nil
+}
+
+// Stat implements the fs.StatFS interface.
+func (w *webdavFS) Stat(name string) (fs.FileInfo, error) {
+	if err := validPath("stat", name); err != nil {
+		return nil, errWebDAVFSFn(err)
+	}
+	url, err := w.http.parse(name)
+	if err != nil {
+		return nil, errWebDAVFSFn(err)
+	}
+	infos, err := w.propfind(url, "0")
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		// With depth 0, the response should contain only the requested
+		// resource, but some servers use a different href for it, e.g.
+		// after a redirect.
+		if len(infos) == 1 || samePath(info.path, url.Path) {
+			info.name = path.Base(name)
+			return info.fileInfo, nil
+		}
+	}
+	return nil, errWebDAVFSRequestErrorFn(url, fs.ErrNotExist)
+}
+
+// ReadDir implements the fs.ReadDirFS interface. Entries are sorted by
+// name.
+func (w *webdavFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := validPath("readDir", name); err != nil {
+		return nil, errWebDAVFSFn(err)
+	}
+	url, err := w.http.parse(name)
+	if err != nil {
+		return nil, errWebDAVFSFn(err)
+	}
+	// Servers may redirect requests for collections without a trailing
+	// slash, so it is added upfront.
+	if !strings.HasSuffix(url.Path, "/") {
+		url.Path += "/"
+		url.RawPath = ""
+	}
+	infos, err := w.propfind(url, "1")
+	if err != nil {
+		return nil, err
+	}
+	var (
+		entries []fs.DirEntry
+		isDir   bool
+	)
+	for _, info := range infos {
+		if samePath(info.path, url.Path) {
+			isDir = info.isDir
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info.fileInfo))
+	}
+	if !isDir {
+		return nil, errWebDAVFSRequestErrorFn(url, errWebDAVFSNotDir)
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// davFileInfo is a fileInfo of a resource listed in a PROPFIND response,
+// together with its decoded path.
+type davFileInfo struct {
+	*fileInfo
+	path string
+}
+
+// propfindBody is the body of PROPFIND requests. Only the properties used
+// to build fs.FileInfo values are requested.
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop>
+    <d:resourcetype/>
+    <d:getcontentlength/>
+    <d:getlastmodified/>
+    <d:getetag/>
+    <d:getcontenttype/>
+  </d:prop>
+</d:propfind>`
+
+// propfind performs a PROPFIND request with the given depth and returns the
+// resources listed in the response.
+func (w *webdavFS) propfind(url *netURL.URL, depth string) ([]davFileInfo, error) {
+	req, err := http.NewRequestWithContext(w.http.ctx, "PROPFIND", url.String(), strings.NewReader(propfindBody))
+	if err != nil {
+		return nil, errWebDAVFSRequestErrorFn(url, err)
+	}
+	req.Header.Set("Depth", depth)
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	logutil.OrNop(w.http.logger).Debug("Fetching properties", "url", urlutil.Redact(url), "depth", depth)
+	res, err := w.http.client.Do(req)
+	if err != nil {
+		return nil, errutil.WithCode(errWebDAVFSRequestErrorFn(url, err), errutil.CodeTransient)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusMultiStatus {
+		switch res.StatusCode {
+		case http.StatusNotFound:
+			return nil, errWebDAVFSRequestErrorFn(url, fs.ErrNotExist)
+		case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden:
+			return nil, errWebDAVFSRequestErrorFn(url, fs.ErrPermission)
+		}
+		return nil, errWebDAVFSRequestErrorCodeFn(url, res.StatusCode)
+	}
+	var body io.Reader = res.Body
+	if w.http.maxBytes > 0 {
+		if res.ContentLength > int64(w.http.maxBytes) {
+			return nil, errWebDAVFSRequestErrorFn(url, ErrFileTooLarge)
+		}
+		body = newMaxBytesReader(res.Body, int64(w.http.maxBytes))
+	}
+	var ms davMultiStatus
+	if err := xml.NewDecoder(body).Decode(&ms); err != nil {
+		if errors.Is(err, ErrFileTooLarge) {
+			return nil, errWebDAVFSRequestErrorFn(url, err)
+		}
+		return nil, errutil.WithCode(errWebDAVFSRequestErrorFn(url, errWebDAVFSInvalidResponseFn(err)), errutil.CodeIntegrity)
+	}
+	infos := make([]davFileInfo, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		info, ok := r.fileInfo(url)
+		if !ok {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// davMultiStatus is the body of a 207 Multi-Status response, as defined in
+// RFC 4918.
+type davMultiStatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href     string        `xml:"DAV: href"`
+	PropStat []davPropStat `xml:"DAV: propstat"`
+}
+
+type davPropStat struct {
+	Prop   davProp `xml:"DAV: prop"`
+	Status string  `xml:"DAV: status"`
+}
+
+type davProp struct {
+	ResourceType  davResourceType `xml:"DAV: resourcetype"`
+	ContentLength string          `xml:"DAV: getcontentlength"`
+	LastModified  string          `xml:"DAV: getlastmodified"`
+	ETag          string          `xml:"DAV: getetag"`
+	ContentType   string          `xml:"DAV: getcontenttype"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"DAV: collection"`
+}
+
+// fileInfo converts the response to a davFileInfo. Only the properties
+// with the 200 status are used. The href is resolved against the request
+// URL. It returns false if the href is invalid.
+func (r davResponse) fileInfo(reqURL *netURL.URL) (davFileInfo, bool) {
+	href, err := netURL.Parse(strings.TrimSpace(r.Href))
+	if err != nil {
+		return davFileInfo{}, false
+	}
+	url := reqURL.ResolveReference(href)
+	info := &fileInfo{name: path.Base(strings.TrimSuffix(url.Path, "/"))}
+	meta := &HTTPMetadata{URL: urlutil.Redact(url)}
+	for _, ps := range r.PropStat {
+		if !davStatusOK(ps.Status) {
+			continue
+		}
+		p := ps.Prop
+		if p.ResourceType.Collection != nil {
+			info.isDir = true
+			info.mode = fs.ModeDir
+		}
+		if n, err := strconv.ParseInt(strings.TrimSpace(p.ContentLength), 10, 64); err == nil {
+			info.size = n
+		}
+		if t, err := http.ParseTime(strings.TrimSpace(p.LastModified)); err == nil {
+			info.modTime = t
+			meta.LastModified = t
+		}
+		if p.ETag != "" {
+			meta.ETag = strings.TrimSpace(p.ETag)
+		}
+		if p.ContentType != "" {
+			meta.ContentType = strings.TrimSpace(p.ContentType)
+		}
+	}
+	info.sys = meta
+	return davFileInfo{fileInfo: info, path: url.Path}, true
+}
+
+// davStatusOK reports whether the status line, e.g. "HTTP/1.1 200 OK",
+// has the 200 status code.
+func davStatusOK(status string) bool {
+	_, code, _ := strings.Cut(strings.TrimSpace(status), " ")
+	return strings.HasPrefix(code, "200")
+}
+
+// samePath reports whether two decoded URL paths refer to the same
+// resource, ignoring trailing slashes.
+func samePath(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+var (
+	errWebDAVProtoNilURI = errutil.WithCode(errors.New("fsutil.webdavProto: nil URI"), errutil.CodeConfig)
+	errWebDAVFSNotDir    = errors.New("not a directory")
+)
+
+func errWebDAVProtoFn(err error) error {
+	return fmt.Errorf("fsutil.webdavProto: %w", err)
+}
+
+func errWebDAVProtoUnexpectedSchemeFn(scheme string) error {
+	return errutil.WithCode(fmt.Errorf("fsutil.webdavProto: unexpected scheme: %s", scheme), errutil.CodeConfig)
+}
+
+func errWebDAVFSFn(err error) error {
+	return fmt.Errorf("fsutil.webdavFS: %w", err)
+}
+
+func errWebDAVFSInvalidResponseFn(err error) error {
+	return fmt.Errorf("invalid multistatus response: %w", err)
+}
+
+func errWebDAVFSRequestErrorFn(url *netURL.URL, err error) error {
+	return fmt.Errorf("fsutil.webdavFS: %s: %w", urlutil.Redact(url), urlutil.RedactError(err))
+}
+
+func errWebDAVFSRequestErrorCodeFn(url *netURL.URL, code int) error {
+	err := fmt.Errorf("fsutil.webdavFS: %s: unexpected status code: %d %s", urlutil.Redact(url), code, http.StatusText(code))
+	if code >= http.StatusInternalServerError || code == http.StatusTooManyRequests || code == http.StatusRequestTimeout {
+		return errutil.WithCode(err, errutil.CodeTransient)
+	}
+	return err
+}
@@ -0,0 +1,298 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fsutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// webdavTestFiles is the content of the test WebDAV server. Directories
+// have names ending with a slash.
+var webdavTestFiles = map[string]string{
+	"/dav/":                "",
+	"/dav/b.hcl":           "b",
+	"/dav/a.hcl":           "a",
+	"/dav/with space.json": "{}",
+	"/dav/sub/":            "",
+	"/dav/sub/c.hcl":       "c",
+}
+
+var webdavTestModTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// webdavTestHandler serves webdavTestFiles using GET and PROPFIND requests.
+func webdavTestHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path
+		if _, ok := webdavTestFiles[name]; !ok {
+			name = strings.TrimSuffix(name, "/") + "/"
+		}
+		if _, ok := webdavTestFiles[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(webdavTestFiles[name]))
+		case "PROPFIND":
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "propfind")
+			names := []string{name}
+			if r.Header.Get("Depth") == "1" && strings.HasSuffix(name, "/") {
+				for n := range webdavTestFiles {
+					if n != name && path.Dir(strings.TrimSuffix(n, "/"))+"/" == name {
+						names = append(names, n)
+					}
+				}
+			}
+			sort.Strings(names)
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.WriteHeader(http.StatusMultiStatus)
+			_, _ = fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:">`)
+			for _, n := range names {
+				href := (&url.URL{Path: n}).EscapedPath()
+				if strings.HasSuffix(n, "/") {
+					_, _ = fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype><d:getlastmodified>%s</d:getlastmodified></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat><d:propstat><d:prop><d:getcontentlength/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat></d:response>`, href, webdavTestModTime.Format(http.TimeFormat))
+					continue
+				}
+				_, _ = fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype/><d:getcontentlength>%d</d:getcontentlength><d:getlastmodified>%s</d:getlastmodified><d:getetag>"%s"</d:getetag><d:getcontenttype>text/plain</d:getcontenttype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, href, len(webdavTestFiles[n]), webdavTestModTime.Format(http.TimeFormat), path.Base(n))
+			}
+			_, _ = fmt.Fprint(w, `</d:multistatus>`)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func TestWebDAVProto(t *testing.T) {
+	server := httptest.NewServer(webdavTestHandler(t))
+	defer server.Close()
+	host := server.Listener.Addr().String()
+
+	tc := []struct {
+		name     string
+		uri      string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "nil URL", wantErr: true},
+		{name: "unexpected scheme", uri: "http://" + host, wantErr: true},
+		{name: "empty host", uri: "dav://", wantErr: true},
+		{name: "fragment", uri: "dav://" + host + "/dav#fragment", wantErr: true},
+		{name: "file", uri: "dav://" + host + "/dav/a.hcl", wantPath: "dav/a.hcl"},
+		{name: "secure", uri: "davs://" + host + "/dav/a.hcl", wantPath: "dav/a.hcl"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var u *url.URL
+			if tt.uri != "" {
+				var err error
+				u, err = url.Parse(tt.uri)
+				require.NoError(t, err)
+			}
+			fsys, path, err := NewWebDAVProto(context.Background()).FileSystem(u)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, errutil.CodeConfig, errutil.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, strings.HasPrefix(tt.uri, "davs"), fsys.(*webdavFS).http.baseURI.Scheme == "https")
+		})
+	}
+
+	// The scheme of the URI passed to the protocol must not be modified.
+	u, err := url.Parse("dav://" + host + "/dav/a.hcl")
+	require.NoError(t, err)
+	fsys, name, err := NewWebDAVProto(context.Background()).FileSystem(u)
+	require.NoError(t, err)
+	assert.Equal(t, "dav", u.Scheme)
+	b, err := fs.ReadFile(fsys, name)
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(b))
+}
+
+func TestWebDAVFS(t *testing.T) {
+	server := httptest.NewServer(webdavTestHandler(t))
+	defer server.Close()
+	baseURL, err := url.Parse(server.URL + "/dav")
+	require.NoError(t, err)
+	fsys, err := NewWebDAVFS(context.Background(), baseURL)
+	require.NoError(t, err)
+
+	t.Run("open", func(t *testing.T) {
+		b, err := fs.ReadFile(fsys, "sub/c.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, "c", string(b))
+	})
+	t.Run("stat file", func(t *testing.T) {
+		info, err := fs.Stat(fsys, "with space.json")
+		require.NoError(t, err)
+		assert.Equal(t, "with space.json", info.Name())
+		assert.Equal(t, int64(2), info.Size())
+		assert.False(t, info.IsDir())
+		assert.Equal(t, webdavTestModTime, info.ModTime().UTC())
+		meta, ok := HTTPMetadataOf(info)
+		require.True(t, ok)
+		assert.Equal(t, `"with space.json"`, meta.ETag)
+		assert.Equal(t, "text/plain", meta.ContentType)
+		assert.Equal(t, server.URL+"/dav/with%20space.json", meta.URL)
+	})
+	t.Run("stat directory", func(t *testing.T) {
+		info, err := fs.Stat(fsys, "sub")
+		require.NoError(t, err)
+		assert.Equal(t, "sub", info.Name())
+		assert.True(t, info.IsDir())
+		assert.Equal(t, fs.ModeDir, info.Mode().Type())
+	})
+	t.Run("stat not found", func(t *testing.T) {
+		_, err := fs.Stat(fsys, "missing.hcl")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		assert.Equal(t, errutil.CodeNotFound, errutil.CodeOf(err))
+	})
+	t.Run("read dir", func(t *testing.T) {
+		entries, err := fs.ReadDir(fsys, ".")
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, fmt.Sprintf("%s:%t", e.Name(), e.IsDir()))
+		}
+		assert.Equal(t, []string{"a.hcl:false", "b.hcl:false", "sub:true", "with space.json:false"}, names)
+	})
+	t.Run("read dir of a file", func(t *testing.T) {
+		_, err := fs.ReadDir(fsys, "a.hcl")
+		assert.Error(t, err)
+	})
+	t.Run("walk", func(t *testing.T) {
+		var names []string
+		require.NoError(t, fs.WalkDir(fsys, ".", func(path string, _ fs.DirEntry, err error) error {
+			names = append(names, path)
+			return err
+		}))
+		assert.Equal(t, []string{".", "a.hcl", "b.hcl", "sub", "sub/c.hcl", "with space.json"}, names)
+	})
+	t.Run("glob", func(t *testing.T) {
+		names, err := fs.Glob(fsys, "*.hcl")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.hcl", "b.hcl"}, names)
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := fs.Stat(fsys, "../a.hcl")
+		assert.Error(t, err)
+		_, err = fs.ReadDir(fsys, "../")
+		assert.Error(t, err)
+	})
+}
+
+func TestWebDAVFS_Errors(t *testing.T) {
+	tc := []struct {
+		name     string
+		handler  http.HandlerFunc
+		opts     []HTTPFSOption
+		wantErr  error
+		wantCode errutil.Code
+	}{
+		{
+			name:     "unauthorized",
+			handler:  func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusUnauthorized) },
+			wantErr:  fs.ErrPermission,
+			wantCode: errutil.CodePermission,
+		},
+		{
+			name:     "server error",
+			handler:  func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) },
+			wantCode: errutil.CodeTransient,
+		},
+		{
+			name: "invalid response",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusMultiStatus)
+				_, _ = w.Write([]byte("<d:multistatus"))
+			},
+			wantCode: errutil.CodeIntegrity,
+		},
+		{
+			name: "response too large",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusMultiStatus)
+				_, _ = w.Write([]byte(`<d:multistatus xmlns:d="DAV:">` + strings.Repeat(" ", 100) + `</d:multistatus>`))
+			},
+			opts:     []HTTPFSOption{WithHTTPMaxBytes(10 * bytesize.B)},
+			wantErr:  ErrFileTooLarge,
+			wantCode: errutil.CodeConfig,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			baseURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			fsys, err := NewWebDAVFS(context.Background(), baseURL, tt.opts...)
+			require.NoError(t, err)
+
+			_, err = fs.ReadDir(fsys, ".")
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantCode, errutil.CodeOf(err))
+		})
+	}
+}
+
+func TestWebDAVFS_Credentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		webdavTestHandler(t).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	u, err := url.Parse(strings.Replace(server.URL, "http://", "dav://user:secret@", 1) + "/dav/sub/c.hcl")
+	require.NoError(t, err)
+
+	fsys, name, err := NewWebDAVProto(context.Background()).FileSystem(u)
+	require.NoError(t, err)
+	info, err := fs.Stat(fsys, name)
+	require.NoError(t, err)
+	meta, ok := HTTPMetadataOf(info)
+	require.True(t, ok)
+	assert.NotContains(t, meta.URL, "secret")
+	b, err := fs.ReadFile(fsys, name)
+	require.NoError(t, err)
+	assert.Equal(t, "c", string(b))
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package hashutil provides helpers for computing and encoding digests.
+//
+// The TeeHasher type computes one or more digests of the data read from
+// an io.Reader, so files can be verified while they are streamed instead of
+// being hashed in a separate pass. Digests can be encoded and parsed in the
+// Subresource Integrity (SRI) format, e.g. "sha256-<base64 digest>".
+package hashutil
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Algorithm is the name of a hash function, as used in the SRI format.
+type Algorithm string
+
+// Supported algorithms. SHA256, SHA384 and SHA512 are defined by the SRI
+// specification, Keccak256 is an extension used for Ethereum compatible
+// checksums.
+const (
+	SHA256    Algorithm = "sha256"
+	SHA384    Algorithm = "sha384"
+	SHA512    Algorithm = "sha512"
+	Keccak256 Algorithm = "keccak256"
+)
+
+// New returns a new hash.Hash computing the algorithm's digest. It returns
+// nil if the algorithm is not supported.
+func (a Algorithm) New() hash.Hash {
+	switch a {
+	case SHA256:
+		return sha256.New()
+	case SHA384:
+		return sha512.New384()
+	case SHA512:
+		return sha512.New()
+	case Keccak256:
+		return sha3.NewLegacyKeccak256()
+	default:
+		return nil
+	}
+}
+
+// Supported reports whether the algorithm is supported.
+func (a Algorithm) Supported() bool {
+	switch a {
+	case SHA256, SHA384, SHA512, Keccak256:
+		return true
+	default:
+		return false
+	}
+}
+
+// Size returns the length of the algorithm's digest in bytes, or 0 if the
+// algorithm is not supported.
+func (a Algorithm) Size() int {
+	switch a {
+	case SHA256, Keccak256:
+		return 32
+	case SHA384:
+		return 48
+	case SHA512:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// priority returns the strength of the algorithm, used to select the
+// strongest digest from SRI metadata. Unsupported algorithms have priority 0.
+func (a Algorithm) priority() int {
+	switch a {
+	case SHA256, Keccak256:
+		return 1
+	case SHA384:
+		return 2
+	case SHA512:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// TeeHasher is an io.Reader that computes digests of the data read from
+// the underlying reader.
+//
+// Digests are complete only after the underlying reader is fully consumed.
+type TeeHasher struct {
+	r      io.Reader
+	hashes []hash.Hash
+	n      int64
+}
+
+// NewTeeHasher returns a TeeHasher that reads from r and writes the data to
+// the given hashes.
+func NewTeeHasher(r io.Reader, hashes ...hash.Hash) *TeeHasher {
+	return &TeeHasher{r: r, hashes: hashes}
+}
+
+// Read implements the io.Reader interface.
+func (t *TeeHasher) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.n += int64(n)
+		for _, h := range t.hashes {
+			// Writing to a hash.Hash never returns an error.
+			h.Write(p[:n])
+		}
+	}
+	return n, err
+}
+
+// Sum returns the digest computed by the i-th hash.
+func (t *TeeHasher) Sum(i int) []byte {
+	return t.hashes[i].Sum(nil)
+}
+
+// Sums returns the digests computed by all hashes, in the order in which
+// the hashes were given to NewTeeHasher.
+func (t *TeeHasher) Sums() [][]byte {
+	sums := make([][]byte, len(t.hashes))
+	for i, h := range t.hashes {
+		sums[i] = h.Sum(nil)
+	}
+	return sums
+}
+
+// BytesRead returns the number of bytes read so far.
+func (t *TeeHasher) BytesRead() int64 {
+	return t.n
+}
+
+// Sum returns the digest of the data using the given algorithm.
+func Sum(alg Algorithm, data []byte) (Digest, error) {
+	h := alg.New()
+	if h == nil {
+		return Digest{}, errUnsupportedAlgorithm(alg)
+	}
+	h.Write(data)
+	return Digest{Algorithm: alg, Sum: h.Sum(nil)}, nil
+}
+
+// ErrUnsupportedAlgorithm is returned when a digest uses an unsupported
+// hash algorithm.
+var ErrUnsupportedAlgorithm = errors.New("unsupported hash algorithm")
+
+func errUnsupportedAlgorithm(alg Algorithm) error {
+	return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hashutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlgorithm(t *testing.T) {
+	tests := []struct {
+		alg  Algorithm
+		data string
+		want string
+	}{
+		{alg: SHA256, data: "", want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{alg: SHA384, data: "", want: "38b060a751ac96384cd9327eb1b1e36a21fdb71114be07434c0cc7bf63f6e1da274edebfe76f65fbd51ad2f14898b95b"},
+		{alg: SHA512, data: "", want: "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"},
+		{alg: Keccak256, data: "", want: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.alg), func(t *testing.T) {
+			require.True(t, tt.alg.Supported())
+			d, err := Sum(tt.alg, []byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(d.Sum))
+			assert.Equal(t, tt.alg.Size(), len(d.Sum))
+		})
+	}
+	assert.False(t, Algorithm("md5").Supported())
+	assert.Nil(t, Algorithm("md5").New())
+	_, err := Sum("md5", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
+
+func TestTeeHasher(t *testing.T) {
+	data := strings.Repeat("hello world ", 1000)
+	th := NewTeeHasher(iotest.OneByteReader(strings.NewReader(data)), SHA256.New(), Keccak256.New())
+	b, err := io.ReadAll(th)
+	require.NoError(t, err)
+	assert.Equal(t, data, string(b))
+	assert.Equal(t, int64(len(data)), th.BytesRead())
+
+	want256 := sha256.Sum256([]byte(data))
+	wantKeccak, _ := Sum(Keccak256, []byte(data))
+	assert.Equal(t, want256[:], th.Sum(0))
+	assert.Equal(t, wantKeccak.Sum, th.Sum(1))
+	assert.Equal(t, [][]byte{want256[:], wantKeccak.Sum}, th.Sums())
+}
+
+func TestTeeHasher_DataWithEOF(t *testing.T) {
+	th := NewTeeHasher(iotest.DataErrReader(strings.NewReader("abc")), SHA256.New())
+	b, err := io.ReadAll(th)
+	require.NoError(t, err)
+	want := sha256.Sum256(b)
+	assert.Equal(t, want[:], th.Sum(0))
+}
@@ -0,0 +1,150 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hashutil
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Digest is a digest computed using a known algorithm.
+type Digest struct {
+	Algorithm Algorithm
+	Sum       []byte
+}
+
+// String returns the digest in the SRI format.
+func (d Digest) String() string {
+	return EncodeSRI(d.Algorithm, d.Sum)
+}
+
+// Equal reports whether the digest matches the given sum. The comparison
+// is done in constant time.
+func (d Digest) Equal(sum []byte) bool {
+	return subtle.ConstantTimeCompare(d.Sum, sum) == 1
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Digest) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Digest) UnmarshalText(text []byte) error {
+	v, err := ParseDigest(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// EncodeSRI encodes the digest in the SRI format, that is, the algorithm
+// name followed by a dash and the base64 encoded digest, e.g.
+// "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=".
+func EncodeSRI(alg Algorithm, sum []byte) string {
+	return string(alg) + "-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+// ParseDigest parses a single digest in the SRI format. Options that follow
+// the digest after a "?" character are ignored.
+//
+// Unlike ParseSRI, ParseDigest returns an error if the algorithm is not
+// supported.
+func ParseDigest(s string) (Digest, error) {
+	d, err := parseDigest(s)
+	if err != nil {
+		return Digest{}, err
+	}
+	if !d.Algorithm.Supported() {
+		return Digest{}, errUnsupportedAlgorithm(d.Algorithm)
+	}
+	if len(d.Sum) != d.Algorithm.Size() {
+		return Digest{}, fmt.Errorf("invalid SRI digest %q: expected %d bytes, got %d", s, d.Algorithm.Size(), len(d.Sum))
+	}
+	return d, nil
+}
+
+// ParseSRI parses SRI metadata, which is a whitespace separated list of
+// digests, and returns the digests that use supported algorithms, as the
+// SRI specification requires unknown algorithms to be ignored.
+//
+// An error is returned if any of the digests is malformed, or if none of the
+// digests uses a supported algorithm.
+func ParseSRI(s string) ([]Digest, error) {
+	var ds []Digest
+	for _, f := range strings.Fields(s) {
+		d, err := parseDigest(f)
+		if err != nil {
+			return nil, err
+		}
+		if !d.Algorithm.Supported() {
+			continue
+		}
+		if len(d.Sum) != d.Algorithm.Size() {
+			return nil, fmt.Errorf("invalid SRI digest %q: expected %d bytes, got %d", f, d.Algorithm.Size(), len(d.Sum))
+		}
+		ds = append(ds, d)
+	}
+	if len(ds) == 0 {
+		return nil, fmt.Errorf("%w: no supported digest in %q", ErrUnsupportedAlgorithm, s)
+	}
+	return ds, nil
+}
+
+// Strongest returns the digests that use the strongest algorithm from the
+// list. According to the SRI specification, data is valid if it matches
+// any of them.
+func Strongest(ds []Digest) []Digest {
+	var (
+		res []Digest
+		max int
+	)
+	for _, d := range ds {
+		switch p := d.Algorithm.priority(); {
+		case p > max:
+			max = p
+			res = append(res[:0], d)
+		case p == max && p > 0:
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// parseDigest parses a digest without checking whether the algorithm is
+// supported.
+func parseDigest(s string) (Digest, error) {
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		s = s[:i]
+	}
+	alg, b64, ok := strings.Cut(s, "-")
+	if !ok || alg == "" || b64 == "" {
+		return Digest{}, fmt.Errorf("invalid SRI digest %q", s)
+	}
+	sum, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		// Some tools produce digests using the URL-safe alphabet, which
+		// is unambiguous, so it is accepted as well.
+		sum, err = base64.URLEncoding.DecodeString(b64)
+	}
+	if err != nil {
+		return Digest{}, fmt.Errorf("invalid SRI digest %q: %w", s, err)
+	}
+	return Digest{Algorithm: Algorithm(strings.ToLower(alg)), Sum: sum}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hashutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Digests of an empty string.
+const (
+	emptySHA256 = "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	emptySHA384 = "sha384-OLBgp1GsljhM2TJ+sbHjaiH9txEUvgdDTAzHv2P24donTt6/529l+9Ua0vFImLlb"
+)
+
+func TestEncodeSRI(t *testing.T) {
+	d, err := Sum(SHA256, nil)
+	require.NoError(t, err)
+	assert.Equal(t, emptySHA256, d.String())
+	assert.Equal(t, emptySHA256, EncodeSRI(SHA256, d.Sum))
+
+	text, err := d.MarshalText()
+	require.NoError(t, err)
+	var d2 Digest
+	require.NoError(t, d2.UnmarshalText(text))
+	assert.Equal(t, d, d2)
+}
+
+func TestParseDigest(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Algorithm
+		wantErr string
+	}{
+		{input: emptySHA256, want: SHA256},
+		{input: emptySHA256 + "?foo", want: SHA256},
+		{input: "SHA256-47DEQpj8HBSa-_TImW-5JCeuQeRkm5NMpJWZG3hSuFU=", want: SHA256},
+		{input: emptySHA384, want: SHA384},
+		{input: "md5-1B2M2Y8AsgTpgAmY7PhCfg==", wantErr: "unsupported hash algorithm"},
+		{input: "sha256-AAAA", wantErr: "expected 32 bytes, got 3"},
+		{input: "sha256-!!!!", wantErr: "invalid SRI digest"},
+		{input: "sha256", wantErr: "invalid SRI digest"},
+		{input: "-AAAA", wantErr: "invalid SRI digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d, err := ParseDigest(tt.input)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, d.Algorithm)
+			sum, err := Sum(tt.want, nil)
+			require.NoError(t, err)
+			assert.True(t, d.Equal(sum.Sum))
+		})
+	}
+}
+
+func TestParseSRI(t *testing.T) {
+	ds, err := ParseSRI("md5-1B2M2Y8AsgTpgAmY7PhCfg== " + emptySHA256 + "\n" + emptySHA384)
+	require.NoError(t, err)
+	require.Len(t, ds, 2)
+	assert.Equal(t, SHA256, ds[0].Algorithm)
+	assert.Equal(t, SHA384, ds[1].Algorithm)
+
+	strongest := Strongest(ds)
+	require.Len(t, strongest, 1)
+	assert.Equal(t, SHA384, strongest[0].Algorithm)
+
+	_, err = ParseSRI("md5-1B2M2Y8AsgTpgAmY7PhCfg==")
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = ParseSRI("")
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = ParseSRI(emptySHA256 + " sha384-AAAA")
+	assert.ErrorContains(t, err, "expected 48 bytes")
+}
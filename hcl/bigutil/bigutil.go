@@ -0,0 +1,182 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package bigutil provides helpers for handling arbitrary precision numbers
+// in HCL configurations.
+//
+// Numbers such as price bounds and token amounts should not be converted to
+// float64, because most decimal fractions cannot be represented exactly.
+// The functions in this package parse decimal strings directly into big.Int
+// and big.Rat values, scale them by a number of decimals, and convert them
+// to and from cty values without going through float64.
+package bigutil
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ratPrec is the precision used when converting big.Rat values to cty
+// numbers. It is the same as the precision used by cty to parse numbers.
+const ratPrec = 512
+
+// ParseInt parses a decimal integer. The number may use the scientific
+// notation, e.g. "1e18", as long as the result is an integer.
+func ParseInt(s string) (*big.Int, error) {
+	r, err := ParseRat(s)
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return r.Num(), nil
+}
+
+// MustParseInt works like ParseInt, but panics on error.
+func MustParseInt(s string) *big.Int {
+	i, err := ParseInt(s)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// ParseRat parses a decimal number, e.g. "1.5" or "-2.5e-3", without
+// losing precision.
+func ParseRat(s string) (*big.Rat, error) {
+	if strings.Contains(s, "/") {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return r, nil
+}
+
+// MustParseRat works like ParseRat, but panics on error.
+func MustParseRat(s string) *big.Rat {
+	r, err := ParseRat(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// ParseUnits parses a decimal amount and converts it to the smallest units
+// of a token with the given number of decimals, e.g. ParseUnits("1.5", 6)
+// returns 1500000. The amount must not have more decimal places than
+// decimals.
+func ParseUnits(s string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("decimals must not be negative")
+	}
+	r, err := ParseRat(s)
+	if err != nil {
+		return nil, err
+	}
+	r.Mul(r, new(big.Rat).SetInt(pow10(decimals)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", s, decimals)
+	}
+	return r.Num(), nil
+}
+
+// FormatUnits formats an amount in the smallest units of a token with the
+// given number of decimals as a decimal string, e.g. FormatUnits(1500000, 6)
+// returns "1.5". Trailing zeros are removed.
+func FormatUnits(x *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return x.String()
+	}
+	r := new(big.Rat).SetFrac(x, pow10(decimals))
+	s := strings.TrimRight(r.FloatString(decimals), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// IntVal returns a cty number with the value of x.
+func IntVal(x *big.Int) cty.Value {
+	return cty.NumberVal(new(big.Float).SetInt(x))
+}
+
+// RatVal returns a cty number with the value of r. Because cty numbers are
+// binary floating point numbers, fractions that cannot be represented
+// exactly are rounded to the precision used by cty.
+func RatVal(r *big.Rat) cty.Value {
+	return cty.NumberVal(new(big.Float).SetPrec(ratPrec).SetRat(r))
+}
+
+// IntFromVal converts a cty number or a string parsed using ParseInt to a
+// big.Int. Numbers must be integers. Marks on the value are ignored.
+func IntFromVal(val cty.Value) (*big.Int, error) {
+	s, err := numberString(val)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInt(s)
+}
+
+// RatFromVal converts a cty number or a string parsed using ParseRat to a
+// big.Rat. Marks on the value are ignored.
+//
+// Numbers are converted using their shortest decimal representation, so a
+// number written as 0.1 in a configuration file is converted to exactly
+// 1/10, and not to the nearest binary fraction.
+func RatFromVal(val cty.Value) (*big.Rat, error) {
+	s, err := numberString(val)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRat(s)
+}
+
+// UnitsFromVal converts a cty number or string to the smallest units of a
+// token with the given number of decimals, see ParseUnits. Marks on the
+// value are ignored.
+func UnitsFromVal(val cty.Value, decimals int) (*big.Int, error) {
+	s, err := numberString(val)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnits(s, decimals)
+}
+
+// numberString returns the decimal representation of a cty number, or the
+// value of a cty string.
+func numberString(val cty.Value) (string, error) {
+	val, _ = val.Unmark()
+	switch {
+	case val.IsNull():
+		return "", fmt.Errorf("value must not be null")
+	case !val.IsKnown():
+		return "", fmt.Errorf("value must be known")
+	case val.Type() == cty.String:
+		return val.AsString(), nil
+	case val.Type() == cty.Number:
+		f := val.AsBigFloat()
+		if f.IsInf() {
+			return "", fmt.Errorf("value must be finite")
+		}
+		return f.Text('g', -1), nil
+	}
+	return "", fmt.Errorf("wrong value type: expected number or string, got %s", val.Type().FriendlyName())
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
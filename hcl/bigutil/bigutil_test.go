@@ -0,0 +1,157 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package bigutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "42", want: "42"},
+		{input: "-42", want: "-42"},
+		{input: "1e18", want: "1000000000000000000"},
+		{input: "123456789012345678901234567890", want: "123456789012345678901234567890"},
+		{input: "1.5", wantErr: `invalid integer "1.5"`},
+		{input: "1/2", wantErr: `invalid number "1/2"`},
+		{input: "foo", wantErr: `invalid number "foo"`},
+		{input: "", wantErr: `invalid number ""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseInt(tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestParseRat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "0.1", want: "1/10"},
+		{input: "-2.5e-3", want: "-1/400"},
+		{input: "1234.000000000000000001", want: "1234000000000000000001/1000000000000000000"},
+		{input: "1,5", wantErr: `invalid number "1,5"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRat(tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		input    string
+		decimals int
+		want     string
+		format   string
+		wantErr  string
+	}{
+		{input: "1.5", decimals: 6, want: "1500000", format: "1.5"},
+		{input: "1.5", decimals: 18, want: "1500000000000000000", format: "1.5"},
+		{input: "0.000001", decimals: 6, want: "1", format: "0.000001"},
+		{input: "-2", decimals: 2, want: "-200", format: "-2"},
+		{input: "7", decimals: 0, want: "7", format: "7"},
+		{input: "1.0000005", decimals: 6, wantErr: "amount 1.0000005 has more than 6 decimal places"},
+		{input: "1", decimals: -1, wantErr: "decimals must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseUnits(tt.input, tt.decimals)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.format, FormatUnits(got, tt.decimals))
+		})
+	}
+}
+
+func TestVal(t *testing.T) {
+	t.Run("int", func(t *testing.T) {
+		x := MustParseInt("123456789012345678901234567890")
+		got, err := IntFromVal(IntVal(x))
+		require.NoError(t, err)
+		assert.Equal(t, x, got)
+	})
+	t.Run("int from string", func(t *testing.T) {
+		got, err := IntFromVal(cty.StringVal("1e18"))
+		require.NoError(t, err)
+		assert.Equal(t, "1000000000000000000", got.String())
+	})
+	t.Run("int from float", func(t *testing.T) {
+		_, err := IntFromVal(cty.MustParseNumberVal("1.5"))
+		assert.EqualError(t, err, `invalid integer "1.5"`)
+	})
+	t.Run("rat", func(t *testing.T) {
+		got, err := RatFromVal(cty.MustParseNumberVal("0.1"))
+		require.NoError(t, err)
+		assert.Equal(t, big.NewRat(1, 10), got)
+	})
+	t.Run("rat round trip", func(t *testing.T) {
+		r := MustParseRat("12345.6789")
+		got, err := RatFromVal(RatVal(r))
+		require.NoError(t, err)
+		assert.Equal(t, r, got)
+	})
+	t.Run("units", func(t *testing.T) {
+		got, err := UnitsFromVal(cty.MustParseNumberVal("0.3"), 18)
+		require.NoError(t, err)
+		assert.Equal(t, "300000000000000000", got.String())
+	})
+	t.Run("marked", func(t *testing.T) {
+		got, err := IntFromVal(cty.NumberIntVal(42).Mark("sensitive"))
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(42), got)
+	})
+	t.Run("null", func(t *testing.T) {
+		_, err := IntFromVal(cty.NullVal(cty.Number))
+		assert.EqualError(t, err, "value must not be null")
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := RatFromVal(cty.UnknownVal(cty.Number))
+		assert.EqualError(t, err, "value must be known")
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		_, err := RatFromVal(cty.True)
+		assert.EqualError(t, err, "wrong value type: expected number or string, got bool")
+	})
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/go-lib/maputil"
 )
 
 // PreDecodeAttribute is called before an attribute is decoded.
@@ -63,7 +65,8 @@ type PostDecodeBlock interface {
 //     the field will be left as zero value.
 //   - ignore - the field will be ignored but still be a part of the schema.
 //   - block - the field is a block, it will be decoded from the HCL blocks.
-//     The field must be a struct, slice of structs or a map of structs.
+//     The field must be a struct, slice of structs, a map of structs or
+//     a maputil.OrderedMap of structs.
 //   - remain - the field is populated with the remaining HCL body. The field
 //     must be hcl.Body.
 //   - body - the field is populated with the HCL body. The field must
@@ -75,6 +78,9 @@ type PostDecodeBlock interface {
 //   - range - the block range. The field must be hcl.Range.
 //
 // If name is omitted, the field name will be used.
+//
+// Values decoded into maputil.OrderedMap keep the order in which blocks and
+// object attributes appear in the configuration.
 func Decode(ctx *hcl.EvalContext, body hcl.Body, val any) hcl.Diagnostics {
 	return decodeSingleBlock(ctx, &hcl.Block{Body: body}, reflect.ValueOf(val))
 }
@@ -86,18 +92,7 @@ func DecodeBlock(ctx *hcl.EvalContext, block *hcl.Block, val any) hcl.Diagnostic
 
 // DecodeExpression decodes the given HCL expression into the given value.
 func DecodeExpression(ctx *hcl.EvalContext, expr hcl.Expression, val any) hcl.Diagnostics {
-	ctyVal, diags := expr.Value(ctx)
-	if diags.HasErrors() {
-		return diags
-	}
-	if err := mapper.Map(ctyVal, val); err != nil {
-		return hcl.Diagnostics{{
-			Severity: hcl.DiagError,
-			Summary:  "Decode error",
-			Detail:   err.Error(),
-		}}
-	}
-	return nil
+	return decodeExpression(ctx, expr, reflect.ValueOf(val), nil)
 }
 
 // decodeSingleBlock decodes a single block into the given value. A value must
@@ -269,9 +264,15 @@ func decodeSingleBlock(ctx *hcl.EvalContext, block *hcl.Block, ptrVal reflect.Va
 //   - If a value is a slice, it will append a new element to the slice.
 //   - If a block is a map, it will append a new element to the map and label
 //     will be used as a key. Block must have only one label.
+//   - If a block is a maputil.OrderedMap, it works like a map, but the blocks
+//     are kept in the order in which they appear in the configuration.
 func decodeMultipleBlocks(ctx *hcl.EvalContext, block *hcl.Block, val reflect.Value) hcl.Diagnostics {
 	val = derefValue(val)
 
+	if o, ok := orderedValue(val); ok {
+		return decodeOrderedBlock(ctx, block, o)
+	}
+
 	switch val.Kind() {
 	case reflect.Slice:
 		if val.IsNil() {
@@ -325,6 +326,51 @@ func decodeMultipleBlocks(ctx *hcl.EvalContext, block *hcl.Block, val reflect.Va
 	}}
 }
 
+// decodeOrderedBlock decodes a block into an ordered map, using its label as
+// a key. Block must have only one label.
+func decodeOrderedBlock(ctx *hcl.EvalContext, block *hcl.Block, o maputil.Ordered) hcl.Diagnostics {
+	if len(block.Labels) != 1 {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Decode error",
+			Detail: fmt.Sprintf(
+				"Cannot decode block %q into map: block must have only one label",
+				block.Type,
+			),
+			Subject: block.DefRange.Ptr(),
+		}}
+	}
+	if o.KeyType().Kind() != reflect.String {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Decode error",
+			Detail: fmt.Sprintf(
+				"Cannot decode block %q into map: map key is not a string",
+				block.Type,
+			),
+			Subject: block.DefRange.Ptr(),
+		}}
+	}
+	key := reflect.ValueOf(block.Labels[0]).Convert(o.KeyType())
+	if o.HasRefl(key) {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Decode error",
+			Detail: fmt.Sprintf(
+				"Cannot decode block %q into map: duplicate label %q",
+				block.Type, block.Labels[0],
+			),
+			Subject: block.DefRange.Ptr(),
+		}}
+	}
+	elem := reflect.New(o.ValueType())
+	if diags := decodeSingleBlock(ctx, block, elem); diags.HasErrors() {
+		return diags
+	}
+	o.SetRefl(key, elem.Elem())
+	return nil
+}
+
 // decodeAttribute decodes a single attribute into the given value.
 func decodeAttribute(ctx *hcl.EvalContext, attr *hcl.Attribute, val reflect.Value) hcl.Diagnostics {
 	// Pre decode hook.
@@ -335,8 +381,35 @@ func decodeAttribute(ctx *hcl.EvalContext, attr *hcl.Attribute, val reflect.Valu
 		}
 	}
 
+	// Decode the expression.
+	if diags := decodeExpression(ctx, attr.Expr, val, &attr.Range); diags.HasErrors() {
+		return diags
+	}
+
+	// Post decode hook.
+	if n, ok := val.Interface().(PostDecodeAttribute); ok {
+		diags := n.PostDecodeAttribute(ctx, attr)
+		if diags.HasErrors() {
+			return diags
+		}
+	}
+
+	return nil
+}
+
+// decodeExpression evaluates the expression and maps its value into the
+// given value. The subject is used in the diagnostics if mapping fails.
+//
+// Object constructors decoded into ordered maps are evaluated attribute by
+// attribute, so the map keeps the order of the attributes in the source,
+// which is lost in cty object values.
+func decodeExpression(ctx *hcl.EvalContext, expr hcl.Expression, val reflect.Value, subject *hcl.Range) hcl.Diagnostics {
+	if pairs, ok := orderedPairs(expr, val); ok {
+		return decodeOrderedPairs(ctx, pairs, derefValue(val))
+	}
+
 	// Evaluate the expression.
-	ctyVal, diags := attr.Expr.Value(ctx)
+	ctyVal, diags := expr.Value(ctx)
 	if diags.HasErrors() {
 		return diags
 	}
@@ -347,18 +420,41 @@ func decodeAttribute(ctx *hcl.EvalContext, attr *hcl.Attribute, val reflect.Valu
 			Severity: hcl.DiagError,
 			Summary:  "Decode error",
 			Detail:   err.Error(),
-			Subject:  &attr.Range,
+			Subject:  subject,
 		}}
 	}
+	return nil
+}
 
-	// Post decode hook.
-	if n, ok := val.Interface().(PostDecodeAttribute); ok {
-		diags := n.PostDecodeAttribute(ctx, attr)
-		if diags.HasErrors() {
+// orderedPairs returns the key-value pairs of the expression if it is an
+// object constructor and the value is an ordered map.
+func orderedPairs(expr hcl.Expression, val reflect.Value) ([]hcl.KeyValuePair, bool) {
+	if !val.IsValid() {
+		return nil, false
+	}
+	if _, ok := orderedType(val.Type()); !ok {
+		return nil, false
+	}
+	pairs, diags := hcl.ExprMap(expr)
+	return pairs, !diags.HasErrors()
+}
+
+// decodeOrderedPairs decodes the key-value pairs of an object constructor
+// into an ordered map. Existing entries are removed first.
+func decodeOrderedPairs(ctx *hcl.EvalContext, pairs []hcl.KeyValuePair, val reflect.Value) hcl.Diagnostics {
+	val.Set(reflect.Zero(val.Type()))
+	o, _ := orderedValue(val)
+	for _, pair := range pairs {
+		key := reflect.New(o.KeyType())
+		if diags := decodeExpression(ctx, pair.Key, key, pair.Key.Range().Ptr()); diags.HasErrors() {
 			return diags
 		}
+		elem := reflect.New(o.ValueType())
+		if diags := decodeExpression(ctx, pair.Value, elem, pair.Value.Range().Ptr()); diags.HasErrors() {
+			return diags
+		}
+		o.SetRefl(key.Elem(), elem.Elem())
 	}
-
 	return nil
 }
 
@@ -528,7 +624,11 @@ func getStructFieldMeta(field reflect.StructField) (structFieldMeta, hcl.Diagnos
 	// type must be extracted.
 	if sfm.Type == fieldBlock {
 		typ := derefType(sfm.Reflect.Type)
-		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+		if o, ok := orderedType(typ); ok {
+			typ = derefType(o.ValueType())
+			// An ordered map works like a map.
+			sfm.Multiple = true
+		} else if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
 			typ = derefType(typ.Elem())
 			// If it is a slice or map, the block can be repeated.
 			sfm.Multiple = true
@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/chronicleprotocol/go-lib/maputil"
 	"github.com/chronicleprotocol/go-lib/ptrutil"
 )
 
@@ -501,3 +502,68 @@ func TestDecodeDuplicatedOptionalBlocks(t *testing.T) {
 	diags = Decode(&hcl.EvalContext{}, file.Body, &dest)
 	require.True(t, diags.HasErrors(), diags.Error())
 }
+
+func TestDecodeOrderedMap(t *testing.T) {
+	type block struct {
+		Name string `hcl:"name,label"`
+		Attr string `hcl:"attr"`
+	}
+	type config struct {
+		Attr    maputil.OrderedMap[string, int]                               `hcl:"attr"`
+		Nested  *maputil.OrderedMap[string, *maputil.OrderedMap[string, any]] `hcl:"nested"`
+		Expr    maputil.OrderedMap[string, string]                            `hcl:"expr"`
+		Blocks  maputil.OrderedMap[string, block]                             `hcl:"block,block"`
+		Pointer *maputil.OrderedMap[string, *block]                           `hcl:"ptr,block"`
+	}
+	var data = `
+		attr   = { c = 1, a = 2, "b" = 3 }
+		nested = { z = { y = 1, x = "x" }, a = {} }
+		expr   = { for k, v in { b = "b", a = "a" } : k => v }
+		block "z" { attr = "z" }
+		block "a" { attr = "a" }
+		block "m" { attr = "m" }
+		ptr "b" { attr = "b" }
+		ptr "a" { attr = "a" }
+	`
+	file, diags := hclsyntax.ParseConfig([]byte(data), "test.hcl", hcl.Pos{})
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	var dest config
+	diags = Decode(&hcl.EvalContext{}, file.Body, &dest)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	assert.Equal(t, []string{"c", "a", "b"}, dest.Attr.Keys())
+	assert.Equal(t, []int{1, 2, 3}, dest.Attr.Values())
+	require.NotNil(t, dest.Nested)
+	assert.Equal(t, []string{"z", "a"}, dest.Nested.Keys())
+	z, _ := dest.Nested.Get("z")
+	assert.Equal(t, []string{"y", "x"}, z.Keys())
+
+	// Object values produced by other expressions are ordered by key.
+	assert.Equal(t, []string{"a", "b"}, dest.Expr.Keys())
+
+	assert.Equal(t, []string{"z", "a", "m"}, dest.Blocks.Keys())
+	a, _ := dest.Blocks.Get("a")
+	assert.Equal(t, block{Name: "a", Attr: "a"}, a)
+	require.NotNil(t, dest.Pointer)
+	assert.Equal(t, []string{"b", "a"}, dest.Pointer.Keys())
+}
+
+func TestDecodeOrderedMapDuplicatedLabels(t *testing.T) {
+	type config struct {
+		Blocks maputil.OrderedMap[string, struct {
+			Name string `hcl:"name,label"`
+		}] `hcl:"block,block"`
+	}
+	var data = `
+		block "a" {}
+		block "a" {}
+	`
+	file, diags := hclsyntax.ParseConfig([]byte(data), "test.hcl", hcl.Pos{})
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	var dest config
+	diags = Decode(&hcl.EvalContext{}, file.Body, &dest)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), `duplicate label "a"`)
+}
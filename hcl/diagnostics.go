@@ -0,0 +1,151 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// DiagnosticsOption is a functional option for the WriteDiagnostics
+// function.
+type DiagnosticsOption func(*diagnosticsOptions)
+
+type diagnosticsOptions struct {
+	color bool
+}
+
+// WithColor enables or disables ANSI colors in the rendered diagnostics.
+// Colors are disabled by default.
+func WithColor(color bool) DiagnosticsOption {
+	return func(o *diagnosticsOptions) {
+		o.color = color
+	}
+}
+
+// WriteDiagnostics writes human-readable diagnostics to the given writer
+// using the hcl.NewDiagnosticTextWriter renderer.
+//
+// If the source file of a diagnostic is present in the files map, the
+// diagnostic is rendered with an excerpt of the source code. If colors are
+// enabled, the offending part of a single line is highlighted, otherwise it
+// is marked with carets. The files map can be obtained from the Parser.Files
+// method.
+//
+// Example output:
+//
+//	Error: Invalid expression
+//
+//	  on config.hcl line 2:
+//	   2: baz =
+//	            ^
+//
+//	Expected the start of an expression, but found an invalid expression token.
+func WriteDiagnostics(w io.Writer, files map[string]*hcl.File, diags hcl.Diagnostics, opts ...DiagnosticsOption) error {
+	var o diagnosticsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	bw := bufio.NewWriter(w)
+	for _, diag := range diags {
+		var buf bytes.Buffer
+		// Width 0 disables wrapping of the detail text.
+		if err := hcl.NewDiagnosticTextWriter(&buf, files, 0, o.color).WriteDiagnostic(diag); err != nil {
+			return err
+		}
+		out := buf.Bytes()
+		if !o.color {
+			// Without colors, the offending part of the source is not
+			// marked, so carets are added below the source line.
+			out = addCarets(out, files, diag)
+		}
+		if _, err := bw.Write(out); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteDiagnostics writes human-readable diagnostics to the given writer,
+// with excerpts from the files parsed by the parser.
+//
+// See the WriteDiagnostics function for details.
+func (p *Parser) WriteDiagnostics(w io.Writer, diags hcl.Diagnostics, opts ...DiagnosticsOption) error {
+	return WriteDiagnostics(w, p.Files(), diags, opts...)
+}
+
+// addCarets adds a line with carets below the source line of a diagnostic
+// rendered by hcl.NewDiagnosticTextWriter. Only ranges within a single line
+// are marked.
+func addCarets(out []byte, files map[string]*hcl.File, diag *hcl.Diagnostic) []byte {
+	rng := diag.Subject
+	if rng == nil || rng.Start.Line != rng.End.Line || files[rng.Filename] == nil {
+		return out
+	}
+	src := files[rng.Filename].Bytes
+	lines := bytes.Split(src, []byte{'\n'})
+	if rng.Start.Line < 1 || rng.Start.Line > len(lines) {
+		return out
+	}
+	line := strings.TrimSuffix(string(lines[rng.Start.Line-1]), "\r")
+	lineStart := 0
+	for _, l := range lines[:rng.Start.Line-1] {
+		lineStart += len(l) + 1
+	}
+	start, end := rng.Start.Byte-lineStart, min(rng.End.Byte-lineStart, len(line))
+	if start < 0 || start > len(line) || end < start {
+		return out
+	}
+	// The source lines are prefixed with the line number, see
+	// hcl.NewDiagnosticTextWriter.
+	prefix := fmt.Sprintf("%4d: ", rng.Start.Line)
+	excerpt := []byte(prefix + line + "\n")
+	i := bytes.Index(out, excerpt)
+	if i < 0 {
+		return out
+	}
+	i += len(excerpt)
+	marks := strings.Repeat(" ", len(prefix)) + indent(line[:start]) + carets(line[start:end]) + "\n"
+	return slices.Concat(out[:i], []byte(marks), out[i:])
+}
+
+// indent returns a whitespace string that aligns text with the end of the
+// given string. Tabs are preserved, so the alignment does not depend on the
+// tab width.
+func indent(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
+
+// carets returns a string of carets of the same length as the given string.
+// Empty strings are marked with a single caret.
+func carets(s string) string {
+	return strings.Repeat("^", max(utf8.RuneCountInString(s), 1))
+}
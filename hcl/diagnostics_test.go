@@ -0,0 +1,113 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDiagnostics(t *testing.T) {
+	p := NewParser()
+	body, diags := p.ParseSource("test.hcl", []byte("foo = \"bar\"\nbaz = qux\n"))
+	require.False(t, diags.HasErrors(), diags.Error())
+	attrs, diags := body.JustAttributes()
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	tests := []struct {
+		name  string
+		diags hcl.Diagnostics
+		opts  []DiagnosticsOption
+		want  string
+	}{
+		{
+			name: "error with excerpt",
+			diags: hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Unknown variable",
+				Detail:   `There is no variable named "qux".`,
+				Subject:  attrs["baz"].Expr.Range().Ptr(),
+			}},
+			want: "Error: Unknown variable\n\n" +
+				"  on test.hcl line 2:\n" +
+				"   2: baz = qux\n" +
+				"            ^^^\n\n" +
+				"There is no variable named \"qux\".\n\n",
+		},
+		{
+			name: "warning with color",
+			diags: hcl.Diagnostics{{
+				Severity: hcl.DiagWarning,
+				Summary:  "Deprecated attribute",
+				Subject:  attrs["foo"].NameRange.Ptr(),
+			}},
+			opts: []DiagnosticsOption{WithColor(true)},
+			want: "\x1b[33mWarning\x1b[0m: Deprecated attribute\n\n" +
+				"  on test.hcl line 1:\n" +
+				"   1: \x1b[1;4mfoo\x1b[0m = \"bar\"\n\n",
+		},
+		{
+			name: "unknown file",
+			diags: hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read configuration",
+				Subject:  &hcl.Range{Filename: "missing.hcl", Start: hcl.Pos{Line: 1}, End: hcl.Pos{Line: 1}},
+			}},
+			want: "Error: Failed to read configuration\n\n" +
+				"  on missing.hcl line 1:\n" +
+				"  (source code not available)\n\n",
+		},
+		{
+			name: "without subject",
+			diags: hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Decode error",
+				Detail:   "Value must be a pointer to a struct",
+			}},
+			want: "Error: Decode error\n\nValue must be a pointer to a struct\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, p.WriteDiagnostics(buf, tt.diags, tt.opts...))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestParser_Files(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/config.hcl":         &fstest.MapFile{Data: []byte(`a = 1`)},
+		"b/config.hcl":         &fstest.MapFile{Data: []byte(`b = 2`)},
+		"c.json?checksum=0x01": &fstest.MapFile{Data: []byte(`{"c": 3}`)},
+	}
+	p := NewParser()
+	for _, name := range []string{"a/config.hcl", "b/config.hcl", "c.json?checksum=0x01"} {
+		_, diags := p.ParseFileFS(fsys, name, nil)
+		require.False(t, diags.HasErrors(), diags.Error())
+	}
+	files := p.Files()
+	assert.Len(t, files, 3)
+	assert.Equal(t, "a = 1", string(files["a/config.hcl"].Bytes))
+	assert.Equal(t, "b = 2", string(files["b/config.hcl"].Bytes))
+	assert.Contains(t, files, "c.json")
+}
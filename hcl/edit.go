@@ -0,0 +1,246 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/go-lib/hcl/ethutil"
+)
+
+// Editor allows to modify an existing HCL configuration while preserving
+// its comments and formatting. Only the edited parts of the configuration
+// are changed.
+//
+// It is intended for tools that need to update configuration files, like
+// key rotation or migration tools.
+//
+// Example:
+//
+//	e, diags := utilHCL.LoadEditor("config.hcl")
+//	if diags.HasErrors() {
+//		return diags
+//	}
+//	key := e.Body().EnsureBlock("ethereum").EnsureBlock("key", "default")
+//	key.SetAttribute("keystore_path", "./keystore")
+//	e.SetSecret("api_key", addr, encryptedAPIKey)
+//	err := e.WriteFile("config.hcl", 0o644)
+type Editor struct {
+	file *hclwrite.File
+}
+
+// EditorBody represents a body of an HCL configuration or a block that can
+// be modified.
+type EditorBody struct {
+	body *hclwrite.Body
+}
+
+// NewEditor creates a new Editor for the given HCL source. The filename is
+// used only in diagnostics.
+//
+// Only the native HCL syntax is supported.
+func NewEditor(filename string, src []byte) (*Editor, hcl.Diagnostics) {
+	file, diags := hclwrite.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return &Editor{file: file}, nil
+}
+
+// LoadEditor creates a new Editor for the HCL file at the given path.
+func LoadEditor(path string) (*Editor, hcl.Diagnostics) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read configuration",
+			Detail:   fmt.Sprintf("Cannot read file %s: %s.", path, err),
+		}}
+	}
+	return NewEditor(filepath.Base(path), src)
+}
+
+// Body returns the root body of the configuration.
+func (e *Editor) Body() *EditorBody {
+	return &EditorBody{body: e.file.Body()}
+}
+
+// SetSecret sets the ciphertext of a secret for the given address.
+//
+// Secrets are maps of addresses to ciphertexts, as expected by the secrets
+// extension:
+//
+//	secrets {
+//	  api_key = {
+//	    "0x123...abc" = "0x234...bcd"
+//	  }
+//	}
+//
+// If the secret already has a value for the address, it is replaced. Values
+// for other addresses are kept. If the "secrets" block or the secret does not
+// exist, it is created. The ciphertext must already be encrypted.
+func (e *Editor) SetSecret(name string, addr types.Address, ciphertext string) hcl.Diagnostics {
+	secrets := e.Body().EnsureBlock("secrets").body
+	src := []byte("{\n}")
+	if attr := secrets.GetAttribute(name); attr != nil {
+		src = attr.Expr().BuildTokens(nil).Bytes()
+	}
+	src, diags := setSecretEntry(name, src, addr, ciphertext)
+	if diags.HasErrors() {
+		return diags
+	}
+	// The source is parsed again to get the tokens, so that the edited
+	// map is formatted like the rest of the file.
+	file, diags := hclwrite.ParseConfig(append([]byte(name+" = "), src...), name, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return diags
+	}
+	secrets.SetAttributeRaw(name, file.Body().GetAttribute(name).Expr().BuildTokens(nil))
+	return nil
+}
+
+// setSecretEntry sets the value for the address in the source of a secret
+// map. Other entries and comments are kept as they are.
+func setSecretEntry(name string, src []byte, addr types.Address, ciphertext string) ([]byte, hcl.Diagnostics) {
+	expr, diags := hclsyntax.ParseExpression(src, name, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Secret value is not a map",
+			Detail:   fmt.Sprintf("The %q secret must be a map of addresses to ciphertexts.", name),
+			Subject:  expr.Range().Ptr(),
+		}}
+	}
+	value := hclwrite.TokensForValue(cty.StringVal(ciphertext)).Bytes()
+	for _, item := range obj.Items {
+		key, diags := item.KeyExpr.Value(nil)
+		if diags.HasErrors() || key.IsNull() || key.Type() != cty.String {
+			continue
+		}
+		if keyAddr, err := ethutil.ParseAddressUnchecked(key.AsString()); err != nil || keyAddr != addr {
+			continue
+		}
+		rng := item.ValueExpr.Range()
+		return slices.Concat(src[:rng.Start.Byte], value, src[rng.End.Byte:]), nil
+	}
+	// The closing brace is the last byte of the object.
+	end := obj.SrcRange.End.Byte - 1
+	entry := slices.Concat(hclwrite.TokensForValue(ethutil.AddressVal(addr)).Bytes(), []byte(" = "), value, []byte("\n"))
+	if !bytes.HasSuffix(bytes.TrimRight(src[:end], " \t"), []byte("\n")) {
+		entry = append([]byte("\n"), entry...)
+	}
+	return slices.Concat(src[:end], entry, src[end:]), nil
+}
+
+// RemoveSecret removes an entry from the "secrets" block. It returns false
+// if the entry does not exist.
+func (e *Editor) RemoveSecret(name string) bool {
+	secrets := e.Body().Block("secrets")
+	if secrets == nil {
+		return false
+	}
+	return secrets.RemoveAttribute(name)
+}
+
+// Bytes returns the modified configuration.
+func (e *Editor) Bytes() []byte {
+	return e.file.Bytes()
+}
+
+// WriteFile writes the modified configuration to the file at the given path.
+func (e *Editor) WriteFile(path string, perm fs.FileMode) error {
+	return os.WriteFile(path, e.Bytes(), perm)
+}
+
+// Block returns the first block with the given type and labels. It returns
+// nil if there is no such block.
+func (b *EditorBody) Block(typeName string, labels ...string) *EditorBody {
+	block := b.body.FirstMatchingBlock(typeName, labels)
+	if block == nil {
+		return nil
+	}
+	return &EditorBody{body: block.Body()}
+}
+
+// EnsureBlock returns the first block with the given type and labels.
+// If there is no such block, a new, empty block is appended.
+func (b *EditorBody) EnsureBlock(typeName string, labels ...string) *EditorBody {
+	if block := b.Block(typeName, labels...); block != nil {
+		return block
+	}
+	return &EditorBody{body: b.body.AppendNewBlock(typeName, labels).Body()}
+}
+
+// AppendBlock appends the given block. The block must have a type name.
+func (b *EditorBody) AppendBlock(block *Block) hcl.Diagnostics {
+	if block.TypeName == "" {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Encode error",
+			Detail:   "AppendBlock requires a block with a non-empty TypeName",
+		}}
+	}
+	return block.Write(b.body)
+}
+
+// RemoveBlock removes the first block with the given type and labels.
+// It returns false if there is no such block.
+func (b *EditorBody) RemoveBlock(typeName string, labels ...string) bool {
+	block := b.body.FirstMatchingBlock(typeName, labels)
+	if block == nil {
+		return false
+	}
+	return b.body.RemoveBlock(block)
+}
+
+// SetAttribute sets the value of the attribute with the given name. If the
+// attribute exists, its value is replaced, otherwise a new attribute is
+// appended.
+//
+// The value is converted to cty.Value the same way as in the Encode function.
+func (b *EditorBody) SetAttribute(name string, value any) hcl.Diagnostics {
+	var ctyVal cty.Value
+	if err := mapper.Map(value, &ctyVal); err != nil {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Encode error",
+			Detail:   err.Error(),
+		}}
+	}
+	b.body.SetAttributeValue(name, ctyVal)
+	return nil
+}
+
+// RemoveAttribute removes the attribute with the given name. It returns
+// false if there is no such attribute.
+func (b *EditorBody) RemoveAttribute(name string) bool {
+	return b.body.RemoveAttribute(name) != nil
+}
@@ -0,0 +1,279 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/hcl/ethutil"
+	"github.com/chronicleprotocol/go-lib/hcl/ext/secrets"
+)
+
+var (
+	editTestAddr1 = ethutil.MustParseAddress("0x1111111111111111111111111111111111111111")
+	editTestAddr3 = ethutil.MustParseAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+)
+
+const editTestConfig = `# Ethereum configuration.
+ethereum {
+  # Default key.
+  key "default" {
+    address       = "0x1111111111111111111111111111111111111111"
+    keystore_path = "./keystore" # Keystore directory.
+  }
+}
+
+secrets {
+  foo = {
+    "0x1111111111111111111111111111111111111111" = "0xencrypted-foo-1"
+    # Backup key.
+    "0x2222222222222222222222222222222222222222" = "0xencrypted-foo-2"
+  }
+}
+`
+
+func TestEditor(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(t *testing.T, e *Editor)
+		want string
+	}{
+		{
+			name: "no changes",
+			edit: func(t *testing.T, e *Editor) {},
+			want: editTestConfig,
+		},
+		{
+			name: "set existing attribute",
+			edit: func(t *testing.T, e *Editor) {
+				key := e.Body().Block("ethereum").Block("key", "default")
+				require.NotNil(t, key)
+				require.False(t, key.SetAttribute("address", "0x2").HasErrors())
+			},
+			want: `# Ethereum configuration.
+ethereum {
+  # Default key.
+  key "default" {
+    address       = "0x2"
+    keystore_path = "./keystore" # Keystore directory.
+  }
+}
+
+secrets {
+  foo = {
+    "0x1111111111111111111111111111111111111111" = "0xencrypted-foo-1"
+    # Backup key.
+    "0x2222222222222222222222222222222222222222" = "0xencrypted-foo-2"
+  }
+}
+`,
+		},
+		{
+			name: "add block",
+			edit: func(t *testing.T, e *Editor) {
+				key := e.Body().Block("ethereum").EnsureBlock("key", "other")
+				require.False(t, key.SetAttribute("address", "0x3").HasErrors())
+			},
+			want: `# Ethereum configuration.
+ethereum {
+  # Default key.
+  key "default" {
+    address       = "0x1111111111111111111111111111111111111111"
+    keystore_path = "./keystore" # Keystore directory.
+  }
+  key "other" {
+    address = "0x3"
+  }
+}
+
+secrets {
+  foo = {
+    "0x1111111111111111111111111111111111111111" = "0xencrypted-foo-1"
+    # Backup key.
+    "0x2222222222222222222222222222222222222222" = "0xencrypted-foo-2"
+  }
+}
+`,
+		},
+		{
+			name: "append block",
+			edit: func(t *testing.T, e *Editor) {
+				block := &Block{TypeName: "feeds"}
+				block.AppendAttribute("addresses", []string{"0x4"})
+				require.False(t, e.Body().AppendBlock(block).HasErrors())
+			},
+			want: editTestConfig + `feeds { addresses = ["0x4"] }
+`,
+		},
+		{
+			name: "update secrets",
+			edit: func(t *testing.T, e *Editor) {
+				require.False(t, e.SetSecret("foo", editTestAddr1, "0xencrypted-foo-3").HasErrors())
+				require.False(t, e.SetSecret("foo", editTestAddr3, "0xencrypted-foo-4").HasErrors())
+				require.False(t, e.SetSecret("bar", editTestAddr1, "0xencrypted-bar").HasErrors())
+			},
+			want: `# Ethereum configuration.
+ethereum {
+  # Default key.
+  key "default" {
+    address       = "0x1111111111111111111111111111111111111111"
+    keystore_path = "./keystore" # Keystore directory.
+  }
+}
+
+secrets {
+  foo = {
+    "0x1111111111111111111111111111111111111111" = "0xencrypted-foo-3"
+    # Backup key.
+    "0x2222222222222222222222222222222222222222" = "0xencrypted-foo-2"
+    "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed" = "0xencrypted-foo-4"
+  }
+  bar = {
+    "0x1111111111111111111111111111111111111111" = "0xencrypted-bar"
+  }
+}
+`,
+		},
+		{
+			name: "update appended secret",
+			edit: func(t *testing.T, e *Editor) {
+				require.False(t, e.SetSecret("foo", editTestAddr3, "0xencrypted-foo-4").HasErrors())
+				require.False(t, e.SetSecret("foo", editTestAddr3, "0xencrypted-foo-5").HasErrors())
+			},
+			want: `# Ethereum configuration.
+ethereum {
+  # Default key.
+  key "default" {
+    address       = "0x1111111111111111111111111111111111111111"
+    keystore_path = "./keystore" # Keystore directory.
+  }
+}
+
+secrets {
+  foo = {
+    "0x1111111111111111111111111111111111111111" = "0xencrypted-foo-1"
+    # Backup key.
+    "0x2222222222222222222222222222222222222222" = "0xencrypted-foo-2"
+    "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed" = "0xencrypted-foo-5"
+  }
+}
+`,
+		},
+		{
+			name: "remove",
+			edit: func(t *testing.T, e *Editor) {
+				assert.True(t, e.RemoveSecret("foo"))
+				assert.False(t, e.RemoveSecret("foo"))
+				assert.True(t, e.Body().RemoveBlock("ethereum"))
+				assert.False(t, e.Body().RemoveBlock("ethereum"))
+			},
+			want: "\nsecrets {\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, diags := NewEditor("test.hcl", []byte(editTestConfig))
+			require.False(t, diags.HasErrors(), diags.Error())
+			tt.edit(t, e)
+			assert.Equal(t, tt.want, string(e.Bytes()))
+		})
+	}
+}
+
+func TestEditor_SetSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "lowercase key",
+			src:  "secrets {\n  foo = { \"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed\" = \"0xold\" }\n}\n",
+			want: "secrets {\n  foo = { \"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed\" = \"0xnew\" }\n}\n",
+		},
+		{
+			name: "single line map",
+			src:  "secrets {\n  foo = { \"0x1111111111111111111111111111111111111111\" = \"0xold\" }\n}\n",
+			want: "secrets {\n  foo = { \"0x1111111111111111111111111111111111111111\" = \"0xold\"\n    \"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\" = \"0xnew\"\n  }\n}\n",
+		},
+		{
+			name: "new secrets block",
+			src:  "",
+			want: "secrets {\n  foo = {\n    \"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\" = \"0xnew\"\n  }\n}\n",
+		},
+		{
+			name:    "not a map",
+			src:     "secrets {\n  foo = \"0xold\"\n}\n",
+			wantErr: "Secret value is not a map",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, diags := NewEditor("test.hcl", []byte(tt.src))
+			require.False(t, diags.HasErrors(), diags.Error())
+			diags = e.SetSecret("foo", editTestAddr3, "0xnew")
+			if tt.wantErr != "" {
+				require.True(t, diags.HasErrors())
+				assert.Contains(t, diags.Error(), tt.wantErr)
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, tt.want, string(e.Bytes()))
+		})
+	}
+}
+
+func TestEditor_SetSecret_Decode(t *testing.T) {
+	// Decryption is skipped, only the format of the secrets is verified.
+	t.Setenv("XXX_SECRETS_SKIP_DECRYPT", "true")
+
+	e, diags := NewEditor("test.hcl", []byte(editTestConfig))
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.False(t, e.SetSecret("foo", editTestAddr1, "0xencrypted-foo-3").HasErrors())
+	require.False(t, e.SetSecret("bar", editTestAddr1, "0xencrypted-bar").HasErrors())
+
+	file, diags := hclsyntax.ParseConfig(e.Bytes(), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	require.False(t, diags.HasErrors(), diags.Error())
+	ctx := &hcl.EvalContext{}
+	_, diags = secrets.DecryptSecrets(ctx, file.Body)
+	require.False(t, diags.HasErrors(), diags.Error())
+	vars := ctx.Variables["secrets"].AsValueMap()
+	assert.Equal(t, "<encrypted>", vars["foo"].AsString())
+	assert.Equal(t, "<encrypted>", vars["bar"].AsString())
+}
+
+func TestEditor_WriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(editTestConfig), 0o600))
+
+	e, diags := LoadEditor(path)
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.False(t, e.SetSecret("foo", editTestAddr1, "0xencrypted-foo-3").HasErrors())
+	require.NoError(t, e.WriteFile(path, 0o600))
+
+	src, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), `"0x1111111111111111111111111111111111111111" = "0xencrypted-foo-3"`)
+	assert.Contains(t, string(src), `# Keystore directory.`)
+}
@@ -151,6 +151,23 @@ func encodeSingleBlock(val reflect.Value, body *Block, typeName string, labels [
 func encodeMultipleBlocks(body *Block, val reflect.Value, typeName string) hcl.Diagnostics {
 	val = derefValue(val)
 
+	if o, ok := orderedValue(val); ok {
+		if o.KeyType().Kind() != reflect.String {
+			return hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Encode error",
+				Detail:   "Unable to encode value as HCL; map key is not a string",
+			}}
+		}
+		// Ordered maps are encoded in their own order.
+		var diags hcl.Diagnostics
+		o.RangeRefl(func(key, elem reflect.Value) bool {
+			diags = encodeSingleBlock(elem, body, typeName, []string{key.String()})
+			return !diags.HasErrors()
+		})
+		return diags
+	}
+
 	switch val.Kind() {
 	case reflect.Map:
 		if val.Type().Key().Kind() != reflect.String {
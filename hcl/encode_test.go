@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/chronicleprotocol/go-lib/maputil"
 	"github.com/chronicleprotocol/go-lib/ptrutil"
 )
 
@@ -248,3 +249,40 @@ var = {
 		})
 	}
 }
+
+func TestEncodeOrderedMap(t *testing.T) {
+	type block struct {
+		Attr string `hcl:"attr"`
+	}
+	type config struct {
+		Attr   maputil.OrderedMap[string, any]    `hcl:"attr"`
+		Blocks *maputil.OrderedMap[string, block] `hcl:"block,block"`
+	}
+	nested := maputil.NewOrderedMap[string, int](2)
+	nested.Set("y", 1)
+	nested.Set("x", 2)
+	cfg := &config{Blocks: maputil.NewOrderedMap[string, block](2)}
+	cfg.Attr.Set("c", "c")
+	cfg.Attr.Set("a", nested)
+	cfg.Attr.Set("b", []int{1})
+	cfg.Blocks.Set("z", block{Attr: "z"})
+	cfg.Blocks.Set("a", block{Attr: "a"})
+
+	body := &Block{}
+	require.False(t, Encode(cfg, body).HasErrors())
+	hcl, diags := body.Bytes()
+	require.False(t, diags.HasErrors())
+	assert.Equal(t, strings.TrimSpace(`
+attr = {
+  c = "c"
+  a = {
+    y = 1
+    x = 2
+  }
+  b = [1]
+}
+
+block "z" { attr = "z" }
+block "a" { attr = "a" }
+`), strings.TrimSpace(string(hcl)))
+}
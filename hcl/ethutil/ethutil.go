@@ -0,0 +1,110 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package ethutil provides helpers for working with Ethereum addresses and
+// hashes in HCL configurations.
+//
+// It converts the go-eth types.Address and types.Hash types to and from cty
+// values, so that HCL functions, extensions and decoders handle them in the
+// same way. The parsing functions are those of the ethutil package from the
+// root module, repeated here for convenience.
+package ethutil
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/go-lib/ethutil"
+)
+
+// ParseAddress parses a hex encoded Ethereum address, see
+// ethutil.ParseAddress.
+func ParseAddress(s string) (types.Address, error) {
+	return ethutil.ParseAddress(s)
+}
+
+// ParseAddressUnchecked parses a hex encoded Ethereum address without
+// verifying its checksum, see ethutil.ParseAddressUnchecked.
+func ParseAddressUnchecked(s string) (types.Address, error) {
+	return ethutil.ParseAddressUnchecked(s)
+}
+
+// MustParseAddress works like ParseAddress, but panics on error.
+func MustParseAddress(s string) types.Address {
+	return ethutil.MustParseAddress(s)
+}
+
+// ParseHash parses a hex encoded hash, see ethutil.ParseHash.
+func ParseHash(s string) (types.Hash, error) {
+	return ethutil.ParseHash(s)
+}
+
+// MustParseHash works like ParseHash, but panics on error.
+func MustParseHash(s string) types.Hash {
+	return ethutil.MustParseHash(s)
+}
+
+// Checksum returns the address in the EIP-55 checksummed form, see
+// ethutil.Checksum.
+func Checksum(addr types.Address) string {
+	return ethutil.Checksum(addr)
+}
+
+// AddressVal returns a cty string with the address in the EIP-55
+// checksummed form.
+func AddressVal(addr types.Address) cty.Value {
+	return cty.StringVal(Checksum(addr))
+}
+
+// HashVal returns a cty string with the hash encoded as a lower case hex
+// string, prefixed with "0x".
+func HashVal(hash types.Hash) cty.Value {
+	return cty.StringVal("0x" + hex.EncodeToString(hash[:]))
+}
+
+// AddressFromVal parses an address from a cty string using ParseAddress.
+// Marks on the value are ignored.
+func AddressFromVal(val cty.Value) (types.Address, error) {
+	s, err := stringFromVal(val)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return ParseAddress(s)
+}
+
+// HashFromVal parses a hash from a cty string using ParseHash. Marks on the
+// value are ignored.
+func HashFromVal(val cty.Value) (types.Hash, error) {
+	s, err := stringFromVal(val)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	return ParseHash(s)
+}
+
+func stringFromVal(val cty.Value) (string, error) {
+	val, _ = val.Unmark()
+	switch {
+	case val.IsNull():
+		return "", fmt.Errorf("value must not be null")
+	case !val.IsKnown():
+		return "", fmt.Errorf("value must be known")
+	case val.Type() != cty.String:
+		return "", fmt.Errorf("wrong value type: expected string, got %s", val.Type().FriendlyName())
+	}
+	return val.AsString(), nil
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package ethutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestHashVal(t *testing.T) {
+	hash := MustParseHash("0x512227571B4B801D3BBE8F01E3B651E6C4462EB9780EE5B9FB9EA4FB6899A5C4")
+	assert.True(t, HashVal(hash).RawEquals(cty.StringVal("0x512227571b4b801d3bbe8f01e3b651e6c4462eb9780ee5b9fb9ea4fb6899a5c4")))
+}
+
+func TestAddressVal(t *testing.T) {
+	addr := MustParseAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	val := AddressVal(addr)
+	assert.True(t, val.RawEquals(cty.StringVal("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")))
+
+	got, err := AddressFromVal(val.Mark("sensitive"))
+	require.NoError(t, err)
+	assert.Equal(t, addr, got)
+}
+
+func TestHashFromVal(t *testing.T) {
+	hash := MustParseHash("0x512227571b4b801d3bbe8f01e3b651e6c4462eb9780ee5b9fb9ea4fb6899a5c4")
+	got, err := HashFromVal(HashVal(hash))
+	require.NoError(t, err)
+	assert.Equal(t, hash, got)
+}
+
+func TestFromVal_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     cty.Value
+		wantErr string
+	}{
+		{name: "null", val: cty.NullVal(cty.String), wantErr: "value must not be null"},
+		{name: "unknown", val: cty.UnknownVal(cty.String), wantErr: "value must be known"},
+		{name: "number", val: cty.NumberIntVal(1), wantErr: "wrong value type: expected string, got number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AddressFromVal(tt.val)
+			assert.EqualError(t, err, tt.wantErr)
+			_, err = HashFromVal(tt.val)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
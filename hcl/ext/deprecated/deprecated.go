@@ -0,0 +1,228 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package deprecated
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+const (
+	// labelWildcard is a path segment that matches a single block label.
+	labelWildcard = "*"
+)
+
+// Registry holds a list of deprecated attributes and blocks.
+//
+// Deprecated elements are identified by a dot-separated path. Each segment
+// of the path is a block type name, except the last one, which is the name
+// of the deprecated attribute or block. Block labels are matched using the
+// "*" wildcard, one per label.
+//
+// Example:
+//
+//	r := deprecated.NewRegistry()
+//	r.Attribute("ethereum.key.*.keystore", "keystore_path")
+//	r.Block("feeds.*", "")
+//
+// In this example, the "keystore" attribute of the "key" block with one label
+// inside the "ethereum" block is deprecated in favor of the "keystore_path"
+// attribute, and the "feeds" block with one label is deprecated without
+// a replacement.
+type Registry struct {
+	items []*item
+}
+
+// NewRegistry creates a new, empty registry of deprecated elements.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Attribute registers a deprecated attribute. The replacement argument is
+// an optional name of the attribute that should be used instead.
+func (r *Registry) Attribute(path, replacement string) *Registry {
+	r.items = append(r.items, &item{
+		path:        path,
+		segments:    parsePath(path),
+		block:       false,
+		replacement: replacement,
+	})
+	return r
+}
+
+// Block registers a deprecated block. The replacement argument is an optional
+// name of the block that should be used instead.
+func (r *Registry) Block(path, replacement string) *Registry {
+	r.items = append(r.items, &item{
+		path:        path,
+		segments:    parsePath(path),
+		block:       true,
+		replacement: replacement,
+	})
+	return r
+}
+
+// Lookup reports whether the attribute or block with the given path is
+// registered as deprecated and returns its replacement. The path must have
+// the same format as the paths passed to the Attribute and Block methods.
+func (r *Registry) Lookup(path string, block bool) (replacement string, ok bool) {
+	segs := parsePath(path)
+	if len(segs) == 0 {
+		return "", false
+	}
+	for _, i := range r.items {
+		if i.block == block && slices.Equal(i.segments, segs) {
+			return i.replacement, true
+		}
+	}
+	return "", false
+}
+
+// Check looks for deprecated attributes and blocks in the given body and
+// returns a warning diagnostic for every occurrence.
+//
+// The check never returns errors, except when a registered path is invalid.
+// The body itself is not modified, deprecated elements must still be handled
+// by the decoder.
+func (r *Registry) Check(body hcl.Body) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, i := range r.items {
+		if !i.valid() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid deprecation path",
+				Detail:   fmt.Sprintf("Invalid deprecation path %q.", i.path),
+			})
+			continue
+		}
+		diags = diags.Extend(i.check(body, 0))
+	}
+	return diags
+}
+
+// Deprecated checks the given body for deprecated attributes and blocks
+// registered in the registry. It returns the body unchanged, so it can be
+// used along with other extensions.
+func Deprecated(_ *hcl.EvalContext, body hcl.Body, r *Registry) (hcl.Body, hcl.Diagnostics) {
+	return body, r.Check(body)
+}
+
+type item struct {
+	path        string
+	segments    []segment
+	block       bool
+	replacement string
+}
+
+type segment struct {
+	name   string
+	labels int
+}
+
+// valid returns true if the path is not empty and, in case of attributes,
+// the last segment is not followed by label wildcards.
+func (i *item) valid() bool {
+	if len(i.segments) == 0 {
+		return false
+	}
+	return i.block || i.segments[len(i.segments)-1].labels == 0
+}
+
+// check recursively looks for the deprecated element, starting from the
+// segment at the given index.
+func (i *item) check(body hcl.Body, idx int) hcl.Diagnostics {
+	seg := i.segments[idx]
+	last := idx == len(i.segments)-1
+
+	// Deprecated attribute.
+	if last && !i.block {
+		content, _, _ := body.PartialContent(&hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{{Name: seg.name}},
+		})
+		attr := content.Attributes[seg.name]
+		if attr == nil {
+			return nil
+		}
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated attribute",
+			Detail:   i.detail("attribute", seg.name),
+			Subject:  attr.NameRange.Ptr(),
+			Context:  attr.Range.Ptr(),
+		}}
+	}
+
+	// Deprecated block or a block on the path to the deprecated element.
+	content, _, _ := body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{
+			Type:       seg.name,
+			LabelNames: labelNames(seg.labels),
+		}},
+	})
+	var diags hcl.Diagnostics
+	for _, block := range content.Blocks {
+		if last {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Deprecated block",
+				Detail:   i.detail("block", seg.name),
+				Subject:  block.TypeRange.Ptr(),
+				Context:  block.DefRange.Ptr(),
+			})
+			continue
+		}
+		diags = diags.Extend(i.check(block.Body, idx+1))
+	}
+	return diags
+}
+
+func (i *item) detail(kind, name string) string {
+	if i.replacement == "" {
+		return fmt.Sprintf("The %q %s is deprecated and will be removed in a future release.", name, kind)
+	}
+	return fmt.Sprintf("The %q %s is deprecated and will be removed in a future release. Use %q instead.", name, kind, i.replacement)
+}
+
+// parsePath splits the path into segments. The "*" wildcards are counted
+// as labels of the preceding segment.
+func parsePath(path string) []segment {
+	var segs []segment
+	for _, part := range strings.Split(path, ".") {
+		switch {
+		case part == labelWildcard && len(segs) > 0:
+			segs[len(segs)-1].labels++
+		case part == labelWildcard, part == "":
+			return nil
+		default:
+			segs = append(segs, segment{name: part})
+		}
+	}
+	return segs
+}
+
+func labelNames(n int) []string {
+	if n == 0 {
+		return nil
+	}
+	l := make([]string, n)
+	for i := range l {
+		l[i] = fmt.Sprintf("label%d", i)
+	}
+	return l
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package deprecated
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+)
+
+func TestDeprecated(t *testing.T) {
+	tests := []struct {
+		name         string
+		registry     *Registry
+		expectedDiag []string
+		expectedErr  string
+	}{
+		{
+			name:     "attribute",
+			registry: NewRegistry().Attribute("ethereum.key.*.keystore", "keystore_path"),
+			expectedDiag: []string{
+				`testdata/deprecated.hcl:4,5-13: Deprecated attribute; The "keystore" attribute is deprecated and will be removed in a future release. Use "keystore_path" instead.`,
+			},
+		},
+		{
+			name:     "block",
+			registry: NewRegistry().Block("feeds.*", ""),
+			expectedDiag: []string{
+				`testdata/deprecated.hcl:8,1-6: Deprecated block; The "feeds" block is deprecated and will be removed in a future release.`,
+			},
+		},
+		{
+			name:     "nested block",
+			registry: NewRegistry().Block("gofer.origin.*", "data_source"),
+			expectedDiag: []string{
+				`testdata/deprecated.hcl:13,3-9: Deprecated block; The "origin" block is deprecated and will be removed in a future release. Use "data_source" instead.`,
+			},
+		},
+		{
+			name: "multiple",
+			registry: NewRegistry().
+				Attribute("ethereum.key.*.keystore", "").
+				Block("feeds.*", ""),
+			expectedDiag: []string{
+				`testdata/deprecated.hcl:4,5-13: Deprecated attribute; The "keystore" attribute is deprecated and will be removed in a future release.`,
+				`testdata/deprecated.hcl:8,1-6: Deprecated block; The "feeds" block is deprecated and will be removed in a future release.`,
+			},
+		},
+		{
+			name:     "label count mismatch",
+			registry: NewRegistry().Block("feeds", ""),
+		},
+		{
+			name:     "not used",
+			registry: NewRegistry().Attribute("ethereum.key.*.passphrase", ""),
+		},
+		{
+			name:        "invalid path",
+			registry:    NewRegistry().Attribute("ethereum.*", ""),
+			expectedErr: `Invalid deprecation path; Invalid deprecation path "ethereum.*".`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, diags := utilHCL.ParseFile("./testdata/deprecated.hcl", nil)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			res, diags := Deprecated(&hcl.EvalContext{}, body, tt.registry)
+			if tt.expectedErr != "" {
+				require.True(t, diags.HasErrors())
+				assert.Contains(t, diags.Error(), tt.expectedErr)
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, body, res)
+			require.Len(t, diags, len(tt.expectedDiag))
+			for i, d := range diags {
+				assert.Equal(t, hcl.DiagWarning, d.Severity)
+				assert.Equal(t, tt.expectedDiag[i], d.Error())
+			}
+		})
+	}
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := NewRegistry().
+		Attribute("ethereum.key.*.keystore", "keystore_path").
+		Block("feeds.*", "")
+	tests := []struct {
+		path            string
+		block           bool
+		wantReplacement string
+		wantOK          bool
+	}{
+		{path: "ethereum.key.*.keystore", wantReplacement: "keystore_path", wantOK: true},
+		{path: "feeds.*", block: true, wantOK: true},
+		{path: "feeds.*", block: false},
+		{path: "feeds", block: true},
+		{path: "ethereum.key.keystore"},
+		{path: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			replacement, ok := r.Lookup(tt.path, tt.block)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantReplacement, replacement)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+ethereum {
+  key "default" {
+    address  = "0x2d800d93b065ce011af83f316cef9f0d005b0aa4"
+    keystore = "./keystore"
+  }
+}
+
+feeds "default" {
+  addresses = []
+}
+
+gofer {
+  origin "foo" {}
+}
@@ -18,6 +18,7 @@ package include
 import (
 	"fmt"
 	"io/fs"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -26,9 +27,66 @@ import (
 	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
 )
 
+// ChecksumParam is the name of the query parameter that contains the
+// checksum of an included file. It is the parameter verified by the
+// fsutil checksum file system.
+const ChecksumParam = "checksum"
+
+// Option is a functional option for the Include function.
+type Option func(*options)
+
+type options struct {
+	requireChecksum bool
+	parser          *utilHCL.Parser
+	dir             string // Directory of the file system, used in filenames.
+}
+
+// WithRequireChecksum makes Include refuse to load included files without
+// the "checksum" query parameter, e.g. "feeds.hcl?checksum=0x...". Glob
+// patterns are refused as well, because they cannot have a checksum.
+//
+// It should be used when the file system reads remote files, so the
+// configuration cannot depend on unverified remote content. The checksum
+// itself must be verified by the file system, e.g. fsutil.NewChecksumFS.
+func WithRequireChecksum() Option {
+	return func(o *options) {
+		o.requireChecksum = true
+	}
+}
+
+// WithParser sets the parser used to parse the included files. It allows
+// to render diagnostics of the included files with source excerpts, see
+// utilHCL.Parser.WriteDiagnostics. By default, a new parser is used.
+func WithParser(p *utilHCL.Parser) Option {
+	return func(o *options) {
+		o.parser = p
+	}
+}
+
+// WithDir sets the directory of the file system, e.g. the location of the
+// including file. It is used as a prefix of the names of the included files
+// in diagnostics, so that files with the same name in different locations
+// can be told apart.
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
 // Include merges the contents of multiple HCL files specified in the "include"
 // attribute. It uses glob patterns.
-func Include(ctx *hcl.EvalContext, f fs.FS, body hcl.Body, maxDepth int) (hcl.Body, hcl.Diagnostics) {
+func Include(ctx *hcl.EvalContext, f fs.FS, body hcl.Body, maxDepth int, opts ...Option) (hcl.Body, hcl.Diagnostics) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.parser == nil {
+		o.parser = utilHCL.NewParser()
+	}
+	return include(ctx, f, body, maxDepth, o)
+}
+
+func include(ctx *hcl.EvalContext, f fs.FS, body hcl.Body, maxDepth int, o options) (hcl.Body, hcl.Diagnostics) {
 	// Decode the "include" attribute.
 	content, remain, diags := body.PartialContent(&hcl.BodySchema{
 		Attributes: []hcl.AttributeSchema{{Name: "include"}},
@@ -55,6 +113,19 @@ func Include(ctx *hcl.EvalContext, f fs.FS, body hcl.Body, maxDepth int) (hcl.Bo
 	// Iterate over the glob patterns.
 	var bodies []hcl.Body
 	for _, pattern := range includes {
+		if o.requireChecksum && !hasChecksum(pattern) {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Unverified include",
+				Detail: fmt.Sprintf(
+					"Include %q has no checksum. Included files must have the %q parameter.",
+					pattern,
+					ChecksumParam,
+				),
+				Subject: attr.Expr.Range().Ptr(),
+			}}
+		}
+
 		// Find all files matching the glob pattern.
 		paths, err := glob(f, pattern)
 		if err != nil {
@@ -68,14 +139,26 @@ func Include(ctx *hcl.EvalContext, f fs.FS, body hcl.Body, maxDepth int) (hcl.Bo
 
 		// Iterate over the files from the glob pattern.
 		for _, path := range paths {
-			// Parse the file.
-			fileBody, diags := utilHCL.ParseFileFS(f, path, attr.Expr.Range().Ptr())
+			// Parse the file. Files are named by their path relative to the
+			// root file system, without the query.
+			name := filename(o.dir, utilHCL.TrimQuery(path))
+			src, err := fs.ReadFile(f, path)
+			if err != nil {
+				return nil, hcl.Diagnostics{{
+					Severity: hcl.DiagError,
+					Summary:  "Failed to read configuration",
+					Detail:   fmt.Sprintf("Cannot read file %s: %s.", name, err),
+					Subject:  attr.Expr.Range().Ptr(),
+				}}
+			}
+			fileBody, diags := o.parser.ParseSource(name, src)
 			if diags.HasErrors() {
 				return nil, diags
 			}
 
 			// Allow including files only from the same directory or subdirectories.
-			sub, err := fs.Sub(f, filepath.Dir(path))
+			dir := filepath.Dir(utilHCL.TrimQuery(path))
+			sub, err := fs.Sub(f, dir)
 			if err != nil {
 				return nil, hcl.Diagnostics{{
 					Severity: hcl.DiagError,
@@ -86,7 +169,9 @@ func Include(ctx *hcl.EvalContext, f fs.FS, body hcl.Body, maxDepth int) (hcl.Bo
 			}
 
 			// Recursively include files.
-			body, diags := Include(ctx, sub, fileBody, maxDepth-1)
+			subOpts := o
+			subOpts.dir = filename(o.dir, dir)
+			body, diags := include(ctx, sub, fileBody, maxDepth-1, subOpts)
 			if diags.HasErrors() {
 				return nil, diags
 			}
@@ -98,6 +183,35 @@ func Include(ctx *hcl.EvalContext, f fs.FS, body hcl.Body, maxDepth int) (hcl.Bo
 	return hcl.MergeBodies(append([]hcl.Body{remain}, bodies...)), nil
 }
 
+// hasChecksum reports whether the include path has a non-empty checksum
+// parameter. Glob patterns never have a checksum.
+func hasChecksum(path string) bool {
+	if strings.Contains(path, "*") {
+		return false
+	}
+	_, query, ok := strings.Cut(path, "?")
+	if !ok {
+		return false
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return false
+	}
+	return values.Get(ChecksumParam) != ""
+}
+
+// filename joins the directory and the path. Unlike path.Join, it does not
+// clean the directory, which may be a URL.
+func filename(dir, path string) string {
+	switch {
+	case dir == "" || dir == ".":
+		return path
+	case path == ".":
+		return dir
+	}
+	return strings.TrimSuffix(dir, "/") + "/" + path
+}
+
 func glob(f fs.FS, pattern string) ([]string, error) {
 	if !strings.Contains(pattern, "*") {
 		return []string{pattern}, nil
@@ -18,6 +18,7 @@ package include
 import (
 	"os"
 	"testing"
+	"testing/fstest"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
@@ -85,3 +86,80 @@ func TestInclude(t *testing.T) {
 		})
 	}
 }
+
+func TestInclude_WithParser(t *testing.T) {
+	parser := utilHCL.NewParser()
+	body, diags := parser.ParseFile("./testdata/relative-dir.hcl", nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	_, diags = Include(&hcl.EvalContext{}, os.DirFS("testdata"), body, 2, WithParser(parser), WithDir("testdata"))
+	require.False(t, diags.HasErrors(), diags.Error())
+	files := parser.Files()
+	assert.Len(t, files, 3)
+	assert.Contains(t, files, "testdata/relative-dir.hcl")
+	assert.Contains(t, files, "testdata/subdir/included.hcl")
+	assert.Contains(t, files, "testdata/subdir/level2/more-included.hcl")
+}
+
+func TestInclude_RequireChecksum(t *testing.T) {
+	files := fstest.MapFS{
+		"a.hcl?checksum=0x01":          &fstest.MapFile{Data: []byte(`a = 1`)},
+		"b.hcl":                        &fstest.MapFile{Data: []byte(`b = 2`)},
+		"nested.hcl?checksum=0x02":     &fstest.MapFile{Data: []byte(`include = ["b.hcl"]`)},
+		"c.json?checksum=0x03":         &fstest.MapFile{Data: []byte(`{"c": 3}`)},
+		"d/e.hcl?checksum=sha256-a/b=": &fstest.MapFile{Data: []byte(`include = ["f.hcl?checksum=0x04"]`)},
+		"d/f.hcl?checksum=0x04":        &fstest.MapFile{Data: []byte(`f = 4`)},
+	}
+	tests := []struct {
+		name        string
+		src         string
+		expectedErr string
+	}{
+		{
+			name: "with checksum",
+			src:  `include = ["a.hcl?checksum=0x01"]`,
+		},
+		{
+			name: "JSON with checksum",
+			src:  `include = ["c.json?checksum=0x03"]`,
+		},
+		{
+			name: "checksum with a slash",
+			src:  `include = ["d/e.hcl?checksum=sha256-a/b="]`,
+		},
+		{
+			name:        "without checksum",
+			src:         `include = ["b.hcl"]`,
+			expectedErr: `Unverified include; Include "b.hcl" has no checksum.`,
+		},
+		{
+			name:        "empty checksum",
+			src:         `include = ["b.hcl?checksum="]`,
+			expectedErr: `Unverified include; Include "b.hcl?checksum=" has no checksum.`,
+		},
+		{
+			name:        "glob pattern",
+			src:         `include = ["*.hcl"]`,
+			expectedErr: `Unverified include; Include "*.hcl" has no checksum.`,
+		},
+		{
+			name:        "nested include without checksum",
+			src:         `include = ["nested.hcl?checksum=0x02"]`,
+			expectedErr: `Unverified include; Include "b.hcl" has no checksum.`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, diags := utilHCL.ParseSource("config.hcl", []byte(tt.src))
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			_, diags = Include(&hcl.EvalContext{}, files, body, 2, WithRequireChecksum())
+			if tt.expectedErr != "" {
+				require.True(t, diags.HasErrors())
+				assert.Contains(t, diags.Error(), tt.expectedErr)
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+		})
+	}
+}
@@ -16,18 +16,21 @@
 package secrets
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 
-	"github.com/chronicleprotocol/ecies"
-	"github.com/defiweb/go-eth/hexutil"
 	"github.com/defiweb/go-eth/types"
 	"github.com/defiweb/go-eth/wallet"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/dynblock"
 	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/go-lib/cryptoutil"
+	"github.com/chronicleprotocol/go-lib/envutil"
+	"github.com/chronicleprotocol/go-lib/hcl/ethutil"
+	"github.com/chronicleprotocol/go-lib/logutil"
 )
 
 const (
@@ -41,6 +44,35 @@ const (
 	skipDecryptEnv = "XXX_SECRETS_SKIP_DECRYPT"
 )
 
+// Option is a functional option for the NewDecryptSecrets function.
+type Option func(*options)
+
+type options struct {
+	logger logutil.Logger
+}
+
+// WithLogger sets the logger used to log which key is used to decrypt the
+// secrets and how many secrets were decrypted. Secret values are never
+// logged.
+func WithLogger(logger logutil.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// NewDecryptSecrets returns the DecryptSecrets function configured with the
+// given options.
+func NewDecryptSecrets(opts ...Option) func(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnostics) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.logger = logutil.OrNop(o.logger)
+	return func(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnostics) {
+		return decryptSecrets(ctx, body, o)
+	}
+}
+
 // DecryptSecrets decrypts secrets in the given HCL body.
 // Example:
 //
@@ -72,6 +104,10 @@ const (
 // NOTE: if there is no secret value for configured ethereum public key, then
 // secrets.foo will return a hcl.Diagnostics that value is not set.
 func DecryptSecrets(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnostics) {
+	return decryptSecrets(ctx, body, options{logger: logutil.Nop()})
+}
+
+func decryptSecrets(ctx *hcl.EvalContext, body hcl.Body, o options) (hcl.Body, hcl.Diagnostics) {
 	content, remain, diags := body.PartialContent(&hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{{Type: secretsBlockName}},
 	})
@@ -84,7 +120,10 @@ func DecryptSecrets(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnost
 		return remain, nil
 	}
 
-	skipDecrypt, _ := strconv.ParseBool(os.Getenv(skipDecryptEnv))
+	skipDecrypt, _ := envutil.Get(skipDecryptEnv, false)
+	if skipDecrypt {
+		o.logger.Warn("Secrets decryption is disabled", "env", skipDecryptEnv)
+	}
 	addr, key, diags := findEthereumKey(ctx, body, skipDecrypt)
 	if diags.HasErrors() {
 		return nil, diags
@@ -122,6 +161,7 @@ func DecryptSecrets(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnost
 		ctx.Variables = make(map[string]cty.Value)
 	}
 	ctx.Variables[varName] = cty.ObjectVal(secrets)
+	o.logger.Debug("Secrets decrypted", "address", addr.String(), "count", len(secrets))
 	return remain, nil
 }
 
@@ -250,7 +290,9 @@ func (l *ethereumBlockLoader) loadAddress(ctx *hcl.EvalContext, attrName string)
 		}}
 	}
 
-	addr, err = types.AddressFromHex(s)
+	// The checksum is not verified, addresses were always accepted
+	// regardless of the letter case.
+	addr, err = ethutil.ParseAddressUnchecked(s)
 	if err != nil {
 		return addr, hcl.Diagnostics{{
 			Severity:    hcl.DiagError,
@@ -334,6 +376,15 @@ func asString(ctx *hcl.EvalContext, attr *hcl.Attribute) (string, error) {
 	return val.AsString(), nil
 }
 
+// addressFromKey parses the address of a secret map key. The checksum is
+// not verified, for the same reason as in the loadAddress method.
+func addressFromKey(k cty.Value) (types.Address, error) {
+	if k.Type() != cty.String {
+		return types.Address{}, fmt.Errorf("wrong key type: expected string, got %s", k.Type().FriendlyName())
+	}
+	return ethutil.ParseAddressUnchecked(k.AsString())
+}
+
 func decryptVariables(
 	ctx *hcl.EvalContext,
 	addr types.Address,
@@ -342,17 +393,6 @@ func decryptVariables(
 	skipDecrypt bool,
 ) (map[string]cty.Value, hcl.Diagnostics) {
 	m := make(map[string]cty.Value)
-	ownAddr := cty.StringVal(strings.ToLower(addr.String()))
-	var privateKey *ecies.PrivateKey
-	if !skipDecrypt {
-		// keyBytes := crypto.FromECDSA(key.PrivateKey())
-		pk, _ := ecies.GenerateKey()
-		pk.PublicKey.X = key.PublicKey().X
-		pk.PublicKey.Y = key.PublicKey().Y
-		pk.D = key.PrivateKey().D
-		privateKey = pk
-	}
-
 	for name, attr := range attrs {
 		value, diags := attr.Expr.Value(ctx)
 		if diags.HasErrors() {
@@ -368,14 +408,22 @@ func decryptVariables(
 		}
 
 		ciphertext := cty.NullVal(cty.String)
-		value.ForEachElement(func(k, v cty.Value) bool {
-			addr := strings.ToLower(k.AsString())
-			if addr == ownAddr.AsString() {
+		for it := value.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			keyAddr, err := addressFromKey(k)
+			if err != nil {
+				return nil, hcl.Diagnostics{{
+					Severity:    hcl.DiagError,
+					Summary:     "Malformed ethereum address in secret key",
+					Detail:      err.Error(),
+					Subject:     attr.Range.Ptr(),
+					EvalContext: ctx,
+				}}
+			}
+			if keyAddr == addr {
 				ciphertext = v
-				return true
 			}
-			return false
-		})
+		}
 
 		if ciphertext.IsNull() {
 			return nil, hcl.Diagnostics{{
@@ -391,18 +439,22 @@ func decryptVariables(
 			continue
 		}
 
-		b, err := hexutil.HexToBytes(ciphertext.AsString())
+		_, b, err := cryptoutil.ParseCiphertext(ciphertext.AsString())
 		if err != nil {
+			summary := "Secret is not hex encoded"
+			if errors.Is(err, cryptoutil.ErrUnknownScheme) {
+				summary = "Secret uses an unknown encryption scheme"
+			}
 			return nil, hcl.Diagnostics{{
 				Severity:    hcl.DiagError,
-				Summary:     "Secret is not hex encoded",
+				Summary:     summary,
 				Detail:      err.Error(),
 				Subject:     attr.Range.Ptr(),
 				EvalContext: ctx,
 			}}
 		}
 
-		plaintext, err := ecies.Decrypt(privateKey, b)
+		plaintext, err := cryptoutil.Decrypt(key.PrivateKey(), b)
 		if err != nil {
 			return nil, hcl.Diagnostics{{
 				Severity:    hcl.DiagError,
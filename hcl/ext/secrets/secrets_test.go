@@ -16,7 +16,9 @@
 package secrets
 
 import (
+	"bytes"
 	"errors"
+	"log/slog"
 	"os"
 	"strconv"
 	"testing"
@@ -54,6 +56,16 @@ func TestVariables(t *testing.T) {
 				"foo": cty.StringVal("<encrypted>"),
 			},
 		},
+		{
+			filename: "./testdata/mixed-case-key.hcl",
+			expectedSecrets: map[string]cty.Value{
+				"foo": cty.StringVal("hello world\n"),
+			},
+		},
+		{
+			filename:    "./testdata/malformed-key.hcl",
+			expectedErr: "Malformed ethereum address in secret key",
+		},
 		{
 			filename:    "./testdata/wrong-key.hcl",
 			expectedErr: "Secret can not be decrypted",
@@ -118,3 +130,20 @@ func TestVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDecryptSecrets_Logger(t *testing.T) {
+	t.Setenv(skipDecryptEnv, "true")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	body, diags := utilHCL.ParseFile("./testdata/valid-skip-decrypt.hcl", nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+	_, diags = NewDecryptSecrets(WithLogger(logger))(&hcl.EvalContext{}, body)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	assert.Contains(t, buf.String(), `level=WARN msg="Secrets decryption is disabled"`)
+	assert.Contains(t, buf.String(), `level=DEBUG msg="Secrets decrypted"`)
+	assert.Contains(t, buf.String(), `count=1`)
+	assert.NotContains(t, buf.String(), "<encrypted>")
+}
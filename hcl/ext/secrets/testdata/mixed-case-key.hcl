@@ -0,0 +1,14 @@
+ethereum {
+  key "default" {
+    address = "0xAFDDAB345f13d74a35d1e97253e042742faf306d"
+	  keystore_path = "./testdata/keystore"
+	  passphrase_file = "./testdata/password.txt"
+  }
+}
+
+secrets {
+  foo = {
+	  "0xAFDDAB345f13d74a35d1e97253e042742faf306d" = "0x04597320390b088c57500dcce6c8006460e665dbb01a2e37dde87243bae899b46cf97585d4dc705330823209a65e6b1058b840026e2299983c6db106cf79389b70621f6cf69d90a1f00a6055a69ab9f97fd264f31e2b84f82413188551c2eba12a5d0b3b32108762fa17d720bb",
+    "0x62c9756f366d2ff882647c3826f55af327681d3c" = "0x04d162904120270fc03e9ad7a0de14f3cf60b7f312087b633fb4fe1a1992135da96692c9fee83e065e66e50a96c550d336065d9cb21203cb4482b61f4211feeae574599cae45a7daab41a33a3f47e929ab790cd7af5fe58df537546e79df3914aa66478e2946243771"
+  }
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package hcl
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// FieldKind is the kind of a struct field with the "hcl" tag.
+type FieldKind int
+
+const (
+	FieldAttr    FieldKind = fieldAttr    // An attribute, the "attr" tag.
+	FieldBlock   FieldKind = fieldBlock   // A block, the "block" tag.
+	FieldLabel   FieldKind = fieldLabel   // A block label, the "label" tag.
+	FieldRemain  FieldKind = fieldRemain  // The remaining body, the "remain" tag.
+	FieldBody    FieldKind = fieldBody    // The block body, the "body" tag.
+	FieldContent FieldKind = fieldContent // The body content, the "content" tag.
+	FieldSchema  FieldKind = fieldSchema  // The body schema, the "schema" tag.
+	FieldRange   FieldKind = fieldRange   // The block range, the "range" tag.
+)
+
+// Field describes a struct field with the "hcl" tag, as interpreted by the
+// Decode function.
+type Field struct {
+	// Name is the name of the attribute, block or label.
+	Name string
+
+	// Kind is the kind of the field.
+	Kind FieldKind
+
+	// Optional is true if the attribute or block is optional.
+	Optional bool
+
+	// Multiple is true if the block can be repeated, which is the case for
+	// slices and maps of structs.
+	Multiple bool
+
+	// Ignore is true if the field is part of the schema, but it is not
+	// decoded.
+	Ignore bool
+
+	// StructField is the struct field.
+	StructField reflect.StructField
+
+	// BlockType is the struct type to which a block is decoded. It is nil
+	// for fields other than blocks.
+	BlockType reflect.Type
+}
+
+// StructFields returns the fields of the given struct type that have the
+// "hcl" tag, in the order they are declared. Fields of embedded structs are
+// included. See the Decode function for the description of the tag.
+//
+// It can be used to inspect the schema used to decode a struct, e.g. to
+// generate documentation. Invalid tags are reported as diagnostics, the
+// same way as by the Decode function.
+func StructFields(typ reflect.Type) ([]Field, hcl.Diagnostics) {
+	typ = derefType(typ)
+	if typ.Kind() != reflect.Struct {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Schema error",
+			Detail:   "Value must be a struct or a pointer to a struct",
+		}}
+	}
+	// Used only to report duplicated names.
+	if _, diags := getStructMeta(typ); diags.HasErrors() {
+		return nil, diags
+	}
+	var fields []Field
+	for _, fieldRef := range reflect.VisibleFields(typ) {
+		meta, diags := getStructFieldMeta(fieldRef)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if !meta.Tagged {
+			continue
+		}
+		fields = append(fields, Field{
+			Name:        meta.Name,
+			Kind:        FieldKind(meta.Type),
+			Optional:    meta.Optional,
+			Multiple:    meta.Multiple,
+			Ignore:      meta.Ignore,
+			StructField: meta.Reflect,
+			BlockType:   meta.StructReflect,
+		})
+	}
+	return fields, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package hcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructFields(t *testing.T) {
+	type block struct {
+		Name string `hcl:"name,label"`
+	}
+	type embedded struct {
+		Embedded string `hcl:"embedded,optional"`
+	}
+	type config struct {
+		embedded
+		Attr     string    `hcl:"attr"`
+		Blocks   []block   `hcl:"block,block"`
+		Remain   hcl.Body  `hcl:",remain"`
+		Range    hcl.Range `hcl:",range"`
+		Untagged string
+	}
+
+	fields, diags := StructFields(reflect.TypeOf(&config{}))
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	var got []string
+	for _, f := range fields {
+		got = append(got, f.Name)
+	}
+	assert.Equal(t, []string{"embedded", "attr", "block", "Remain", "Range"}, got)
+	assert.Equal(t, FieldAttr, fields[0].Kind)
+	assert.True(t, fields[0].Optional)
+	assert.Equal(t, FieldBlock, fields[2].Kind)
+	assert.True(t, fields[2].Multiple)
+	assert.Equal(t, reflect.TypeOf(block{}), fields[2].BlockType)
+	assert.Equal(t, FieldRemain, fields[3].Kind)
+	assert.Equal(t, FieldRange, fields[4].Kind)
+
+	_, diags = StructFields(reflect.TypeOf(""))
+	assert.True(t, diags.HasErrors())
+
+	_, diags = StructFields(reflect.TypeOf(struct {
+		A string `hcl:"a"`
+		B string `hcl:"a"`
+	}{}))
+	assert.True(t, diags.HasErrors())
+}
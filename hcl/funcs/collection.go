@@ -0,0 +1,98 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"errors"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Collections returns a map of functions that operate on lists, maps and
+// other collections. Each call returns a new map.
+//
+// The functions work just like their counterparts in Terraform.
+func Collections() map[string]function.Function {
+	return map[string]function.Function{
+		"length":       Length(),
+		"merge":        stdlib.MergeFunc,
+		"concat":       stdlib.ConcatFunc,
+		"lookup":       stdlib.LookupFunc,
+		"coalesce":     stdlib.CoalesceFunc,
+		"coalescelist": stdlib.CoalesceListFunc,
+		"contains":     stdlib.ContainsFunc,
+		"keys":         stdlib.KeysFunc,
+		"values":       stdlib.ValuesFunc,
+		"element":      stdlib.ElementFunc,
+		"slice":        stdlib.SliceFunc,
+		"flatten":      stdlib.FlattenFunc,
+		"distinct":     stdlib.DistinctFunc,
+		"compact":      stdlib.CompactFunc,
+		"reverse":      stdlib.ReverseListFunc,
+		"sort":         stdlib.SortFunc,
+		"zipmap":       stdlib.ZipmapFunc,
+		"range":        stdlib.RangeFunc,
+	}
+}
+
+// Length returns a function that returns the length of the given string,
+// collection or object. The length of a string is the number of Unicode
+// characters, and the length of an object is the number of its attributes.
+//
+// Unlike the "length" function from the cty standard library, it also
+// accepts strings and objects, just like the "length" function in Terraform.
+func Length() function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the length of the given string, collection or object.",
+		Params: []function.Parameter{
+			{
+				Name:             "value",
+				Description:      "The string, collection or object.",
+				Type:             cty.DynamicPseudoType,
+				AllowDynamicType: true,
+				AllowMarked:      true,
+			},
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			typ := args[0].Type()
+			switch {
+			case typ == cty.String, typ == cty.DynamicPseudoType:
+			case typ.IsObjectType(), typ.IsTupleType():
+			case typ.IsListType(), typ.IsMapType(), typ.IsSetType():
+			default:
+				return cty.NilType, function.NewArgError(0, errors.New("argument must be a string, a collection or an object"))
+			}
+			return cty.Number, nil
+		},
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			val, marks := args[0].Unmark()
+			switch typ := val.Type(); {
+			case typ == cty.String:
+				l, err := stdlib.Strlen(val)
+				if err != nil {
+					return cty.NilVal, err
+				}
+				return l.WithMarks(marks), nil
+			case typ.IsObjectType():
+				return cty.NumberIntVal(int64(len(typ.AttributeTypes()))).WithMarks(marks), nil
+			default:
+				return val.Length().WithMarks(marks), nil
+			}
+		},
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   cty.Value
+		want    cty.Value
+		wantErr bool
+	}{
+		{
+			name:  "string",
+			input: cty.StringVal("zażółć"),
+			want:  cty.NumberIntVal(6),
+		},
+		{
+			name:  "list",
+			input: cty.ListVal([]cty.Value{cty.True, cty.False}),
+			want:  cty.NumberIntVal(2),
+		},
+		{
+			name:  "map",
+			input: cty.MapVal(map[string]cty.Value{"foo": cty.True}),
+			want:  cty.NumberIntVal(1),
+		},
+		{
+			name:  "object",
+			input: cty.ObjectVal(map[string]cty.Value{"foo": cty.True, "bar": cty.NumberIntVal(1)}),
+			want:  cty.NumberIntVal(2),
+		},
+		{
+			name:  "tuple",
+			input: cty.TupleVal([]cty.Value{cty.True, cty.NumberIntVal(1), cty.StringVal("foo")}),
+			want:  cty.NumberIntVal(3),
+		},
+		{
+			name:    "number",
+			input:   cty.NumberIntVal(1),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := Length().Call([]cty.Value{tt.input})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
+
+func TestStringAndCollectionFuncs(t *testing.T) {
+	ctx := &hcl.EvalContext{Functions: Default()}
+	tests := []struct {
+		expr string
+		want cty.Value
+	}{
+		{expr: `format("%s/%s", "BTC", "USD")`, want: cty.StringVal("BTC/USD")},
+		{expr: `join("/", ["BTC", "USD"])`, want: cty.StringVal("BTC/USD")},
+		{expr: `split("/", "BTC/USD")`, want: cty.ListVal([]cty.Value{cty.StringVal("BTC"), cty.StringVal("USD")})},
+		{expr: `lower("BTC")`, want: cty.StringVal("btc")},
+		{expr: `upper("btc")`, want: cty.StringVal("BTC")},
+		{expr: `trim("/BTC/", "/")`, want: cty.StringVal("BTC")},
+		{expr: `trimspace(" BTC ")`, want: cty.StringVal("BTC")},
+		{expr: `merge({a = 1}, {b = 2}).b`, want: cty.NumberIntVal(2)},
+		{expr: `length(concat(["a"], ["b", "c"]))`, want: cty.NumberIntVal(3)},
+		{expr: `lookup({a = "foo"}, "b", "bar")`, want: cty.StringVal("bar")},
+		{expr: `coalesce(null, "foo")`, want: cty.StringVal("foo")},
+		{expr: `length("BTC/USD")`, want: cty.NumberIntVal(7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.expr), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			require.False(t, diags.HasErrors(), diags.Error())
+			val, diags := expr.Value(ctx)
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.True(t, val.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, val)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"io/fs"
+	"maps"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Default returns a map of functions that should be available in every
+// configuration, including the string and collection functions. Each call
+// returns a new map, so it can be safely modified.
+//
+// Functions that require access to files, such as "file" or "remote", are
+// not included, because they need a file system or a protocol. Use
+// NewEvalContext with the WithFS and WithProtocol options to add them.
+func Default() map[string]function.Function {
+	fns := map[string]function.Function{
+		// Type conversion.
+		"tostring": MakeToFunc(cty.String),
+		"tonumber": MakeToFunc(cty.Number),
+		"tobool":   MakeToFunc(cty.Bool),
+		"tolist":   MakeToFunc(cty.List(cty.DynamicPseudoType)),
+		"toset":    MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+		"tomap":    MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+
+		// Encoding.
+		"jsonencode":   JSONEncode(),
+		"jsondecode":   JSONDecode(),
+		"base64encode": Base64Encode(),
+		"base64decode": Base64Decode(),
+		"hexencode":    HexEncode(),
+		"hexdecode":    HexDecode(),
+		"urlencode":    URLEncode(),
+		"urldecode":    URLDecode(),
+
+		// Regular expressions.
+		"regex":        Regex(),
+		"regexall":     RegexAll(),
+		"regexreplace": RegexReplace(),
+
+		// Parsing.
+		"duration": Duration(),
+		"bytesize": ByteSize(),
+
+		// Time.
+		"timestamp":  Timestamp(),
+		"timeadd":    TimeAdd(),
+		"timecmp":    TimeCmp(),
+		"formatdate": FormatDate(),
+
+		// Crypto.
+		"keccak256": Keccak256(),
+		"sha256":    SHA256(),
+
+		// Ethereum.
+		"ethaddress": EthAddress(),
+		"towei":      ToWei(),
+		"fromwei":    FromWei(),
+		"tounits":    ToUnits(),
+		"fromunits":  FromUnits(),
+
+		// Environment.
+		"env":    Env(),
+		"tryenv": TryEnv(),
+
+		// Versioning.
+		"semver": Semver(),
+	}
+	maps.Copy(fns, Strings())
+	maps.Copy(fns, Collections())
+	return fns
+}
+
+// EvalContextOption is an option for NewEvalContext.
+type EvalContextOption func(*hcl.EvalContext)
+
+// WithFunctions adds the given functions to the evaluation context. Functions
+// with the same name as the default ones replace them.
+func WithFunctions(fns map[string]function.Function) EvalContextOption {
+	return func(ctx *hcl.EvalContext) {
+		maps.Copy(ctx.Functions, fns)
+	}
+}
+
+// WithVariables adds the given variables to the evaluation context.
+func WithVariables(vars map[string]cty.Value) EvalContextOption {
+	return func(ctx *hcl.EvalContext) {
+		maps.Copy(ctx.Variables, vars)
+	}
+}
+
+// WithFS adds the "file", "filekeccak256" and "filesha256" functions that
+// read files from the given file system.
+func WithFS(f fs.FS) EvalContextOption {
+	return func(ctx *hcl.EvalContext) {
+		ctx.Functions["file"] = MakeFileFunc(f)
+		ctx.Functions["filekeccak256"] = MakeFileKeccak256Func(f)
+		ctx.Functions["filesha256"] = MakeFileSHA256Func(f)
+	}
+}
+
+// WithProtocol adds the "remote" function that reads files using the given
+// protocol and options, see MakeRemoteFunc.
+func WithProtocol(p Protocol, opts ...RemoteOption) EvalContextOption {
+	return func(ctx *hcl.EvalContext) {
+		ctx.Functions["remote"] = MakeRemoteFunc(p, opts...)
+	}
+}
+
+// NewEvalContext creates a new evaluation context with the default functions
+// and the given options applied.
+func NewEvalContext(opts ...EvalContextOption) *hcl.EvalContext {
+	ctx := &hcl.EvalContext{
+		Functions: Default(),
+		Variables: make(map[string]cty.Value),
+	}
+	for _, opt := range opts {
+		opt(ctx)
+	}
+	return ctx
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestDefault(t *testing.T) {
+	fns := Default()
+	assert.Contains(t, fns, "semver")
+	assert.Contains(t, fns, "jsondecode")
+	assert.Contains(t, fns, "base64decode")
+	assert.Contains(t, fns, "keccak256")
+	assert.NotContains(t, fns, "file")
+	assert.NotContains(t, fns, "remote")
+
+	// Every call must return a new map.
+	delete(fns, "semver")
+	assert.Contains(t, Default(), "semver")
+}
+
+func TestNewEvalContext(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []EvalContextOption
+		expr string
+		want cty.Value
+	}{
+		{
+			name: "default functions",
+			expr: `semver("1.2.3", ">=1.0.0")`,
+			want: cty.True,
+		},
+		{
+			name: "variables",
+			opts: []EvalContextOption{WithVariables(map[string]cty.Value{"foo": cty.StringVal("bar")})},
+			expr: `foo`,
+			want: cty.StringVal("bar"),
+		},
+		{
+			name: "custom functions",
+			opts: []EvalContextOption{WithFunctions(map[string]function.Function{"semver": MakeToFunc(cty.String)})},
+			expr: `semver(1)`,
+			want: cty.StringVal("1"),
+		},
+		{
+			name: "file system",
+			opts: []EvalContextOption{WithFS(os.DirFS("testdata"))},
+			expr: `file("file.txt")`,
+			want: cty.StringVal("hello world\n"),
+		},
+		{
+			name: "protocol",
+			opts: []EvalContextOption{WithProtocol(testProto{fs: os.DirFS("testdata")})},
+			expr: `remote("test://file.txt")`,
+			want: cty.StringVal("hello world\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewEvalContext(tt.opts...)
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.expr), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			require.False(t, diags.HasErrors(), diags.Error())
+			val, diags := expr.Value(ctx)
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.True(t, val.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, val)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package funcs
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Base64Encode returns a function that encodes the given string using the
+// standard base64 encoding, as defined in RFC 4648.
+//
+// It works just like the "base64encode" function in Terraform.
+func Base64Encode() function.Function {
+	return makeEncodeFunc("base64", func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	})
+}
+
+// Base64Decode returns a function that decodes the given string using the
+// standard base64 encoding, as defined in RFC 4648. The decoded data must
+// be valid UTF-8 text.
+//
+// It works just like the "base64decode" function in Terraform.
+func Base64Decode() function.Function {
+	return makeDecodeFunc("base64", base64.StdEncoding.DecodeString)
+}
+
+// HexEncode returns a function that encodes the given string as
+// a lowercase hex string, without the "0x" prefix.
+func HexEncode() function.Function {
+	return makeEncodeFunc("hex", func(s string) string {
+		return hex.EncodeToString([]byte(s))
+	})
+}
+
+// HexDecode returns a function that decodes the given hex string. The
+// string may have the "0x" prefix. The decoded data must be valid UTF-8
+// text.
+func HexDecode() function.Function {
+	return makeDecodeFunc("hex", func(s string) ([]byte, error) {
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			s = s[2:]
+		}
+		return hex.DecodeString(s)
+	})
+}
+
+// URLEncode returns a function that escapes the given string, so it can be
+// safely placed inside a URL query.
+//
+// It works just like the "urlencode" function in Terraform.
+func URLEncode() function.Function {
+	return makeEncodeFunc("URL query", url.QueryEscape)
+}
+
+// URLDecode returns a function that reverses the escaping done by the
+// "urlencode" function.
+func URLDecode() function.Function {
+	return makeDecodeFunc("URL query", func(s string) ([]byte, error) {
+		s, err := url.QueryUnescape(s)
+		return []byte(s), err
+	})
+}
+
+func makeEncodeFunc(name string, encode func(string) string) function.Function {
+	return function.New(&function.Spec{
+		Description: "Encodes the given string using the " + name + " encoding.",
+		Params: []function.Parameter{
+			{
+				Name:        "str",
+				Description: "The string to encode.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			return cty.StringVal(encode(args[0].AsString())), nil
+		},
+	})
+}
+
+func makeDecodeFunc(name string, decode func(string) ([]byte, error)) function.Function {
+	return function.New(&function.Spec{
+		Description: "Decodes the given string using the " + name + " encoding.",
+		Params: []function.Parameter{
+			{
+				Name:        "str",
+				Description: "The string to decode.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			b, err := decode(args[0].AsString())
+			if err != nil {
+				// The argument is not included in the error, because
+				// encoded values are often credentials.
+				return cty.NilVal, function.NewArgErrorf(0, "invalid %s encoded string", name)
+			}
+			if !utf8.Valid(b) {
+				return cty.NilVal, function.NewArgErrorf(0, "decoded data is not valid UTF-8")
+			}
+			return cty.StringVal(string(b)), nil
+		},
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestEncodingFuncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      function.Function
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "base64encode", fn: Base64Encode(), input: "user:p@ss", want: "dXNlcjpwQHNz"},
+		{name: "base64encode empty", fn: Base64Encode(), input: "", want: ""},
+		{name: "base64decode", fn: Base64Decode(), input: "dXNlcjpwQHNz", want: "user:p@ss"},
+		{name: "base64decode invalid", fn: Base64Decode(), input: "dXNlcjpwQHNz!", wantErr: true},
+		{name: "base64decode invalid UTF-8", fn: Base64Decode(), input: "/w==", wantErr: true},
+		{name: "hexencode", fn: HexEncode(), input: "foo", want: "666f6f"},
+		{name: "hexdecode", fn: HexDecode(), input: "666f6f", want: "foo"},
+		{name: "hexdecode prefixed", fn: HexDecode(), input: "0x666F6F", want: "foo"},
+		{name: "hexdecode odd length", fn: HexDecode(), input: "666f6", wantErr: true},
+		{name: "hexdecode invalid UTF-8", fn: HexDecode(), input: "ff", wantErr: true},
+		{name: "urlencode", fn: URLEncode(), input: "a b&c=d/é", want: "a+b%26c%3Dd%2F%C3%A9"},
+		{name: "urldecode", fn: URLDecode(), input: "a+b%26c%3Dd%2F%C3%A9", want: "a b&c=d/é"},
+		{name: "urldecode invalid", fn: URLDecode(), input: "%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.fn.Call([]cty.Value{cty.StringVal(tt.input)})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.NotContains(t, err.Error(), tt.input)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, output.AsString())
+		})
+	}
+}
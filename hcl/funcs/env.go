@@ -0,0 +1,81 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"os"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Env returns a function that returns the value of the environment variable
+// with the given name. If the variable is not set, the function returns an
+// error.
+func Env() function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the value of the environment variable. Fails if the variable is not set.",
+		Params: []function.Parameter{
+			{
+				Name:        "name",
+				Description: "The name of the environment variable.",
+				Type:        cty.String,
+				AllowMarked: true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			name, marks := args[0].Unmark()
+			val, ok := os.LookupEnv(name.AsString())
+			if !ok {
+				return cty.NilVal, function.NewArgErrorf(0, "environment variable %q is not set", name.AsString())
+			}
+			return cty.StringVal(val).WithMarks(marks), nil
+		},
+	})
+}
+
+// TryEnv returns a function that returns the value of the environment
+// variable with the given name. If the variable is not set, the function
+// returns the default value given as the second argument.
+func TryEnv() function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the value of the environment variable or the default value if the variable is not set.",
+		Params: []function.Parameter{
+			{
+				Name:        "name",
+				Description: "The name of the environment variable.",
+				Type:        cty.String,
+				AllowMarked: true,
+			},
+			{
+				Name:        "default",
+				Description: "The value to return if the environment variable is not set.",
+				Type:        cty.String,
+				AllowMarked: true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			name, marks := args[0].Unmark()
+			val, ok := os.LookupEnv(name.AsString())
+			if !ok {
+				return args[1], nil
+			}
+			return cty.StringVal(val).WithMarks(marks), nil
+		},
+	})
+}
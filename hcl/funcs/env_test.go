@@ -0,0 +1,115 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestEnv(t *testing.T) {
+	t.Setenv("GO_LIB_TEST_ENV", "foo")
+	t.Setenv("GO_LIB_TEST_EMPTY_ENV", "")
+
+	tests := []struct {
+		name    string
+		input   cty.Value
+		want    cty.Value
+		wantErr string
+	}{
+		{
+			name:  "set",
+			input: cty.StringVal("GO_LIB_TEST_ENV"),
+			want:  cty.StringVal("foo"),
+		},
+		{
+			name:  "empty",
+			input: cty.StringVal("GO_LIB_TEST_EMPTY_ENV"),
+			want:  cty.StringVal(""),
+		},
+		{
+			name:    "not set",
+			input:   cty.StringVal("GO_LIB_TEST_UNSET_ENV"),
+			wantErr: `environment variable "GO_LIB_TEST_UNSET_ENV" is not set`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := Env().Call([]cty.Value{tt.input})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
+
+func TestEnvDiagnostics(t *testing.T) {
+	expr, diags := hclsyntax.ParseExpression([]byte(`env("GO_LIB_TEST_UNSET_ENV")`), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	_, diags = expr.Value(&hcl.EvalContext{
+		Functions: map[string]function.Function{"env": Env()},
+	})
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "test.hcl:1,6-27", diags[0].Subject.String())
+}
+
+func TestTryEnv(t *testing.T) {
+	t.Setenv("GO_LIB_TEST_ENV", "foo")
+	t.Setenv("GO_LIB_TEST_EMPTY_ENV", "")
+
+	tests := []struct {
+		name  string
+		input cty.Value
+		def   cty.Value
+		want  cty.Value
+	}{
+		{
+			name:  "set",
+			input: cty.StringVal("GO_LIB_TEST_ENV"),
+			def:   cty.StringVal("bar"),
+			want:  cty.StringVal("foo"),
+		},
+		{
+			name:  "empty",
+			input: cty.StringVal("GO_LIB_TEST_EMPTY_ENV"),
+			def:   cty.StringVal("bar"),
+			want:  cty.StringVal(""),
+		},
+		{
+			name:  "not set",
+			input: cty.StringVal("GO_LIB_TEST_UNSET_ENV"),
+			def:   cty.StringVal("bar"),
+			want:  cty.StringVal("bar"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := TryEnv().Call([]cty.Value{tt.input, tt.def})
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/chronicleprotocol/go-lib/hcl/ethutil"
+)
+
+// EthAddress returns a function that validates an Ethereum address and
+// returns it in the EIP-55 checksummed form.
+//
+// The address must be a 20 bytes long hex string, optionally prefixed with
+// "0x". If the address contains both upper and lower case letters, it is
+// treated as checksummed, and the checksum must be valid.
+func EthAddress() function.Function {
+	return function.New(&function.Spec{
+		Description: "Validates an Ethereum address and returns it in the EIP-55 checksummed form.",
+		Params: []function.Parameter{
+			{
+				Name:        "address",
+				Description: "The Ethereum address.",
+				Type:        cty.String,
+				AllowMarked: true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			val, marks := args[0].Unmark()
+			addr, err := ethutil.ParseAddress(val.AsString())
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			return ethutil.AddressVal(addr).WithMarks(marks), nil
+		},
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEthAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   cty.Value
+		want    cty.Value
+		wantErr string
+	}{
+		{
+			name:  "lower case",
+			input: cty.StringVal("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"),
+			want:  cty.StringVal("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"),
+		},
+		{
+			name:  "upper case",
+			input: cty.StringVal("0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"),
+			want:  cty.StringVal("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"),
+		},
+		{
+			name:  "valid checksum",
+			input: cty.StringVal("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"),
+			want:  cty.StringVal("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"),
+		},
+		{
+			name:  "without prefix",
+			input: cty.StringVal("dbf03b407c01e7cd3cbea99509d93f8dddc8c6fb"),
+			want:  cty.StringVal("0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"),
+		},
+		{
+			name:    "invalid checksum",
+			input:   cty.StringVal("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d35A"),
+			wantErr: `invalid address "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d35A": invalid checksum, expected 0xFb6916095CA1dF60bB79cE92Ce3Ea74c37C5D35a`,
+		},
+		{
+			name:    "invalid length",
+			input:   cty.StringVal("0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea"),
+			wantErr: `invalid address "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea": must be 20 bytes long`,
+		},
+		{
+			name:    "invalid hex",
+			input:   cty.StringVal("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaxx"),
+			wantErr: `invalid address "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaxx": must be a hex string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := EthAddress().Call([]cty.Value{tt.input})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Protocol provides a file system and a path within that file system for
+// a given URI.
+//
+// It is compatible with the fsutil.Protocol interface, so any protocol from
+// the fsutil package can be used.
+type Protocol interface {
+	FileSystem(uri *url.URL) (fs fs.FS, path string, err error)
+}
+
+// MakeFileFunc returns a function that reads the contents of a file from the
+// given file system. The path is relative to the root of the file system.
+//
+// The file must contain valid UTF-8 text.
+func MakeFileFunc(f fs.FS) function.Function {
+	return function.New(&function.Spec{
+		Description: "Reads the contents of a file at the given path and returns it as a string.",
+		Params: []function.Parameter{
+			{
+				Name:        "path",
+				Description: "The path to the file, relative to the base directory.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			b, err := readFile(f, args[0].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return fileContent(b)
+		},
+	})
+}
+
+// RemoteOption is an option for MakeRemoteFunc.
+type RemoteOption func(*remoteOptions)
+
+type remoteOptions struct {
+	requireChecksum bool
+}
+
+// WithRemoteRequireChecksum makes the "remote" function refuse to read
+// files with URIs other than "file" that do not have the "checksum"
+// parameter, so the configuration cannot depend on unverified remote
+// content. The checksum itself must be verified by the protocol.
+func WithRemoteRequireChecksum() RemoteOption {
+	return func(o *remoteOptions) {
+		o.requireChecksum = true
+	}
+}
+
+// MakeRemoteFunc returns a function that reads the contents of a file
+// identified by a URI using the given protocol.
+//
+// To verify the integrity of remote files, the protocol should be wrapped
+// with fsutil.NewChecksumProto, so the checksum can be passed in the URI,
+// e.g. "ipfs://<cid>?checksum=0x...". Use the WithRemoteRequireChecksum
+// option to require a checksum for every remote file.
+//
+// The file must contain valid UTF-8 text.
+func MakeRemoteFunc(p Protocol, opts ...RemoteOption) function.Function {
+	var o remoteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return function.New(&function.Spec{
+		Description: "Reads the contents of a file at the given URI and returns it as a string.",
+		Params: []function.Parameter{
+			{
+				Name:        "uri",
+				Description: "The URI of the file.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			uri := args[0].AsString()
+			if !strings.Contains(uri, "://") {
+				return cty.NilVal, function.NewArgErrorf(0, "invalid URI %q: missing scheme", uri)
+			}
+			u, err := url.Parse(uri)
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(0, "invalid URI %q: %s", uri, err)
+			}
+			if o.requireChecksum && u.Scheme != "file" && u.Query().Get("checksum") == "" {
+				return cty.NilVal, function.NewArgErrorf(0, "unverified URI %q: missing checksum", uri)
+			}
+			f, name, err := p.FileSystem(u)
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(0, "cannot open %q: %s", uri, err)
+			}
+			b, err := fs.ReadFile(f, name)
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(0, "cannot read %q: %s", uri, err)
+			}
+			return fileContent(b)
+		},
+	})
+}
+
+// readFile reads the file at the given path, relative to the root of the
+// file system.
+func readFile(f fs.FS, name string) ([]byte, error) {
+	clean := path.Clean(name)
+	if !fs.ValidPath(clean) {
+		return nil, function.NewArgErrorf(0, "invalid path %q", name)
+	}
+	b, err := fs.ReadFile(f, clean)
+	if err != nil {
+		return nil, function.NewArgErrorf(0, "cannot read file: %s", err)
+	}
+	return b, nil
+}
+
+func fileContent(b []byte) (cty.Value, error) {
+	if !utf8.Valid(b) {
+		return cty.NilVal, function.NewArgErrorf(0, "file contents is not valid UTF-8")
+	}
+	return cty.StringVal(string(b)), nil
+}
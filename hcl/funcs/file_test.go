@@ -0,0 +1,143 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestMakeFileFunc(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   cty.Value
+		want    cty.Value
+		wantErr bool
+	}{
+		{
+			name:  "read file",
+			input: cty.StringVal("testdata/file.txt"),
+			want:  cty.StringVal("hello world\n"),
+		},
+		{
+			name:  "relative path",
+			input: cty.StringVal("./testdata/file.txt"),
+			want:  cty.StringVal("hello world\n"),
+		},
+		{
+			name:    "missing file",
+			input:   cty.StringVal("testdata/missing.txt"),
+			wantErr: true,
+		},
+		{
+			name:    "outside of base directory",
+			input:   cty.StringVal("../funcs/testdata/file.txt"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := MakeFileFunc(os.DirFS(".")).Call([]cty.Value{tt.input})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
+
+type testProto struct {
+	fs fs.FS
+}
+
+func (p testProto) FileSystem(uri *url.URL) (fs.FS, string, error) {
+	if uri.Scheme != "test" {
+		return nil, "", errors.New("unexpected scheme")
+	}
+	return p.fs, uri.Host + uri.Path, nil
+}
+
+func TestMakeRemoteFunc(t *testing.T) {
+	proto := testProto{fs: fstest.MapFS{
+		"foo/bar.txt": &fstest.MapFile{Data: []byte("bar")},
+		"foo/bin":     &fstest.MapFile{Data: []byte{0xff, 0xfe}},
+	}}
+	tests := []struct {
+		name    string
+		opts    []RemoteOption
+		input   cty.Value
+		want    cty.Value
+		wantErr bool
+	}{
+		{
+			name:  "read file",
+			input: cty.StringVal("test://foo/bar.txt"),
+			want:  cty.StringVal("bar"),
+		},
+		{
+			name:  "require checksum",
+			opts:  []RemoteOption{WithRemoteRequireChecksum()},
+			input: cty.StringVal("test://foo/bar.txt?checksum=0x01"),
+			want:  cty.StringVal("bar"),
+		},
+		{
+			name:    "require checksum - missing checksum",
+			opts:    []RemoteOption{WithRemoteRequireChecksum()},
+			input:   cty.StringVal("test://foo/bar.txt"),
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			input:   cty.StringVal("test://foo/baz.txt"),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported scheme",
+			input:   cty.StringVal("http://foo/bar.txt"),
+			wantErr: true,
+		},
+		{
+			name:    "missing scheme",
+			input:   cty.StringVal("foo/bar.txt"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid UTF-8",
+			input:   cty.StringVal("test://foo/bin"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := MakeRemoteFunc(proto, tt.opts...).Call([]cty.Value{tt.input})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
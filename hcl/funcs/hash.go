@@ -0,0 +1,105 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io/fs"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/crypto/sha3"
+)
+
+// Keccak256 returns a function that computes the Keccak-256 hash of the given
+// string and returns it as a 0x-prefixed hex string.
+//
+// The result can be used as a checksum for the fsutil checksum file system.
+func Keccak256() function.Function {
+	return makeHashFunc("Keccak-256", sha3.NewLegacyKeccak256)
+}
+
+// SHA256 returns a function that computes the SHA-256 hash of the given
+// string and returns it as a 0x-prefixed hex string.
+func SHA256() function.Function {
+	return makeHashFunc("SHA-256", sha256.New)
+}
+
+// MakeFileKeccak256Func returns a function that computes the Keccak-256 hash
+// of the contents of a file from the given file system and returns it as
+// a 0x-prefixed hex string.
+//
+// Unlike combining the "keccak256" and "file" functions, it also works for
+// files that are not valid UTF-8 text.
+func MakeFileKeccak256Func(f fs.FS) function.Function {
+	return makeFileHashFunc(f, "Keccak-256", sha3.NewLegacyKeccak256)
+}
+
+// MakeFileSHA256Func returns a function that computes the SHA-256 hash of the
+// contents of a file from the given file system and returns it as
+// a 0x-prefixed hex string.
+//
+// Unlike combining the "sha256" and "file" functions, it also works for
+// files that are not valid UTF-8 text.
+func MakeFileSHA256Func(f fs.FS) function.Function {
+	return makeFileHashFunc(f, "SHA-256", sha256.New)
+}
+
+func makeHashFunc(name string, h func() hash.Hash) function.Function {
+	return function.New(&function.Spec{
+		Description: "Computes the " + name + " hash of the given string.",
+		Params: []function.Parameter{
+			{
+				Name:        "str",
+				Description: "The string to hash.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			return hashHex(h, []byte(args[0].AsString())), nil
+		},
+	})
+}
+
+func makeFileHashFunc(f fs.FS, name string, h func() hash.Hash) function.Function {
+	return function.New(&function.Spec{
+		Description: "Computes the " + name + " hash of the contents of the file at the given path.",
+		Params: []function.Parameter{
+			{
+				Name:        "path",
+				Description: "The path to the file, relative to the base directory.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			b, err := readFile(f, args[0].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return hashHex(h, b), nil
+		},
+	})
+}
+
+func hashHex(h func() hash.Hash, b []byte) cty.Value {
+	hh := h()
+	hh.Write(b)
+	return cty.StringVal("0x" + hex.EncodeToString(hh.Sum(nil)))
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestHashFuncs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input cty.Value
+		want  cty.Value
+		fn    function.Function
+	}{
+		{
+			name:  "keccak256",
+			fn:    Keccak256(),
+			input: cty.StringVal("hello world"),
+			want:  cty.StringVal("0x47173285a8d7341e5e972fc677286384f802f8ef42a5ec5f03bbfa254cb01fad"),
+		},
+		{
+			name:  "keccak256 empty",
+			fn:    Keccak256(),
+			input: cty.StringVal(""),
+			want:  cty.StringVal("0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"),
+		},
+		{
+			name:  "sha256",
+			fn:    SHA256(),
+			input: cty.StringVal("hello world"),
+			want:  cty.StringVal("0xb94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.fn.Call([]cty.Value{tt.input})
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
+
+func TestFileHashFuncs(t *testing.T) {
+	f := os.DirFS(".")
+
+	t.Run("sha256", func(t *testing.T) {
+		output, err := MakeFileSHA256Func(f).Call([]cty.Value{cty.StringVal("testdata/file.txt")})
+		require.NoError(t, err)
+		assert.Equal(t, "0xa948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447", output.AsString())
+	})
+
+	t.Run("keccak256", func(t *testing.T) {
+		output, err := MakeFileKeccak256Func(f).Call([]cty.Value{cty.StringVal("testdata/file.txt")})
+		require.NoError(t, err)
+		want, err := Keccak256().Call([]cty.Value{cty.StringVal("hello world\n")})
+		require.NoError(t, err)
+		assert.Equal(t, want.AsString(), output.AsString())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := MakeFileKeccak256Func(f).Call([]cty.Value{cty.StringVal("testdata/missing.txt")})
+		require.Error(t, err)
+	})
+}
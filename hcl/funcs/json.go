@@ -0,0 +1,38 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// JSONEncode returns a function that encodes the given value as a JSON
+// string.
+//
+// It works just like the "jsonencode" function in Terraform.
+func JSONEncode() function.Function {
+	return stdlib.JSONEncodeFunc
+}
+
+// JSONDecode returns a function that decodes the given JSON string into
+// a cty value. Objects are decoded as cty objects and arrays as cty tuples,
+// so the result can be indexed directly in the configuration.
+//
+// It works just like the "jsondecode" function in Terraform.
+func JSONDecode() function.Function {
+	return stdlib.JSONDecodeFunc
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestJSONEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input cty.Value
+		want  cty.Value
+	}{
+		{
+			name:  "string",
+			input: cty.StringVal("foo"),
+			want:  cty.StringVal(`"foo"`),
+		},
+		{
+			name: "object",
+			input: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.NumberIntVal(1),
+				"bar": cty.ListVal([]cty.Value{cty.True, cty.False}),
+			}),
+			want: cty.StringVal(`{"bar":[true,false],"foo":1}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := JSONEncode().Call([]cty.Value{tt.input})
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
+
+func TestJSONDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   cty.Value
+		want    cty.Value
+		wantErr bool
+	}{
+		{
+			name:  "string",
+			input: cty.StringVal(`"foo"`),
+			want:  cty.StringVal("foo"),
+		},
+		{
+			name:  "object",
+			input: cty.StringVal(`{"foo":1,"bar":[true,false]}`),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.NumberIntVal(1),
+				"bar": cty.TupleVal([]cty.Value{cty.True, cty.False}),
+			}),
+		},
+		{
+			name:    "invalid JSON",
+			input:   cty.StringVal(`{"foo":`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := JSONDecode().Call([]cty.Value{tt.input})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
+
+func TestJSONDecodeIndex(t *testing.T) {
+	src := `jsondecode("{\"contracts\":{\"WatRegistry\":\"0x1234\"}}").contracts.WatRegistry`
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	val, diags := expr.Value(&hcl.EvalContext{
+		Functions: map[string]function.Function{"jsondecode": JSONDecode()},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Equal(t, "0x1234", val.AsString())
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/chronicleprotocol/go-lib/bytesize"
+)
+
+// Duration returns a function that parses a duration string, such as "30s",
+// "5m" or "1h30m", and returns the number of seconds. Fractions of seconds are
+// preserved, e.g. "1500ms" returns 1.5.
+//
+// The duration format is the same as the one used by time.ParseDuration.
+func Duration() function.Function {
+	return function.New(&function.Spec{
+		Description: "Parses a duration string and returns the number of seconds.",
+		Params: []function.Parameter{
+			{
+				Name:        "duration",
+				Description: "The duration string, e.g. \"30s\" or \"5m\".",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			d, err := time.ParseDuration(strings.TrimSpace(args[0].AsString()))
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(0, "invalid duration %q", args[0].AsString())
+			}
+			s := new(big.Rat).SetFrac64(int64(d), int64(time.Second))
+			return cty.NumberVal(new(big.Float).SetRat(s)), nil
+		},
+	})
+}
+
+// ByteSize returns a function that parses a human-readable byte size, such
+// as "128MiB" or "1.5GB", and returns the number of bytes.
+//
+// Supported units are B, kB, MB, GB, TB (powers of 1000) and KiB, MiB, GiB,
+// TiB (powers of 1024). A number without a unit is interpreted as bytes.
+// The result must be a whole number of bytes.
+func ByteSize() function.Function {
+	return function.New(&function.Spec{
+		Description: "Parses a human-readable byte size and returns the number of bytes.",
+		Params: []function.Parameter{
+			{
+				Name:        "size",
+				Description: "The byte size string, e.g. \"128MiB\" or \"1.5GB\".",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			n, err := bytesize.Parse(args[0].AsString())
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			return cty.NumberIntVal(int64(n)), nil
+		},
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestParseFuncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      function.Function
+		input   cty.Value
+		want    cty.Value
+		wantErr string
+	}{
+		{
+			name:  "duration seconds",
+			fn:    Duration(),
+			input: cty.StringVal("30s"),
+			want:  cty.NumberIntVal(30),
+		},
+		{
+			name:  "duration minutes",
+			fn:    Duration(),
+			input: cty.StringVal("5m"),
+			want:  cty.NumberIntVal(300),
+		},
+		{
+			name:  "duration compound",
+			fn:    Duration(),
+			input: cty.StringVal("1h30m"),
+			want:  cty.NumberIntVal(5400),
+		},
+		{
+			name:  "duration fraction",
+			fn:    Duration(),
+			input: cty.StringVal("1500ms"),
+			want:  cty.MustParseNumberVal("1.5"),
+		},
+		{
+			name:    "duration invalid",
+			fn:      Duration(),
+			input:   cty.StringVal("5 minutes"),
+			wantErr: `invalid duration "5 minutes"`,
+		},
+		{
+			name:  "bytesize binary",
+			fn:    ByteSize(),
+			input: cty.StringVal("128MiB"),
+			want:  cty.NumberIntVal(128 * 1024 * 1024),
+		},
+		{
+			name:  "bytesize decimal",
+			fn:    ByteSize(),
+			input: cty.StringVal("1.5GB"),
+			want:  cty.NumberIntVal(1500000000),
+		},
+		{
+			name:  "bytesize with space",
+			fn:    ByteSize(),
+			input: cty.StringVal("2 kib"),
+			want:  cty.NumberIntVal(2048),
+		},
+		{
+			name:  "bytesize without unit",
+			fn:    ByteSize(),
+			input: cty.StringVal("512"),
+			want:  cty.NumberIntVal(512),
+		},
+		{
+			name:    "bytesize fractional bytes",
+			fn:      ByteSize(),
+			input:   cty.StringVal("1.5B"),
+			wantErr: `invalid byte size "1.5B": must be a whole number of bytes`,
+		},
+		{
+			name:    "bytesize unknown unit",
+			fn:      ByteSize(),
+			input:   cty.StringVal("1PB"),
+			wantErr: `invalid byte size "1PB": unknown unit "PB"`,
+		},
+		{
+			name:    "bytesize missing number",
+			fn:      ByteSize(),
+			input:   cty.StringVal("MiB"),
+			wantErr: `invalid byte size "MiB"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.fn.Call([]cty.Value{tt.input})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.Equals(tt.want).True(), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Regex returns a function that applies a regular expression to a string
+// and returns the first match. If the pattern has capture groups, the result
+// is a list of captures, or an object if the groups are named.
+//
+// It works just like the "regex" function in Terraform.
+func Regex() function.Function {
+	return stdlib.RegexFunc
+}
+
+// RegexAll returns a function that applies a regular expression to a string
+// and returns a list of all matches.
+//
+// It works just like the "regexall" function in Terraform.
+func RegexAll() function.Function {
+	return stdlib.RegexAllFunc
+}
+
+// RegexReplace returns a function that replaces all matches of a regular
+// expression in a string. The replacement string may reference capture groups
+// using the "$1" or "${name}" syntax.
+func RegexReplace() function.Function {
+	return stdlib.RegexReplaceFunc
+}
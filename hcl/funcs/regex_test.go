@@ -0,0 +1,91 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestRegexFuncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      function.Function
+		args    []cty.Value
+		want    cty.Value
+		wantErr bool
+	}{
+		{
+			name: "regex",
+			fn:   Regex(),
+			args: []cty.Value{cty.StringVal("[A-Z]+"), cty.StringVal("btc/USD")},
+			want: cty.StringVal("USD"),
+		},
+		{
+			name: "regex named groups",
+			fn:   Regex(),
+			args: []cty.Value{cty.StringVal("(?P<base>\\w+)/(?P<quote>\\w+)"), cty.StringVal("BTC/USD")},
+			want: cty.ObjectVal(map[string]cty.Value{
+				"base":  cty.StringVal("BTC"),
+				"quote": cty.StringVal("USD"),
+			}),
+		},
+		{
+			name:    "regex no match",
+			fn:      Regex(),
+			args:    []cty.Value{cty.StringVal("[0-9]+"), cty.StringVal("BTC/USD")},
+			wantErr: true,
+		},
+		{
+			name: "regexall",
+			fn:   RegexAll(),
+			args: []cty.Value{cty.StringVal("[A-Z]+"), cty.StringVal("BTC/USD")},
+			want: cty.ListVal([]cty.Value{cty.StringVal("BTC"), cty.StringVal("USD")}),
+		},
+		{
+			name: "regexall no match",
+			fn:   RegexAll(),
+			args: []cty.Value{cty.StringVal("[0-9]+"), cty.StringVal("BTC/USD")},
+			want: cty.ListValEmpty(cty.String),
+		},
+		{
+			name: "regexreplace",
+			fn:   RegexReplace(),
+			args: []cty.Value{cty.StringVal("https://api.example.com/v1/ticker"), cty.StringVal("^https://([^/]+)/v1/"), cty.StringVal("https://$1/v2/")},
+			want: cty.StringVal("https://api.example.com/v2/ticker"),
+		},
+		{
+			name:    "invalid pattern",
+			fn:      RegexAll(),
+			args:    []cty.Value{cty.StringVal("[A-Z"), cty.StringVal("BTC/USD")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.fn.Call(tt.args)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
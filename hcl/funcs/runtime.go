@@ -0,0 +1,97 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// runtimeVarName is the name of the variable that holds the runtime
+// information.
+const runtimeVarName = "runtime"
+
+// Runtime describes the environment in which the application runs. It is
+// exposed in the evaluation context as the "runtime" variable, so that
+// configurations can depend on it, e.g.:
+//
+//	feeds = runtime.environment == "prod" ? var.prod_feeds : var.test_feeds
+//	enabled = semver(runtime.app_version, ">=1.2.0")
+type Runtime struct {
+	// Hostname is the host name. If empty, os.Hostname is used.
+	Hostname string
+
+	// OS is the operating system. If empty, runtime.GOOS is used.
+	OS string
+
+	// Arch is the architecture. If empty, runtime.GOARCH is used.
+	Arch string
+
+	// AppName is the name of the application.
+	AppName string
+
+	// AppVersion is the version of the application. It can be used along
+	// with the "semver" function.
+	AppVersion string
+
+	// Environment is the name of the environment, like "prod" or "staging".
+	Environment string
+
+	// ChainIDs maps chain names to their IDs.
+	ChainIDs map[string]uint64
+}
+
+// Value returns the runtime information as a cty object with the hostname,
+// os, arch, app_name, app_version, environment and chain_ids attributes.
+func (r Runtime) Value() cty.Value {
+	if r.Hostname == "" {
+		r.Hostname, _ = os.Hostname()
+	}
+	if r.OS == "" {
+		r.OS = runtime.GOOS
+	}
+	if r.Arch == "" {
+		r.Arch = runtime.GOARCH
+	}
+	chainIDs := cty.MapValEmpty(cty.Number)
+	if len(r.ChainIDs) > 0 {
+		m := make(map[string]cty.Value, len(r.ChainIDs))
+		for name, id := range r.ChainIDs {
+			m[name] = cty.NumberUIntVal(id)
+		}
+		chainIDs = cty.MapVal(m)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"hostname":    cty.StringVal(r.Hostname),
+		"os":          cty.StringVal(r.OS),
+		"arch":        cty.StringVal(r.Arch),
+		"app_name":    cty.StringVal(r.AppName),
+		"app_version": cty.StringVal(r.AppVersion),
+		"environment": cty.StringVal(r.Environment),
+		"chain_ids":   chainIDs,
+	})
+}
+
+// WithRuntime adds the "runtime" variable with the given runtime information
+// to the evaluation context.
+func WithRuntime(r Runtime) EvalContextOption {
+	return func(ctx *hcl.EvalContext) {
+		ctx.Variables[runtimeVarName] = r.Value()
+	}
+}
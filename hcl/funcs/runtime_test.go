@@ -0,0 +1,69 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestWithRuntime(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	ctx := NewEvalContext(WithRuntime(Runtime{
+		AppName:     "ghost",
+		AppVersion:  "1.2.3",
+		Environment: "prod",
+		ChainIDs:    map[string]uint64{"eth": 1},
+	}))
+	tests := []struct {
+		expr string
+		want cty.Value
+	}{
+		{expr: `runtime.hostname`, want: cty.StringVal(hostname)},
+		{expr: `runtime.os`, want: cty.StringVal(runtime.GOOS)},
+		{expr: `runtime.arch`, want: cty.StringVal(runtime.GOARCH)},
+		{expr: `runtime.app_name`, want: cty.StringVal("ghost")},
+		{expr: `semver(runtime.app_version, ">=1.2.0")`, want: cty.True},
+		{expr: `runtime.environment == "prod"`, want: cty.True},
+		{expr: `runtime.chain_ids.eth`, want: cty.NumberIntVal(1)},
+		{expr: `lookup(runtime.chain_ids, "arb", 0)`, want: cty.NumberIntVal(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.expr), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			require.False(t, diags.HasErrors(), diags.Error())
+			val, diags := expr.Value(ctx)
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.True(t, val.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, val)
+		})
+	}
+}
+
+func TestRuntime_Value(t *testing.T) {
+	val := Runtime{Hostname: "host", OS: "plan9", Arch: "mips"}.Value()
+	require.Equal(t, "host", val.GetAttr("hostname").AsString())
+	require.Equal(t, "plan9", val.GetAttr("os").AsString())
+	require.Equal(t, "mips", val.GetAttr("arch").AsString())
+	require.Equal(t, 0, val.GetAttr("chain_ids").LengthInt())
+}
@@ -18,22 +18,16 @@ package funcs
 import (
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
-	"golang.org/x/mod/semver"
-)
 
-const (
-	semverLess         string = "<"
-	semverLessEqual    string = "<="
-	semverGreater      string = ">"
-	semverGreaterEqual string = ">="
-	semverEqual        string = "="
-	semverNotEqual     string = "!="
+	"github.com/chronicleprotocol/go-lib/version"
 )
 
+// Semver returns a function that checks if a version satisfies a target
+// constraint, such as ">=1.2.0". The "dev" version is greater than any other
+// version. See version.ParseConstraint for the constraint syntax.
 func Semver() function.Function {
 	spec := function.Spec{
 		Description: "Checks if semver matches target",
@@ -49,7 +43,7 @@ func Semver() function.Function {
 			},
 			{
 				Name:             "target",
-				Description:      "Semver target to check against.",
+				Description:      "Semver target to check against, such as \">=1.2.0\" or \">=1.2.0, <2.0.0\".",
 				Type:             cty.String,
 				AllowNull:        false,
 				AllowUnknown:     false,
@@ -74,98 +68,15 @@ func Semver() function.Function {
 				return cty.NilVal, errors.New("invalid arguments")
 			}
 
-			version := args[0].AsString()
-			target := args[1].AsString()
-
-			if len(version) == 0 {
-				return cty.BoolVal(false), errors.New(`invalid version`)
+			v, err := version.Parse(args[0].AsString())
+			if err != nil {
+				return cty.False, err
 			}
-			if len(target) == 0 {
-				return cty.BoolVal(false), errors.New(`invalid target`)
-			}
-
-			condition := semverEqual
-			switch {
-			// 2 char conditions
-			case strings.HasPrefix(target, semverLessEqual):
-				condition = semverLessEqual
-			case strings.HasPrefix(target, semverGreaterEqual):
-				condition = semverGreaterEqual
-			case strings.HasPrefix(target, semverNotEqual):
-				condition = semverNotEqual
-			// 1 char conditions
-			case strings.HasPrefix(target, semverLess):
-				condition = semverLess
-			case strings.HasPrefix(target, semverGreater):
-				condition = semverGreater
-			case strings.HasPrefix(target, semverEqual):
-				condition = semverEqual
-			default:
+			c, err := version.ParseConstraint(args[1].AsString())
+			if err != nil {
+				return cty.False, fmt.Errorf("invalid target: %w", err)
 			}
-
-			// Special case, dev version.
-			// The dev version is always higher than any other version.
-			if version == "dev" {
-				switch condition {
-				case semverLess:
-					return cty.False, nil
-				case semverLessEqual:
-					return cty.False, nil
-				case semverGreater:
-					return cty.True, nil
-				case semverGreaterEqual:
-					return cty.True, nil
-				case semverEqual:
-					return cty.False, nil
-				case semverNotEqual:
-					return cty.True, nil
-				}
-			}
-
-			target = strings.TrimPrefix(target, condition)   // remove condition from target
-			version = "v" + strings.TrimPrefix(version, "v") // add v prefix to version if missing
-			target = "v" + strings.TrimPrefix(target, "v")   // add v prefix to target if missing
-
-			if !semver.IsValid(version) {
-				return cty.BoolVal(false), fmt.Errorf("invalid version %q", version[1:])
-			}
-			if !semver.IsValid(target) {
-				return cty.BoolVal(false), fmt.Errorf("invalid target %q", target[1:])
-			}
-
-			switch semver.Compare(version, target) {
-			case -1:
-				switch condition {
-				case semverLess:
-					return cty.True, nil
-				case semverLessEqual:
-					return cty.True, nil
-				default:
-					return cty.False, nil
-				}
-			case 0:
-				switch condition {
-				case semverLessEqual:
-					return cty.True, nil
-				case semverGreaterEqual:
-					return cty.True, nil
-				case semverEqual:
-					return cty.True, nil
-				default:
-					return cty.False, nil
-				}
-			case 1:
-				switch condition {
-				case semverGreater:
-					return cty.True, nil
-				case semverGreaterEqual:
-					return cty.True, nil
-				default:
-					return cty.False, nil
-				}
-			}
-
-			panic("unreachable")
+			return cty.BoolVal(c.Check(v)), nil
 		},
 	}
 	return function.New(&spec)
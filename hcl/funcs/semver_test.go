@@ -162,6 +162,36 @@ func TestSemver(t *testing.T) {
 			Target:   cty.StringVal(">1.2.3"),
 			Expected: cty.True,
 		},
+		{
+			Name:     "range match",
+			Version:  cty.StringVal("1.5.0"),
+			Target:   cty.StringVal(">=1.2.0, <2.0.0"),
+			Expected: cty.True,
+		},
+		{
+			Name:     "range not match",
+			Version:  cty.StringVal("2.0.0"),
+			Target:   cty.StringVal(">=1.2.0, <2.0.0"),
+			Expected: cty.False,
+		},
+		{
+			Name:     "dev version equal dev",
+			Version:  cty.StringVal("dev"),
+			Target:   cty.StringVal("dev"),
+			Expected: cty.True,
+		},
+		{
+			Name:     "dev version not equal dev",
+			Version:  cty.StringVal("dev"),
+			Target:   cty.StringVal("!=dev"),
+			Expected: cty.False,
+		},
+		{
+			Name:      "dev version invalid target",
+			Version:   cty.StringVal("dev"),
+			Target:    cty.StringVal(">1.2.hello"),
+			ExpectErr: "invalid target",
+		},
 	}
 	for _, tc := range tt {
 		t.Run(tc.Name, func(t *testing.T) {
@@ -0,0 +1,77 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestStrings(t *testing.T) {
+	ctx := &hcl.EvalContext{Functions: Strings()}
+	tests := []struct {
+		expr    string
+		want    cty.Value
+		wantErr bool
+	}{
+		{expr: `format("%s/%s", "BTC", "USD")`, want: cty.StringVal("BTC/USD")},
+		{expr: `format("%d", "BTC")`, wantErr: true},
+		{expr: `formatlist("%s/USD", ["BTC", "ETH"])`, want: cty.ListVal([]cty.Value{cty.StringVal("BTC/USD"), cty.StringVal("ETH/USD")})},
+		{expr: `join("/", ["BTC", "USD"])`, want: cty.StringVal("BTC/USD")},
+		{expr: `join("/", [])`, want: cty.StringVal("")},
+		{expr: `split("/", "BTC/USD")`, want: cty.ListVal([]cty.Value{cty.StringVal("BTC"), cty.StringVal("USD")})},
+		{expr: `lower("BTC")`, want: cty.StringVal("btc")},
+		{expr: `upper("btc")`, want: cty.StringVal("BTC")},
+		{expr: `title("bitcoin price")`, want: cty.StringVal("Bitcoin Price")},
+		{expr: `trim("/BTC/", "/")`, want: cty.StringVal("BTC")},
+		{expr: `trimspace(" BTC\n")`, want: cty.StringVal("BTC")},
+		{expr: `trimprefix("BTC/USD", "BTC/")`, want: cty.StringVal("USD")},
+		{expr: `trimsuffix("BTC/USD", "/USD")`, want: cty.StringVal("BTC")},
+		{expr: `chomp("BTC\n\n")`, want: cty.StringVal("BTC")},
+		{expr: `indent(2, "a\nb")`, want: cty.StringVal("a\n  b")},
+		{expr: `replace("BTC-USD", "-", "/")`, want: cty.StringVal("BTC/USD")},
+		{expr: `replace("/[A-Z]+/", "/[A-Z]+/", "X")`, want: cty.StringVal("X")},
+		{expr: `substr("BTC/USD", 4, 3)`, want: cty.StringVal("USD")},
+		{expr: `substr("BTC/USD", -3, -1)`, want: cty.StringVal("USD")},
+		{expr: `strlen("zażółć")`, want: cty.NumberIntVal(6)},
+		{expr: `strrev("BTC")`, want: cty.StringVal("CTB")},
+		{expr: `upper(1)`, want: cty.StringVal("1")},
+		{expr: `upper(["BTC"])`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.expr), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			require.False(t, diags.HasErrors(), diags.Error())
+			val, diags := expr.Value(ctx)
+			if tt.wantErr {
+				require.True(t, diags.HasErrors())
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.True(t, val.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, val)
+		})
+	}
+}
+
+func TestStrings_NewMap(t *testing.T) {
+	m := Strings()
+	delete(m, "format")
+	assert.Contains(t, Strings(), "format")
+}
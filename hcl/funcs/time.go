@@ -0,0 +1,98 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Timestamp returns a function that returns the current time as an RFC 3339
+// string in UTC.
+//
+// The time is taken at the moment of evaluation, so the result may differ
+// between evaluations.
+func Timestamp() function.Function {
+	return MakeTimestampFunc(time.Now)
+}
+
+// MakeTimestampFunc works like Timestamp but uses the given function to
+// obtain the current time. It is useful for reproducible tests.
+func MakeTimestampFunc(now func() time.Time) function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the current time as an RFC 3339 string in UTC.",
+		Params:      []function.Parameter{},
+		Type:        function.StaticReturnType(cty.String),
+		Impl: func(_ []cty.Value, _ cty.Type) (cty.Value, error) {
+			return cty.StringVal(now().UTC().Format(time.RFC3339)), nil
+		},
+	})
+}
+
+// TimeAdd returns a function that adds a duration to an RFC 3339 timestamp,
+// e.g. timeadd(timestamp(), "24h"). Negative durations are allowed.
+//
+// It works just like the "timeadd" function in Terraform.
+func TimeAdd() function.Function {
+	return stdlib.TimeAddFunc
+}
+
+// FormatDate returns a function that formats an RFC 3339 timestamp using
+// the given format specification, e.g. formatdate("YYYY-MM-DD", timestamp()).
+//
+// It works just like the "formatdate" function in Terraform.
+func FormatDate() function.Function {
+	return stdlib.FormatDateFunc
+}
+
+// TimeCmp returns a function that compares two RFC 3339 timestamps. It
+// returns -1 if the first timestamp is before the second one, 0 if they are
+// equal, and 1 if it is after.
+//
+// It allows to express validity windows, e.g.
+// timecmp(timestamp(), "2025-01-01T00:00:00Z") >= 0.
+func TimeCmp() function.Function {
+	return function.New(&function.Spec{
+		Description: "Compares two RFC 3339 timestamps.",
+		Params: []function.Parameter{
+			{
+				Name:        "timestamp_a",
+				Description: "The first timestamp.",
+				Type:        cty.String,
+			},
+			{
+				Name:        "timestamp_b",
+				Description: "The second timestamp.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			a, err := time.Parse(time.RFC3339, args[0].AsString())
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(0, "invalid timestamp %q", args[0].AsString())
+			}
+			b, err := time.Parse(time.RFC3339, args[1].AsString())
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(1, "invalid timestamp %q", args[1].AsString())
+			}
+			return cty.NumberIntVal(int64(a.Compare(b))), nil
+		},
+	})
+}
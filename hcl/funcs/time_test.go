@@ -0,0 +1,98 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestTimeFuncs(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2025, 3, 14, 15, 9, 26, 0, time.FixedZone("CET", 3600))
+	}
+	tests := []struct {
+		name    string
+		fn      function.Function
+		args    []cty.Value
+		want    cty.Value
+		wantErr bool
+	}{
+		{
+			name: "timestamp",
+			fn:   MakeTimestampFunc(now),
+			args: []cty.Value{},
+			want: cty.StringVal("2025-03-14T14:09:26Z"),
+		},
+		{
+			name: "timeadd",
+			fn:   TimeAdd(),
+			args: []cty.Value{cty.StringVal("2025-03-14T14:09:26Z"), cty.StringVal("24h")},
+			want: cty.StringVal("2025-03-15T14:09:26Z"),
+		},
+		{
+			name: "timeadd negative",
+			fn:   TimeAdd(),
+			args: []cty.Value{cty.StringVal("2025-03-14T14:09:26Z"), cty.StringVal("-10m")},
+			want: cty.StringVal("2025-03-14T13:59:26Z"),
+		},
+		{
+			name: "formatdate",
+			fn:   FormatDate(),
+			args: []cty.Value{cty.StringVal("YYYY-MM-DD hh:mm"), cty.StringVal("2025-03-14T14:09:26Z")},
+			want: cty.StringVal("2025-03-14 14:09"),
+		},
+		{
+			name: "timecmp before",
+			fn:   TimeCmp(),
+			args: []cty.Value{cty.StringVal("2025-03-14T14:09:26Z"), cty.StringVal("2025-03-14T15:00:00Z")},
+			want: cty.NumberIntVal(-1),
+		},
+		{
+			name: "timecmp equal",
+			fn:   TimeCmp(),
+			args: []cty.Value{cty.StringVal("2025-03-14T14:09:26Z"), cty.StringVal("2025-03-14T15:09:26+01:00")},
+			want: cty.NumberIntVal(0),
+		},
+		{
+			name: "timecmp after",
+			fn:   TimeCmp(),
+			args: []cty.Value{cty.StringVal("2025-03-15T00:00:00Z"), cty.StringVal("2025-03-14T14:09:26Z")},
+			want: cty.NumberIntVal(1),
+		},
+		{
+			name:    "timecmp invalid",
+			fn:      TimeCmp(),
+			args:    []cty.Value{cty.StringVal("2025-03-15"), cty.StringVal("2025-03-14T14:09:26Z")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.fn.Call(tt.args)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.RawEquals(tt.want), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
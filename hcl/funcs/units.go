@@ -0,0 +1,220 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/chronicleprotocol/go-lib/hcl/bigutil"
+)
+
+// maxDecimals is the maximum number of decimals supported by the unit
+// conversion functions.
+const maxDecimals = 255
+
+// ethUnits maps Ethereum unit names to the number of decimals.
+var ethUnits = map[string]int64{
+	"wei":    0,
+	"kwei":   3,
+	"mwei":   6,
+	"gwei":   9,
+	"szabo":  12,
+	"finney": 15,
+	"ether":  18,
+}
+
+// ToWei returns a function that converts an amount in the given Ethereum unit
+// to wei, e.g. towei("1.5", "ether") returns 1500000000000000000.
+//
+// The amount is given as a string to avoid precision loss. The result must be
+// an integer, otherwise an error is returned.
+func ToWei() function.Function {
+	return function.New(&function.Spec{
+		Description: "Converts an amount in the given Ethereum unit to wei.",
+		Params: []function.Parameter{
+			{
+				Name:        "amount",
+				Description: "The amount to convert.",
+				Type:        cty.String,
+			},
+			{
+				Name:        "unit",
+				Description: "The Ethereum unit of the amount, e.g. \"ether\" or \"gwei\".",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			dec, err := ethUnitDecimals(args[1])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return toUnits(args[0], dec)
+		},
+	})
+}
+
+// FromWei returns a function that converts an amount in wei to the given
+// Ethereum unit, e.g. fromwei("1500000000000000000", "ether") returns 1.5.
+func FromWei() function.Function {
+	return function.New(&function.Spec{
+		Description: "Converts an amount in wei to the given Ethereum unit.",
+		Params: []function.Parameter{
+			{
+				Name:        "value",
+				Description: "The amount in wei to convert.",
+				Type:        cty.String,
+			},
+			{
+				Name:        "unit",
+				Description: "The Ethereum unit to convert to, e.g. \"ether\" or \"gwei\".",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			dec, err := ethUnitDecimals(args[1])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return fromUnits(args[0], dec)
+		},
+	})
+}
+
+// ToUnits returns a function that converts a decimal amount to the smallest
+// units of a token with the given number of decimals, e.g.
+// tounits("1.5", 6) returns 1500000.
+//
+// The amount is given as a string to avoid precision loss. The result must be
+// an integer, otherwise an error is returned.
+func ToUnits() function.Function {
+	return function.New(&function.Spec{
+		Description: "Converts a decimal amount to the smallest units of a token with the given number of decimals.",
+		Params: []function.Parameter{
+			{
+				Name:        "amount",
+				Description: "The amount to convert.",
+				Type:        cty.String,
+			},
+			{
+				Name:        "decimals",
+				Description: "The number of decimals of the token.",
+				Type:        cty.Number,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			dec, err := decimals(args[1])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return toUnits(args[0], dec)
+		},
+	})
+}
+
+// FromUnits returns a function that converts an amount in the smallest units
+// of a token with the given number of decimals to a decimal amount, e.g.
+// fromunits("1500000", 6) returns 1.5.
+func FromUnits() function.Function {
+	return function.New(&function.Spec{
+		Description: "Converts an amount in the smallest units of a token with the given number of decimals to a decimal amount.",
+		Params: []function.Parameter{
+			{
+				Name:        "value",
+				Description: "The amount in the smallest units to convert.",
+				Type:        cty.String,
+			},
+			{
+				Name:        "decimals",
+				Description: "The number of decimals of the token.",
+				Type:        cty.Number,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			dec, err := decimals(args[1])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return fromUnits(args[0], dec)
+		},
+	})
+}
+
+// toUnits multiplies the amount by 10^dec. The result must be an integer.
+func toUnits(amount cty.Value, dec int64) (cty.Value, error) {
+	i, err := bigutil.ParseUnits(strings.TrimSpace(amount.AsString()), int(dec))
+	if err != nil {
+		return cty.NilVal, function.NewArgError(0, err)
+	}
+	return bigutil.IntVal(i), nil
+}
+
+// fromUnits divides the value by 10^dec.
+func fromUnits(value cty.Value, dec int64) (cty.Value, error) {
+	r, err := parseRat(value)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	r.Quo(r, new(big.Rat).SetInt(pow10(dec)))
+	f, err := cty.ParseNumberVal(r.FloatString(int(dec)))
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return f, nil
+}
+
+// parseRat parses the amount given as the first argument.
+func parseRat(v cty.Value) (*big.Rat, error) {
+	r, err := bigutil.ParseRat(strings.TrimSpace(v.AsString()))
+	if err != nil {
+		return nil, function.NewArgError(0, err)
+	}
+	return r, nil
+}
+
+// ethUnitDecimals returns the number of decimals for the unit given as the
+// second argument.
+func ethUnitDecimals(v cty.Value) (int64, error) {
+	dec, ok := ethUnits[strings.ToLower(v.AsString())]
+	if !ok {
+		return 0, function.NewArgErrorf(1, "unknown unit %q", v.AsString())
+	}
+	return dec, nil
+}
+
+// decimals validates the number of decimals given as the second argument.
+func decimals(v cty.Value) (int64, error) {
+	bf := v.AsBigFloat()
+	if !bf.IsInt() {
+		return 0, function.NewArgErrorf(1, "decimals must be an integer")
+	}
+	dec, _ := bf.Int64()
+	if dec < 0 || dec > maxDecimals {
+		return 0, function.NewArgErrorf(1, "decimals must be between 0 and %d", maxDecimals)
+	}
+	return dec, nil
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestUnitFuncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      function.Function
+		args    []cty.Value
+		want    cty.Value
+		wantErr string
+	}{
+		{
+			name: "toWei ether",
+			fn:   ToWei(),
+			args: []cty.Value{cty.StringVal("1.5"), cty.StringVal("ether")},
+			want: cty.MustParseNumberVal("1500000000000000000"),
+		},
+		{
+			name: "toWei gwei",
+			fn:   ToWei(),
+			args: []cty.Value{cty.StringVal("30"), cty.StringVal("GWEI")},
+			want: cty.MustParseNumberVal("30000000000"),
+		},
+		{
+			name: "toWei large amount",
+			fn:   ToWei(),
+			args: []cty.Value{cty.StringVal("123456789012345678901234567890.123456789012345678"), cty.StringVal("ether")},
+			want: cty.MustParseNumberVal("123456789012345678901234567890123456789012345678"),
+		},
+		{
+			name:    "toWei fractional wei",
+			fn:      ToWei(),
+			args:    []cty.Value{cty.StringVal("1.5"), cty.StringVal("wei")},
+			wantErr: "amount 1.5 has more than 0 decimal places",
+		},
+		{
+			name:    "toWei unknown unit",
+			fn:      ToWei(),
+			args:    []cty.Value{cty.StringVal("1"), cty.StringVal("foo")},
+			wantErr: `unknown unit "foo"`,
+		},
+		{
+			name:    "toWei invalid amount",
+			fn:      ToWei(),
+			args:    []cty.Value{cty.StringVal("1,5"), cty.StringVal("ether")},
+			wantErr: `invalid number "1,5"`,
+		},
+		{
+			name: "fromWei",
+			fn:   FromWei(),
+			args: []cty.Value{cty.StringVal("1500000000000000000"), cty.StringVal("ether")},
+			want: cty.MustParseNumberVal("1.5"),
+		},
+		{
+			name: "toUnits",
+			fn:   ToUnits(),
+			args: []cty.Value{cty.StringVal("1.5"), cty.NumberIntVal(6)},
+			want: cty.NumberIntVal(1500000),
+		},
+		{
+			name:    "toUnits too many decimal places",
+			fn:      ToUnits(),
+			args:    []cty.Value{cty.StringVal("1.0000005"), cty.NumberIntVal(6)},
+			wantErr: "amount 1.0000005 has more than 6 decimal places",
+		},
+		{
+			name:    "toUnits negative decimals",
+			fn:      ToUnits(),
+			args:    []cty.Value{cty.StringVal("1"), cty.NumberIntVal(-1)},
+			wantErr: "decimals must be between 0 and 255",
+		},
+		{
+			name:    "toUnits fractional decimals",
+			fn:      ToUnits(),
+			args:    []cty.Value{cty.StringVal("1"), cty.NumberFloatVal(1.5)},
+			wantErr: "decimals must be an integer",
+		},
+		{
+			name: "fromUnits",
+			fn:   FromUnits(),
+			args: []cty.Value{cty.StringVal("1500000"), cty.NumberIntVal(6)},
+			want: cty.MustParseNumberVal("1.5"),
+		},
+		{
+			name: "fromUnits zero decimals",
+			fn:   FromUnits(),
+			args: []cty.Value{cty.StringVal("42"), cty.NumberIntVal(0)},
+			want: cty.NumberIntVal(42),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.fn.Call(tt.args)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, output.Equals(tt.want).True(), "expected output %#v, but got %#v", tt.want, output)
+		})
+	}
+}
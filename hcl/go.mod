@@ -3,14 +3,13 @@ module github.com/chronicleprotocol/go-lib/hcl
 go 1.24.0
 
 require (
-	github.com/chronicleprotocol/ecies v0.0.0-20241017151548-381690fa1131
-	github.com/chronicleprotocol/go-lib v0.57.1
+	github.com/chronicleprotocol/go-lib v0.57.2-0.20261016130621-f1c58982d4e9
 	github.com/defiweb/go-anymapper v0.3.0
 	github.com/defiweb/go-eth v0.7.0
 	github.com/hashicorp/hcl/v2 v2.23.0
 	github.com/stretchr/testify v1.10.0
 	github.com/zclconf/go-cty v1.16.2
-	golang.org/x/mod v0.24.0
+	golang.org/x/crypto v0.37.0
 )
 
 require (
@@ -20,17 +19,18 @@ require (
 	github.com/btcsuite/btcd/btcec/v2 v2.3.2 // indirect
 	github.com/btcsuite/btcd/btcutil v1.1.5 // indirect
 	github.com/btcsuite/btcd/chaincfg/chainhash v1.1.0 // indirect
+	github.com/chronicleprotocol/ecies v0.0.0-20241017151548-381690fa1131 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.3.0 // indirect
 	github.com/defiweb/go-rlp v0.3.0 // indirect
 	github.com/google/go-cmp v0.6.0 // indirect
-	github.com/kr/text v0.2.0 // indirect
 	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/tyler-smith/go-bip39 v1.1.0 // indirect
-	golang.org/x/crypto v0.21.0 // indirect
-	golang.org/x/sys v0.18.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
-	golang.org/x/tools v0.13.0 // indirect
+	golang.org/x/mod v0.24.0 // indirect
+	golang.org/x/sync v0.13.0 // indirect
+	golang.org/x/sys v0.32.0 // indirect
+	golang.org/x/text v0.24.0 // indirect
+	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
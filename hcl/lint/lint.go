@@ -0,0 +1,106 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package lint checks HCL configurations for mistakes that are not reported
+// by the decoder, or are reported only after all extensions were applied.
+//
+// The checks are implemented as rules. Lint runs the given rules, or the
+// default ones, and returns their findings as diagnostics, so that they can
+// be printed in the same way as decoding errors:
+//
+//	body, diags := utilHCL.ParseFile("config.hcl", nil)
+//	diags = diags.Extend(lint.Lint(body))
+//
+// Rules inspect the configuration source, so the body must be parsed from
+// the native HCL syntax. Bodies merged using utilHCL.Merge are supported,
+// and every merged body is inspected. Other kinds of bodies are skipped.
+package lint
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Rule checks a configuration body and reports problems as diagnostics.
+type Rule interface {
+	// Name returns a short, unique name of the rule, such as
+	// "unused-variables".
+	Name() string
+
+	// Check checks the body and returns the problems found.
+	Check(body hcl.Body) hcl.Diagnostics
+}
+
+// NewRule returns a rule with the given name that uses the check function
+// to check bodies.
+func NewRule(name string, check func(body hcl.Body) hcl.Diagnostics) Rule {
+	return &funcRule{name: name, check: check}
+}
+
+// DefaultRules returns the rules used by Lint when no rules are given:
+// UnusedVariables, UndeclaredSecrets and DuplicateLabels.
+func DefaultRules() []Rule {
+	return []Rule{
+		UnusedVariables(),
+		UndeclaredSecrets(),
+		DuplicateLabels(),
+	}
+}
+
+// Lint checks the body using the given rules. If no rules are given, the
+// rules returned by DefaultRules are used.
+//
+// The name of the rule that reported a diagnostic can be obtained using
+// the RuleName function.
+func Lint(body hcl.Body, rules ...Rule) hcl.Diagnostics {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	var diags hcl.Diagnostics
+	for _, rule := range rules {
+		for _, diag := range rule.Check(body) {
+			if diag.Extra == nil {
+				diag.Extra = ruleExtra{name: rule.Name()}
+			}
+			diags = diags.Append(diag)
+		}
+	}
+	return diags
+}
+
+// RuleName returns the name of the rule that reported the diagnostic. It
+// returns an empty string if the diagnostic was not returned by Lint.
+func RuleName(diag *hcl.Diagnostic) string {
+	if e, ok := hcl.DiagnosticExtra[ruleExtra](diag); ok {
+		return e.name
+	}
+	return ""
+}
+
+// ruleExtra is stored in the Extra field of diagnostics returned by Lint.
+type ruleExtra struct {
+	name string
+}
+
+type funcRule struct {
+	name  string
+	check func(body hcl.Body) hcl.Diagnostics
+}
+
+func (r *funcRule) Name() string {
+	return r.name
+}
+
+func (r *funcRule) Check(body hcl.Body) hcl.Diagnostics {
+	return r.check(body)
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package lint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+	"github.com/chronicleprotocol/go-lib/hcl/ext/deprecated"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        []string
+		rules        []Rule
+		expectedDiag []string
+	}{
+		{
+			name:  "unused variables",
+			files: []string{"config.hcl"},
+			rules: []Rule{UnusedVariables()},
+			expectedDiag: []string{
+				`config.hcl:5,3-9: Unused variable; The variable "unused" is declared but never used.`,
+				`config.hcl:6,3-7: Unused variable; The variable "self" is declared but never used.`,
+			},
+		},
+		{
+			name:  "undeclared secrets",
+			files: []string{"config.hcl"},
+			rules: []Rule{UndeclaredSecrets()},
+			expectedDiag: []string{
+				`config.hcl:22,13-26: Undeclared secret; The secret "token" is not declared in the "secrets" block.`,
+				`config.hcl:27,12-31: Undeclared secret; The secret "password" is not declared in the "secrets" block.`,
+			},
+		},
+		{
+			name:  "duplicate labels",
+			files: []string{"config.hcl"},
+			rules: []Rule{DuplicateLabels()},
+			expectedDiag: []string{
+				`config.hcl:30,1-11: Duplicate block; A "feed" block with labels "eth" was already defined at config.hcl:26,1-11.`,
+				`config.hcl:18,3-19: Duplicate block; A "client" block with labels "default" was already defined at config.hcl:14,3-19.`,
+			},
+		},
+		{
+			name:  "deprecated",
+			files: []string{"config.hcl"},
+			rules: []Rule{Deprecated(deprecated.NewRegistry().Block("feed.*", "data_source"))},
+			expectedDiag: []string{
+				`config.hcl:26,1-5: Deprecated block; The "feed" block is deprecated and will be removed in a future release. Use "data_source" instead.`,
+				`config.hcl:30,1-5: Deprecated block; The "feed" block is deprecated and will be removed in a future release. Use "data_source" instead.`,
+			},
+		},
+		{
+			name:  "clean",
+			files: []string{"clean.hcl"},
+		},
+		{
+			name:  "merged bodies",
+			files: []string{"config.hcl", "override.hcl"},
+			rules: []Rule{UnusedVariables()},
+			expectedDiag: []string{
+				`config.hcl:6,3-7: Unused variable; The variable "self" is declared but never used.`,
+			},
+		},
+		{
+			name:  "custom rule",
+			files: []string{"clean.hcl"},
+			rules: []Rule{NewRule("custom", func(body hcl.Body) hcl.Diagnostics {
+				return hcl.Diagnostics{{Severity: hcl.DiagWarning, Summary: "Custom"}}
+			})},
+			expectedDiag: []string{`<nil>: Custom; `},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []hcl.Body
+			for _, f := range tt.files {
+				body, diags := utilHCL.ParseFileFS(os.DirFS("testdata"), f, nil)
+				require.False(t, diags.HasErrors(), diags.Error())
+				bodies = append(bodies, body)
+			}
+			diags := Lint(utilHCL.Merge(bodies...), tt.rules...)
+			require.Len(t, diags, len(tt.expectedDiag), diags.Error())
+			for i, d := range diags {
+				assert.Equal(t, tt.expectedDiag[i], d.Error())
+				assert.Equal(t, tt.rules[0].Name(), RuleName(d))
+			}
+		})
+	}
+}
+
+func TestLint_DefaultRules(t *testing.T) {
+	body, diags := utilHCL.ParseFile("testdata/config.hcl", nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	diags = Lint(body)
+	var names []string
+	for _, d := range diags {
+		names = append(names, RuleName(d))
+	}
+	assert.Equal(t, []string{
+		"unused-variables",
+		"unused-variables",
+		"undeclared-secrets",
+		"undeclared-secrets",
+		"duplicate-labels",
+		"duplicate-labels",
+	}, names)
+	assert.True(t, diags.HasErrors())
+}
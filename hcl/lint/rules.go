@@ -0,0 +1,197 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/chronicleprotocol/go-lib/hcl/ext/deprecated"
+)
+
+const (
+	// variablesBlockName and variablesObjectName must match the names used
+	// by the variables extension.
+	variablesBlockName  = "variables"
+	variablesObjectName = "var"
+
+	// secretsBlockName and secretsObjectName must match the names used by
+	// the secrets extension.
+	secretsBlockName  = "secrets"
+	secretsObjectName = "secrets"
+
+	// dynamicBlockName is the type of blocks expanded by the dynblock
+	// extension.
+	dynamicBlockName = "dynamic"
+)
+
+// UnusedVariables returns a rule that reports variables declared in the
+// "variables" block that are never referenced, neither through the "var"
+// object nor by other variables.
+func UnusedVariables() Rule {
+	return NewRule("unused-variables", func(body hcl.Body) hcl.Diagnostics {
+		var (
+			declared []*hclsyntax.Attribute
+			used     = make(map[string]bool)
+		)
+		for _, block := range topLevelBlocks(body, variablesBlockName) {
+			for _, attr := range block.Body.Attributes {
+				declared = append(declared, attr)
+				// Variables can refer to each other without the "var"
+				// prefix inside the "variables" block.
+				for _, tr := range attr.Expr.Variables() {
+					if name := tr.RootName(); name != attr.Name {
+						used[name] = true
+					}
+				}
+			}
+		}
+		walkBodies(body, func(b *hclsyntax.Body) {
+			for _, attr := range b.Attributes {
+				for _, tr := range attr.Expr.Variables() {
+					if tr.RootName() != variablesObjectName {
+						continue
+					}
+					if name, ok := traversalAttr(tr); ok {
+						used[name] = true
+					}
+				}
+			}
+		})
+		sortAttributes(declared)
+		var diags hcl.Diagnostics
+		for _, attr := range declared {
+			if used[attr.Name] {
+				continue
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Unused variable",
+				Detail:   fmt.Sprintf("The variable %q is declared but never used.", attr.Name),
+				Subject:  attr.NameRange.Ptr(),
+				Context:  attr.SrcRange.Ptr(),
+			})
+		}
+		return diags
+	})
+}
+
+// UndeclaredSecrets returns a rule that reports references to secrets
+// through the "secrets" object that are not declared in the "secrets"
+// block.
+func UndeclaredSecrets() Rule {
+	return NewRule("undeclared-secrets", func(body hcl.Body) hcl.Diagnostics {
+		declared := make(map[string]bool)
+		for _, block := range topLevelBlocks(body, secretsBlockName) {
+			for name := range block.Body.Attributes {
+				declared[name] = true
+			}
+		}
+		var refs []hcl.Traversal
+		walkBodies(body, func(b *hclsyntax.Body) {
+			for _, attr := range b.Attributes {
+				for _, tr := range attr.Expr.Variables() {
+					if tr.RootName() != secretsObjectName {
+						continue
+					}
+					if name, ok := traversalAttr(tr); ok && !declared[name] {
+						refs = append(refs, tr)
+					}
+				}
+			}
+		})
+		sort.Slice(refs, func(i, j int) bool {
+			return rangeLess(refs[i].SourceRange(), refs[j].SourceRange())
+		})
+		var diags hcl.Diagnostics
+		for _, tr := range refs {
+			name, _ := traversalAttr(tr)
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Undeclared secret",
+				Detail:   fmt.Sprintf("The secret %q is not declared in the %q block.", name, secretsBlockName),
+				Subject:  tr.SourceRange().Ptr(),
+			})
+		}
+		return diags
+	})
+}
+
+// DuplicateLabels returns a rule that reports blocks that have the same
+// type and labels as a preceding block in the same body. Blocks without
+// labels and dynamic blocks are not checked.
+//
+// Bodies merged using utilHCL.Merge are checked separately, because
+// overriding blocks of earlier bodies is the purpose of merging.
+func DuplicateLabels() Rule {
+	return NewRule("duplicate-labels", func(body hcl.Body) hcl.Diagnostics {
+		var diags hcl.Diagnostics
+		walkBodies(body, func(b *hclsyntax.Body) {
+			seen := make(map[string]*hclsyntax.Block)
+			for _, block := range b.Blocks {
+				if len(block.Labels) == 0 || block.Type == dynamicBlockName {
+					continue
+				}
+				key := block.Type + " " + quoteLabels(block.Labels)
+				prev, ok := seen[key]
+				if !ok {
+					seen[key] = block
+					continue
+				}
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate block",
+					Detail: fmt.Sprintf(
+						"A %q block with labels %s was already defined at %s.",
+						block.Type, quoteLabels(block.Labels), prev.DefRange(),
+					),
+					Subject: block.DefRange().Ptr(),
+				})
+			}
+		})
+		return diags
+	})
+}
+
+// Deprecated returns a rule that reports deprecated attributes and blocks
+// registered in the given registry, see deprecated.Registry.
+func Deprecated(r *deprecated.Registry) Rule {
+	return NewRule("deprecated", r.Check)
+}
+
+func quoteLabels(labels []string) string {
+	q := make([]string, len(labels))
+	for i, l := range labels {
+		q[i] = fmt.Sprintf("%q", l)
+	}
+	return strings.Join(q, " ")
+}
+
+func sortAttributes(attrs []*hclsyntax.Attribute) {
+	sort.Slice(attrs, func(i, j int) bool {
+		return rangeLess(attrs[i].SrcRange, attrs[j].SrcRange)
+	})
+}
+
+func rangeLess(a, b hcl.Range) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	return a.Start.Byte < b.Start.Byte
+}
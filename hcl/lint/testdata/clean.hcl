@@ -0,0 +1,9 @@
+variables {
+  rpc_url = "https://example.com"
+}
+
+ethereum {
+  client "default" {
+    rpc_urls = [var.rpc_url]
+  }
+}
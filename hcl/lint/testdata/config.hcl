@@ -0,0 +1,30 @@
+variables {
+  rpc_url  = "https://example.com"
+  base     = "https://feeds.example.com"
+  feed_url = "${base}/feed"
+  unused   = 1
+  self     = self
+}
+
+secrets {
+  api_key = "0x00"
+}
+
+ethereum {
+  client "default" {
+    rpc_urls = [var.rpc_url]
+    api_key  = secrets.api_key
+  }
+  client "default" {
+    rpc_urls = [var.feed_url]
+  }
+  client "backup" {
+    token = secrets.token
+  }
+}
+
+feed "eth" {
+  source = secrets["password"]
+}
+
+feed "eth" {}
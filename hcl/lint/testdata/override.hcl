@@ -0,0 +1,5 @@
+ethereum {
+  client "default" {
+    rpc_urls = [var.unused]
+  }
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package lint
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// syntaxBodies returns the native syntax bodies that make up the body.
+func syntaxBodies(body hcl.Body) []*hclsyntax.Body {
+	switch b := body.(type) {
+	case *hclsyntax.Body:
+		return []*hclsyntax.Body{b}
+	case interface{ Unwrap() []hcl.Body }:
+		var l []*hclsyntax.Body
+		for _, u := range b.Unwrap() {
+			l = append(l, syntaxBodies(u)...)
+		}
+		return l
+	default:
+		return nil
+	}
+}
+
+// walkBodies calls fn for every native syntax body of the given body and
+// for the bodies of all their nested blocks.
+func walkBodies(body hcl.Body, fn func(*hclsyntax.Body)) {
+	var walk func(*hclsyntax.Body)
+	walk = func(b *hclsyntax.Body) {
+		fn(b)
+		for _, block := range b.Blocks {
+			walk(block.Body)
+		}
+	}
+	for _, b := range syntaxBodies(body) {
+		walk(b)
+	}
+}
+
+// topLevelBlocks returns the top-level blocks of the given type.
+func topLevelBlocks(body hcl.Body, typ string) []*hclsyntax.Block {
+	var l []*hclsyntax.Block
+	for _, b := range syntaxBodies(body) {
+		for _, block := range b.Blocks {
+			if block.Type == typ {
+				l = append(l, block)
+			}
+		}
+	}
+	return l
+}
+
+// traversalAttr returns the name of the attribute accessed directly on the
+// root object of the traversal, e.g. "foo" for "var.foo.bar" and
+// var["foo"].
+func traversalAttr(tr hcl.Traversal) (string, bool) {
+	if len(tr) < 2 {
+		return "", false
+	}
+	switch t := tr[1].(type) {
+	case hcl.TraverseAttr:
+		return t.Name, true
+	case hcl.TraverseIndex:
+		if t.Key.Type() == cty.String && t.Key.IsKnown() && !t.Key.IsNull() {
+			return t.Key.AsString(), true
+		}
+	}
+	return "", false
+}
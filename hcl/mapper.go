@@ -24,6 +24,8 @@ import (
 	"github.com/defiweb/go-anymapper"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/go-lib/maputil"
 )
 
 // Marshaler is the interface implemented by types that can marshal themselves
@@ -158,6 +160,31 @@ func fromCtyMapper(_ *anymapper.Mapper, _, dst reflect.Type) anymapper.MapFunc {
 	return func(m *anymapper.Mapper, _ *anymapper.Context, src, dst reflect.Value) error {
 		ctyVal := src.Interface().(cty.Value)
 
+		// Ordered maps are filled in the order of the cty elements, which
+		// for objects and maps is the lexicographical order of the keys.
+		if o, ok := orderedValue(dst); ok {
+			if !ctyVal.Type().IsMapType() && !ctyVal.Type().IsObjectType() {
+				return fmt.Errorf(
+					"cannot decode %s type into a map",
+					ctyVal.Type().FriendlyName(),
+				)
+			}
+			dst.Set(reflect.Zero(dst.Type()))
+			for it := ctyVal.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				key := reflect.New(o.KeyType())
+				if err := m.MapRefl(reflect.ValueOf(k), key); err != nil {
+					return err
+				}
+				val := reflect.New(o.ValueType())
+				if err := m.MapRefl(reflect.ValueOf(v), val); err != nil {
+					return err
+				}
+				o.SetRefl(key.Elem(), val.Elem())
+			}
+			return nil
+		}
+
 		// Try to use unmarshaler interfaces.
 		if dst.CanAddr() {
 			if u, ok := dst.Addr().Interface().(Unmarshaler); ok {
@@ -325,6 +352,31 @@ func toCtyMapper(_ *anymapper.Mapper, src, _ reflect.Type) anymapper.MapFunc { /
 	// slice -> cty.Value
 	// map -> cty.Value
 	return func(m *anymapper.Mapper, _ *anymapper.Context, src, dst reflect.Value) error {
+		// Ordered maps are encoded as objects, because their values may
+		// have different types. The order of the keys is not preserved in
+		// cty values, see tokensForValue.
+		if o, ok := orderedOf(src.Interface()); ok {
+			attrs := make(map[string]cty.Value, o.Len())
+			var err error
+			o.RangeRefl(func(k, v reflect.Value) bool {
+				var key string
+				var val cty.Value
+				if err = m.MapRefl(k, reflect.ValueOf(&key)); err != nil {
+					return false
+				}
+				if err = m.MapRefl(v, reflect.ValueOf(&val)); err != nil {
+					return false
+				}
+				attrs[key] = val
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			dst.Set(reflect.ValueOf(cty.ObjectVal(attrs)))
+			return nil
+		}
+
 		// Try to use unmarshaler interfaces.
 		if u, ok := src.Interface().(Marshaler); ok {
 			ctyVal, err := u.MarshalHCL()
@@ -400,6 +452,7 @@ var (
 	ctyValTy      = reflect.TypeOf((*cty.Value)(nil)).Elem()
 	bigIntTy      = reflect.TypeOf((*big.Int)(nil)).Elem()
 	bigFloatTy    = reflect.TypeOf((*big.Float)(nil)).Elem()
+	orderedTy     = reflect.TypeOf((*maputil.Ordered)(nil)).Elem()
 	stringTy      = reflect.TypeOf((*string)(nil)).Elem()
 	anyTy         = reflect.TypeOf((*any)(nil)).Elem()
 )
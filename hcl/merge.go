@@ -0,0 +1,204 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Merge deep-merges the given bodies into a single body.
+//
+// Unlike hcl.MergeBodies, which reports an error if the same attribute is
+// defined in more than one body, Merge allows later bodies to override
+// earlier ones. It is intended for layering configurations, e.g. a base
+// configuration with environment specific overrides.
+//
+// The precedence rules are:
+//   - Attributes defined in later bodies replace attributes with the same
+//     name defined in earlier bodies.
+//   - Blocks with the same type and labels are merged recursively using the
+//     same rules. The n-th block with a given type and labels in a body is
+//     merged with the n-th block with the same type and labels in earlier
+//     bodies. Blocks that do not have a counterpart are appended.
+//   - Blocks are returned in the order in which they first appear.
+//
+// The required attributes are checked after merging, so an attribute may be
+// defined in any of the bodies.
+func Merge(bodies ...hcl.Body) hcl.Body {
+	return mergedBody(bodies)
+}
+
+type mergedBody []hcl.Body
+
+// mergedBlock is a block that is merged from the blocks of multiple bodies.
+type mergedBlock struct {
+	key    string
+	block  *hcl.Block
+	bodies []hcl.Body
+}
+
+// Content implements the hcl.Body interface.
+func (m mergedBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	return m.content(schema, false)
+}
+
+// PartialContent implements the hcl.Body interface.
+func (m mergedBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	content, diags := m.content(schema, true)
+	remain := make(mergedBody, 0, len(m))
+	for _, body := range m {
+		_, r, _ := body.PartialContent(optionalSchema(schema))
+		remain = append(remain, r)
+	}
+	return content, remain, diags
+}
+
+// JustAttributes implements the hcl.Body interface.
+func (m mergedBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	var (
+		attrs = make(hcl.Attributes)
+		diags hcl.Diagnostics
+	)
+	for _, body := range m {
+		bodyAttrs, bodyDiags := body.JustAttributes()
+		diags = diags.Extend(bodyDiags)
+		for name, attr := range bodyAttrs {
+			attrs[name] = attr
+		}
+	}
+	return attrs, diags
+}
+
+// Unwrap returns the merged bodies, in the order they were given to Merge.
+// It allows tools that inspect the configuration source, such as the lint
+// package, to access the original bodies.
+func (m mergedBody) Unwrap() []hcl.Body {
+	return m
+}
+
+// MissingItemRange implements the hcl.Body interface.
+func (m mergedBody) MissingItemRange() hcl.Range {
+	if len(m) == 0 {
+		return hcl.Range{Filename: "<empty>"}
+	}
+	return m[0].MissingItemRange()
+}
+
+func (m mergedBody) content(schema *hcl.BodySchema, partial bool) (*hcl.BodyContent, hcl.Diagnostics) {
+	var (
+		diags   hcl.Diagnostics
+		content = &hcl.BodyContent{
+			Attributes:       make(hcl.Attributes),
+			MissingItemRange: m.MissingItemRange(),
+		}
+		blocks []*mergedBlock
+	)
+
+	// Required attributes are checked after merging, otherwise every body
+	// would have to define all of them.
+	bodySchema := optionalSchema(schema)
+	for _, body := range m {
+		var (
+			bodyContent *hcl.BodyContent
+			bodyDiags   hcl.Diagnostics
+		)
+		if partial {
+			bodyContent, _, bodyDiags = body.PartialContent(bodySchema)
+		} else {
+			bodyContent, bodyDiags = body.Content(bodySchema)
+		}
+		diags = diags.Extend(bodyDiags)
+		if bodyContent == nil {
+			continue
+		}
+		for name, attr := range bodyContent.Attributes {
+			content.Attributes[name] = attr
+		}
+		blocks = mergeBlocks(blocks, bodyContent.Blocks)
+	}
+
+	// Check for missing required attributes.
+	for _, attr := range schema.Attributes {
+		if attr.Required && content.Attributes[attr.Name] == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing required argument",
+				Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", attr.Name),
+				Subject:  content.MissingItemRange.Ptr(),
+			})
+		}
+	}
+
+	for _, b := range blocks {
+		block := *b.block
+		if len(b.bodies) > 1 {
+			block.Body = mergedBody(b.bodies)
+		}
+		content.Blocks = append(content.Blocks, &block)
+	}
+	return content, diags
+}
+
+// mergeBlocks merges the blocks of a single body into the list of already
+// merged blocks.
+func mergeBlocks(merged []*mergedBlock, blocks hcl.Blocks) []*mergedBlock {
+	seen := make(map[string]int)
+	for _, block := range blocks {
+		key := blockKey(block)
+		n := seen[key]
+		seen[key]++
+
+		// Find the n-th merged block with the same key.
+		var target *mergedBlock
+		for _, mb := range merged {
+			if mb.key != key {
+				continue
+			}
+			if n == 0 {
+				target = mb
+				break
+			}
+			n--
+		}
+		if target == nil {
+			merged = append(merged, &mergedBlock{key: key, block: block, bodies: []hcl.Body{block.Body}})
+			continue
+		}
+		target.bodies = append(target.bodies, block.Body)
+	}
+	return merged
+}
+
+// blockKey returns a string that identifies a block by its type and labels.
+func blockKey(block *hcl.Block) string {
+	return strings.Join(append([]string{block.Type}, block.Labels...), "\x00")
+}
+
+// optionalSchema returns a copy of the schema with all attributes optional.
+func optionalSchema(schema *hcl.BodySchema) *hcl.BodySchema {
+	s := &hcl.BodySchema{
+		Attributes: make([]hcl.AttributeSchema, len(schema.Attributes)),
+		Blocks:     schema.Blocks,
+	}
+	for i, attr := range schema.Attributes {
+		attr.Required = false
+		s.Attributes[i] = attr
+	}
+	return s
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeTestConfig struct {
+	Name   string                  `hcl:"name"`
+	Port   int                     `hcl:"port,optional"`
+	Keys   map[string]mergeTestKey `hcl:"key,block"`
+	Feeds  []mergeTestFeed         `hcl:"feed,block"`
+	Logger *mergeTestLogger        `hcl:"logger,block,optional"`
+	Remain hcl.Body                `hcl:",remain"`
+}
+
+type mergeTestKey struct {
+	Name     string `hcl:",label"`
+	Address  string `hcl:"address"`
+	Keystore string `hcl:"keystore,optional"`
+}
+
+type mergeTestFeed struct {
+	Address string `hcl:"address"`
+}
+
+type mergeTestLogger struct {
+	Level  string `hcl:"level"`
+	Format string `hcl:"format,optional"`
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		srcs    []string
+		want    mergeTestConfig
+		wantErr string
+	}{
+		{
+			name: "later wins",
+			srcs: []string{
+				`
+					name = "base"
+					port = 8080
+					logger {
+						level  = "info"
+						format = "text"
+					}
+				`,
+				`
+					name = "override"
+					logger {
+						level = "debug"
+					}
+				`,
+			},
+			want: mergeTestConfig{
+				Name:   "override",
+				Port:   8080,
+				Logger: &mergeTestLogger{Level: "debug", Format: "text"},
+			},
+		},
+		{
+			name: "blocks merged by labels",
+			srcs: []string{
+				`
+					name = "base"
+					key "a" {
+						address  = "0x1"
+						keystore = "./keystore"
+					}
+				`,
+				`
+					key "b" {
+						address = "0x2"
+					}
+					key "a" {
+						address = "0x3"
+					}
+				`,
+			},
+			want: mergeTestConfig{
+				Name: "base",
+				Keys: map[string]mergeTestKey{
+					"a": {Name: "a", Address: "0x3", Keystore: "./keystore"},
+					"b": {Name: "b", Address: "0x2"},
+				},
+			},
+		},
+		{
+			name: "repeated blocks",
+			srcs: []string{
+				`
+					name = "base"
+					feed { address = "0x1" }
+					feed { address = "0x2" }
+				`,
+				`
+					feed { address = "0x3" }
+				`,
+				`
+					feed { address = "0x4" }
+					feed { address = "0x5" }
+					feed { address = "0x6" }
+				`,
+			},
+			want: mergeTestConfig{
+				Name:  "base",
+				Feeds: []mergeTestFeed{{Address: "0x4"}, {Address: "0x5"}, {Address: "0x6"}},
+			},
+		},
+		{
+			name: "required attribute in later body",
+			srcs: []string{
+				`port = 1`,
+				`name = "override"`,
+			},
+			want: mergeTestConfig{Name: "override", Port: 1},
+		},
+		{
+			name:    "missing required attribute",
+			srcs:    []string{`port = 1`, `port = 2`},
+			wantErr: `The argument "name" is required`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []hcl.Body
+			for _, src := range tt.srcs {
+				body, diags := ParseSource("test.hcl", []byte(src))
+				require.False(t, diags.HasErrors(), diags.Error())
+				bodies = append(bodies, body)
+			}
+			var cfg mergeTestConfig
+			diags := Decode(nil, Merge(bodies...), &cfg)
+			if tt.wantErr != "" {
+				require.True(t, diags.HasErrors())
+				assert.Contains(t, diags.Error(), tt.wantErr)
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			cfg.Remain = nil
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestMerge_UnknownAttribute(t *testing.T) {
+	base, diags := ParseSource("base.hcl", []byte(`name = "base"`))
+	require.False(t, diags.HasErrors(), diags.Error())
+	override, diags := ParseSource("override.hcl", []byte(`nam = "override"`))
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	_, diags = Merge(base, override).Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "name", Required: true}},
+	})
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), `override.hcl:1,1-4: Unsupported argument`)
+}
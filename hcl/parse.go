@@ -18,32 +18,102 @@ package hcl
 import (
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
 )
 
+// jsonExt is the file extension of configuration files written in the JSON
+// variant of the HCL syntax.
+const jsonExt = ".json"
+
+// Parser parses HCL configuration files and keeps their sources, so that
+// diagnostics can be rendered with source excerpts using the
+// WriteDiagnostics method.
+//
+// Parser is safe for concurrent use.
+type Parser struct {
+	mu    sync.Mutex
+	files map[string]*hcl.File
+}
+
+// NewParser creates a new Parser.
+func NewParser() *Parser {
+	return &Parser{files: make(map[string]*hcl.File)}
+}
+
 // ParseSource parses a single HCL source file.
+//
+// If the filename has the ".json" extension, the source is parsed using
+// the JSON variant of the HCL syntax, otherwise the native syntax is used.
 func ParseSource(filename string, src []byte) (hcl.Body, hcl.Diagnostics) {
-	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	return NewParser().ParseSource(filename, src)
+}
+
+// ParseSources into separate bodies and merge them into one.
+//
+// The keys of the map are used as the filename for the source. Sources
+// with the ".json" extension are parsed as JSON, see ParseSource.
+func ParseSources(srcs map[string][]byte) (hcl.Body, hcl.Diagnostics) {
+	return NewParser().ParseSources(srcs)
+}
+
+// ParseFile parses a single HCL file from the filesystem. Files with the
+// ".json" extension are parsed as JSON, see ParseSource.
+func ParseFile(path string, subject *hcl.Range) (hcl.Body, hcl.Diagnostics) {
+	return NewParser().ParseFile(path, subject)
+}
+
+// ParseFileFS parses a single HCL file using the given fs.FS. Files with
+// the ".json" extension are parsed as JSON, see ParseSource.
+//
+// The path may have a query, e.g. "config.json?checksum=0x...", which is
+// passed to the file system, see the fsutil package. The query is not a
+// part of the filename.
+func ParseFileFS(f fs.FS, path string, subject *hcl.Range) (hcl.Body, hcl.Diagnostics) {
+	return NewParser().ParseFileFS(f, path, subject)
+}
+
+// ParseSource works like the ParseSource function, but it also keeps
+// the parsed source.
+func (p *Parser) ParseSource(filename string, src []byte) (hcl.Body, hcl.Diagnostics) {
+	var (
+		file  *hcl.File
+		diags hcl.Diagnostics
+	)
+	if strings.EqualFold(filepath.Ext(filename), jsonExt) {
+		file, diags = json.Parse(src, filename)
+	} else {
+		file, diags = hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	}
+	if file != nil {
+		// The file is stored even if there are errors, so the errors
+		// can be rendered with the source excerpts.
+		p.mu.Lock()
+		p.files[filename] = file
+		p.mu.Unlock()
+	}
 	if diags.HasErrors() {
 		return nil, diags
 	}
 	return file.Body, diags
 }
 
-// ParseSources into separate bodies and merge them into one.
-//
-// The keys of the map are used as the filename for the source.
-func ParseSources(srcs map[string][]byte) (hcl.Body, hcl.Diagnostics) {
+// ParseSources works like the ParseSources function, but it also keeps
+// the parsed sources.
+func (p *Parser) ParseSources(srcs map[string][]byte) (hcl.Body, hcl.Diagnostics) {
 	var (
 		bodies []hcl.Body
 		diags  hcl.Diagnostics
 	)
 	for k, v := range srcs {
-		srcBody, srcDiags := ParseSource(k, v)
+		srcBody, srcDiags := p.ParseSource(k, v)
 		diags = diags.Extend(srcDiags)
 		if srcDiags.HasErrors() {
 			continue
@@ -56,8 +126,9 @@ func ParseSources(srcs map[string][]byte) (hcl.Body, hcl.Diagnostics) {
 	return hcl.MergeBodies(bodies), diags
 }
 
-// ParseFile parses a single HCL file from the filesystem.
-func ParseFile(path string, subject *hcl.Range) (hcl.Body, hcl.Diagnostics) {
+// ParseFile works like the ParseFile function, but it also keeps
+// the parsed source.
+func (p *Parser) ParseFile(path string, subject *hcl.Range) (hcl.Body, hcl.Diagnostics) {
 	src, err := os.ReadFile(path)
 	if err != nil {
 		return nil, hcl.Diagnostics{{
@@ -67,11 +138,12 @@ func ParseFile(path string, subject *hcl.Range) (hcl.Body, hcl.Diagnostics) {
 			Subject:  subject,
 		}}
 	}
-	return ParseSource(filepath.Base(path), src)
+	return p.ParseSource(filepath.Clean(path), src)
 }
 
-// ParseFileFS parses a single HCL file using the given fs.FS.
-func ParseFileFS(f fs.FS, path string, subject *hcl.Range) (hcl.Body, hcl.Diagnostics) {
+// ParseFileFS works like the ParseFileFS function, but it also keeps
+// the parsed source.
+func (p *Parser) ParseFileFS(f fs.FS, path string, subject *hcl.Range) (hcl.Body, hcl.Diagnostics) {
 	src, err := fs.ReadFile(f, path)
 	if err != nil {
 		return nil, hcl.Diagnostics{{
@@ -81,5 +153,21 @@ func ParseFileFS(f fs.FS, path string, subject *hcl.Range) (hcl.Body, hcl.Diagno
 			Subject:  subject,
 		}}
 	}
-	return ParseSource(filepath.Base(path), src)
+	return p.ParseSource(TrimQuery(path), src)
+}
+
+// TrimQuery removes the query from a file system path, e.g.
+// "config.json?checksum=0x..." becomes "config.json".
+func TrimQuery(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	return path
+}
+
+// Files returns a copy of the map of parsed files, keyed by their filenames.
+// Files read from a file system are keyed by their full path, so files with
+// the same name in different directories do not overwrite each other.
+func (p *Parser) Files() map[string]*hcl.File {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.files)
 }
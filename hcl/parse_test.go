@@ -0,0 +1,96 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type parseTestConfig struct {
+	Attr   int                    `hcl:"attr"`
+	Blocks []parseTestConfigBlock `hcl:"block,block"`
+}
+
+type parseTestConfigBlock struct {
+	Label string `hcl:",label"`
+	Attr  int    `hcl:"attr"`
+}
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    parseTestConfig
+		wantErr bool
+	}{
+		{
+			path: "./testdata/valid1.hcl",
+			want: parseTestConfig{Attr: 1, Blocks: []parseTestConfigBlock{{Label: "label", Attr: 2}}},
+		},
+		{
+			path: "./testdata/valid.json",
+			want: parseTestConfig{Attr: 3, Blocks: []parseTestConfigBlock{{Label: "json", Attr: 4}}},
+		},
+		{
+			path:    "./testdata/invalid.hcl",
+			wantErr: true,
+		},
+		{
+			path:    "./testdata/missing.json",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			body, diags := ParseFile(tt.path, nil)
+			if tt.wantErr {
+				require.True(t, diags.HasErrors())
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			var cfg parseTestConfig
+			diags = Decode(nil, body, &cfg)
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestParseSources(t *testing.T) {
+	hclSrc, err := os.ReadFile("./testdata/valid1.hcl")
+	require.NoError(t, err)
+	jsonSrc, err := os.ReadFile("./testdata/valid.json")
+	require.NoError(t, err)
+
+	body, diags := ParseSources(map[string][]byte{
+		"valid1.hcl": hclSrc,
+		"valid.json": jsonSrc,
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	content, _, diags := body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "block", LabelNames: []string{"label"}}},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Len(t, content.Blocks, 2)
+
+	_, diags = ParseSources(map[string][]byte{"invalid.json": []byte(`{`)})
+	assert.True(t, diags.HasErrors())
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+	"github.com/chronicleprotocol/go-lib/hcl/funcs"
+)
+
+const (
+	// secretsVarName is the name of the variable that holds the decrypted
+	// secrets, as defined by the secrets extension.
+	secretsVarName = "secrets"
+
+	// redactedValue replaces the secret values in the rendered
+	// configuration.
+	redactedValue = "(redacted)"
+)
+
+// Fingerprint returns a stable, hex-encoded SHA-256 hash of the evaluated
+// configuration. It can be used to log or expose the exact configuration
+// a service runs and to detect drift between nodes.
+//
+// The fingerprint is the hash of the configuration returned by the Render
+// function. Because of that, it does not depend on the formatting, comments,
+// order of attributes, or the values of secrets, but only on the decoded
+// values. The order of entries does matter for fields decoded into
+// maputil.OrderedMap, because it is a part of their values.
+func Fingerprint(ctx *hcl.EvalContext, body hcl.Body, val any, opts ...Option) (string, hcl.Diagnostics) {
+	b, diags := Render(ctx, body, val, opts...)
+	if diags.HasErrors() {
+		return "", diags
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), diags
+}
+
+// Render returns the evaluated configuration encoded back to HCL, with all
+// secrets replaced by a placeholder. It can be used to show the effective
+// configuration without revealing the secrets.
+//
+// The body is processed the same way as in the Decode function, using the
+// same options. The value must be a pointer to a struct and is used only to
+// determine the configuration type; it is not modified. The configuration
+// is decoded into a new value of that type, then encoded using the
+// utilHCL.Encode function.
+func Render(ctx *hcl.EvalContext, body hcl.Body, val any, opts ...Option) ([]byte, hcl.Diagnostics) {
+	if ctx == nil {
+		ctx = funcs.NewEvalContext()
+	}
+	body, diags := apply(ctx, body, val, opts)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Replace secrets with placeholders.
+	ctx = redactSecrets(ctx)
+
+	// Decode the configuration into a new value.
+	typ := reflect.TypeOf(val)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Render error",
+			Detail:   "Value must be a pointer to a struct",
+		})
+	}
+	cfg := reflect.New(typ.Elem()).Interface()
+	if diags = diags.Extend(utilHCL.Decode(ctx, body, cfg)); diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Encode the configuration.
+	block := &utilHCL.Block{}
+	if diags = diags.Extend(utilHCL.Encode(cfg, block)); diags.HasErrors() {
+		return nil, diags
+	}
+	b, encDiags := block.Bytes()
+	if diags = diags.Extend(encDiags); diags.HasErrors() {
+		return nil, diags
+	}
+	return b, diags
+}
+
+// redactSecrets returns a child context in which every secret value is
+// replaced with a placeholder.
+func redactSecrets(ctx *hcl.EvalContext) *hcl.EvalContext {
+	secrets, ok := ctx.Variables[secretsVarName]
+	if !ok || !secrets.IsKnown() || secrets.IsNull() || !secrets.Type().IsObjectType() {
+		return ctx
+	}
+	redacted := make(map[string]cty.Value)
+	for name := range secrets.Type().AttributeTypes() {
+		redacted[name] = cty.StringVal(redactedValue)
+	}
+	child := ctx.NewChild()
+	child.Variables = map[string]cty.Value{secretsVarName: cty.ObjectVal(redacted)}
+	return child
+}
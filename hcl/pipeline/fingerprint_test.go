@@ -0,0 +1,80 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+)
+
+func fingerprint(t *testing.T, ctx *hcl.EvalContext, filename string) string {
+	body, diags := utilHCL.ParseFile("./testdata/"+filename, nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+	fp, diags := Fingerprint(ctx, body, &config{})
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Len(t, fp, 64)
+	return fp
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := fingerprint(t, nil, "fingerprint.hcl")
+	assert.Equal(t, fp, fingerprint(t, nil, "fingerprint.hcl"))
+	assert.Equal(t, fp, fingerprint(t, nil, "fingerprint-formatted.hcl"))
+	assert.NotEqual(t, fp, fingerprint(t, nil, "fingerprint-changed.hcl"))
+}
+
+func TestFingerprint_RedactedSecrets(t *testing.T) {
+	src := []byte(`pair = secrets.pair` + "\n" + `feed { address = "0x1" }`)
+	ctx := func(secret string) *hcl.EvalContext {
+		return &hcl.EvalContext{Variables: map[string]cty.Value{
+			"secrets": cty.ObjectVal(map[string]cty.Value{"pair": cty.StringVal(secret)}),
+		}}
+	}
+
+	var fps []string
+	for _, secret := range []string{"BTC/USD", "ETH/USD"} {
+		body, diags := utilHCL.ParseSource("test.hcl", src)
+		require.False(t, diags.HasErrors(), diags.Error())
+		fp, diags := Fingerprint(ctx(secret), body, &config{}, WithoutSecrets())
+		require.False(t, diags.HasErrors(), diags.Error())
+		fps = append(fps, fp)
+	}
+	assert.Equal(t, fps[0], fps[1])
+}
+
+func TestRender(t *testing.T) {
+	src := []byte(`pair = secrets.pair` + "\n" + `feed { address = "0x1" }`)
+	ctx := &hcl.EvalContext{Variables: map[string]cty.Value{
+		"secrets": cty.ObjectVal(map[string]cty.Value{"pair": cty.StringVal("BTC/USD")}),
+	}}
+	body, diags := utilHCL.ParseSource("test.hcl", src)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	b, diags := Render(ctx, body, &config{}, WithoutSecrets())
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Contains(t, string(b), `pair = "(redacted)"`)
+	assert.Contains(t, string(b), `address = "0x1"`)
+	assert.NotContains(t, string(b), "BTC/USD")
+
+	_, diags = Render(nil, body, config{}, WithoutSecrets())
+	assert.True(t, diags.HasErrors())
+}
@@ -0,0 +1,194 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package pipeline provides a single entry point for decoding HCL
+// configurations with all extensions applied in the correct order.
+//
+// The pipeline lives in a separate package because the extensions depend
+// on the hcl package itself.
+package pipeline
+
+import (
+	"io/fs"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/dynblock"
+
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+	"github.com/chronicleprotocol/go-lib/hcl/ext/include"
+	"github.com/chronicleprotocol/go-lib/hcl/ext/secrets"
+	"github.com/chronicleprotocol/go-lib/hcl/ext/variables"
+	"github.com/chronicleprotocol/go-lib/hcl/funcs"
+	"github.com/chronicleprotocol/go-lib/logutil"
+)
+
+// DefaultMaxIncludeDepth is the default maximum depth of nested includes.
+const DefaultMaxIncludeDepth = 10
+
+// Extension is a function that transforms the configuration body before
+// it is decoded. The extension may modify the evaluation context.
+type Extension func(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnostics)
+
+// Option is a functional option for the Decode function.
+type Option func(*options)
+
+type options struct {
+	includeFS       fs.FS
+	includeOpts     []include.Option
+	maxIncludeDepth int
+	noVariables     bool
+	noSecrets       bool
+	noDynamicBlocks bool
+	strict          bool
+	extensions      []Extension
+	logger          logutil.Logger
+}
+
+// WithIncludes enables the "include" attribute. Included files are read
+// from the given file system. The options are passed to include.Include,
+// e.g. include.WithRequireChecksum should be used if the file system reads
+// remote files.
+func WithIncludes(f fs.FS, opts ...include.Option) Option {
+	return func(o *options) {
+		o.includeFS = f
+		o.includeOpts = opts
+	}
+}
+
+// WithMaxIncludeDepth sets the maximum depth of nested includes.
+func WithMaxIncludeDepth(depth int) Option {
+	return func(o *options) {
+		o.maxIncludeDepth = depth
+	}
+}
+
+// WithoutVariables disables the "variables" block.
+func WithoutVariables() Option {
+	return func(o *options) {
+		o.noVariables = true
+	}
+}
+
+// WithoutSecrets disables the "secrets" block.
+func WithoutSecrets() Option {
+	return func(o *options) {
+		o.noSecrets = true
+	}
+}
+
+// WithLogger sets the logger passed to the extensions that support logging.
+func WithLogger(logger logutil.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithoutDynamicBlocks disables the expansion of "dynamic" blocks.
+func WithoutDynamicBlocks() Option {
+	return func(o *options) {
+		o.noDynamicBlocks = true
+	}
+}
+
+// WithStrictValidation enables the strict validation of the configuration
+// using the utilHCL.Validate function. All unknown attributes and blocks
+// are reported before the configuration is decoded.
+func WithStrictValidation() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithExtension adds a custom extension. Custom extensions are applied
+// after the built-in ones, right before the dynamic blocks are expanded,
+// in the order they were added.
+func WithExtension(ext Extension) Option {
+	return func(o *options) {
+		o.extensions = append(o.extensions, ext)
+	}
+}
+
+// Decode applies the extensions to the given body and decodes it into
+// the given value using the utilHCL.Decode function.
+//
+// The extensions are applied in the following order:
+//  1. includes, if enabled using the WithIncludes option,
+//  2. variables,
+//  3. secrets,
+//  4. custom extensions,
+//  5. dynamic blocks,
+//  6. strict validation, if enabled using the WithStrictValidation option.
+//
+// Includes are applied first, so variables and secrets may be defined in
+// included files. Dynamic blocks are expanded last, so they can use
+// variables and secrets.
+//
+// If ctx is nil, a context created by funcs.NewEvalContext is used. Extensions
+// add their variables to the context, so it must not be shared between
+// concurrent calls.
+//
+// The returned diagnostics contain warnings from all steps. The pipeline stops
+// at the first step that returns an error.
+func Decode(ctx *hcl.EvalContext, body hcl.Body, val any, opts ...Option) hcl.Diagnostics {
+	if ctx == nil {
+		ctx = funcs.NewEvalContext()
+	}
+	body, diags := apply(ctx, body, val, opts)
+	if diags.HasErrors() {
+		return diags
+	}
+	return diags.Extend(utilHCL.Decode(ctx, body, val))
+}
+
+// apply applies the extensions to the given body. See Decode for details.
+func apply(ctx *hcl.EvalContext, body hcl.Body, val any, opts []Option) (hcl.Body, hcl.Diagnostics) {
+	o := options{maxIncludeDepth: DefaultMaxIncludeDepth}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var exts []Extension
+	if o.includeFS != nil {
+		exts = append(exts, func(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnostics) {
+			return include.Include(ctx, o.includeFS, body, o.maxIncludeDepth, o.includeOpts...)
+		})
+	}
+	if !o.noVariables {
+		exts = append(exts, variables.Variables)
+	}
+	if !o.noSecrets {
+		exts = append(exts, secrets.NewDecryptSecrets(secrets.WithLogger(o.logger)))
+	}
+	exts = append(exts, o.extensions...)
+	if !o.noDynamicBlocks {
+		exts = append(exts, func(ctx *hcl.EvalContext, body hcl.Body) (hcl.Body, hcl.Diagnostics) {
+			return dynblock.Expand(body, ctx), nil
+		})
+	}
+
+	var diags hcl.Diagnostics
+	for _, ext := range exts {
+		var extDiags hcl.Diagnostics
+		body, extDiags = ext(ctx, body)
+		diags = diags.Extend(extDiags)
+		if extDiags.HasErrors() {
+			return nil, diags
+		}
+	}
+	if o.strict {
+		diags = diags.Extend(utilHCL.Validate(body, val))
+	}
+	return body, diags
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package pipeline
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+)
+
+type config struct {
+	Pair  string `hcl:"pair"`
+	Feeds []feed `hcl:"feed,block"`
+}
+
+type feed struct {
+	Address string `hcl:"address"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		filename    string
+		opts        []Option
+		asserts     func(t *testing.T, cfg config)
+		expectedErr string
+	}{
+		{
+			filename: "config.hcl",
+			opts:     []Option{WithIncludes(os.DirFS("testdata"))},
+			asserts: func(t *testing.T, cfg config) {
+				assert.Equal(t, "BTC/USD", cfg.Pair)
+				require.Len(t, cfg.Feeds, 2)
+				assert.Equal(t, "0x1", cfg.Feeds[0].Address)
+				assert.Equal(t, "0x2", cfg.Feeds[1].Address)
+			},
+		},
+		{
+			filename:    "config.hcl",
+			expectedErr: `Unsupported argument; An argument named "include" is not expected here.`,
+		},
+		{
+			filename:    "invalid.hcl",
+			expectedErr: "Unsupported attribute",
+		},
+		{
+			filename:    "typo.hcl",
+			opts:        []Option{WithStrictValidation()},
+			expectedErr: `Did you mean "feed"?`,
+		},
+		{
+			filename: "invalid.hcl",
+			opts: []Option{WithExtension(func(_ *hcl.EvalContext, _ hcl.Body) (hcl.Body, hcl.Diagnostics) {
+				return nil, hcl.Diagnostics{{Severity: hcl.DiagError, Summary: "Extension error"}}
+			})},
+			expectedErr: "Extension error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			body, diags := utilHCL.ParseFile("./testdata/"+tt.filename, nil)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			var cfg config
+			diags = Decode(nil, body, &cfg, tt.opts...)
+			if tt.expectedErr != "" {
+				require.True(t, diags.HasErrors())
+				assert.Contains(t, diags.Error(), tt.expectedErr)
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			tt.asserts(t, cfg)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+include = ["included.hcl"]
+
+variables {
+  base = "USD"
+}
+
+pair = "${var.asset}/${var.base}"
+
+dynamic "feed" {
+  for_each = var.feeds
+  content {
+    address = feed.value
+  }
+}
@@ -0,0 +1,2 @@
+pair = "BTC/USD"
+feed { address = "0x1" }
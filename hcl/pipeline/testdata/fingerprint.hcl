@@ -0,0 +1,10 @@
+# Comments and formatting do not affect the fingerprint.
+variables {
+  asset = "BTC"
+}
+
+pair = "${var.asset}/USD"
+
+feed {
+  address = "0x1"
+}
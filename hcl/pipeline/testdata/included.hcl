@@ -0,0 +1,4 @@
+variables {
+  asset = "BTC"
+  feeds = ["0x1", "0x2"]
+}
@@ -0,0 +1 @@
+pair = var.missing
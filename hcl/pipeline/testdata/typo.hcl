@@ -0,0 +1,5 @@
+pair = "BTC/USD"
+
+feeds {
+  address = "0x1"
+}
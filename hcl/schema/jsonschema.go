@@ -0,0 +1,173 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// JSONSchemaDialect is the JSON Schema dialect used by the JSONSchema
+// function.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema converts the schema to a JSON Schema that describes
+// configuration files written in the HCL JSON syntax.
+//
+// Blocks are represented as described in the HCL JSON specification:
+// a block is an object property named after the block type, with one level
+// of nested objects for every label. Repeated blocks may also be given as
+// an array of objects. Attributes that are not strings may also be given as
+// a string with a "${...}" template, which is evaluated as an expression.
+//
+// The root object also accepts the "include" attribute and the "variables",
+// "secrets" and "ethereum" blocks handled by the HCL extensions, unless the
+// schema defines them itself.
+func JSONSchema(body *Body) ([]byte, error) {
+	s := bodyJSONSchema(body)
+	props := s["properties"].(map[string]any)
+	for name, ext := range extensionsJSONSchema() {
+		if _, ok := props[name]; !ok {
+			props[name] = ext
+		}
+	}
+	s["$schema"] = JSONSchemaDialect
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// extensionsJSONSchema returns the schemas of the attributes and blocks
+// handled by the extensions in the hcl/ext directory, which are removed
+// from the body before it is decoded.
+func extensionsJSONSchema() map[string]any {
+	block := map[string]any{"type": []string{"object", "array"}}
+	return map[string]any{
+		"include": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "Files to include, see the include extension.",
+		},
+		"variables": withDescription(block, "Variables, see the variables extension."),
+		"secrets": map[string]any{
+			"type": "object",
+			"additionalProperties": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+			"description": "Encrypted secrets keyed by the address, see the secrets extension.",
+		},
+		"ethereum": withDescription(block, "Ethereum keys used to decrypt secrets, see the secrets extension."),
+	}
+}
+
+// withDescription returns a copy of the schema with the given description.
+func withDescription(s map[string]any, description string) map[string]any {
+	s = maps.Clone(s)
+	s["description"] = description
+	return s
+}
+
+func bodyJSONSchema(body *Body) map[string]any {
+	var (
+		props    = map[string]any{}
+		required []string
+	)
+	for _, attr := range body.Attributes {
+		s := typeJSONSchema(attr.Type)
+		annotate(s, attr.Description, attr.Deprecation)
+		props[attr.Name] = s
+		if attr.Required {
+			required = append(required, attr.Name)
+		}
+	}
+	for _, block := range body.Blocks {
+		s := bodyJSONSchema(block.Body)
+		if block.Multiple {
+			s = map[string]any{
+				"oneOf": []any{s, map[string]any{"type": "array", "items": s}},
+			}
+		}
+		for i := len(block.Labels) - 1; i >= 0; i-- {
+			s = map[string]any{
+				"type":                 "object",
+				"description":          fmt.Sprintf("Blocks keyed by the %q label.", block.Labels[i]),
+				"additionalProperties": s,
+			}
+		}
+		annotate(s, block.Description, block.Deprecation)
+		props[block.Type] = s
+		if block.Required {
+			required = append(required, block.Type)
+		}
+	}
+	s := map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	if !body.Open {
+		s["additionalProperties"] = false
+	}
+	return s
+}
+
+// templateJSONSchema describes a string with a "${...}" template. In the
+// HCL JSON syntax, such strings can be used in place of values of any type.
+var templateJSONSchema = map[string]any{"type": "string", "pattern": `\$\{`}
+
+func typeJSONSchema(typ *Type) map[string]any {
+	var s map[string]any
+	switch typ.Kind {
+	case KindString:
+		return map[string]any{"type": "string"}
+	case KindNumber:
+		s = map[string]any{"type": "number"}
+	case KindInteger:
+		s = map[string]any{"type": "integer"}
+	case KindBool:
+		s = map[string]any{"type": "boolean"}
+	case KindList:
+		s = map[string]any{"type": "array", "items": typeJSONSchema(typ.Elem)}
+	case KindMap:
+		s = map[string]any{"type": "object", "additionalProperties": typeJSONSchema(typ.Elem)}
+	default:
+		return map[string]any{}
+	}
+	return map[string]any{"anyOf": []any{s, templateJSONSchema}}
+}
+
+// annotate adds the description and the deprecation to the schema.
+func annotate(s map[string]any, description string, deprecation *Deprecation) {
+	if deprecation != nil {
+		s["deprecated"] = true
+		if deprecation.Replacement != "" {
+			description = joinSentences(description, fmt.Sprintf("Deprecated, use %q instead.", deprecation.Replacement))
+		} else {
+			description = joinSentences(description, "Deprecated.")
+		}
+	}
+	if description != "" {
+		s["description"] = description
+	}
+}
+
+func joinSentences(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
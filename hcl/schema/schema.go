@@ -0,0 +1,302 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package schema generates a description of the configuration schema
+// defined by Go structs with the "hcl" tags, the same as the ones passed
+// to the hcl.Decode function.
+//
+// The description can be used to generate documentation, or it can be
+// converted to a JSON Schema using the JSONSchema function, to enable
+// autocompletion in editors and validation of configuration files by
+// external tools.
+//
+// Descriptions of attributes and blocks are read from the "description"
+// struct tag:
+//
+//	type Config struct {
+//		RPC   string `hcl:"rpc" description:"URL of the RPC endpoint."`
+//		Feeds []Feed `hcl:"feed,block" description:"Feed definitions."`
+//	}
+package schema
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	utilHCL "github.com/chronicleprotocol/go-lib/hcl"
+	"github.com/chronicleprotocol/go-lib/hcl/ext/deprecated"
+	"github.com/chronicleprotocol/go-lib/maputil"
+)
+
+// descriptionTag is the name of the struct tag that contains the
+// description of an attribute or a block.
+const descriptionTag = "description"
+
+// Body describes the schema of an HCL body.
+type Body struct {
+	Attributes []*Attribute `json:"attributes,omitempty"`
+	Blocks     []*Block     `json:"blocks,omitempty"`
+
+	// Open is true if the body accepts attributes and blocks that are not
+	// defined in the schema, which is the case for structs with a field
+	// tagged with "remain".
+	Open bool `json:"open,omitempty"`
+}
+
+// Attribute describes an attribute.
+type Attribute struct {
+	Name        string       `json:"name"`
+	Type        *Type        `json:"type"`
+	Required    bool         `json:"required,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+}
+
+// Block describes a block.
+type Block struct {
+	Type        string       `json:"type"`
+	Labels      []string     `json:"labels,omitempty"`
+	Required    bool         `json:"required,omitempty"`
+	Multiple    bool         `json:"multiple,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	Body        *Body        `json:"body"`
+}
+
+// Deprecation describes a deprecated attribute or block.
+type Deprecation struct {
+	// Replacement is the name of the attribute or block that should be
+	// used instead. It may be empty.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Kind is the kind of attribute value.
+type Kind string
+
+const (
+	KindAny     Kind = "any"
+	KindString  Kind = "string"
+	KindNumber  Kind = "number"
+	KindInteger Kind = "integer"
+	KindBool    Kind = "bool"
+	KindList    Kind = "list"
+	KindMap     Kind = "map"
+)
+
+// Type describes the type of an attribute value.
+type Type struct {
+	Kind Kind `json:"kind"`
+
+	// Elem is the type of elements of lists and maps.
+	Elem *Type `json:"elem,omitempty"`
+}
+
+// String returns the type in the HCL type constraint syntax,
+// e.g. "list(string)".
+func (t *Type) String() string {
+	switch t.Kind {
+	case KindList, KindMap:
+		return fmt.Sprintf("%s(%s)", t.Kind, t.Elem)
+	default:
+		return string(t.Kind)
+	}
+}
+
+// Option is a functional option for the Generate function.
+type Option func(*generator)
+
+// WithDeprecations marks attributes and blocks registered in the given
+// registry as deprecated.
+func WithDeprecations(r *deprecated.Registry) Option {
+	return func(g *generator) {
+		g.deprecations = r
+	}
+}
+
+// Generate returns the schema of the body decoded into the given value.
+// The value must be a struct, a pointer to a struct or a reflect.Type of
+// either of them.
+//
+// Errors are returned for invalid "hcl" tags and for recursive block
+// types, which cannot be described by a finite schema.
+func Generate(val any, opts ...Option) (*Body, hcl.Diagnostics) {
+	g := &generator{}
+	for _, opt := range opts {
+		opt(g)
+	}
+	typ, ok := val.(reflect.Type)
+	if !ok {
+		typ = reflect.TypeOf(val)
+	}
+	if typ == nil {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Schema error",
+			Detail:   "Value must be a struct or a pointer to a struct",
+		}}
+	}
+	return g.body(typ, "")
+}
+
+type generator struct {
+	deprecations *deprecated.Registry
+	stack        []reflect.Type // Block types being generated.
+}
+
+// body generates the schema of the given struct type. The path is the path
+// to the body in the format used by deprecated.Registry.
+func (g *generator) body(typ reflect.Type, path string) (*Body, hcl.Diagnostics) {
+	for _, t := range g.stack {
+		if t == typ {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Schema error",
+				Detail:   fmt.Sprintf("Recursive block type %s", typ),
+			}}
+		}
+	}
+	g.stack = append(g.stack, typ)
+	defer func() { g.stack = g.stack[:len(g.stack)-1] }()
+
+	fields, diags := utilHCL.StructFields(typ)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body := &Body{}
+	for _, field := range fields {
+		switch field.Kind {
+		case utilHCL.FieldAttr:
+			attr := &Attribute{
+				Name:        field.Name,
+				Type:        typeOf(field.StructField.Type),
+				Required:    !field.Optional,
+				Description: field.StructField.Tag.Get(descriptionTag),
+				Deprecation: g.deprecation(joinPath(path, field.Name), false),
+			}
+			body.Attributes = append(body.Attributes, attr)
+		case utilHCL.FieldBlock:
+			labels, diags := blockLabels(field.BlockType)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			blockPath := joinPath(path, field.Name) + strings.Repeat(".*", len(labels))
+			blockBody, diags := g.body(field.BlockType, blockPath)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			block := &Block{
+				Type:        field.Name,
+				Labels:      labels,
+				Required:    !field.Optional && !field.Multiple,
+				Multiple:    field.Multiple,
+				Description: field.StructField.Tag.Get(descriptionTag),
+				Deprecation: g.deprecation(blockPath, true),
+				Body:        blockBody,
+			}
+			body.Blocks = append(body.Blocks, block)
+		case utilHCL.FieldRemain:
+			body.Open = true
+		}
+	}
+	return body, nil
+}
+
+// deprecation returns the deprecation of the element with the given path,
+// or nil if the element is not deprecated.
+func (g *generator) deprecation(path string, block bool) *Deprecation {
+	if g.deprecations == nil {
+		return nil
+	}
+	replacement, ok := g.deprecations.Lookup(path, block)
+	if !ok {
+		return nil
+	}
+	return &Deprecation{Replacement: replacement}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// blockLabels returns the names of the labels of the given block type.
+func blockLabels(typ reflect.Type) ([]string, hcl.Diagnostics) {
+	fields, diags := utilHCL.StructFields(typ)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var labels []string
+	for _, field := range fields {
+		if field.Kind == utilHCL.FieldLabel {
+			labels = append(labels, field.Name)
+		}
+	}
+	return labels, nil
+}
+
+// typeOf returns the type of attribute values decoded into the given Go
+// type. Types that implement the hcl.Unmarshaler interface can decode any
+// value, so they are described as KindAny.
+func typeOf(typ reflect.Type) *Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	ptr := reflect.PointerTo(typ)
+	switch {
+	case typ == ctyValTy || ptr.Implements(unmarshalerTy):
+		return &Type{Kind: KindAny}
+	case typ == bigIntTy:
+		return &Type{Kind: KindInteger}
+	case typ == bigFloatTy:
+		return &Type{Kind: KindNumber}
+	case ptr.Implements(orderedTy):
+		return &Type{Kind: KindMap, Elem: typeOf(reflect.New(typ).Interface().(maputil.Ordered).ValueType())}
+	case ptr.Implements(textUnmarshalerTy):
+		return &Type{Kind: KindString}
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return &Type{Kind: KindString}
+	case reflect.Bool:
+		return &Type{Kind: KindBool}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Type{Kind: KindInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Type{Kind: KindNumber}
+	case reflect.Slice, reflect.Array:
+		return &Type{Kind: KindList, Elem: typeOf(typ.Elem())}
+	case reflect.Map:
+		return &Type{Kind: KindMap, Elem: typeOf(typ.Elem())}
+	default:
+		return &Type{Kind: KindAny}
+	}
+}
+
+var (
+	ctyValTy          = reflect.TypeOf((*cty.Value)(nil)).Elem()
+	bigIntTy          = reflect.TypeOf((*big.Int)(nil)).Elem()
+	bigFloatTy        = reflect.TypeOf((*big.Float)(nil)).Elem()
+	unmarshalerTy     = reflect.TypeOf((*utilHCL.Unmarshaler)(nil)).Elem()
+	textUnmarshalerTy = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	orderedTy         = reflect.TypeOf((*maputil.Ordered)(nil)).Elem()
+)
@@ -0,0 +1,268 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package schema
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/go-lib/hcl/ext/deprecated"
+	"github.com/chronicleprotocol/go-lib/maputil"
+)
+
+type textValue struct{}
+
+func (*textValue) UnmarshalText([]byte) error { return nil }
+
+type config struct {
+	RPC      string                                `hcl:"rpc" description:"URL of the RPC endpoint."`
+	Timeout  time.Duration                         `hcl:"timeout,optional"`
+	Ratio    *big.Float                            `hcl:"ratio,optional"`
+	Tags     []string                              `hcl:"tags,optional"`
+	Limits   map[string]int                        `hcl:"limits,optional"`
+	Order    *maputil.OrderedMap[string, []string] `hcl:"order,optional"`
+	Value    cty.Value                             `hcl:"value,optional"`
+	Text     textValue                             `hcl:"text,optional"`
+	Ethereum ethereum                              `hcl:"ethereum,block"`
+	Feeds    []feed                                `hcl:"feed,block" description:"Feed definitions."`
+	Hooks    map[string]hook                       `hcl:"hook,block"`
+	Plugins  maputil.OrderedMap[string, hook]      `hcl:"plugin,block"`
+	Logger   *logger                               `hcl:"logger,block,optional"`
+	Range    hcl.Range                             `hcl:",range"`
+	Unused   int                                   `hcl:"unused,optional,ignore"`
+	Labels   []labelledBlocks                      `hcl:"double,block"`
+}
+
+type ethereum struct {
+	Key      string `hcl:"key"`
+	Keystore string `hcl:"keystore,optional"`
+}
+
+type feed struct {
+	Name    string `hcl:"name,label"`
+	Address string `hcl:"address"`
+}
+
+type hook struct {
+	Name string   `hcl:"name,label"`
+	Rest hcl.Body `hcl:",remain"`
+}
+
+type logger struct {
+	Level string `hcl:"level"`
+}
+
+type labelledBlocks struct {
+	A string `hcl:"a,label"`
+	B string `hcl:"b,label"`
+}
+
+type recursive struct {
+	Child []recursive `hcl:"child,block"`
+}
+
+type invalidTag struct {
+	Foo string `hcl:"foo,unknown"`
+}
+
+func TestGenerate(t *testing.T) {
+	r := deprecated.NewRegistry().
+		Attribute("ethereum.keystore", "key").
+		Block("feed.*", "")
+
+	body, diags := Generate(&config{}, WithDeprecations(r))
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	want := &Body{
+		Attributes: []*Attribute{
+			{Name: "rpc", Type: &Type{Kind: KindString}, Required: true, Description: "URL of the RPC endpoint."},
+			{Name: "timeout", Type: &Type{Kind: KindInteger}},
+			{Name: "ratio", Type: &Type{Kind: KindNumber}},
+			{Name: "tags", Type: &Type{Kind: KindList, Elem: &Type{Kind: KindString}}},
+			{Name: "limits", Type: &Type{Kind: KindMap, Elem: &Type{Kind: KindInteger}}},
+			{Name: "order", Type: &Type{Kind: KindMap, Elem: &Type{Kind: KindList, Elem: &Type{Kind: KindString}}}},
+			{Name: "value", Type: &Type{Kind: KindAny}},
+			{Name: "text", Type: &Type{Kind: KindString}},
+			{Name: "unused", Type: &Type{Kind: KindInteger}},
+		},
+		Blocks: []*Block{
+			{
+				Type:     "ethereum",
+				Required: true,
+				Body: &Body{Attributes: []*Attribute{
+					{Name: "key", Type: &Type{Kind: KindString}, Required: true},
+					{Name: "keystore", Type: &Type{Kind: KindString}, Deprecation: &Deprecation{Replacement: "key"}},
+				}},
+			},
+			{
+				Type:        "feed",
+				Labels:      []string{"name"},
+				Multiple:    true,
+				Description: "Feed definitions.",
+				Deprecation: &Deprecation{},
+				Body: &Body{Attributes: []*Attribute{
+					{Name: "address", Type: &Type{Kind: KindString}, Required: true},
+				}},
+			},
+			{
+				Type:     "hook",
+				Labels:   []string{"name"},
+				Multiple: true,
+				Body:     &Body{Open: true},
+			},
+			{
+				Type:     "plugin",
+				Labels:   []string{"name"},
+				Multiple: true,
+				Body:     &Body{Open: true},
+			},
+			{
+				Type: "logger",
+				Body: &Body{Attributes: []*Attribute{
+					{Name: "level", Type: &Type{Kind: KindString}, Required: true},
+				}},
+			},
+			{
+				Type:     "double",
+				Labels:   []string{"a", "b"},
+				Multiple: true,
+				Body:     &Body{},
+			},
+		},
+	}
+	assert.Equal(t, want, body)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		val  any
+	}{
+		{name: "recursive block", val: recursive{}},
+		{name: "invalid tag", val: &invalidTag{}},
+		{name: "not a struct", val: "foo"},
+		{name: "nil", val: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, diags := Generate(tt.val)
+			assert.True(t, diags.HasErrors())
+		})
+	}
+}
+
+func TestTypeString(t *testing.T) {
+	assert.Equal(t, "string", (&Type{Kind: KindString}).String())
+	assert.Equal(t, "map(list(number))", (&Type{Kind: KindMap, Elem: &Type{Kind: KindList, Elem: &Type{Kind: KindNumber}}}).String())
+}
+
+func TestJSONSchema(t *testing.T) {
+	body := &Body{
+		Attributes: []*Attribute{
+			{Name: "rpc", Type: &Type{Kind: KindString}, Required: true, Description: "RPC URL."},
+			{Name: "tags", Type: &Type{Kind: KindList, Elem: &Type{Kind: KindString}}, Deprecation: &Deprecation{Replacement: "labels"}},
+			{Name: "timeout", Type: &Type{Kind: KindNumber}},
+			{Name: "variables", Type: &Type{Kind: KindAny}},
+		},
+		Blocks: []*Block{
+			{
+				Type:     "feed",
+				Labels:   []string{"name"},
+				Multiple: true,
+				Body: &Body{Attributes: []*Attribute{
+					{Name: "address", Type: &Type{Kind: KindAny}, Required: true},
+				}},
+			},
+			{
+				Type:     "logger",
+				Required: true,
+				Body:     &Body{Open: true},
+			},
+		},
+	}
+	b, err := JSONSchema(body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["rpc", "logger"],
+		"properties": {
+			"rpc": {"type": "string", "description": "RPC URL."},
+			"tags": {
+				"anyOf": [
+					{"type": "array", "items": {"type": "string"}},
+					{"type": "string", "pattern": "\\$\\{"}
+				],
+				"deprecated": true,
+				"description": "Deprecated, use \"labels\" instead."
+			},
+			"timeout": {
+				"anyOf": [
+					{"type": "number"},
+					{"type": "string", "pattern": "\\$\\{"}
+				]
+			},
+			"variables": {},
+			"include": {
+				"type": "array",
+				"items": {"type": "string"},
+				"description": "Files to include, see the include extension."
+			},
+			"secrets": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "object",
+					"additionalProperties": {"type": "string"}
+				},
+				"description": "Encrypted secrets keyed by the address, see the secrets extension."
+			},
+			"ethereum": {
+				"type": ["object", "array"],
+				"description": "Ethereum keys used to decrypt secrets, see the secrets extension."
+			},
+			"feed": {
+				"type": "object",
+				"description": "Blocks keyed by the \"name\" label.",
+				"additionalProperties": {
+					"oneOf": [
+						{
+							"type": "object",
+							"additionalProperties": false,
+							"required": ["address"],
+							"properties": {"address": {}}
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"additionalProperties": false,
+								"required": ["address"],
+								"properties": {"address": {}}
+							}
+						}
+					]
+				}
+			},
+			"logger": {"type": "object", "properties": {}}
+		}
+	}`, string(b))
+}
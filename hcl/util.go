@@ -17,6 +17,8 @@ package hcl
 
 import (
 	"reflect"
+
+	"github.com/chronicleprotocol/go-lib/maputil"
 )
 
 // derefType dereferences the given type until it is not a pointer or an
@@ -39,3 +41,42 @@ func derefValue(v reflect.Value) reflect.Value {
 	}
 	return v
 }
+
+// orderedType returns a new value of the given type as maputil.Ordered if
+// the type is an ordered map. It can be used to obtain the key and value
+// types of the map.
+func orderedType(t reflect.Type) (maputil.Ordered, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || !reflect.PointerTo(t).Implements(orderedTy) {
+		return nil, false
+	}
+	return reflect.New(t).Interface().(maputil.Ordered), true
+}
+
+// orderedOf returns the ordered map held by v, which may be an ordered map
+// or a non-nil pointer to one.
+func orderedOf(v any) (maputil.Ordered, bool) {
+	if o, ok := v.(maputil.Ordered); ok {
+		return o, !reflect.ValueOf(o).IsNil()
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+	ptr := reflect.New(rv.Type())
+	ptr.Elem().Set(rv)
+	o, ok := ptr.Interface().(maputil.Ordered)
+	return o, ok
+}
+
+// orderedValue returns the given value as maputil.Ordered if it is an
+// ordered map. The value must be addressable.
+func orderedValue(v reflect.Value) (maputil.Ordered, bool) {
+	if v.Kind() != reflect.Struct || !v.CanAddr() {
+		return nil, false
+	}
+	o, ok := v.Addr().Interface().(maputil.Ordered)
+	return o, ok
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Validate checks the given HCL body against the schema derived from the
+// given value and reports all unknown attributes and blocks, as well as
+// missing required attributes. The value must be a pointer to a struct
+// with the "hcl" tags, the same as the one passed to the Decode function.
+//
+// Unlike Decode, Validate does not evaluate any expressions and it does not
+// stop at the first invalid block, so all problems in the configuration are
+// reported at once. Diagnostics contain the range of the offending element
+// and a "did you mean" suggestion if there is a similar name in the schema.
+//
+// Structs with a "remain" field accept any attributes and blocks that are
+// not defined in the schema, so they are not reported as unknown.
+func Validate(body hcl.Body, val any) hcl.Diagnostics {
+	typ := reflect.TypeOf(val)
+	if typ == nil || typ.Kind() != reflect.Ptr || derefType(typ).Kind() != reflect.Struct {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Validation error",
+			Detail:   "Value must be a pointer to a struct",
+		}}
+	}
+	return validateBody(body, derefType(typ))
+}
+
+// validateBody recursively validates the body against the schema of the
+// given struct type.
+func validateBody(body hcl.Body, typ reflect.Type) hcl.Diagnostics {
+	meta, diags := getStructMeta(typ)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	var content *hcl.BodyContent
+	if meta.Remain != nil {
+		content, _, diags = body.PartialContent(meta.BodySchema)
+	} else {
+		content, diags = body.Content(meta.BodySchema)
+	}
+
+	// Nested blocks are validated even if the body is invalid, the content
+	// contains all blocks that match the schema.
+	for _, field := range meta.Blocks {
+		if field.Ignore {
+			continue
+		}
+		for _, block := range content.Blocks.OfType(field.Name) {
+			diags = diags.Extend(validateBody(block.Body, field.StructReflect))
+		}
+	}
+	return diags
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package hcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateTestConfig struct {
+	Passphrase string                         `hcl:"passphrase_file,optional"`
+	Keys       map[string]validateTestKey     `hcl:"key,block"`
+	Extensions *validateTestExtensionsSection `hcl:"extensions,block,optional"`
+}
+
+type validateTestKey struct {
+	Name    string `hcl:",label"`
+	Address string `hcl:"address"`
+}
+
+type validateTestExtensionsSection struct {
+	Remain hcl.Body `hcl:",remain"`
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr []string
+	}{
+		{
+			input: `
+				passphrase_file = "pass.txt"
+				key "default" {
+					address = "0x1"
+				}
+				extensions {
+					anything = true
+				}
+			`,
+		},
+		{
+			input: `
+				pasphrase_file = "pass.txt"
+			`,
+			wantErr: []string{
+				`test.hcl:2,5-19: Unsupported argument; An argument named "pasphrase_file" is not expected here. Did you mean "passphrase_file"?`,
+			},
+		},
+		{
+			input: `
+				keys "default" {
+					address = "0x1"
+				}
+				key "other" {
+					adress = "0x2"
+				}
+			`,
+			wantErr: []string{
+				`Unsupported block type; Blocks of type "keys" are not expected here. Did you mean "key"?`,
+				`Unsupported argument; An argument named "adress" is not expected here. Did you mean "address"?`,
+				`Missing required argument; The argument "address" is required, but no definition was found.`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			body, diags := ParseSource("test.hcl", []byte(tt.input))
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			diags = Validate(body, &validateTestConfig{})
+			if len(tt.wantErr) == 0 {
+				require.False(t, diags.HasErrors(), diags.Error())
+				return
+			}
+			require.Len(t, diags, len(tt.wantErr), diags.Error())
+			var errs []string
+			for _, diag := range diags {
+				errs = append(errs, diag.Error())
+			}
+			for _, want := range tt.wantErr {
+				assert.Contains(t, strings.Join(errs, "\n"), want)
+			}
+		})
+	}
+}
+
+func TestValidate_InvalidValue(t *testing.T) {
+	tests := []struct {
+		name string
+		val  any
+	}{
+		{name: "nil", val: nil},
+		{name: "pointer to int", val: new(int)},
+		{name: "struct", val: struct{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := Validate(nil, tt.val)
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, "Value must be a pointer to a struct", diags[0].Detail)
+		})
+	}
+}
@@ -16,10 +16,14 @@
 package hcl
 
 import (
+	"reflect"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
+
+	"github.com/chronicleprotocol/go-lib/maputil"
 )
 
 // Block represents a single HCL block.
@@ -80,7 +84,13 @@ func (b *Block) Write(hclBody *hclwrite.Body) hcl.Diagnostics {
 }
 
 // tokensForValue returns tokens for the given value.
+//
+// Ordered maps are written as objects with the attributes in the order of
+// the map, because the order of the keys is not preserved in cty values.
 func tokensForValue(val any) (hclwrite.Tokens, hcl.Diagnostics) {
+	if o, ok := orderedOf(val); ok {
+		return tokensForOrdered(o)
+	}
 	var ctyVal cty.Value
 	if err := mapper.Map(val, &ctyVal); err != nil {
 		return nil, hcl.Diagnostics{{
@@ -92,6 +102,45 @@ func tokensForValue(val any) (hclwrite.Tokens, hcl.Diagnostics) {
 	return hclwrite.TokensForValue(ctyVal), nil
 }
 
+// tokensForOrdered returns tokens for an object with the entries of the
+// given ordered map.
+func tokensForOrdered(o maputil.Ordered) (hclwrite.Tokens, hcl.Diagnostics) {
+	var (
+		attrs []hclwrite.ObjectAttrTokens
+		diags hcl.Diagnostics
+	)
+	o.RangeRefl(func(key, elem reflect.Value) bool {
+		var name string
+		if err := mapper.MapRefl(key, reflect.ValueOf(&name)); err != nil {
+			diags = hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Encode error",
+				Detail:   err.Error(),
+			}}
+			return false
+		}
+		var valTokens hclwrite.Tokens
+		if valTokens, diags = tokensForValue(elem.Interface()); diags.HasErrors() {
+			return false
+		}
+		// Keys are written as identifiers if possible, as hclwrite does
+		// for object values.
+		nameTokens := hclwrite.TokensForValue(cty.StringVal(name))
+		if hclsyntax.ValidIdentifier(name) {
+			nameTokens = hclwrite.TokensForIdentifier(name)
+		}
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  nameTokens,
+			Value: valTokens,
+		})
+		return true
+	})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return hclwrite.TokensForObject(attrs), nil
+}
+
 // tokensForAttribute returns tokens for the given attribute.
 func tokensForAttribute(attr *Attribute) (hclwrite.Tokens, hcl.Diagnostics) {
 	attrTokens, diags := tokensForValue(attr.Value)
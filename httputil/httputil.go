@@ -0,0 +1,129 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package httputil provides an HTTP client with a composable chain of
+// http.RoundTripper middlewares.
+//
+// Middlewares implement transport policies, like retries, rate limiting,
+// logging, metrics or header injection, that are configured once and then
+// shared by all code that performs HTTP requests:
+//
+//	client := httputil.NewClient(
+//		httputil.WithTimeout(30*time.Second),
+//		httputil.WithMiddleware(
+//			httputil.Logging(logger),
+//			httputil.Retry(retry.WithAttempts(3)),
+//			httputil.RateLimit(10, 5),
+//		),
+//	)
+//	httputil.SetDefault(client)
+//
+// Packages from this library that perform HTTP requests, like fsutil, use
+// the client returned by Default unless a client is set explicitly.
+package httputil
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Middleware wraps an http.RoundTripper to add behavior to it.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter that allows the use of an ordinary function
+// as an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements the http.RoundTripper interface.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps the round tripper with the given middlewares. The first
+// middleware is the outermost one, so it sees the request first and the
+// response last. If rt is nil, http.DefaultTransport is used.
+func Chain(rt http.RoundTripper, mws ...Middleware) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt
+}
+
+// Option is a functional option for the NewClient function.
+type Option func(*options)
+
+type options struct {
+	transport   http.RoundTripper
+	timeout     time.Duration
+	middlewares []Middleware
+}
+
+// WithTransport sets the round tripper that performs the requests. By
+// default, http.DefaultTransport is used.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithTimeout sets the time limit for requests made by the client, including
+// all retries. See http.Client.Timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithMiddleware appends the middlewares to the chain. See Chain for the
+// order in which they are applied.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, mws...)
+	}
+}
+
+// NewClient creates an HTTP client whose transport is wrapped with the
+// configured middlewares.
+func NewClient(opts ...Option) *http.Client {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &http.Client{
+		Transport: Chain(o.transport, o.middlewares...),
+		Timeout:   o.timeout,
+	}
+}
+
+var defaultClient atomic.Pointer[http.Client]
+
+// Default returns the client set by SetDefault. If no client was set, it
+// returns http.DefaultClient.
+func Default() *http.Client {
+	if c := defaultClient.Load(); c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// SetDefault sets the client returned by Default. It should be called once,
+// during the initialization of a program, before any requests are made.
+// Setting a nil client restores http.DefaultClient.
+func SetDefault(c *http.Client) {
+	defaultClient.Store(c)
+}
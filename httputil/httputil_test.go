@@ -0,0 +1,78 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+":req")
+				res, err := next.RoundTrip(req)
+				calls = append(calls, name+":res")
+				return res, err
+			})
+		}
+	}
+	rt := Chain(RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		calls = append(calls, "transport")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), mw("a"), mw("b"))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:req", "b:req", "transport", "b:res", "a:res"}, calls)
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Test")))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithTimeout(time.Second),
+		WithMiddleware(Headers(http.Header{"X-Test": {"value"}})),
+	)
+	assert.Equal(t, time.Second, client.Timeout)
+
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, "value", readBody(t, res))
+}
+
+func TestDefault(t *testing.T) {
+	assert.Same(t, http.DefaultClient, Default())
+
+	client := NewClient()
+	SetDefault(client)
+	assert.Same(t, client, Default())
+
+	SetDefault(nil)
+	assert.Same(t, http.DefaultClient, Default())
+}
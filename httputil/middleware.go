@@ -0,0 +1,257 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/retry"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+	"github.com/chronicleprotocol/go-lib/urlutil"
+)
+
+// Retry returns a middleware that retries failed requests using retry.Do
+// with the given options.
+//
+// A request is retried if the transport returns an error or if the response
+// status code is 408, 429 or 5xx. The delay suggested by the Retry-After
+// header is respected. If all attempts fail because of the status code, the
+// last response is returned, so the caller can handle it as usual.
+//
+// Only requests with idempotent methods are retried. Requests with a body
+// are retried only if the body can be obtained again using the GetBody
+// field, which is set by http.NewRequest for common body types.
+func Retry(opts ...retry.Option) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !retryable(req) {
+				return next.RoundTrip(req)
+			}
+			var res *http.Response
+			err := retry.Do(req.Context(), func(ctx context.Context) error {
+				if res != nil {
+					// Discard the response of the previous attempt.
+					drain(res)
+					res = nil
+				}
+				attemptReq := req
+				if a, ok := retry.AttemptFromContext(ctx); ok && a.Number > 1 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return retry.Permanent(err)
+					}
+					attemptReq = req.Clone(req.Context())
+					attemptReq.Body = body
+				}
+				var err error
+				res, err = next.RoundTrip(attemptReq)
+				if err != nil {
+					res = nil
+					return err
+				}
+				if retryableStatus(res.StatusCode) {
+					if d, ok := retryAfter(res.Header); ok {
+						return retry.WithDelay(errStatusFn(res.StatusCode), d)
+					}
+					return errStatusFn(res.StatusCode)
+				}
+				return nil
+			}, opts...)
+			if res != nil && req.Context().Err() == nil {
+				return res, nil
+			}
+			if res != nil {
+				drain(res)
+			}
+			return nil, err
+		})
+	}
+}
+
+// RateLimit returns a middleware that limits the rate of requests to rate
+// requests per second on average, with bursts of up to burst requests.
+// Requests over the limit wait until they are allowed, or until their
+// context is done.
+//
+// The limit is shared by all requests passing through the middleware.
+func RateLimit(rate float64, burst int) Middleware {
+	l := &limiter{
+		clock:  timeutil.Real(),
+		rate:   rate,
+		burst:  float64(max(burst, 1)),
+		tokens: float64(max(burst, 1)),
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := timeutil.SleepContextClock(req.Context(), l.clock, l.reserve()); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Logging returns a middleware that logs every request. Successful requests
+// are logged at the debug level, failed ones at the warning level. The URL
+// is logged with credentials redacted, see urlutil.Redact.
+func Logging(logger logutil.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.RoundTrip(req)
+			attrs := []any{
+				"method", req.Method,
+				"url", urlutil.Redact(req.URL),
+				"duration", time.Since(start),
+			}
+			switch {
+			case err != nil:
+				logger.Warn("HTTP request failed", append(attrs, "error", urlutil.RedactError(err))...)
+			case res.StatusCode >= 400:
+				logger.Warn("HTTP request failed", append(attrs, "status", res.StatusCode)...)
+			default:
+				logger.Debug("HTTP request", append(attrs, "status", res.StatusCode)...)
+			}
+			return res, err
+		})
+	}
+}
+
+// RequestMetrics describes a completed request. It is passed to the function
+// given to the Metrics middleware.
+type RequestMetrics struct {
+	Method     string
+	Host       string
+	StatusCode int // Zero if the request failed.
+	Duration   time.Duration
+	Err        error
+}
+
+// Metrics returns a middleware that calls the observe function after every
+// request. It can be used to export metrics without depending on a specific
+// metrics library.
+func Metrics(observe func(RequestMetrics)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.RoundTrip(req)
+			m := RequestMetrics{
+				Method:   req.Method,
+				Host:     req.URL.Host,
+				Duration: time.Since(start),
+				Err:      err,
+			}
+			if res != nil {
+				m.StatusCode = res.StatusCode
+			}
+			observe(m)
+			return res, err
+		})
+	}
+}
+
+// Headers returns a middleware that adds the given headers to every request.
+// Headers already set on the request are not overridden.
+func Headers(headers http.Header) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// A round tripper must not modify the request, so the headers
+			// are added to a clone.
+			req = req.Clone(req.Context())
+			for k, v := range headers {
+				if _, ok := req.Header[k]; !ok {
+					req.Header[k] = v
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// limiter is a token bucket used by the RateLimit middleware.
+type limiter struct {
+	mu     sync.Mutex
+	clock  timeutil.Clock
+	rate   float64 // Tokens added per second.
+	burst  float64 // Maximum number of tokens.
+	tokens float64 // Current number of tokens, negative if reserved.
+	last   time.Time
+}
+
+// reserve takes a token and returns how long to wait before it can be used.
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// retryable reports whether the request can be safely retried.
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryableStatus reports whether the status code indicates a temporary
+// failure.
+func retryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryAfter returns the delay from the Retry-After header. It returns false
+// if the header is missing or invalid.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// drain reads the rest of the response body and closes it, so the
+// connection can be reused.
+func drain(res *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	_ = res.Body.Close()
+}
+
+func errStatusFn(code int) error {
+	return fmt.Errorf("httputil: unexpected status code %d", code)
+}
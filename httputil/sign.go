@@ -0,0 +1,196 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+// SignatureScheme is the authentication scheme used in the Authorization
+// header set by the Sign middleware.
+const SignatureScheme = "Ethereum"
+
+// Signer signs messages using an Ethereum key. It is implemented by the keys
+// from the go-eth wallet package.
+type Signer interface {
+	// Address returns the address of the key.
+	Address() types.Address
+
+	// SignMessage signs the message, prefixed as described in EIP-191.
+	SignMessage(ctx context.Context, data []byte) (*types.Signature, error)
+}
+
+// SignOption is a functional option for the Sign middleware.
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	header string
+	clock  timeutil.Clock
+}
+
+// WithSignatureHeader sets the name of the header in which the signature is
+// sent. By default, the Authorization header is used.
+func WithSignatureHeader(name string) SignOption {
+	return func(o *signOptions) {
+		o.header = name
+	}
+}
+
+// WithSignatureClock sets the clock used to obtain the timestamp of signed
+// requests. It is intended for tests, see timeutil.Fake.
+func WithSignatureClock(clock timeutil.Clock) SignOption {
+	return func(o *signOptions) {
+		o.clock = clock
+	}
+}
+
+// Sign returns a middleware that signs every request with the given key, so
+// the server can authenticate the client by its Ethereum address.
+//
+// The signed message is created by the SignedMessage function and covers the
+// method, the request URI, the current timestamp and the hash of the body.
+// The signature is sent in the following header:
+//
+//	Authorization: Ethereum address=0x…,timestamp=1700000000,signature=0x…
+//
+// The server should recover the address from the signature, compare it with
+// the address from the header and reject requests with a timestamp that is
+// too far from its own time, to prevent replay attacks.
+func Sign(signer Signer, opts ...SignOption) Middleware {
+	o := signOptions{header: "Authorization"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	clock := timeutil.OrReal(o.clock)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				var err error
+				body, err = io.ReadAll(req.Body)
+				_ = req.Body.Close()
+				if err != nil {
+					return nil, errSignFn(err)
+				}
+			}
+			ts := clock.Now().Unix()
+			sig, err := signer.SignMessage(req.Context(), SignedMessage(req.Method, req.URL.RequestURI(), ts, body))
+			if err != nil {
+				return nil, errSignFn(err)
+			}
+
+			// A round tripper must not modify the request, so the header is
+			// added to a clone.
+			req = req.Clone(req.Context())
+			if body != nil {
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				req.GetBody = func() (io.ReadCloser, error) {
+					return io.NopCloser(bytes.NewReader(body)), nil
+				}
+			}
+			req.Header.Set(o.header, fmt.Sprintf(
+				"%s address=%s,timestamp=%d,signature=0x%s",
+				SignatureScheme,
+				signer.Address().String(),
+				ts,
+				hex.EncodeToString(sig.Bytes()),
+			))
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// SignedMessage returns the message signed by the Sign middleware. Servers
+// can use it to verify the signature. The message consists of the following
+// lines, separated by a newline character:
+//
+//  1. the request method,
+//  2. the request URI, as returned by url.URL.RequestURI,
+//  3. the Unix timestamp in seconds,
+//  4. the hex-encoded Keccak-256 hash of the body, prefixed with "0x".
+func SignedMessage(method, requestURI string, timestamp int64, body []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(body)
+	var b bytes.Buffer
+	b.WriteString(method)
+	b.WriteByte('\n')
+	b.WriteString(requestURI)
+	b.WriteByte('\n')
+	b.WriteString(strconv.FormatInt(timestamp, 10))
+	b.WriteByte('\n')
+	b.WriteString("0x")
+	b.WriteString(hex.EncodeToString(h.Sum(nil)))
+	return b.Bytes()
+}
+
+// SignatureHeader is the parsed value of the header set by the Sign
+// middleware.
+type SignatureHeader struct {
+	Address   types.Address
+	Timestamp time.Time
+	Signature []byte
+}
+
+// ParseSignatureHeader parses the value of the header set by the Sign
+// middleware. It does not verify the signature.
+func ParseSignatureHeader(header string) (SignatureHeader, error) {
+	var h SignatureHeader
+	scheme, params, ok := strings.Cut(header, " ")
+	if !ok || scheme != SignatureScheme {
+		return h, errSignatureHeaderFn(fmt.Errorf("unsupported scheme"))
+	}
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		var err error
+		switch key {
+		case "address":
+			h.Address, err = types.AddressFromHex(value)
+		case "timestamp":
+			var ts int64
+			ts, err = strconv.ParseInt(value, 10, 64)
+			h.Timestamp = time.Unix(ts, 0)
+		case "signature":
+			h.Signature, err = hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		}
+		if err != nil {
+			return h, errSignatureHeaderFn(fmt.Errorf("invalid %s: %w", key, err))
+		}
+	}
+	if h.Address.IsZero() || h.Timestamp.IsZero() || len(h.Signature) == 0 {
+		return h, errSignatureHeaderFn(fmt.Errorf("missing parameters"))
+	}
+	return h, nil
+}
+
+func errSignFn(err error) error {
+	return fmt.Errorf("httputil: unable to sign request: %w", err)
+}
+
+func errSignatureHeaderFn(err error) error {
+	return fmt.Errorf("httputil: invalid signature header: %w", err)
+}
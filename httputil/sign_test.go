@@ -0,0 +1,157 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package httputil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/defiweb/go-eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+type testSigner struct {
+	address types.Address
+	msgs    [][]byte
+	err     error
+}
+
+func (s *testSigner) Address() types.Address {
+	return s.address
+}
+
+func (s *testSigner) SignMessage(_ context.Context, data []byte) (*types.Signature, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.msgs = append(s.msgs, data)
+	return &types.Signature{V: big.NewInt(27), R: big.NewInt(1), S: big.NewInt(2)}, nil
+}
+
+func TestSign(t *testing.T) {
+	signer := &testSigner{address: types.MustAddressFromHex("0x1111111111111111111111111111111111111111")}
+	clock := timeutil.NewFake(time.Unix(1700000000, 0))
+
+	var (
+		gotHeader string
+		gotBody   string
+	)
+	rt := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		gotHeader = req.Header.Get("Authorization")
+		b, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		gotBody = string(b)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), Sign(signer, WithSignatureClock(clock)))
+
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/feeds?id=1", strings.NewReader("body"))
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	// The body is passed on unchanged.
+	assert.Equal(t, "body", gotBody)
+
+	// The signed message covers the method, URI, timestamp and body.
+	require.Len(t, signer.msgs, 1)
+	assert.Equal(t, SignedMessage(http.MethodPost, "/feeds?id=1", 1700000000, []byte("body")), signer.msgs[0])
+	assert.Equal(t,
+		"POST\n/feeds?id=1\n1700000000\n0x512227571b4b801d3bbe8f01e3b651e6c4462eb9780ee5b9fb9ea4fb6899a5c4",
+		string(signer.msgs[0]),
+	)
+
+	h, err := ParseSignatureHeader(gotHeader)
+	require.NoError(t, err)
+	assert.Equal(t, signer.address, h.Address)
+	assert.Equal(t, clock.Now(), h.Timestamp)
+	assert.Len(t, h.Signature, 65)
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestSign_CustomHeader(t *testing.T) {
+	signer := &testSigner{address: types.MustAddressFromHex("0x1111111111111111111111111111111111111111")}
+	var got http.Header
+	rt := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), Sign(signer, WithSignatureHeader("X-Signature")))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Empty(t, got.Get("Authorization"))
+	assert.True(t, strings.HasPrefix(got.Get("X-Signature"), SignatureScheme+" "))
+}
+
+func TestSign_Error(t *testing.T) {
+	errSign := errors.New("sign error")
+	rt := Chain(RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("request must not be sent")
+		return nil, nil
+	}), Sign(&testSigner{err: errSign}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	assert.ErrorIs(t, err, errSign)
+}
+
+func TestParseSignatureHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			header: "Ethereum address=0x1111111111111111111111111111111111111111,timestamp=1,signature=0x01",
+		},
+		{
+			name:    "invalid scheme",
+			header:  "Bearer token",
+			wantErr: true,
+		},
+		{
+			name:    "invalid timestamp",
+			header:  "Ethereum address=0x1111111111111111111111111111111111111111,timestamp=x,signature=0x01",
+			wantErr: true,
+		},
+		{
+			name:    "missing signature",
+			header:  "Ethereum address=0x1111111111111111111111111111111111111111,timestamp=1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSignatureHeader(tt.header)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package logutil defines a minimal logging interface used across the
+// library.
+//
+// Packages accept a Logger as an option and log nothing by default. The
+// interface is satisfied by *slog.Logger, so most programs can pass their
+// slog logger directly. Other logging libraries can be adapted by
+// implementing the four methods.
+package logutil
+
+import "log/slog"
+
+// Logger logs messages with key-value pairs, in the same form as
+// slog.Logger: a key is a string followed by its value.
+type Logger interface {
+	Debug(msg string, keyvals ...any)
+	Info(msg string, keyvals ...any)
+	Warn(msg string, keyvals ...any)
+	Error(msg string, keyvals ...any)
+}
+
+// Slog returns a Logger that writes to the given slog logger. If l is nil,
+// the logger returned by slog.Default at the time of logging is used.
+func Slog(l *slog.Logger) Logger {
+	if l == nil {
+		return defaultSlog{}
+	}
+	return l
+}
+
+// Nop returns a Logger that discards all messages.
+func Nop() Logger {
+	return nop{}
+}
+
+// OrNop returns l if it is not nil, otherwise it returns a Logger that
+// discards all messages. It is intended for packages that accept an optional
+// logger.
+func OrNop(l Logger) Logger {
+	if l == nil {
+		return nop{}
+	}
+	return l
+}
+
+type defaultSlog struct{}
+
+func (defaultSlog) Debug(msg string, keyvals ...any) { slog.Default().Debug(msg, keyvals...) }
+func (defaultSlog) Info(msg string, keyvals ...any)  { slog.Default().Info(msg, keyvals...) }
+func (defaultSlog) Warn(msg string, keyvals ...any)  { slog.Default().Warn(msg, keyvals...) }
+func (defaultSlog) Error(msg string, keyvals ...any) { slog.Default().Error(msg, keyvals...) }
+
+type nop struct{}
+
+func (nop) Debug(string, ...any) {}
+func (nop) Info(string, ...any)  {}
+func (nop) Warn(string, ...any)  {}
+func (nop) Error(string, ...any) {}
@@ -0,0 +1,62 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package logutil
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	l := Slog(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	l.Debug("debug", "key", "value")
+	l.Info("info", "n", 1)
+	l.Warn("warn")
+	l.Error("error")
+	assert.Contains(t, buf.String(), `level=DEBUG msg=debug key=value`)
+	assert.Contains(t, buf.String(), `level=INFO msg=info n=1`)
+	assert.Contains(t, buf.String(), `level=WARN msg=warn`)
+	assert.Contains(t, buf.String(), `level=ERROR msg=error`)
+}
+
+func TestSlog_Default(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	defer slog.SetDefault(prev)
+
+	l := Slog(nil)
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	l.Info("info", "key", "value")
+	assert.Contains(t, buf.String(), `msg=info key=value`)
+}
+
+func TestOrNop(t *testing.T) {
+	assert.Equal(t, Nop(), OrNop(nil))
+
+	l := Slog(slog.Default())
+	assert.Same(t, l, OrNop(l))
+
+	// Nop does not panic.
+	n := Nop()
+	n.Debug("debug")
+	n.Info("info")
+	n.Warn("warn")
+	n.Error("error")
+}
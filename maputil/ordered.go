@@ -0,0 +1,310 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package maputil provides map types and helpers.
+package maputil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// OrderedMap is a map that remembers the insertion order of its keys.
+//
+// Iterating over an OrderedMap, encoding it to JSON, or encoding it to HCL
+// using the hcl package yields the entries in the order in which the keys
+// were first inserted. Updating the value of an existing key does not change
+// its position.
+//
+// The zero value is an empty map ready to use. An OrderedMap must not be
+// copied after first use and is not safe for concurrent use.
+type OrderedMap[K comparable, V any] struct {
+	keys []K
+	vals map[K]V
+}
+
+// NewOrderedMap returns a new empty OrderedMap with space for size entries.
+func NewOrderedMap[K comparable, V any](size int) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		keys: make([]K, 0, size),
+		vals: make(map[K]V, size),
+	}
+}
+
+// Len returns the number of entries in the map.
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.keys)
+}
+
+// Get returns the value stored under the key.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	v, ok := m.vals[key]
+	return v, ok
+}
+
+// Has reports whether the key is present in the map.
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.vals[key]
+	return ok
+}
+
+// Set stores the value under the key. New keys are appended at the end.
+func (m *OrderedMap[K, V]) Set(key K, val V) {
+	if m.vals == nil {
+		m.vals = make(map[K]V)
+	}
+	if _, ok := m.vals[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.vals[key] = val
+}
+
+// Delete removes the key from the map and reports whether it was present.
+//
+// Deleting a key takes time proportional to the size of the map.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	if _, ok := m.vals[key]; !ok {
+		return false
+	}
+	delete(m.vals, key)
+	m.keys = slices.DeleteFunc(m.keys, func(k K) bool { return k == key })
+	return true
+}
+
+// Keys returns the keys in insertion order.
+func (m *OrderedMap[K, V]) Keys() []K {
+	return slices.Clone(m.keys)
+}
+
+// Values returns the values in the insertion order of their keys.
+func (m *OrderedMap[K, V]) Values() []V {
+	vals := make([]V, len(m.keys))
+	for i, k := range m.keys {
+		vals[i] = m.vals[k]
+	}
+	return vals
+}
+
+// All returns an iterator over the entries in insertion order. The map
+// must not be modified during iteration.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, k := range m.keys {
+			if !yield(k, m.vals[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Clone returns a shallow copy of the map.
+func (m *OrderedMap[K, V]) Clone() *OrderedMap[K, V] {
+	c := NewOrderedMap[K, V](len(m.keys))
+	for k, v := range m.All() {
+		c.Set(k, v)
+	}
+	return c
+}
+
+// Map returns the entries as a Go map. The order of the keys is lost.
+func (m *OrderedMap[K, V]) Map() map[K]V {
+	res := make(map[K]V, len(m.keys))
+	for k, v := range m.All() {
+		res[k] = v
+	}
+	return res
+}
+
+// KeyType implements the Ordered interface.
+func (m *OrderedMap[K, V]) KeyType() reflect.Type {
+	return reflect.TypeFor[K]()
+}
+
+// ValueType implements the Ordered interface.
+func (m *OrderedMap[K, V]) ValueType() reflect.Type {
+	return reflect.TypeFor[V]()
+}
+
+// HasRefl implements the Ordered interface.
+func (m *OrderedMap[K, V]) HasRefl(key reflect.Value) bool {
+	var k K
+	reflect.ValueOf(&k).Elem().Set(key)
+	return m.Has(k)
+}
+
+// SetRefl implements the Ordered interface.
+func (m *OrderedMap[K, V]) SetRefl(key, val reflect.Value) {
+	var (
+		k K
+		v V
+	)
+	reflect.ValueOf(&k).Elem().Set(key)
+	reflect.ValueOf(&v).Elem().Set(val)
+	m.Set(k, v)
+}
+
+// RangeRefl implements the Ordered interface.
+func (m *OrderedMap[K, V]) RangeRefl(fn func(key, val reflect.Value) bool) {
+	for k, v := range m.All() {
+		if !fn(reflect.ValueOf(&k).Elem(), reflect.ValueOf(&v).Elem()) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface. Entries are encoded
+// as a JSON object in insertion order. Keys are encoded the same way as
+// the encoding/json package encodes map keys.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		ks, err := marshalKey(reflect.ValueOf(&k).Elem())
+		if err != nil {
+			return nil, err
+		}
+		kb, err := json.Marshal(ks)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(m.vals[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Entries are
+// added in the order in which they appear in the JSON object. Existing
+// entries are removed first.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		// JSON null leaves the map unchanged, as for Go maps.
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return errors.New("maputil.OrderedMap: JSON value is not an object")
+	}
+	*m = OrderedMap[K, V]{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var k K
+		if err := unmarshalKey(tok.(string), reflect.ValueOf(&k).Elem()); err != nil {
+			return err
+		}
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		m.Set(k, v)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// Ordered is implemented by OrderedMap regardless of its type parameters.
+// It lets reflection based code, such as the HCL decoder, use ordered maps
+// without knowing their types. Keys and values passed to the methods must
+// be of the types returned by KeyType and ValueType.
+type Ordered interface {
+	Len() int
+	KeyType() reflect.Type
+	ValueType() reflect.Type
+	HasRefl(key reflect.Value) bool
+	SetRefl(key, val reflect.Value)
+	RangeRefl(fn func(key, val reflect.Value) bool)
+}
+
+var (
+	textMarshalerTy   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerTy = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// marshalKey converts a map key to a string using the same rules as the
+// encoding/json package.
+func marshalKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerTy) {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("maputil.OrderedMap: unsupported key type %s", k.Type())
+}
+
+// unmarshalKey parses a JSON object key into k using the same rules as the
+// encoding/json package.
+func unmarshalKey(s string, k reflect.Value) error {
+	if k.Kind() == reflect.String {
+		k.SetString(s)
+		return nil
+	}
+	if reflect.PointerTo(k.Type()).Implements(textUnmarshalerTy) {
+		return k.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, k.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("maputil.OrderedMap: invalid key %q: %w", s, err)
+		}
+		k.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, k.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("maputil.OrderedMap: invalid key %q: %w", s, err)
+		}
+		k.SetUint(n)
+		return nil
+	}
+	return fmt.Errorf("maputil.OrderedMap: unsupported key type %s", k.Type())
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package maputil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap[string, int]
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Set("a", 4)
+
+	assert.Equal(t, 3, m.Len())
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	assert.Equal(t, []int{1, 4, 3}, m.Values())
+	assert.Equal(t, map[string]int{"a": 4, "b": 3, "c": 1}, m.Map())
+
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+	_, ok = m.Get("x")
+	assert.False(t, ok)
+
+	c := m.Clone()
+	assert.True(t, m.Delete("c"))
+	assert.False(t, m.Delete("c"))
+	assert.False(t, m.Has("c"))
+	assert.Equal(t, []string{"a", "b"}, m.Keys())
+	assert.Equal(t, []string{"c", "a", "b"}, c.Keys())
+
+	var keys []string
+	for k := range c.All() {
+		if k == "b" {
+			break
+		}
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []string{"c", "a"}, keys)
+}
+
+func TestOrderedMap_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want any
+		got  any
+	}{
+		{
+			name: "string keys",
+			json: `{"z":1,"a":{"x":true},"m":null}`,
+			got:  &OrderedMap[string, any]{},
+		},
+		{
+			name: "int keys",
+			json: `{"3":"c","1":"a","2":"b"}`,
+			got:  &OrderedMap[int, string]{},
+		},
+		{
+			name: "empty",
+			json: `{}`,
+			got:  &OrderedMap[string, string]{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, json.Unmarshal([]byte(tt.json), tt.got))
+			b, err := json.Marshal(tt.got)
+			require.NoError(t, err)
+			assert.Equal(t, tt.json, string(b))
+		})
+	}
+}
+
+func TestOrderedMap_JSONErrors(t *testing.T) {
+	var m OrderedMap[int, string]
+	assert.Error(t, json.Unmarshal([]byte(`[]`), &m))
+	assert.Error(t, json.Unmarshal([]byte(`{"a":"b"}`), &m))
+	assert.Error(t, json.Unmarshal([]byte(`{"1":2}`), &m))
+
+	var f OrderedMap[float64, string]
+	f.Set(1.5, "x")
+	_, err := json.Marshal(&f)
+	assert.Error(t, err)
+}
+
+func TestOrderedMap_Ordered(t *testing.T) {
+	var o Ordered = &OrderedMap[string, any]{}
+	assert.Equal(t, reflect.TypeFor[string](), o.KeyType())
+	assert.Equal(t, reflect.TypeFor[any](), o.ValueType())
+
+	nilVal := reflect.New(o.ValueType()).Elem()
+	o.SetRefl(reflect.ValueOf("b"), reflect.ValueOf(1))
+	o.SetRefl(reflect.ValueOf("a"), nilVal)
+	assert.True(t, o.HasRefl(reflect.ValueOf("a")))
+	assert.False(t, o.HasRefl(reflect.ValueOf("c")))
+
+	var keys []string
+	var vals []any
+	o.RangeRefl(func(k, v reflect.Value) bool {
+		keys = append(keys, k.String())
+		vals = append(vals, v.Interface())
+		return true
+	})
+	assert.Equal(t, []string{"b", "a"}, keys)
+	assert.Equal(t, []any{1, nil}, vals)
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// AttemptError is an error returned by a single attempt.
+type AttemptError struct {
+	Attempt int // Attempt number, starting from 1.
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *AttemptError) Error() string {
+	return fmt.Sprintf("attempt %d: %v", e.Attempt, e.Err)
+}
+
+// Unwrap returns the wrapped error.
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// WithAggregateErrors makes Do return the errors of all attempts instead of
+// only the last one. If all attempts fail, Do returns an errutil.MultiError
+// with the errors of all attempts, each wrapped in an AttemptError. If the
+// context is done, or the retries were stopped for another reason, like an
+// exhausted budget, the reason is appended as the last element. Errors
+// marked as permanent are unwrapped. If no attempt was made, nil is returned.
+//
+// It is useful when attempts may fail for different reasons, for example
+// a DNS error followed by a server error and then a timeout, which would be
+// hidden if only the last error was returned.
+func WithAggregateErrors() Option {
+	return func(c *config) {
+		c.aggregate = true
+	}
+}
+
+// TryErrAll works like TryErrBackoff, but if all attempts fail, it returns
+// the errors of all attempts, see WithAggregateErrors.
+func TryErrAll(ctx context.Context, f func(context.Context) error, attempts int, backoff Backoff) error {
+	return Do(ctx, f, WithAttempts(attempts), WithBackoff(backoff), WithAggregateErrors())
+}
+
+// tryErrAll runs the retry loop for a function that returns an error and
+// collects the errors of all attempts, see WithAggregateErrors.
+func tryErrAll(ctx context.Context, f func(context.Context) error, cfg config) error {
+	var errs errutil.MultiError
+	ok, stopErr := try(ctx, func(ctx context.Context) (bool, error) {
+		err := f(ctx)
+		if err != nil {
+			a, _ := AttemptFromContext(ctx)
+			errs = append(errs, &AttemptError{Attempt: a.Number, Err: unwrapPermanent(err)})
+		}
+		return err == nil, err
+	}, cfg)
+	if ok {
+		return nil
+	}
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	if stopErr != nil {
+		errs = append(errs, stopErr)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+func TestTryErrAll(t *testing.T) {
+	errDNS := errors.New("dns error")
+	err503 := errors.New("503")
+	errTimeout := errors.New("timeout")
+
+	t.Run("all attempts fail", func(t *testing.T) {
+		errs := []error{errDNS, err503, errTimeout}
+		calls := 0
+		err := TryErrAll(context.Background(), func(context.Context) error {
+			calls++
+			return errs[calls-1]
+		}, 3, Constant(time.Millisecond))
+
+		var mErr errutil.MultiError
+		require.ErrorAs(t, err, &mErr)
+		require.Len(t, mErr, 3)
+		for i, e := range mErr {
+			var aErr *AttemptError
+			require.ErrorAs(t, e, &aErr)
+			assert.Equal(t, i+1, aErr.Attempt)
+			assert.Equal(t, errs[i], aErr.Err)
+		}
+		assert.ErrorIs(t, err, errDNS)
+		assert.ErrorIs(t, err, err503)
+		assert.ErrorIs(t, err, errTimeout)
+		assert.EqualError(t, err, "following errors occurred: [attempt 1: dns error, attempt 2: 503, attempt 3: timeout]")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		calls := 0
+		err := TryErrAll(context.Background(), func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return errDNS
+			}
+			return nil
+		}, 3, Constant(time.Millisecond))
+		assert.NoError(t, err)
+	})
+
+	t.Run("no attempts", func(t *testing.T) {
+		calls := 0
+		err := TryErrAll(context.Background(), func(context.Context) error {
+			calls++
+			return errDNS
+		}, 0, Constant(time.Millisecond))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("budget and stats", func(t *testing.T) {
+		calls := 0
+		stats, err := DoStats(context.Background(), func(context.Context) error {
+			calls++
+			return errDNS
+		},
+			WithAttempts(3),
+			WithBackoff(Constant(time.Millisecond)),
+			WithBudget(NewBudget(0, 1)),
+			WithAggregateErrors(),
+		)
+		var mErr errutil.MultiError
+		require.ErrorAs(t, err, &mErr)
+		require.Len(t, mErr, 3)
+		assert.ErrorIs(t, mErr[2], ErrBudgetExhausted)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 2, stats.Attempts)
+	})
+
+	t.Run("delay hint", func(t *testing.T) {
+		start := time.Now()
+		calls := 0
+		err := Do(context.Background(), func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return WithDelay(errDNS, time.Millisecond)
+			}
+			return nil
+		}, WithBackoff(Constant(time.Hour)), WithAggregateErrors())
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := TryErrAll(ctx, func(context.Context) error {
+			cancel()
+			return errDNS
+		}, -1, Constant(time.Millisecond))
+		assert.ErrorIs(t, err, errDNS)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
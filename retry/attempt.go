@@ -0,0 +1,67 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Attempt contains metadata about the current attempt.
+type Attempt struct {
+	// Number is the attempt number, starting from 1.
+	Number int
+
+	// FirstAttempt is the time when the first attempt started.
+	FirstAttempt time.Time
+}
+
+type attemptCtxKey struct{}
+
+// attemptCtx is a context that carries the attempt metadata. It is used
+// instead of context.WithValue to avoid an additional allocation for boxing
+// the Attempt value on every attempt.
+type attemptCtx struct {
+	context.Context
+	attempt Attempt
+}
+
+// Value implements the context.Context interface.
+func (c *attemptCtx) Value(key any) any {
+	if _, ok := key.(attemptCtxKey); ok {
+		return &c.attempt
+	}
+	return c.Context.Value(key)
+}
+
+// AttemptFromContext returns the metadata of the current attempt from the
+// context passed to the retried function. It returns false if the context
+// was not created by one of the Try* functions.
+//
+// It allows the retried function to adjust its behavior based on the attempt
+// number, e.g. to switch to a different endpoint after a few attempts.
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	a, ok := ctx.Value(attemptCtxKey{}).(*Attempt)
+	if !ok {
+		return Attempt{}, false
+	}
+	return *a, true
+}
+
+// withAttempt returns a context that carries the given attempt metadata.
+func withAttempt(ctx context.Context, a Attempt) context.Context {
+	return &attemptCtx{Context: ctx, attempt: a}
+}
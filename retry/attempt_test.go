@@ -0,0 +1,48 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptFromContext(t *testing.T) {
+	_, ok := AttemptFromContext(context.Background())
+	assert.False(t, ok)
+
+	var attempts []Attempt
+	start := time.Now()
+	err := TryErr(context.Background(), func(ctx context.Context) error {
+		a, ok := AttemptFromContext(ctx)
+		require.True(t, ok)
+		attempts = append(attempts, a)
+		return errors.New("error")
+	}, 3, time.Millisecond)
+	require.Error(t, err)
+
+	require.Len(t, attempts, 3)
+	for i, a := range attempts {
+		assert.Equal(t, i+1, a.Number)
+		assert.Equal(t, attempts[0].FirstAttempt, a.FirstAttempt)
+	}
+	assert.False(t, attempts[0].FirstAttempt.Before(start))
+}
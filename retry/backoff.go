@@ -0,0 +1,88 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"time"
+)
+
+// Backoff calculates the delay between attempts.
+type Backoff interface {
+	// Delay returns the delay before the next attempt. The attempt argument
+	// is the number of the attempt that just failed, starting from 1, and
+	// prev is the delay used before that attempt, or zero if it was the
+	// first one.
+	Delay(attempt int, prev time.Duration) time.Duration
+}
+
+// BackoffFunc is an adapter that allows to use a function as a Backoff.
+type BackoffFunc func(attempt int, prev time.Duration) time.Duration
+
+// Delay implements the Backoff interface.
+func (f BackoffFunc) Delay(attempt int, prev time.Duration) time.Duration {
+	return f(attempt, prev)
+}
+
+// Constant returns a backoff that always returns the same delay.
+func Constant(delay time.Duration) Backoff {
+	return BackoffFunc(func(int, time.Duration) time.Duration {
+		return delay
+	})
+}
+
+// Exponential returns a backoff that doubles the delay after every attempt,
+// starting from base. The delay is capped at maxDelay. If maxDelay is zero
+// or negative, the delay is not capped.
+func Exponential(base, maxDelay time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, _ time.Duration) time.Duration {
+		d := base
+		for i := 1; i < attempt; i++ {
+			if d > maxDuration/2 {
+				return capDelay(maxDuration, maxDelay)
+			}
+			d *= 2
+		}
+		return capDelay(d, maxDelay)
+	})
+}
+
+// Fibonacci returns a backoff in which the delay grows according to the
+// Fibonacci sequence, that is base, base, 2*base, 3*base, 5*base and so on.
+// The delay is capped at maxDelay. If maxDelay is zero or negative, the delay
+// is not capped.
+func Fibonacci(base, maxDelay time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, _ time.Duration) time.Duration {
+		a, b := base, base
+		for i := 1; i < attempt; i++ {
+			if b > maxDuration-a {
+				return capDelay(maxDuration, maxDelay)
+			}
+			a, b = b, a+b
+		}
+		return capDelay(a, maxDelay)
+	})
+}
+
+// maxDuration is the maximum value of time.Duration.
+const maxDuration = time.Duration(1<<63 - 1)
+
+// capDelay limits the delay to maxDelay, unless maxDelay is zero or negative.
+func capDelay(d, maxDelay time.Duration) time.Duration {
+	if maxDelay > 0 && d > maxDelay {
+		return maxDelay
+	}
+	return d
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		want    []time.Duration
+	}{
+		{
+			name:    "constant",
+			backoff: Constant(time.Second),
+			want:    []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:    "exponential",
+			backoff: Exponential(time.Second, 0),
+			want:    []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		{
+			name:    "exponential with cap",
+			backoff: Exponential(time.Second, 3*time.Second),
+			want:    []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			name:    "fibonacci",
+			backoff: Fibonacci(time.Second, 0),
+			want:    []time.Duration{1 * time.Second, 1 * time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second, 8 * time.Second},
+		},
+		{
+			name:    "fibonacci with cap",
+			backoff: Fibonacci(time.Second, 4*time.Second),
+			want:    []time.Duration{1 * time.Second, 1 * time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prev time.Duration
+			for i, want := range tt.want {
+				prev = tt.backoff.Delay(i+1, prev)
+				assert.Equal(t, want, prev, "attempt %d", i+1)
+			}
+		})
+	}
+}
+
+func TestBackoff_Overflow(t *testing.T) {
+	assert.Equal(t, time.Hour, Exponential(time.Second, time.Hour).Delay(1000, 0))
+	assert.Equal(t, time.Hour, Fibonacci(time.Second, time.Hour).Delay(1000, 0))
+	assert.Equal(t, maxDuration, Exponential(time.Second, 0).Delay(1000, 0))
+	assert.Equal(t, maxDuration, Fibonacci(time.Second, 0).Delay(1000, 0))
+}
+
+func TestTryErrBackoff(t *testing.T) {
+	var (
+		calls  int
+		delays []time.Duration
+	)
+	backoff := BackoffFunc(func(attempt int, prev time.Duration) time.Duration {
+		delays = append(delays, prev)
+		return time.Duration(attempt) * time.Millisecond
+	})
+	err := TryErrBackoff(context.Background(), func(context.Context) error {
+		calls++
+		return errors.New("error")
+	}, 3, backoff)
+	assert.EqualError(t, err, "error")
+	assert.Equal(t, 3, calls)
+
+	// The backoff is not called after the last attempt.
+	assert.Equal(t, []time.Duration{0, time.Millisecond}, delays)
+}
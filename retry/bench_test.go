@@ -0,0 +1,53 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errBench = errors.New("error")
+
+func BenchmarkTryErr(b *testing.B) {
+	ctx := context.Background()
+	f := func(context.Context) error { return errBench }
+	b.ReportAllocs()
+	for range b.N {
+		_ = TryErr(ctx, f, 10, 0)
+	}
+}
+
+func TestTryErr_Allocs(t *testing.T) {
+	if testing.CoverMode() != "" {
+		t.Skip("allocations are not stable with coverage enabled")
+	}
+	ctx := context.Background()
+	f := func(context.Context) error { return errBench }
+	allocs := func(attempts int) float64 {
+		return testing.AllocsPerRun(100, func() {
+			_ = TryErr(ctx, f, attempts, 0)
+		})
+	}
+
+	// The only allocation made on every attempt is the attempt context.
+	// Timers and other per-call objects must not be allocated per attempt.
+	perAttempt := (allocs(101) - allocs(11)) / 90
+	assert.LessOrEqual(t, perAttempt, 1.0)
+}
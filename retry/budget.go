@@ -0,0 +1,99 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+// ErrBudgetExhausted is returned when retries are stopped because there are
+// no tokens left in the retry budget. The error returned by the last attempt
+// is wrapped along with it.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Budget limits the total rate of retries. A single budget is meant to be
+// shared by many call sites, so that during a widespread outage the number
+// of outgoing requests does not grow with the number of attempts of each
+// call.
+//
+// The budget is a token bucket. Every retry, that is every attempt except
+// the first one, consumes a token. Tokens are refilled at a constant rate,
+// up to the burst size. When there are no tokens left, retries are stopped
+// and the last error is returned.
+//
+// Budget is safe for concurrent use.
+type Budget struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second.
+	burst  float64 // Maximum number of tokens.
+	tokens float64 // Current number of tokens.
+	last   time.Time
+	clock  timeutil.Clock
+}
+
+// BudgetOption is a functional option for NewBudget.
+type BudgetOption func(*Budget)
+
+// WithBudgetClock sets the clock used to refill the budget. It is intended
+// for tests, see timeutil.Fake. By default, the real clock is used.
+func WithBudgetClock(clock timeutil.Clock) BudgetOption {
+	return func(b *Budget) {
+		b.clock = clock
+	}
+}
+
+// NewBudget creates a budget that allows on average rate retries per second,
+// with bursts of up to burst retries. The budget starts full.
+func NewBudget(rate float64, burst int, opts ...BudgetOption) *Budget {
+	b := &Budget{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.clock = timeutil.OrReal(b.clock)
+	return b
+}
+
+// Allow consumes a single token and reports whether it was available.
+func (b *Budget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// TryErrBudget works like TryErrBackoff, but every retry consumes a token
+// from the given budget. If the budget is exhausted, TryErrBudget stops and
+// returns an error wrapping ErrBudgetExhausted and the last error.
+func TryErrBudget(ctx context.Context, f func(context.Context) error, attempts int, backoff Backoff, budget *Budget) error {
+	return Do(ctx, f, WithAttempts(attempts), WithBackoff(backoff), WithBudget(budget))
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+func TestBudget_Allow(t *testing.T) {
+	clock := timeutil.NewFake(time.Unix(0, 0))
+	b := NewBudget(2, 3, WithBudgetClock(clock))
+
+	// Burst.
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	// Refill, 2 tokens per second.
+	clock.Advance(500 * time.Millisecond)
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	// Refill is capped at burst.
+	clock.Advance(time.Hour)
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+}
+
+func TestTryErrBudget(t *testing.T) {
+	errTest := errors.New("error")
+	budget := NewBudget(0, 3)
+
+	// The first call uses 2 tokens for 2 retries.
+	calls := 0
+	err := TryErrBudget(context.Background(), func(context.Context) error {
+		calls++
+		return errTest
+	}, 3, Constant(time.Millisecond), budget)
+	assert.Equal(t, errTest, err)
+	assert.Equal(t, 3, calls)
+
+	// The second call has only 1 token left.
+	calls = 0
+	err = TryErrBudget(context.Background(), func(context.Context) error {
+		calls++
+		return errTest
+	}, 3, Constant(time.Millisecond), budget)
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 2, calls)
+
+	// The first attempt does not use the budget.
+	err = TryErrBudget(context.Background(), func(context.Context) error {
+		return nil
+	}, 3, Constant(time.Millisecond), budget)
+	assert.NoError(t, err)
+}
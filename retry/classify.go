@@ -0,0 +1,42 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import "github.com/chronicleprotocol/go-lib/errutil"
+
+// IfErrors returns a classifier, to be used with the WithClassifier option,
+// that allows retrying only errors that match one of the targets, as
+// reported by errors.Is.
+func IfErrors(targets ...error) func(error) bool {
+	return func(err error) bool {
+		return errutil.AnyIs(err, targets...)
+	}
+}
+
+// Unless returns a classifier, to be used with the WithClassifier option,
+// that allows retrying all errors except those that match one of the
+// targets, as reported by errors.Is.
+//
+// Example:
+//
+//	err := retry.Do(ctx, fetch, retry.WithClassifier(
+//		retry.Unless(fs.ErrNotExist, fs.ErrPermission),
+//	))
+func Unless(targets ...error) func(error) bool {
+	return func(err error) bool {
+		return !errutil.AnyIs(err, targets...)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfErrors(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	retryable := IfErrors(errTimeout, context.DeadlineExceeded)
+	assert.True(t, retryable(errTimeout))
+	assert.True(t, retryable(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+	assert.False(t, retryable(fs.ErrNotExist))
+	assert.False(t, IfErrors()(errTimeout))
+}
+
+func TestUnless(t *testing.T) {
+	retryable := Unless(fs.ErrNotExist, fs.ErrPermission)
+	assert.False(t, retryable(fs.ErrNotExist))
+	assert.False(t, retryable(fmt.Errorf("wrapped: %w", fs.ErrPermission)))
+	assert.True(t, retryable(errors.New("timeout")))
+	assert.True(t, Unless()(fs.ErrNotExist))
+}
+
+func TestDo_Unless(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return &fs.PathError{Op: "open", Path: "foo", Err: fs.ErrNotExist}
+	}, WithBackoff(Constant(time.Millisecond)), WithClassifier(Unless(fs.ErrNotExist)))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, 1, calls)
+}
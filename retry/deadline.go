@@ -0,0 +1,47 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DeadlineError is returned when retries are stopped because the delay before
+// the next attempt would exceed the context deadline. Instead of waiting
+// until the context is done, the retry functions return immediately.
+//
+// The TryErr* functions and Do wrap it along with the error returned by the
+// last attempt, so both can be inspected using errors.Is and errors.As.
+type DeadlineError struct {
+	Delay     time.Duration // Delay before the next attempt.
+	Remaining time.Duration // Time remaining until the deadline.
+}
+
+// Error implements the error interface.
+func (e *DeadlineError) Error() string {
+	return fmt.Sprintf(
+		"retry budget exhausted by deadline: next attempt in %s, deadline in %s",
+		e.Delay, e.Remaining,
+	)
+}
+
+// Is reports whether the target is context.DeadlineExceeded, so existing
+// checks for the context deadline keep working.
+func (e *DeadlineError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
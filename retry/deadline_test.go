@@ -0,0 +1,68 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo_Deadline(t *testing.T) {
+	errTest := errors.New("error")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := Do(ctx, func(context.Context) error {
+		calls++
+		return errTest
+	}, WithAttempts(3), WithBackoff(Constant(time.Hour)))
+
+	// The function is called once, and Do returns without waiting for
+	// the context deadline.
+	assert.Equal(t, 1, calls)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.NoError(t, ctx.Err())
+
+	var dErr *DeadlineError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, time.Hour, dErr.Delay)
+	assert.LessOrEqual(t, dErr.Remaining, time.Second)
+	assert.ErrorIs(t, err, errTest)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDo_DeadlineNotReached(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	calls := 0
+	err := Do(ctx, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("error")
+		}
+		return nil
+	}, WithAttempts(3), WithBackoff(Constant(time.Millisecond)))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
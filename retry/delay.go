@@ -0,0 +1,75 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"time"
+)
+
+// RetryAfter may be implemented by errors returned by retried functions to
+// suggest the delay before the next attempt, e.g. based on the Retry-After
+// header of an HTTP 429 response. The suggested delay is limited, see
+// WithMaxDelayHint.
+type RetryAfter interface {
+	RetryAfter() time.Duration
+}
+
+// WithDelay wraps the error so that the TryErr* functions wait for the given
+// duration before the next attempt, instead of the delay calculated by the
+// backoff strategy. If err is nil, WithDelay returns nil.
+//
+// The returned error wraps err, so it can be inspected using errors.Is and
+// errors.As.
+func WithDelay(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &delayError{err: err, delay: delay}
+}
+
+type delayError struct {
+	err   error
+	delay time.Duration
+}
+
+// Error implements the error interface.
+func (e *delayError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *delayError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter implements the RetryAfter interface.
+func (e *delayError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+// delayHint returns the delay suggested by the error, if any, capped at
+// maxDelay. If maxDelay is zero or negative, the delay is not capped.
+func delayHint(err error, maxDelay time.Duration) (time.Duration, bool) {
+	ra, ok := findError[RetryAfter](err)
+	if !ok {
+		return 0, false
+	}
+	d := ra.RetryAfter()
+	if d < 0 {
+		d = 0
+	}
+	return capDelay(d, maxDelay), true
+}
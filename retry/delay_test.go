@@ -0,0 +1,103 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDelay(t *testing.T) {
+	errTest := errors.New("error")
+	err := WithDelay(errTest, time.Second)
+	assert.EqualError(t, err, "error")
+	assert.ErrorIs(t, err, errTest)
+
+	var ra RetryAfter
+	require.ErrorAs(t, fmt.Errorf("wrapped: %w", err), &ra)
+	assert.Equal(t, time.Second, ra.RetryAfter())
+
+	assert.NoError(t, WithDelay(nil, time.Second))
+}
+
+func TestTryErr_WithDelay(t *testing.T) {
+	var delays []time.Duration
+	backoff := BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+		delays = append(delays, prev)
+		return time.Millisecond
+	})
+
+	calls := 0
+	start := time.Now()
+	err := TryErrBackoff(context.Background(), func(context.Context) error {
+		calls++
+		switch calls {
+		case 1:
+			return WithDelay(errors.New("rate limited"), 50*time.Millisecond)
+		case 2:
+			return errors.New("error")
+		}
+		return nil
+	}, 3, backoff)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// The backoff is called only for the second attempt, and the hinted
+	// delay is passed as the previous one.
+	assert.Equal(t, []time.Duration{50 * time.Millisecond}, delays)
+}
+
+func TestDo_MaxDelayHint(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		hint      time.Duration
+		wantDelay time.Duration
+	}{
+		{name: "below default limit", hint: time.Minute, wantDelay: time.Minute},
+		{name: "above default limit", hint: time.Hour, wantDelay: DefaultMaxDelayHint},
+		{name: "custom limit", opts: []Option{WithMaxDelayHint(time.Second)}, hint: time.Minute, wantDelay: time.Second},
+		{name: "no limit", opts: []Option{WithMaxDelayHint(0)}, hint: time.Hour, wantDelay: time.Hour},
+		{name: "negative hint", hint: -time.Second, wantDelay: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+
+			// The context is canceled in the notify function, so Do does
+			// not actually wait.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			opts := append([]Option{WithAttempts(2)}, tt.opts...)
+			opts = append(opts, WithNotify(func(_ int, _ error, delay time.Duration) {
+				delays = append(delays, delay)
+				cancel()
+			}))
+			err := Do(ctx, func(context.Context) error {
+				return WithDelay(errors.New("rate limited"), tt.hint)
+			}, opts...)
+			require.Error(t, err)
+			assert.Equal(t, []time.Duration{tt.wantDelay}, delays)
+		})
+	}
+}
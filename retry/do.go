@@ -0,0 +1,156 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+const (
+	// DefaultAttempts is the default number of attempts used by Do.
+	DefaultAttempts = 3
+
+	// DefaultBaseDelay is the initial delay of the default backoff used by Do.
+	DefaultBaseDelay = 100 * time.Millisecond
+
+	// DefaultMaxDelay is the maximum delay of the default backoff used by Do.
+	DefaultMaxDelay = 10 * time.Second
+
+	// DefaultMaxDelayHint is the default maximum delay suggested by errors,
+	// see WithMaxDelayHint.
+	DefaultMaxDelayHint = 5 * time.Minute
+)
+
+// Option is a functional option for the Do function.
+type Option func(*config)
+
+// WithAttempts sets the maximum number of attempts. If attempts is negative,
+// Do will try until the function succeeds or the context is done.
+func WithAttempts(attempts int) Option {
+	return func(c *config) {
+		c.attempts = attempts
+	}
+}
+
+// WithBackoff sets the backoff strategy used to calculate the delay between
+// attempts.
+func WithBackoff(backoff Backoff) Option {
+	return func(c *config) {
+		c.backoff = backoff
+	}
+}
+
+// WithMaxDelayHint limits the delays suggested by errors, see RetryAfter
+// and WithDelay. Suggestions often come from servers, e.g. the Retry-After
+// header, and must not make Do wait for an arbitrarily long time. Longer
+// delays are reduced to the limit. If maxDelay is zero or negative, the
+// delays are not limited.
+//
+// The default limit is DefaultMaxDelayHint.
+func WithMaxDelayHint(maxDelay time.Duration) Option {
+	return func(c *config) {
+		c.maxDelayHint = maxDelay
+	}
+}
+
+// WithJitter randomizes the delays returned by the backoff strategy using
+// FullJitter.
+func WithJitter() Option {
+	return func(c *config) {
+		c.jitter = true
+	}
+}
+
+// WithClassifier sets a function that decides whether an error is worth
+// retrying. If it returns false, Do stops immediately and returns the error.
+func WithClassifier(retryable func(error) bool) Option {
+	return func(c *config) {
+		c.retryable = retryable
+	}
+}
+
+// WithNotify sets a function that is called after every failed attempt,
+// before waiting for the next one. See NotifyFunc.
+func WithNotify(notify NotifyFunc) Option {
+	return func(c *config) {
+		c.notify = notify
+	}
+}
+
+// WithBudget sets a retry budget shared with other calls. See Budget.
+func WithBudget(budget *Budget) Option {
+	return func(c *config) {
+		c.budget = budget
+	}
+}
+
+// WithClock sets the clock used to measure time and to wait between attempts.
+// It is intended for tests, see timeutil.Fake. By default, the real clock is
+// used.
+func WithClock(clock timeutil.Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// WithLogger sets the logger used to log failed attempts. By default, nothing
+// is logged.
+func WithLogger(logger logutil.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// Do calls the function f until it returns no error, the context is done,
+// or the retries are stopped according to the given options.
+//
+// By default, Do makes DefaultAttempts attempts with an exponential backoff
+// starting from DefaultBaseDelay, capped at DefaultMaxDelay.
+//
+// If the context is done, the context error is returned. Otherwise, the error
+// returned by the last attempt is returned.
+//
+// Example:
+//
+//	err := retry.Do(ctx, fetch,
+//		retry.WithAttempts(5),
+//		retry.WithBackoff(retry.Exponential(time.Second, time.Minute)),
+//		retry.WithJitter(),
+//		retry.WithNotify(func(attempt int, err error, delay time.Duration) {
+//			logger.Warn("Fetch failed", "attempt", attempt, "error", err, "delay", delay)
+//		}),
+//	)
+func Do(ctx context.Context, f func(context.Context) error, opts ...Option) error {
+	cfg := config{
+		attempts:     DefaultAttempts,
+		backoff:      Exponential(DefaultBaseDelay, DefaultMaxDelay),
+		maxDelayHint: DefaultMaxDelayHint,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.jitter {
+		cfg.backoff = FullJitter(cfg.backoff)
+	}
+	if cfg.aggregate {
+		return tryErrAll(ctx, f, cfg)
+	}
+	return tryErr(ctx, f, cfg)
+}
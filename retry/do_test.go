@@ -0,0 +1,145 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+func TestDo(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errPermanent := errors.New("permanent")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		opts      []Option
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "default attempts",
+			errs:      []error{errTemporary, errTemporary, errTemporary, errTemporary},
+			opts:      []Option{WithBackoff(Constant(time.Millisecond))},
+			wantErr:   errTemporary,
+			wantCalls: DefaultAttempts,
+		},
+		{
+			name:      "success",
+			errs:      []error{errTemporary, nil},
+			opts:      []Option{WithBackoff(Constant(time.Millisecond))},
+			wantCalls: 2,
+		},
+		{
+			name:      "attempts",
+			errs:      []error{errTemporary, errTemporary, errTemporary, errTemporary, errTemporary},
+			opts:      []Option{WithAttempts(5), WithBackoff(Constant(time.Millisecond)), WithJitter()},
+			wantErr:   errTemporary,
+			wantCalls: 5,
+		},
+		{
+			name: "classifier",
+			errs: []error{errTemporary, errPermanent, errTemporary},
+			opts: []Option{
+				WithBackoff(Constant(time.Millisecond)),
+				WithClassifier(func(err error) bool { return !errors.Is(err, errPermanent) }),
+			},
+			wantErr:   errPermanent,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			notified := 0
+			opts := append(tt.opts, WithNotify(func(int, error, time.Duration) {
+				notified++
+			}))
+			err := Do(context.Background(), func(context.Context) error {
+				calls++
+				return tt.errs[calls-1]
+			}, opts...)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != nil && !errors.Is(tt.wantErr, errPermanent) {
+				assert.Equal(t, tt.wantCalls-1, notified)
+			}
+		})
+	}
+}
+
+func TestDo_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := Do(ctx, func(context.Context) error {
+		return errors.New("error")
+	}, WithAttempts(-1), WithBackoff(Constant(time.Millisecond)))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDo_Clock(t *testing.T) {
+	clock := timeutil.NewFake(time.Unix(0, 0))
+
+	var calls []time.Time
+	type result struct {
+		stats AttemptStats
+		err   error
+	}
+	resCh := make(chan result)
+	go func() {
+		stats, err := DoStats(context.Background(), func(context.Context) error {
+			calls = append(calls, clock.Now())
+			if len(calls) < 3 {
+				return errors.New("error")
+			}
+			return nil
+		},
+			WithAttempts(3),
+			WithBackoff(Constant(time.Hour)),
+			WithClock(clock),
+		)
+		resCh <- result{stats: stats, err: err}
+	}()
+
+	// The function waits an hour between attempts without real sleeps.
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	res := <-resCh
+	require.NoError(t, res.err)
+	assert.Equal(t, []time.Time{time.Unix(0, 0), time.Unix(3600, 0), time.Unix(7200, 0)}, calls)
+	assert.Equal(t, 2*time.Hour, res.stats.TotalWait)
+}
+
+func TestDo_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_ = Do(context.Background(), func(context.Context) error {
+		return errors.New("test error")
+	}, WithAttempts(2), WithBackoff(Constant(time.Millisecond)), WithLogger(logger))
+	assert.Contains(t, buf.String(), `msg="Attempt failed, retrying" attempt=1 delay=1ms error="test error"`)
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+// HedgeOption is a functional option for Hedge.
+type HedgeOption func(*hedgeOptions)
+
+type hedgeOptions struct {
+	clock timeutil.Clock
+}
+
+// WithHedgeClock sets the clock used to stagger the calls. It is intended
+// for tests, see timeutil.Fake. By default, the real clock is used.
+func WithHedgeClock(clock timeutil.Clock) HedgeOption {
+	return func(o *hedgeOptions) {
+		o.clock = clock
+	}
+}
+
+// Hedge calls the function f up to n times concurrently and returns the
+// result of the first successful call. The calls are staggered: the next
+// call starts after the stagger duration passes, or as soon as one of the
+// in-flight calls fails. Once a call succeeds, the contexts of the remaining
+// calls are canceled.
+//
+// Unlike the Try* functions, which retry sequentially, Hedge reduces the
+// tail latency of calls to slow or unreliable services, at the cost of
+// additional requests.
+//
+// If all calls fail, an error combining the errors of all calls is returned,
+// see errutil.Append. If a call returns an error marked using the Permanent
+// function, Hedge stops immediately and returns that error. The context
+// passed to f carries the attempt metadata, see AttemptFromContext.
+func Hedge[T any](
+	ctx context.Context,
+	f func(context.Context) (T, error),
+	n int,
+	stagger time.Duration,
+	opts ...HedgeOption,
+) (T, error) {
+	type result struct {
+		res T
+		err error
+	}
+
+	var o hedgeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	clock := timeutil.OrReal(o.clock)
+
+	var zero T
+	if n < 1 {
+		n = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		start    = clock.Now()
+		results  = make(chan result, n) // Buffered, so abandoned calls do not block.
+		launched = 0
+		pending  = 0
+		errs     error
+	)
+	launch := func() {
+		launched++
+		pending++
+		attemptCtx := withAttempt(ctx, Attempt{Number: launched, FirstAttempt: start})
+		go func() {
+			res, err := f(attemptCtx)
+			results <- result{res: res, err: err}
+		}()
+	}
+
+	launch()
+	t := clock.NewTimer(stagger)
+	defer t.Stop()
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.res, nil
+			}
+			if IsPermanent(r.err) {
+				return zero, unwrapPermanent(r.err)
+			}
+			errs = errutil.Append(errs, r.err)
+			if launched < n {
+				launch()
+				t.Reset(stagger)
+			}
+		case <-t.C():
+			if launched < n {
+				launch()
+				t.Reset(stagger)
+			}
+		}
+	}
+	return zero, errs
+}
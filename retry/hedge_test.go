@@ -0,0 +1,136 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
+)
+
+func TestHedge(t *testing.T) {
+	t.Run("first call wins", func(t *testing.T) {
+		var calls atomic.Int32
+		res, err := Hedge(context.Background(), func(context.Context) (int, error) {
+			calls.Add(1)
+			return 1, nil
+		}, 3, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 1, res)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("slow call is hedged", func(t *testing.T) {
+		canceled := make(chan struct{})
+		res, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+			a, _ := AttemptFromContext(ctx)
+			if a.Number == 1 {
+				<-ctx.Done()
+				close(canceled)
+				return 0, ctx.Err()
+			}
+			return a.Number, nil
+		}, 3, 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, 2, res)
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("slow call was not canceled")
+		}
+	})
+
+	t.Run("clock", func(t *testing.T) {
+		start := time.Unix(0, 0)
+		clock := timeutil.NewFake(start)
+		release := make(chan struct{})
+		done := make(chan struct{})
+		var res int
+		var err error
+		go func() {
+			defer close(done)
+			res, err = Hedge(context.Background(), func(ctx context.Context) (int, error) {
+				a, _ := AttemptFromContext(ctx)
+				assert.Equal(t, start, a.FirstAttempt)
+				if a.Number == 1 {
+					<-release
+					return 0, ctx.Err()
+				}
+				return a.Number, nil
+			}, 2, time.Hour, WithHedgeClock(clock))
+		}()
+
+		// The second call starts only after the stagger duration passes.
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+		<-done
+		close(release)
+		require.NoError(t, err)
+		assert.Equal(t, 2, res)
+	})
+
+	t.Run("failed call starts next one immediately", func(t *testing.T) {
+		start := time.Now()
+		res, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+			a, _ := AttemptFromContext(ctx)
+			if a.Number == 1 {
+				return 0, errors.New("error")
+			}
+			return a.Number, nil
+		}, 2, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 2, res)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("all calls fail", func(t *testing.T) {
+		_, err := Hedge(context.Background(), func(context.Context) (int, error) {
+			return 0, errors.New("error")
+		}, 3, time.Millisecond)
+		var mErr errutil.MultiError
+		require.ErrorAs(t, err, &mErr)
+		assert.Len(t, mErr, 3)
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		errTest := errors.New("error")
+		var calls atomic.Int32
+		_, err := Hedge(context.Background(), func(context.Context) (int, error) {
+			calls.Add(1)
+			return 0, Permanent(errTest)
+		}, 3, time.Second)
+		assert.Equal(t, errTest, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := Hedge(ctx, func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}, 3, time.Millisecond)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// FullJitter returns a backoff that randomizes delays returned by the given
+// backoff. The delay is chosen uniformly from the range [0, d], where d is
+// the delay returned by the given backoff.
+//
+// Randomized delays prevent many processes that fail at the same time from
+// retrying in sync.
+func FullJitter(backoff Backoff) Backoff {
+	return BackoffFunc(func(attempt int, prev time.Duration) time.Duration {
+		return randDuration(0, backoff.Delay(attempt, prev))
+	})
+}
+
+// DecorrelatedJitter returns a backoff in which every delay is chosen
+// uniformly from the range [base, 3*prev], where prev is the previous delay.
+// The delay is capped at maxDelay. If maxDelay is zero or negative, the delay
+// is not capped.
+//
+// Unlike FullJitter, the delay depends on the previous, already randomized
+// delay rather than on the attempt number, which spreads retries more evenly
+// over time.
+func DecorrelatedJitter(base, maxDelay time.Duration) Backoff {
+	return BackoffFunc(func(_ int, prev time.Duration) time.Duration {
+		upper := base
+		if prev > base {
+			upper = prev
+		}
+		if upper > maxDuration/3 {
+			upper = maxDuration
+		} else {
+			upper *= 3
+		}
+		return capDelay(randDuration(base, upper), maxDelay)
+	})
+}
+
+// randDuration returns a random duration in the range [low, high].
+func randDuration(low, high time.Duration) time.Duration {
+	if high <= low {
+		return low
+	}
+	if high-low == maxDuration {
+		return low + rand.N(high-low)
+	}
+	return low + rand.N(high-low+1)
+}
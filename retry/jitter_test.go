@@ -0,0 +1,56 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullJitter(t *testing.T) {
+	backoff := FullJitter(Exponential(time.Second, 0))
+	for attempt := 1; attempt <= 5; attempt++ {
+		want := Exponential(time.Second, 0).Delay(attempt, 0)
+		for range 100 {
+			d := backoff.Delay(attempt, 0)
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+			assert.LessOrEqual(t, d, want)
+		}
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	backoff := DecorrelatedJitter(time.Second, 10*time.Second)
+	for range 100 {
+		var prev time.Duration
+		for attempt := 1; attempt <= 10; attempt++ {
+			d := backoff.Delay(attempt, prev)
+			assert.GreaterOrEqual(t, d, time.Second)
+			assert.LessOrEqual(t, d, 10*time.Second)
+			assert.LessOrEqual(t, d, 3*max(prev, time.Second))
+			prev = d
+		}
+	}
+}
+
+func TestRandDuration(t *testing.T) {
+	assert.Equal(t, time.Second, randDuration(time.Second, time.Second))
+	assert.Equal(t, time.Second, randDuration(time.Second, 0))
+	d := randDuration(0, maxDuration)
+	assert.GreaterOrEqual(t, d, time.Duration(0))
+}
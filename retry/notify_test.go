@@ -0,0 +1,66 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryErrNotify(t *testing.T) {
+	type notification struct {
+		attempt int
+		err     string
+		delay   time.Duration
+	}
+	var got []notification
+	calls := 0
+	err := TryErrNotify(context.Background(), func(context.Context) error {
+		calls++
+		return fmt.Errorf("error %d", calls)
+	}, 3, Exponential(time.Millisecond, 0), func(attempt int, err error, delay time.Duration) {
+		got = append(got, notification{attempt: attempt, err: err.Error(), delay: delay})
+	})
+	assert.EqualError(t, err, "error 3")
+	assert.Equal(t, []notification{
+		{attempt: 1, err: "error 1", delay: time.Millisecond},
+		{attempt: 2, err: "error 2", delay: 2 * time.Millisecond},
+	}, got)
+}
+
+func TestTryErrNotify_Success(t *testing.T) {
+	notified := 0
+	err := TryErrNotify(context.Background(), func(context.Context) error {
+		return nil
+	}, 3, Constant(time.Millisecond), func(int, error, time.Duration) {
+		notified++
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, notified)
+
+	err = TryErrNotify(context.Background(), func(context.Context) error {
+		return errors.New("error")
+	}, 1, Constant(time.Millisecond), func(int, error, time.Duration) {
+		notified++
+	})
+	assert.EqualError(t, err, "error")
+	assert.Zero(t, notified)
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+// Permanent wraps the error to signal that the failure is unrecoverable and
+// there is no point in retrying. The TryErr* functions and Do stop
+// immediately when the retried function returns such an error, and return
+// the wrapped error. If err is nil, Permanent returns nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether the error, or any error it wraps, was marked
+// as permanent using the Permanent function.
+func IsPermanent(err error) bool {
+	_, ok := findError[*permanentError](err)
+	return ok
+}
+
+type permanentError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// unwrapPermanent removes the permanent marker from the error, if the error
+// itself is the marker.
+func unwrapPermanent(err error) error {
+	// Using type casting instead of errors.As is intentional.
+	if pErr, ok := err.(*permanentError); ok {
+		return pErr.err
+	}
+	return err
+}
+
+// findError works like errors.As, but it does not allocate, which matters in
+// the retry loop. Unlike errors.As, it ignores the As methods of errors.
+func findError[T any](err error) (T, bool) {
+	for err != nil {
+		// Using type casting instead of errors.As is intentional.
+		if t, ok := err.(T); ok {
+			return t, true
+		}
+		switch u := err.(type) { //nolint:errorlint
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if t, ok := findError[T](e); ok {
+					return t, true
+				}
+			}
+			return *new(T), false
+		default:
+			return *new(T), false
+		}
+	}
+	return *new(T), false
+}
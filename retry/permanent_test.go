@@ -0,0 +1,69 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermanent(t *testing.T) {
+	errTest := errors.New("error")
+	assert.NoError(t, Permanent(nil))
+	assert.True(t, IsPermanent(Permanent(errTest)))
+	assert.True(t, IsPermanent(fmt.Errorf("wrapped: %w", Permanent(errTest))))
+	assert.True(t, IsPermanent(errors.Join(errTest, Permanent(errTest))))
+	assert.False(t, IsPermanent(errTest))
+	assert.False(t, IsPermanent(errors.Join(errTest, errTest)))
+	assert.ErrorIs(t, Permanent(errTest), errTest)
+	assert.EqualError(t, Permanent(errTest), "error")
+}
+
+func TestTryErr_Permanent(t *testing.T) {
+	errTest := errors.New("error")
+
+	calls := 0
+	err := TryErr(context.Background(), func(context.Context) error {
+		calls++
+		return Permanent(errTest)
+	}, 3, time.Millisecond)
+	assert.Equal(t, errTest, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	_, err = Try1Err(context.Background(), func(context.Context) (int, error) {
+		calls++
+		return 0, fmt.Errorf("wrapped: %w", Permanent(errTest))
+	}, 3, time.Millisecond)
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = TryErrAll(context.Background(), func(context.Context) error {
+		calls++
+		return Permanent(errTest)
+	}, 3, Constant(time.Millisecond))
+	var aErr *AttemptError
+	require.ErrorAs(t, err, &aErr)
+	assert.Equal(t, errTest, aErr.Err)
+	assert.Equal(t, 1, calls)
+}
@@ -17,7 +17,11 @@ package retry
 
 import (
 	"context"
+	"fmt"
 	"time"
+
+	"github.com/chronicleprotocol/go-lib/logutil"
+	"github.com/chronicleprotocol/go-lib/timeutil"
 )
 
 const (
@@ -28,23 +32,118 @@ const (
 // Try will call the function f until it returns true or the context is done.
 // If attempts is negative, Try will try forever.
 func Try(ctx context.Context, f func(context.Context) bool, attempts int, delay time.Duration) (ok bool) {
-	for i := 0; attempts < 0 || i < attempts; i++ {
+	return TryBackoff(ctx, f, attempts, Constant(delay))
+}
+
+// TryBackoff works like Try, but the delay between attempts is calculated
+// using the given backoff strategy.
+func TryBackoff(ctx context.Context, f func(context.Context) bool, attempts int, backoff Backoff) (ok bool) {
+	ok, _ = try(ctx, func(ctx context.Context) (bool, error) {
+		return f(ctx), nil
+	}, config{attempts: attempts, backoff: backoff})
+	return ok
+}
+
+// NotifyFunc is called after every failed attempt, before waiting for the
+// next one. The attempt argument is the number of the failed attempt,
+// starting from 1, err is the error returned by the retried function, and
+// delay is the time to wait before the next attempt.
+//
+// It is not called after the last attempt.
+type NotifyFunc func(attempt int, err error, delay time.Duration)
+
+// config holds the parameters of the retry loop.
+type config struct {
+	attempts     int
+	backoff      Backoff
+	maxDelayHint time.Duration
+	notify       NotifyFunc
+	budget       *Budget
+	jitter       bool
+	aggregate    bool
+	retryable    func(error) bool
+	stats        *AttemptStats
+	clock        timeutil.Clock
+	logger       logutil.Logger
+}
+
+// try is the retry loop used by all Try* functions. The error returned by f
+// is used to obtain a delay hint, see WithDelay, and is passed to the notify
+// function. The context passed to f carries the attempt metadata, see
+// AttemptFromContext.
+//
+// If the retries were stopped for a reason other than the context or the
+// number of attempts, the reason is returned as an error.
+func try(ctx context.Context, f func(context.Context) (bool, error), cfg config) (bool, error) {
+	var (
+		clock = timeutil.OrReal(cfg.clock)
+		delay time.Duration
+		start = clock.Now()
+		t     timeutil.Timer // Reused between attempts.
+	)
+	for i := 1; cfg.attempts < 0 || i <= cfg.attempts; i++ {
 		if ctx.Err() != nil {
-			return false
+			return false, nil
+		}
+		attemptStart := clock.Now()
+		ok, err := f(withAttempt(ctx, Attempt{Number: i, FirstAttempt: start}))
+		if cfg.stats != nil {
+			cfg.stats.Attempts++
+			cfg.stats.AttemptDurations = append(cfg.stats.AttemptDurations, clock.Now().Sub(attemptStart))
 		}
-		if f(ctx) {
-			return true
+		if ok {
+			return true, nil
 		}
-		if attempts < 0 || i < attempts {
-			t := time.NewTimer(delay)
+		if err != nil && (IsPermanent(err) || (cfg.retryable != nil && !cfg.retryable(err))) {
+			return false, nil
+		}
+		if cfg.attempts < 0 || i < cfg.attempts {
+			if cfg.budget != nil && !cfg.budget.Allow() {
+				if cfg.logger != nil {
+					cfg.logger.Warn("Retry budget exhausted", "attempt", i, "error", err)
+				}
+				return false, ErrBudgetExhausted
+			}
+			if d, hinted := delayHint(err, cfg.maxDelayHint); hinted {
+				delay = d
+			} else {
+				delay = cfg.backoff.Delay(i, delay)
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				// There is no point in waiting if the next attempt would
+				// start after the deadline. Context deadlines are always
+				// based on the real time, so the clock is not used here.
+				if remaining := time.Until(deadline); remaining <= delay {
+					return false, &DeadlineError{Delay: delay, Remaining: remaining}
+				}
+			}
+			if cfg.logger != nil {
+				// Checked explicitly, because the arguments would be
+				// allocated even for a no-op logger.
+				cfg.logger.Debug("Attempt failed, retrying", "attempt", i, "delay", delay, "error", err)
+			}
+			if cfg.notify != nil {
+				cfg.notify(i, err, delay)
+			}
+			waitStart := clock.Now()
+			if t == nil {
+				t = clock.NewTimer(delay)
+				defer t.Stop()
+			} else {
+				// Since Go 1.23, Reset discards any stale value from the
+				// timer channel, so the timer can be safely reused.
+				t.Reset(delay)
+			}
 			select {
 			case <-ctx.Done():
-			case <-t.C:
+			case <-t.C():
+			}
+			if cfg.stats != nil {
+				cfg.stats.TotalWait += clock.Now().Sub(waitStart)
 			}
-			t.Stop()
 		}
 	}
-	return false
+	return false, nil
 }
 
 // Try1 is a helper function that simplifies the common case of retrying a
@@ -71,39 +170,62 @@ func Try2[T1, T2 any](ctx context.Context, f func(context.Context) (T1, T2, bool
 
 // TryErr will call the function f until it returns no error or the context is
 // done. If attempts is negative, TryErr will try forever.
+//
+// If the error returned by f implements the RetryAfter interface, the delay
+// it suggests is used instead of the given one. If the error was marked using
+// the Permanent function, TryErr stops immediately.
 func TryErr(ctx context.Context, f func(context.Context) error, attempts int, delay time.Duration) (err error) {
-	Try(ctx, func(ctx context.Context) bool {
+	return Do(ctx, f, WithAttempts(attempts), WithBackoff(Constant(delay)))
+}
+
+// TryErrBackoff works like TryErr, but the delay between attempts is
+// calculated using the given backoff strategy.
+//
+// If the error returned by f implements the RetryAfter interface, the delay
+// it suggests is used instead of the one calculated by the backoff.
+func TryErrBackoff(ctx context.Context, f func(context.Context) error, attempts int, backoff Backoff) (err error) {
+	return Do(ctx, f, WithAttempts(attempts), WithBackoff(backoff))
+}
+
+// TryErrNotify works like TryErrBackoff, but it calls the notify function
+// after every failed attempt, before waiting for the next one. It can be
+// used to log or count retries.
+func TryErrNotify(ctx context.Context, f func(context.Context) error, attempts int, backoff Backoff, notify NotifyFunc) (err error) {
+	return Do(ctx, f, WithAttempts(attempts), WithBackoff(backoff), WithNotify(notify))
+}
+
+// tryErr runs the retry loop for a function that returns an error.
+func tryErr(ctx context.Context, f func(context.Context) error, cfg config) (err error) {
+	_, stopErr := try(ctx, func(ctx context.Context) (bool, error) {
 		err = f(ctx)
-		return err == nil
-	}, attempts, delay)
+		return err == nil, err
+	}, cfg)
+	err = unwrapPermanent(err)
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
+	if stopErr != nil {
+		return fmt.Errorf("%w: %w", stopErr, err)
+	}
 	return err
 }
 
 // Try1Err is a helper function that simplifies the common case of retrying a
 // function that returns a single value and an error.
 func Try1Err[T any](ctx context.Context, f func(context.Context) (T, error), attempts int, delay time.Duration) (res T, err error) {
-	Try(ctx, func(ctx context.Context) bool {
+	err = TryErr(ctx, func(ctx context.Context) (err error) {
 		res, err = f(ctx)
-		return err == nil
+		return err
 	}, attempts, delay)
-	if ctx.Err() != nil {
-		return res, ctx.Err()
-	}
 	return res, err
 }
 
 // Try2Err is a helper function that simplifies the common case of retrying a
 // function that returns two values and an error.
 func Try2Err[T1, T2 any](ctx context.Context, f func(context.Context) (T1, T2, error), attempts int, delay time.Duration) (res1 T1, res2 T2, err error) {
-	Try(ctx, func(ctx context.Context) bool {
+	err = TryErr(ctx, func(ctx context.Context) (err error) {
 		res1, res2, err = f(ctx)
-		return err == nil
+		return err
 	}, attempts, delay)
-	if ctx.Err() != nil {
-		return res1, res2, ctx.Err()
-	}
 	return res1, res2, err
 }
@@ -0,0 +1,50 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// AttemptStats contains statistics about the attempts made by DoStats.
+type AttemptStats struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+
+	// TotalWait is the total time spent waiting between attempts.
+	TotalWait time.Duration
+
+	// AttemptDurations contains the duration of every attempt.
+	AttemptDurations []time.Duration
+}
+
+// DoStats works like Do, but it also returns statistics about the attempts.
+// They can be used to tell how much of the total latency was caused by
+// retries and how much by the retried function itself.
+func DoStats(ctx context.Context, f func(context.Context) error, opts ...Option) (AttemptStats, error) {
+	var stats AttemptStats
+	err := Do(ctx, f, slices.Concat(opts, []Option{withStats(&stats)})...)
+	return stats, err
+}
+
+// withStats is an option that collects statistics into the given struct.
+func withStats(stats *AttemptStats) Option {
+	return func(c *config) {
+		c.stats = stats
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoStats(t *testing.T) {
+	calls := 0
+	stats, err := DoStats(context.Background(), func(context.Context) error {
+		calls++
+		time.Sleep(5 * time.Millisecond)
+		if calls < 3 {
+			return errors.New("error")
+		}
+		return nil
+	}, WithBackoff(Constant(10*time.Millisecond)))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.Attempts)
+	assert.GreaterOrEqual(t, stats.TotalWait, 20*time.Millisecond)
+	require.Len(t, stats.AttemptDurations, 3)
+	for _, d := range stats.AttemptDurations {
+		assert.GreaterOrEqual(t, d, 5*time.Millisecond)
+	}
+}
+
+func TestDoStats_Failure(t *testing.T) {
+	stats, err := DoStats(context.Background(), func(context.Context) error {
+		return errors.New("error")
+	}, WithAttempts(2), WithBackoff(Constant(time.Millisecond)))
+	assert.EqualError(t, err, "error")
+	assert.Equal(t, 2, stats.Attempts)
+	assert.Len(t, stats.AttemptDurations, 2)
+}
+
+func TestDoStats_DoesNotModifyOptions(t *testing.T) {
+	opts := make([]Option, 1, 2)
+	opts[0] = WithAttempts(1)
+	_, err := DoStats(context.Background(), func(context.Context) error {
+		return nil
+	}, opts...)
+	require.NoError(t, err)
+	assert.Nil(t, opts[:cap(opts)][1])
+}
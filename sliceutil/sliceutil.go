@@ -17,7 +17,15 @@ package sliceutil
 
 import (
 	"cmp"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"runtime"
 	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
 )
 
 // Copy returns a copy of the slice.
@@ -39,8 +47,9 @@ func Contains[T comparable](s []T, e T) bool {
 
 // ContainsAll returns true if s slice contains all elements in e slice.
 func ContainsAll[T comparable](s []T, e []T) bool {
+	contains := containsFunc(s, len(e))
 	for _, x := range e {
-		if !Contains(s, x) {
+		if !contains(x) {
 			return false
 		}
 	}
@@ -102,20 +111,27 @@ func Intersect[T comparable](slices ...[]T) []T {
 	}
 
 	// Find the smallest slice.
-	min := slices[0]
-	for _, s := range slices {
-		if len(s) < len(min) {
-			min = s
+	minIdx := 0
+	for i, s := range slices {
+		if len(s) < len(slices[minIdx]) {
+			minIdx = i
 		}
 	}
+	min := slices[minIdx]
 
 	// Iterate over the smallest slice and check if the element is present in
 	// all other slices.
+	contains := make([]func(T) bool, 0, len(slices)-1)
+	for i, s := range slices {
+		if i != minIdx {
+			contains = append(contains, containsFunc(s, len(min)))
+		}
+	}
 	out := make([]T, 0, len(min))
 	for _, x := range min {
 		found := true
-		for _, s := range slices {
-			if !Contains(s, x) {
+		for _, contains := range contains {
+			if !contains(x) {
 				found = false
 				break
 			}
@@ -215,3 +231,528 @@ func AppendUniqueSortFunc[T any](s []T, cmp func(T, T) int, vs ...T) []T {
 	}
 	return s
 }
+
+// Chunk splits the slice into consecutive chunks of the given size. The last
+// chunk may be smaller than size. The chunks share the underlying array with
+// the original slice, but their capacity is limited to their length, so
+// appending to a chunk does not modify the original slice.
+//
+// Chunk panics if size is less than 1.
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		panic("sliceutil.Chunk: size must be greater than 0")
+	}
+	out := make([][]T, 0, (len(s)+size-1)/size)
+	for i := 0; i < len(s); i += size {
+		end := min(i+size, len(s))
+		out = append(out, s[i:end:end])
+	}
+	return out
+}
+
+// GroupBy groups the elements of the slice by the key returned by the
+// function f. The order of the elements in each group is preserved.
+func GroupBy[T any, K comparable](s []T, f func(T) K) map[K][]T {
+	out := make(map[K][]T)
+	for _, x := range s {
+		k := f(x)
+		out[k] = append(out[k], x)
+	}
+	return out
+}
+
+// CountBy counts the elements of the slice by the key returned by the
+// function f.
+func CountBy[T any, K comparable](s []T, f func(T) K) map[K]int {
+	out := make(map[K]int)
+	for _, x := range s {
+		out[f(x)]++
+	}
+	return out
+}
+
+// Reduce applies the function f to each element of the slice, accumulating
+// the result, starting with the init value.
+func Reduce[T, A any](s []T, init A, f func(A, T) A) A {
+	acc := init
+	for _, x := range s {
+		acc = f(acc, x)
+	}
+	return acc
+}
+
+// Pair holds two values of possibly different types.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip returns a slice of pairs, where the i-th pair contains the i-th
+// elements of a and b. If the slices have different lengths, the result has
+// the length of the shorter one.
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	out := make([]Pair[A, B], min(len(a), len(b)))
+	for i := range out {
+		out[i] = Pair[A, B]{First: a[i], Second: b[i]}
+	}
+	return out
+}
+
+// Unzip splits a slice of pairs into two slices. It is the inverse of Zip.
+func Unzip[A, B any](s []Pair[A, B]) ([]A, []B) {
+	a := make([]A, len(s))
+	b := make([]B, len(s))
+	for i, p := range s {
+		a[i], b[i] = p.First, p.Second
+	}
+	return a, b
+}
+
+// Difference returns a new slice with the elements of a that are not present
+// in b. The order of the elements is preserved.
+func Difference[T comparable](a, b []T) []T {
+	s := make(map[T]struct{}, len(b))
+	for _, x := range b {
+		s[x] = struct{}{}
+	}
+	out := make([]T, 0, len(a))
+	for _, x := range a {
+		if _, ok := s[x]; !ok {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// SymmetricDifference returns a new slice with the elements that are present
+// in exactly one of the slices. The elements of a are returned first,
+// followed by the elements of b. The order of the elements is preserved.
+func SymmetricDifference[T comparable](a, b []T) []T {
+	return append(Difference(a, b), Difference(b, a)...)
+}
+
+// Partition splits the slice into two new slices, the first one with the
+// elements that satisfy the predicate f and the second one with the rest.
+// The order of the elements is preserved.
+func Partition[T any](s []T, f func(T) bool) (matched, rest []T) {
+	matched = make([]T, 0, len(s))
+	rest = make([]T, 0, len(s))
+	for _, x := range s {
+		if f(x) {
+			matched = append(matched, x)
+		} else {
+			rest = append(rest, x)
+		}
+	}
+	return matched, rest
+}
+
+// Flatten returns a new slice with the elements of all slices concatenated.
+func Flatten[T any](s [][]T) []T {
+	n := 0
+	for _, x := range s {
+		n += len(x)
+	}
+	out := make([]T, 0, n)
+	for _, x := range s {
+		out = append(out, x...)
+	}
+	return out
+}
+
+// FlatMap returns a new slice with the concatenated results of applying the
+// function f to each element of the original slice.
+func FlatMap[T, U any](s []T, f func(T) []U) []U {
+	out := make([]U, 0, len(s))
+	for _, x := range s {
+		out = append(out, f(x)...)
+	}
+	return out
+}
+
+// Shuffle returns a new slice with the elements of the original slice in
+// random order. If r is nil, the global random number generator is used.
+func Shuffle[T any](s []T, r *rand.Rand) []T {
+	out := Copy(s)
+	swap := func(i, j int) { out[i], out[j] = out[j], out[i] }
+	if r == nil {
+		rand.Shuffle(len(out), swap)
+	} else {
+		r.Shuffle(len(out), swap)
+	}
+	return out
+}
+
+// Sample returns a new slice with n randomly chosen elements of the original
+// slice. Each element is chosen at most once. If n is greater than the length
+// of the slice, all elements are returned in random order. If r is nil, the
+// global random number generator is used.
+func Sample[T any](s []T, n int, r *rand.Rand) []T {
+	n = max(0, min(n, len(s)))
+	out := Copy(s)
+	for i := range n {
+		var j int
+		if r == nil {
+			j = i + rand.IntN(len(out)-i)
+		} else {
+			j = i + r.IntN(len(out)-i)
+		}
+		out[i], out[j] = out[j], out[i]
+	}
+	return out[:n:n]
+}
+
+// ParallelMapErr works like MapErr, but the function f is applied to the
+// elements concurrently, using at most the given number of workers. If
+// workers is less than 1, runtime.GOMAXPROCS(0) workers are used. The order
+// of the results matches the order of the elements.
+//
+// Unlike MapErr, ParallelMapErr does not stop at the first error. All errors
+// are combined into an errutil.MultiError, in the order of the elements. If
+// the context is canceled, the remaining elements are skipped and the context
+// error is added to the returned errors. Panics in f are recovered and
+// reported as an errutil.PanicError.
+func ParallelMapErr[T, U any](ctx context.Context, s []T, workers int, f func(context.Context, T) (U, error)) ([]U, error) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var (
+		out  = make([]U, len(s))
+		errs = make([]error, len(s))
+		next atomic.Int64
+		done atomic.Int64
+		wg   sync.WaitGroup
+	)
+	for range min(workers, len(s)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= len(s) {
+					return
+				}
+				out[i], errs[i] = parallelCall(ctx, f, s[i])
+				done.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	err := errutil.Append(nil, errs...)
+	if int(done.Load()) < len(s) {
+		err = errutil.Append(err, ctx.Err())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parallelCall[T, U any](ctx context.Context, f func(context.Context, T) (U, error), x T) (r U, err error) {
+	defer errutil.Recover(&err)
+	return f(ctx, x)
+}
+
+// Find returns the first element of the slice that satisfies the predicate f.
+// If no element satisfies the predicate, the zero value and false are
+// returned.
+func Find[T any](s []T, f func(T) bool) (T, bool) {
+	if i := FindIndex(s, f); i >= 0 {
+		return s[i], true
+	}
+	var zero T
+	return zero, false
+}
+
+// FindLast returns the last element of the slice that satisfies the predicate
+// f. If no element satisfies the predicate, the zero value and false are
+// returned.
+func FindLast[T any](s []T, f func(T) bool) (T, bool) {
+	for i := len(s) - 1; i >= 0; i-- {
+		if f(s[i]) {
+			return s[i], true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// FindIndex returns the index of the first element of the slice that
+// satisfies the predicate f, or -1 if no element satisfies the predicate.
+func FindIndex[T any](s []T, f func(T) bool) int {
+	for i, x := range s {
+		if f(x) {
+			return i
+		}
+	}
+	return -1
+}
+
+// number is a constraint that permits any integer or floating-point type.
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// MinBy returns the element of the slice with the smallest key returned by
+// the function f. If multiple elements have the smallest key, the first one
+// is returned. If the slice is empty, the zero value and false are returned.
+func MinBy[T any, K cmp.Ordered](s []T, f func(T) K) (T, bool) {
+	return extremeBy(s, f, -1)
+}
+
+// MaxBy returns the element of the slice with the largest key returned by
+// the function f. If multiple elements have the largest key, the first one
+// is returned. If the slice is empty, the zero value and false are returned.
+func MaxBy[T any, K cmp.Ordered](s []T, f func(T) K) (T, bool) {
+	return extremeBy(s, f, 1)
+}
+
+// SumBy returns the sum of the values returned by the function f for each
+// element of the slice.
+func SumBy[T any, N number](s []T, f func(T) N) N {
+	var sum N
+	for _, x := range s {
+		sum += f(x)
+	}
+	return sum
+}
+
+// extremeBy returns the element with the smallest key if sign is -1 or the
+// largest key if sign is 1.
+func extremeBy[T any, K cmp.Ordered](s []T, f func(T) K, sign int) (T, bool) {
+	if len(s) == 0 {
+		var zero T
+		return zero, false
+	}
+	res, resKey := s[0], f(s[0])
+	for _, x := range s[1:] {
+		if k := f(x); cmp.Compare(k, resKey) == sign {
+			res, resKey = x, k
+		}
+	}
+	return res, true
+}
+
+// hashThreshold is the number of comparisons above which set operations use
+// a map instead of a linear search.
+const hashThreshold = 1024
+
+// containsFunc returns a function that checks if the slice contains an
+// element. If the function is going to be called n times and the number of
+// comparisons required by a linear search would exceed hashThreshold, the
+// elements are indexed in a map first.
+func containsFunc[T comparable](s []T, n int) func(T) bool {
+	if len(s)*n <= hashThreshold {
+		return func(x T) bool { return Contains(s, x) }
+	}
+	m := make(map[T]struct{}, len(s))
+	for _, x := range s {
+		m[x] = struct{}{}
+	}
+	return func(x T) bool {
+		_, ok := m[x]
+		return ok
+	}
+}
+
+// SortedIntersect returns a new slice with the elements that are present in
+// all slices. All slices must be sorted in ascending order. The result is
+// sorted as well. An element that occurs multiple times in every slice is
+// included as many times as it occurs in the slice where it occurs the least.
+//
+// It runs in linear time, unlike Intersect, which may need to perform a
+// linear search for every element.
+func SortedIntersect[T cmp.Ordered](slices ...[]T) []T {
+	if len(slices) == 0 {
+		return nil
+	}
+	out := Copy(slices[0])
+	for _, s := range slices[1:] {
+		var i, j, n int
+		for i < len(out) && j < len(s) {
+			switch cmp.Compare(out[i], s[j]) {
+			case -1:
+				i++
+			case 1:
+				j++
+			default:
+				out[n] = out[i]
+				n++
+				i++
+				j++
+			}
+		}
+		out = out[:n]
+	}
+	return out
+}
+
+// SortedContainsAll returns true if s slice contains all elements in e slice.
+// Both slices must be sorted in ascending order.
+//
+// It runs in linear time, unlike ContainsAll, which may need to perform a
+// linear search for every element.
+func SortedContainsAll[T cmp.Ordered](s []T, e []T) bool {
+	var i int
+	for _, x := range e {
+		for i < len(s) && cmp.Less(s[i], x) {
+			i++
+		}
+		if i == len(s) || s[i] != x {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterInPlace works like Filter, but it reuses the backing array of the
+// slice instead of allocating a new one. The elements after the new length
+// are zeroed. The original slice must not be used after calling this
+// function.
+func FilterInPlace[T any](s []T, f func(T) bool) []T {
+	n := 0
+	for _, x := range s {
+		if f(x) {
+			s[n] = x
+			n++
+		}
+	}
+	clear(s[n:])
+	return s[:n]
+}
+
+// UniqueInPlace removes duplicate elements from the slice, reusing its
+// backing array. The order of the elements is preserved. The elements after
+// the new length are zeroed. The original slice must not be used after
+// calling this function.
+func UniqueInPlace[T comparable](s []T) []T {
+	seen := make(map[T]struct{}, len(s))
+	return FilterInPlace(s, func(x T) bool {
+		if _, ok := seen[x]; ok {
+			return false
+		}
+		seen[x] = struct{}{}
+		return true
+	})
+}
+
+// Reverse returns a new slice with the elements of the original slice in
+// reverse order.
+func Reverse[T any](s []T) []T {
+	out := make([]T, len(s))
+	for i, x := range s {
+		out[len(s)-1-i] = x
+	}
+	return out
+}
+
+// RotateLeft returns a new slice with the elements of the original slice
+// rotated n positions to the left. A negative n rotates the elements to the
+// right.
+func RotateLeft[T any](s []T, n int) []T {
+	if len(s) == 0 {
+		return []T{}
+	}
+	n %= len(s)
+	if n < 0 {
+		n += len(s)
+	}
+	out := make([]T, 0, len(s))
+	out = append(out, s[n:]...)
+	return append(out, s[:n]...)
+}
+
+// Window returns all windows of the given size that can be obtained by
+// sliding over the slice one element at a time. If size is greater than the
+// length of the slice, an empty slice is returned. The windows share the
+// underlying array with the original slice, but their capacity is limited to
+// their length.
+//
+// Window panics if size is less than 1.
+func Window[T any](s []T, size int) [][]T {
+	if size < 1 {
+		panic("sliceutil.Window: size must be greater than 0")
+	}
+	out := make([][]T, 0, max(0, len(s)-size+1))
+	for i := 0; i+size <= len(s); i++ {
+		out = append(out, s[i:i+size:i+size])
+	}
+	return out
+}
+
+// ErrSkip can be returned by the function passed to MapFilterErr to skip
+// the element.
+var ErrSkip = errors.New("sliceutil: skip element")
+
+// FilterMap returns a new slice with the results of applying the function f
+// to each element of the original slice, for which f returns true.
+func FilterMap[T, U any](s []T, f func(T) (U, bool)) []U {
+	out := make([]U, 0, len(s))
+	for _, x := range s {
+		if u, ok := f(x); ok {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// MapFilterErr works like MapErr, but elements for which the function f
+// returns ErrSkip are left out of the result.
+func MapFilterErr[T, U any](s []T, f func(T) (U, error)) ([]U, error) {
+	out := make([]U, 0, len(s))
+	for _, x := range s {
+		u, err := f(x)
+		if errors.Is(err, ErrSkip) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+// EqualUnordered returns true if both slices contain the same elements, the
+// same number of times, regardless of their order.
+func EqualUnordered[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	c := make(map[T]int, len(a))
+	for _, x := range a {
+		c[x]++
+	}
+	for _, x := range b {
+		if c[x] == 0 {
+			return false
+		}
+		c[x]--
+	}
+	return true
+}
+
+// Associate returns a map with the key/value pairs returned by the function
+// f for each element of the slice. If multiple elements produce the same key,
+// the last one wins.
+func Associate[T any, K comparable, V any](s []T, f func(T) (K, V)) map[K]V {
+	out := make(map[K]V, len(s))
+	for _, x := range s {
+		k, v := f(x)
+		out[k] = v
+	}
+	return out
+}
+
+// KeyBy returns a map of the elements of the slice indexed by the key
+// returned by the function f. If multiple elements produce the same key, the
+// last one wins.
+func KeyBy[T any, K comparable](s []T, f func(T) K) map[K]T {
+	out := make(map[K]T, len(s))
+	for _, x := range s {
+		out[f(x)] = x
+	}
+	return out
+}
@@ -16,10 +16,18 @@
 package sliceutil
 
 import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/chronicleprotocol/go-lib/errutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCopy(t *testing.T) {
@@ -158,3 +166,465 @@ func TestMapErr(t *testing.T) {
 	assert.Equal(t, assert.AnError, err)
 	assert.Nil(t, result)
 }
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		s    []int
+		size int
+		want [][]int
+	}{
+		{name: "empty", s: nil, size: 2, want: [][]int{}},
+		{name: "exact", s: []int{1, 2, 3, 4}, size: 2, want: [][]int{{1, 2}, {3, 4}}},
+		{name: "remainder", s: []int{1, 2, 3, 4, 5}, size: 2, want: [][]int{{1, 2}, {3, 4}, {5}}},
+		{name: "size larger than slice", s: []int{1, 2}, size: 5, want: [][]int{{1, 2}}},
+		{name: "size one", s: []int{1, 2}, size: 1, want: [][]int{{1}, {2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Chunk(tt.s, tt.size))
+		})
+	}
+
+	s := []int{1, 2, 3, 4}
+	c := Chunk(s, 2)
+	_ = append(c[0], 5)
+	assert.Equal(t, []int{1, 2, 3, 4}, s)
+
+	assert.Panics(t, func() { Chunk(s, 0) })
+}
+
+func TestGroupBy(t *testing.T) {
+	s := []string{"apple", "avocado", "banana", "cherry", "blueberry"}
+	g := GroupBy(s, func(x string) byte { return x[0] })
+	assert.Equal(t, map[byte][]string{
+		'a': {"apple", "avocado"},
+		'b': {"banana", "blueberry"},
+		'c': {"cherry"},
+	}, g)
+	assert.Empty(t, GroupBy([]string{}, func(x string) byte { return x[0] }))
+}
+
+func TestCountBy(t *testing.T) {
+	s := []string{"apple", "avocado", "banana", "cherry", "blueberry"}
+	c := CountBy(s, func(x string) byte { return x[0] })
+	assert.Equal(t, map[byte]int{'a': 2, 'b': 2, 'c': 1}, c)
+	assert.Empty(t, CountBy([]string{}, func(x string) byte { return x[0] }))
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce([]int{1, 2, 3}, 0, func(acc, x int) int { return acc + x })
+	assert.Equal(t, 6, sum)
+
+	str := Reduce([]int{1, 2, 3}, "", func(acc string, x int) string { return acc + strconv.Itoa(x) })
+	assert.Equal(t, "123", str)
+
+	assert.Equal(t, 42, Reduce([]int{}, 42, func(acc, x int) int { return acc + x }))
+}
+
+func TestZip(t *testing.T) {
+	assert.Equal(t,
+		[]Pair[string, int]{{"a", 1}, {"b", 2}},
+		Zip([]string{"a", "b"}, []int{1, 2}),
+	)
+	assert.Equal(t,
+		[]Pair[string, int]{{"a", 1}},
+		Zip([]string{"a", "b"}, []int{1}),
+	)
+	assert.Empty(t, Zip([]string{}, []int{1, 2}))
+}
+
+func TestUnzip(t *testing.T) {
+	a, b := Unzip([]Pair[string, int]{{"a", 1}, {"b", 2}})
+	assert.Equal(t, []string{"a", "b"}, a)
+	assert.Equal(t, []int{1, 2}, b)
+
+	a, b = Unzip(Zip([]string{"a", "b", "c"}, []int{1, 2}))
+	assert.Equal(t, []string{"a", "b"}, a)
+	assert.Equal(t, []int{1, 2}, b)
+}
+
+func TestDifference(t *testing.T) {
+	assert.Equal(t, []int{1, 3}, Difference([]int{1, 2, 3, 4}, []int{2, 4, 5}))
+	assert.Equal(t, []int{1, 2}, Difference([]int{1, 2}, nil))
+	assert.Empty(t, Difference(nil, []int{1, 2}))
+	assert.Empty(t, Difference([]int{1, 2}, []int{2, 1}))
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	assert.Equal(t, []int{1, 3, 5}, SymmetricDifference([]int{1, 2, 3, 4}, []int{2, 4, 5}))
+	assert.Equal(t, []int{1, 2}, SymmetricDifference(nil, []int{1, 2}))
+	assert.Empty(t, SymmetricDifference([]int{1, 2}, []int{2, 1}))
+}
+
+func TestPartition(t *testing.T) {
+	even, odd := Partition([]int{1, 2, 3, 4, 5}, func(x int) bool { return x%2 == 0 })
+	assert.Equal(t, []int{2, 4}, even)
+	assert.Equal(t, []int{1, 3, 5}, odd)
+
+	matched, rest := Partition([]int{}, func(x int) bool { return true })
+	assert.Empty(t, matched)
+	assert.Empty(t, rest)
+}
+
+func TestFlatten(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3, 4}, Flatten([][]int{{1, 2}, {}, {3}, nil, {4}}))
+	assert.Empty(t, Flatten([][]int{}))
+}
+
+func TestFlatMap(t *testing.T) {
+	s := FlatMap([]string{"a,b", "", "c"}, func(x string) []string {
+		if x == "" {
+			return nil
+		}
+		return strings.Split(x, ",")
+	})
+	assert.Equal(t, []string{"a", "b", "c"}, s)
+	assert.Empty(t, FlatMap([]string{}, func(x string) []string { return []string{x} }))
+}
+
+func TestShuffle(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	r1 := Shuffle(s, rand.New(rand.NewPCG(1, 2)))
+	r2 := Shuffle(s, rand.New(rand.NewPCG(1, 2)))
+	assert.Equal(t, r1, r2)
+	assert.ElementsMatch(t, s, r1)
+	assert.NotEqual(t, s, r1)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, s)
+
+	assert.ElementsMatch(t, s, Shuffle(s, nil))
+	assert.Empty(t, Shuffle([]int{}, nil))
+}
+
+func TestSample(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	r1 := Sample(s, 3, rand.New(rand.NewPCG(1, 2)))
+	r2 := Sample(s, 3, rand.New(rand.NewPCG(1, 2)))
+	assert.Equal(t, r1, r2)
+	assert.Len(t, r1, 3)
+	assert.True(t, IsUnique(r1))
+	assert.True(t, ContainsAll(s, r1))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, s)
+
+	assert.ElementsMatch(t, s, Sample(s, 20, nil))
+	assert.Empty(t, Sample(s, 0, nil))
+	assert.Empty(t, Sample(s, -1, nil))
+}
+
+func TestParallelMapErr(t *testing.T) {
+	ctx := context.Background()
+	s := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	t.Run("success", func(t *testing.T) {
+		var running, peak atomic.Int32
+		r, err := ParallelMapErr(ctx, s, 3, func(_ context.Context, x int) (string, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return strconv.Itoa(x), nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3", "4", "5", "6", "7", "8"}, r)
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+	})
+	t.Run("errors", func(t *testing.T) {
+		r, err := ParallelMapErr(ctx, s, 0, func(_ context.Context, x int) (int, error) {
+			if x%3 == 0 {
+				return 0, fmt.Errorf("error %d", x)
+			}
+			return x, nil
+		})
+		assert.Nil(t, r)
+		assert.EqualError(t, err, "following errors occurred: [error 3, error 6]")
+	})
+	t.Run("panic", func(t *testing.T) {
+		_, err := ParallelMapErr(ctx, s, 2, func(_ context.Context, x int) (int, error) {
+			if x == 4 {
+				panic("boom")
+			}
+			return x, nil
+		})
+		var pErr *errutil.PanicError
+		assert.ErrorAs(t, err, &pErr)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		r, err := ParallelMapErr(ctx, s, 1, func(_ context.Context, x int) (int, error) {
+			if x == 2 {
+				cancel()
+			}
+			return x, nil
+		})
+		assert.Nil(t, r)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("empty", func(t *testing.T) {
+		r, err := ParallelMapErr(ctx, []int{}, 2, func(_ context.Context, x int) (int, error) {
+			return x, nil
+		})
+		require.NoError(t, err)
+		assert.Empty(t, r)
+	})
+}
+
+func TestFind(t *testing.T) {
+	s := []string{"a", "bb", "cc", "d"}
+	long := func(x string) bool { return len(x) > 1 }
+	none := func(x string) bool { return len(x) > 2 }
+
+	v, ok := Find(s, long)
+	assert.True(t, ok)
+	assert.Equal(t, "bb", v)
+
+	v, ok = Find(s, none)
+	assert.False(t, ok)
+	assert.Empty(t, v)
+}
+
+func TestFindLast(t *testing.T) {
+	s := []string{"a", "bb", "cc", "d"}
+	long := func(x string) bool { return len(x) > 1 }
+	none := func(x string) bool { return len(x) > 2 }
+
+	v, ok := FindLast(s, long)
+	assert.True(t, ok)
+	assert.Equal(t, "cc", v)
+
+	v, ok = FindLast(s, none)
+	assert.False(t, ok)
+	assert.Empty(t, v)
+}
+
+func TestFindIndex(t *testing.T) {
+	s := []string{"a", "bb", "cc", "d"}
+	assert.Equal(t, 1, FindIndex(s, func(x string) bool { return len(x) > 1 }))
+	assert.Equal(t, -1, FindIndex(s, func(x string) bool { return len(x) > 2 }))
+	assert.Equal(t, -1, FindIndex(nil, func(x string) bool { return true }))
+}
+
+func TestMinBy(t *testing.T) {
+	type gateway struct {
+		name    string
+		latency time.Duration
+	}
+	s := []gateway{{"a", 30}, {"b", 10}, {"c", 20}, {"d", 10}}
+	v, ok := MinBy(s, func(g gateway) time.Duration { return g.latency })
+	assert.True(t, ok)
+	assert.Equal(t, "b", v.name)
+
+	_, ok = MinBy([]gateway{}, func(g gateway) time.Duration { return g.latency })
+	assert.False(t, ok)
+}
+
+func TestMaxBy(t *testing.T) {
+	s := []string{"bb", "a", "cc", "d"}
+	v, ok := MaxBy(s, func(x string) int { return len(x) })
+	assert.True(t, ok)
+	assert.Equal(t, "bb", v)
+
+	_, ok = MaxBy([]string{}, func(x string) int { return len(x) })
+	assert.False(t, ok)
+}
+
+func TestSumBy(t *testing.T) {
+	s := []string{"bb", "a", "ccc"}
+	assert.Equal(t, 6, SumBy(s, func(x string) int { return len(x) }))
+	assert.Equal(t, 1.5, SumBy(s, func(x string) float64 { return 0.5 }))
+	assert.Equal(t, uint64(0), SumBy([]string{}, func(x string) uint64 { return 1 }))
+}
+
+func TestSortedIntersect(t *testing.T) {
+	tests := []struct {
+		name   string
+		slices [][]int
+		want   []int
+	}{
+		{name: "no slices", slices: nil, want: nil},
+		{name: "single slice", slices: [][]int{{1, 2, 3}}, want: []int{1, 2, 3}},
+		{name: "two slices", slices: [][]int{{1, 2, 3, 5}, {2, 3, 4, 5}}, want: []int{2, 3, 5}},
+		{name: "three slices", slices: [][]int{{1, 2, 3, 5}, {2, 3, 4, 5}, {3, 5, 7}}, want: []int{3, 5}},
+		{name: "disjoint", slices: [][]int{{1, 2}, {3, 4}}, want: []int{}},
+		{name: "duplicates", slices: [][]int{{1, 1, 1, 2}, {1, 1, 2, 2}}, want: []int{1, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SortedIntersect(tt.slices...))
+		})
+	}
+}
+
+func TestSortedContainsAll(t *testing.T) {
+	assert.True(t, SortedContainsAll([]int{1, 2, 3, 4}, []int{2, 4}))
+	assert.True(t, SortedContainsAll([]int{1, 2, 3, 4}, []int{2, 2, 4}))
+	assert.True(t, SortedContainsAll([]int{1, 2}, nil))
+	assert.False(t, SortedContainsAll([]int{1, 2, 3, 4}, []int{2, 5}))
+	assert.False(t, SortedContainsAll([]int{1, 3}, []int{2}))
+	assert.False(t, SortedContainsAll(nil, []int{1}))
+}
+
+func TestIntersect_Large(t *testing.T) {
+	a := make([]int, 2000)
+	b := make([]int, 2000)
+	for i := range a {
+		a[i] = i
+		b[i] = i * 2
+	}
+	want := make([]int, 1000)
+	for i := range want {
+		want[i] = i * 2
+	}
+	assert.Equal(t, want, Intersect(a, b))
+	assert.Equal(t, want, SortedIntersect(a, b))
+	assert.True(t, ContainsAll(a, want))
+	assert.True(t, SortedContainsAll(a, want))
+	assert.False(t, ContainsAll(want, a))
+	assert.False(t, SortedContainsAll(want, a))
+}
+
+func BenchmarkIntersect(b *testing.B) {
+	x := make([]int, 5000)
+	y := make([]int, 5000)
+	for i := range x {
+		x[i] = i
+		y[i] = i * 2
+	}
+	b.Run("Intersect", func(b *testing.B) {
+		for range b.N {
+			Intersect(x, y)
+		}
+	})
+	b.Run("SortedIntersect", func(b *testing.B) {
+		for range b.N {
+			SortedIntersect(x, y)
+		}
+	})
+}
+
+func TestFilterInPlace(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+	r := FilterInPlace(s, func(x int) bool { return x%2 == 1 })
+	assert.Equal(t, []int{1, 3, 5}, r)
+	assert.Same(t, &s[0], &r[0])
+	assert.Equal(t, []int{1, 3, 5, 0, 0}, s)
+
+	assert.Empty(t, FilterInPlace([]int{}, func(x int) bool { return true }))
+}
+
+func TestUniqueInPlace(t *testing.T) {
+	s := []int{1, 2, 1, 3, 2, 4}
+	r := UniqueInPlace(s)
+	assert.Equal(t, []int{1, 2, 3, 4}, r)
+	assert.Same(t, &s[0], &r[0])
+	assert.Equal(t, []int{1, 2, 3, 4, 0, 0}, s)
+
+	assert.Empty(t, UniqueInPlace([]int{}))
+}
+
+func TestReverse(t *testing.T) {
+	s := []int{1, 2, 3}
+	assert.Equal(t, []int{3, 2, 1}, Reverse(s))
+	assert.Equal(t, []int{1, 2, 3}, s)
+	assert.Empty(t, Reverse([]int{}))
+}
+
+func TestRotateLeft(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{n: 0, want: []int{1, 2, 3, 4, 5}},
+		{n: 2, want: []int{3, 4, 5, 1, 2}},
+		{n: 5, want: []int{1, 2, 3, 4, 5}},
+		{n: 7, want: []int{3, 4, 5, 1, 2}},
+		{n: -1, want: []int{5, 1, 2, 3, 4}},
+		{n: -6, want: []int{5, 1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.n), func(t *testing.T) {
+			assert.Equal(t, tt.want, RotateLeft(s, tt.n))
+		})
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, s)
+	assert.Empty(t, RotateLeft([]int{}, 3))
+}
+
+func TestWindow(t *testing.T) {
+	s := []int{1, 2, 3, 4}
+	assert.Equal(t, [][]int{{1, 2}, {2, 3}, {3, 4}}, Window(s, 2))
+	assert.Equal(t, [][]int{{1, 2, 3, 4}}, Window(s, 4))
+	assert.Equal(t, [][]int{{1}, {2}, {3}, {4}}, Window(s, 1))
+	assert.Empty(t, Window(s, 5))
+	assert.Panics(t, func() { Window(s, 0) })
+}
+
+func TestFilterMap(t *testing.T) {
+	r := FilterMap([]string{"1", "a", "3"}, func(x string) (int, bool) {
+		n, err := strconv.Atoi(x)
+		return n, err == nil
+	})
+	assert.Equal(t, []int{1, 3}, r)
+	assert.Empty(t, FilterMap([]string{}, func(x string) (int, bool) { return 0, true }))
+}
+
+func TestMapFilterErr(t *testing.T) {
+	parse := func(x string) (int, error) {
+		if x == "" {
+			return 0, ErrSkip
+		}
+		return strconv.Atoi(x)
+	}
+
+	r, err := MapFilterErr([]string{"1", "", "3"}, parse)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, r)
+
+	r, err = MapFilterErr([]string{"1", "", "a"}, parse)
+	assert.Error(t, err)
+	assert.Nil(t, r)
+}
+
+func TestEqualUnordered(t *testing.T) {
+	tests := []struct {
+		a, b []int
+		want bool
+	}{
+		{a: nil, b: nil, want: true},
+		{a: nil, b: []int{}, want: true},
+		{a: []int{1, 2, 3}, b: []int{3, 1, 2}, want: true},
+		{a: []int{1, 1, 2}, b: []int{1, 2, 1}, want: true},
+		{a: []int{1, 1, 2}, b: []int{1, 2, 2}, want: false},
+		{a: []int{1, 2}, b: []int{1, 2, 3}, want: false},
+		{a: []int{1, 2}, b: []int{1, 3}, want: false},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			assert.Equal(t, tt.want, EqualUnordered(tt.a, tt.b))
+		})
+	}
+}
+
+func TestAssociate(t *testing.T) {
+	m := Associate([]string{"a=1", "b=2", "a=3"}, func(x string) (string, string) {
+		k, v, _ := strings.Cut(x, "=")
+		return k, v
+	})
+	assert.Equal(t, map[string]string{"a": "3", "b": "2"}, m)
+	assert.Empty(t, Associate([]string{}, func(x string) (string, int) { return x, 0 }))
+}
+
+func TestKeyBy(t *testing.T) {
+	type block struct {
+		label string
+		value int
+	}
+	m := KeyBy([]block{{"a", 1}, {"b", 2}, {"a", 3}}, func(b block) string { return b.label })
+	assert.Equal(t, map[string]block{"a": {"a", 3}, "b": {"b", 2}}, m)
+	assert.Empty(t, KeyBy([]block{}, func(b block) string { return b.label }))
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package syncutil provides synchronization primitives that complement the
+// sync package.
+package syncutil
+
+import (
+	"sync"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// Group deduplicates concurrent calls that share the same key. While a call
+// for a key is in progress, other calls for the same key wait for it and
+// receive its result instead of running their own function.
+//
+// The zero value is ready to use. A Group must not be copied after first
+// use.
+type Group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+// call is an in-progress or completed Group call.
+type call[V any] struct {
+	wg  sync.WaitGroup
+	val V
+	err error
+	dup int
+}
+
+// Do calls fn and returns its results, unless a call for the same key is
+// already in progress, in which case Do waits for it and returns its results.
+// The shared result reports whether the results were given to more than one
+// caller.
+//
+// A panic in fn is recovered and returned to all callers as an
+// errutil.PanicError.
+func (g *Group[K, V]) Do(key K, fn func() (V, error)) (v V, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dup++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := new(call[V])
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	g.run(key, c, fn)
+	return c.val, c.err, c.dup > 0
+}
+
+// Forget makes the next call to Do for the key run its function instead of
+// waiting for a call that is already in progress.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
+
+// run calls fn, stores its results in c and wakes up waiting callers.
+func (g *Group[K, V]) run(key K, c *call[V], fn func() (V, error)) {
+	defer func() {
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	defer errutil.Recover(&c.err)
+	c.val, c.err = fn()
+}
@@ -0,0 +1,138 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package syncutil
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var (
+		g       Group[string, int]
+		calls   atomic.Int32
+		release = make(chan struct{})
+		started = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	fn := func() (int, error) {
+		calls.Add(1)
+		close(started)
+		<-release
+		return 42, nil
+	}
+
+	results := make([]int, 5)
+	shared := make([]bool, 5)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _, shared[0] = g.Do("key", fn)
+	}()
+	<-started
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _, shared[i] = g.Do("key", fn)
+		}()
+	}
+	// Wait until all callers are waiting for the first call.
+	for {
+		g.mu.Lock()
+		dup := g.calls["key"].dup
+		g.mu.Unlock()
+		if dup == len(results)-1 {
+			break
+		}
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, []int{42, 42, 42, 42, 42}, results)
+	assert.Equal(t, []bool{true, true, true, true, true}, shared)
+}
+
+func TestGroup_DoSequential(t *testing.T) {
+	var (
+		g     Group[string, int]
+		calls int
+	)
+	fn := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+	v, err, shared := g.Do("key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.False(t, shared)
+	v, _, _ = g.Do("key", fn)
+	assert.Equal(t, 2, v)
+	v, _, _ = g.Do("other", fn)
+	assert.Equal(t, 3, v)
+}
+
+func TestGroup_DoError(t *testing.T) {
+	var g Group[string, int]
+	errTest := errors.New("test")
+	_, err, _ := g.Do("key", func() (int, error) { return 0, errTest })
+	assert.ErrorIs(t, err, errTest)
+}
+
+func TestGroup_DoPanic(t *testing.T) {
+	var g Group[string, int]
+	_, err, _ := g.Do("key", func() (int, error) { panic("boom") })
+	_, ok := errutil.As[*errutil.PanicError](err)
+	assert.True(t, ok)
+
+	// The key must be usable after a panic.
+	v, err, _ := g.Do("key", func() (int, error) { return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestGroup_Forget(t *testing.T) {
+	var (
+		g       Group[string, int]
+		release = make(chan struct{})
+		started = make(chan struct{})
+		done    = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		g.Do("key", func() (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+	g.Forget("key")
+	v, _, shared := g.Do("key", func() (int, error) { return 2, nil })
+	assert.Equal(t, 2, v)
+	assert.False(t, shared)
+	close(release)
+	<-done
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package syncutil
+
+import "sync"
+
+// KeyedMutex is a set of mutexes identified by keys. Locking one key does not
+// block other keys. Mutexes are created on demand and removed once they are
+// no longer held or awaited, so the set does not grow with the number of
+// distinct keys used over time.
+//
+// The zero value is ready to use. A KeyedMutex must not be copied after first
+// use.
+type KeyedMutex[K comparable] struct {
+	mu    sync.Mutex
+	locks map[K]*keyedLock
+}
+
+// keyedLock is a mutex with the number of goroutines holding or waiting for
+// it.
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Lock locks the mutex for the key. If the mutex is already locked, Lock
+// blocks until it is available.
+func (m *KeyedMutex[K]) Lock(key K) {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[K]*keyedLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = new(keyedLock)
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+	l.mu.Lock()
+}
+
+// TryLock tries to lock the mutex for the key and reports whether it
+// succeeded.
+func (m *KeyedMutex[K]) TryLock(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.locks[key]; ok {
+		// Another goroutine holds or waits for the mutex.
+		return false
+	}
+	if m.locks == nil {
+		m.locks = make(map[K]*keyedLock)
+	}
+	l := &keyedLock{refs: 1}
+	l.mu.Lock()
+	m.locks[key] = l
+	return true
+}
+
+// Unlock unlocks the mutex for the key. It panics if the mutex is not
+// locked.
+func (m *KeyedMutex[K]) Unlock(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.locks[key]
+	if !ok {
+		panic("syncutil: unlock of unlocked keyed mutex")
+	}
+	l.refs--
+	if l.refs == 0 {
+		delete(m.locks, key)
+	}
+	l.mu.Unlock()
+}
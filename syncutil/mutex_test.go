@@ -0,0 +1,69 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package syncutil
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	var (
+		m        KeyedMutex[string]
+		wg       sync.WaitGroup
+		keys     = []string{"a", "b", "c"}
+		counters = map[string]*int{"a": new(int), "b": new(int), "c": new(int)}
+	)
+	for i := 0; i < 300; i++ {
+		key := keys[i%len(keys)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Lock(key)
+			defer m.Unlock(key)
+			*counters[key]++
+		}()
+	}
+	wg.Wait()
+	for _, key := range keys {
+		assert.Equal(t, 100, *counters[key])
+	}
+	assert.Empty(t, m.locks)
+}
+
+func TestKeyedMutex_Independent(t *testing.T) {
+	var m KeyedMutex[int]
+	m.Lock(1)
+	defer m.Unlock(1)
+	assert.True(t, m.TryLock(2))
+	m.Unlock(2)
+}
+
+func TestKeyedMutex_TryLock(t *testing.T) {
+	var m KeyedMutex[string]
+	assert.True(t, m.TryLock("key"))
+	assert.False(t, m.TryLock("key"))
+	m.Unlock("key")
+	assert.True(t, m.TryLock("key"))
+	m.Unlock("key")
+	assert.Empty(t, m.locks)
+}
+
+func TestKeyedMutex_UnlockUnlocked(t *testing.T) {
+	var m KeyedMutex[string]
+	assert.Panics(t, func() { m.Unlock("key") })
+}
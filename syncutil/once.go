@@ -0,0 +1,70 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package syncutil
+
+import (
+	"sync"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+// Once computes a value once and returns it on every subsequent call.
+//
+// Unlike sync.OnceValues, an error is not stored: if the function fails, the
+// next call to Do tries again. This makes Once suitable for lazily loading
+// resources that may be temporarily unavailable.
+//
+// The zero value is ready to use. A Once must not be copied after first use.
+type Once[T any] struct {
+	mu   sync.Mutex
+	done bool
+	val  T
+}
+
+// Do returns the stored value. If no value is stored, Do calls fn and, if
+// it succeeds, stores its result. Concurrent calls wait for the running call
+// to finish. A panic in fn is recovered and returned as an
+// errutil.PanicError.
+func (o *Once[T]) Do(fn func() (T, error)) (v T, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.done {
+		return o.val, nil
+	}
+	defer errutil.Recover(&err)
+	v, err = fn()
+	if err != nil {
+		return v, err
+	}
+	o.val, o.done = v, true
+	return v, nil
+}
+
+// Get returns the stored value and reports whether it is present, without
+// calling any function. If a call to Do is in progress, Get waits for it.
+func (o *Once[T]) Get() (v T, ok bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.val, o.done
+}
+
+// Reset removes the stored value, so the next call to Do calls its function
+// again.
+func (o *Once[T]) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var zero T
+	o.val, o.done = zero, false
+}
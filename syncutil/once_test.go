@@ -0,0 +1,85 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package syncutil
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chronicleprotocol/go-lib/errutil"
+)
+
+func TestOnce_Do(t *testing.T) {
+	var (
+		o     Once[int]
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := o.Do(func() (int, error) {
+				calls.Add(1)
+				return 42, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestOnce_DoError(t *testing.T) {
+	var o Once[int]
+	errTest := errors.New("test")
+	_, err := o.Do(func() (int, error) { return 0, errTest })
+	assert.ErrorIs(t, err, errTest)
+	_, ok := o.Get()
+	assert.False(t, ok)
+
+	// A failed call is not stored, so the function is called again.
+	v, err := o.Do(func() (int, error) { return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestOnce_DoPanic(t *testing.T) {
+	var o Once[int]
+	_, err := o.Do(func() (int, error) { panic("boom") })
+	_, ok := errutil.As[*errutil.PanicError](err)
+	assert.True(t, ok)
+	_, ok = o.Get()
+	assert.False(t, ok)
+}
+
+func TestOnce_Reset(t *testing.T) {
+	var o Once[int]
+	_, _ = o.Do(func() (int, error) { return 1, nil })
+	v, ok := o.Get()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	o.Reset()
+	_, ok = o.Get()
+	assert.False(t, ok)
+	v, _ = o.Do(func() (int, error) { return 2, nil })
+	assert.Equal(t, 2, v)
+}
@@ -0,0 +1,254 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package testutil provides helpers for testing code that uses this library.
+package testutil
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IPFSGatewayOption is an option for NewIPFSGateway.
+type IPFSGatewayOption func(*IPFSGateway)
+
+// WithIPFSLatency delays every response of the gateway by d.
+func WithIPFSLatency(d time.Duration) IPFSGatewayOption {
+	return func(g *IPFSGateway) {
+		g.latency = d
+	}
+}
+
+// WithIPFSFailures makes the gateway respond to the first n requests with
+// the 502 Bad Gateway status. If n is negative, all requests fail.
+func WithIPFSFailures(n int) IPFSGatewayOption {
+	return func(g *IPFSGateway) {
+		g.failures = n
+	}
+}
+
+// WithIPFSCorruption makes the gateway modify the content of every file
+// it serves, as a misconfigured or malicious gateway could do. It is used
+// to test checksum verification.
+func WithIPFSCorruption() IPFSGatewayOption {
+	return func(g *IPFSGateway) {
+		g.corrupt = true
+	}
+}
+
+// IPFSGateway is a fake IPFS gateway that serves files added using AddFile.
+// It is based on httptest.Server and supports both path resolution, in the
+// form of "/ipfs/<cid>/<path>", and subdomain resolution, in the form of
+// "<cid>.<host>/<path>". IPNS records added using AddIPNSRecord are served
+// in the form of "/ipns/<name>".
+//
+// Subdomains of the gateway host cannot be resolved using DNS, so requests
+// must be sent using the client returned by the Client method, which
+// connects to the gateway regardless of the requested host.
+//
+// The gateway can be used with fsutil.NewIPFSFS:
+//
+//	gw := testutil.NewIPFSGateway()
+//	defer gw.Close()
+//	gw.AddFile("QmTest", "config.hcl", data)
+//	fs, err := fsutil.NewIPFSFS(ctx, "QmTest",
+//		fsutil.WithIPFSHTTPClient(gw.Client()),
+//		fsutil.WithIPFSGateways(&fsutil.IPFSGateway{
+//			Scheme:    gw.Scheme(),
+//			Host:      gw.Host(),
+//			ResolveFn: fsutil.IPFSPathResolution,
+//		}),
+//	)
+type IPFSGateway struct {
+	server   *httptest.Server
+	client   *http.Client
+	latency  time.Duration
+	corrupt  bool
+	mu       sync.Mutex
+	failures int
+	requests int
+	files    map[string]map[string][]byte // CID -> path -> content
+	records  map[string][]byte            // IPNS name -> record
+}
+
+// NewIPFSGateway starts a new fake IPFS gateway. The gateway must be closed
+// using the Close method.
+func NewIPFSGateway(opts ...IPFSGatewayOption) *IPFSGateway {
+	g := &IPFSGateway{
+		files:   make(map[string]map[string][]byte),
+		records: make(map[string][]byte),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.server = httptest.NewServer(http.HandlerFunc(g.serveHTTP))
+	addr := g.server.Listener.Addr().String()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+	g.client = &http.Client{Transport: transport}
+	return g
+}
+
+// Close shuts down the gateway.
+func (g *IPFSGateway) Close() {
+	g.client.CloseIdleConnections()
+	g.server.Close()
+}
+
+// Scheme returns the URL scheme of the gateway.
+func (g *IPFSGateway) Scheme() string {
+	return "http"
+}
+
+// Host returns the host of the gateway, including the port.
+func (g *IPFSGateway) Host() string {
+	return g.server.Listener.Addr().String()
+}
+
+// URL returns the base URL of the gateway.
+func (g *IPFSGateway) URL() string {
+	return g.server.URL
+}
+
+// Client returns an HTTP client that sends all requests to the gateway.
+func (g *IPFSGateway) Client() *http.Client {
+	return g.client
+}
+
+// AddFile adds a file with the given content to the gateway. If path is
+// empty, the CID itself refers to the file.
+func (g *IPFSGateway) AddFile(cid, path string, data []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.files[cid] == nil {
+		g.files[cid] = make(map[string][]byte)
+	}
+	g.files[cid][strings.Trim(path, "/")] = data
+}
+
+// AddIPNSRecord adds the serialized IPNS record of the given name to the
+// gateway. Records are served as they are, even if corruption is enabled,
+// so that they can be corrupted by the caller as needed.
+func (g *IPFSGateway) AddIPNSRecord(name string, record []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.records[name] = record
+}
+
+// Requests returns the number of requests received by the gateway.
+func (g *IPFSGateway) Requests() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.requests
+}
+
+func (g *IPFSGateway) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	g.requests++
+	fail := g.failures != 0
+	if g.failures > 0 {
+		g.failures--
+	}
+	g.mu.Unlock()
+
+	if g.latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(g.latency):
+		}
+	}
+	if fail {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if name, found := strings.CutPrefix(r.URL.Path, "/ipns/"); found {
+		g.serveIPNSRecord(w, r, name)
+		return
+	}
+	cid, path, ok := g.resolve(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	g.mu.Lock()
+	data, ok := g.files[cid][path]
+	g.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if g.corrupt {
+		data = corrupt(data)
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(data)
+}
+
+// serveIPNSRecord serves the IPNS record of the name. As in real gateways,
+// the record is only returned if it is explicitly requested.
+func (g *IPFSGateway) serveIPNSRecord(w http.ResponseWriter, r *http.Request, name string) {
+	if r.URL.Query().Get("format") != "ipns-record" && r.Header.Get("Accept") != ipnsRecordContentType {
+		http.Error(w, "only IPNS records are supported", http.StatusNotImplemented)
+		return
+	}
+	g.mu.Lock()
+	record, ok := g.records[name]
+	g.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", ipnsRecordContentType)
+	_, _ = w.Write(record)
+}
+
+const ipnsRecordContentType = "application/vnd.ipfs.ipns-record"
+
+// resolve returns the CID and the path requested using either path or
+// subdomain resolution.
+func (g *IPFSGateway) resolve(r *http.Request) (cid, path string, ok bool) {
+	if sub, found := strings.CutSuffix(r.Host, "."+g.Host()); found {
+		return sub, strings.Trim(r.URL.Path, "/"), sub != ""
+	}
+	rest, found := strings.CutPrefix(r.URL.Path, "/ipfs/")
+	if !found {
+		return "", "", false
+	}
+	cid, path, _ = strings.Cut(rest, "/")
+	return cid, strings.Trim(path, "/"), cid != ""
+}
+
+// corrupt returns a copy of data with its content modified.
+func corrupt(data []byte) []byte {
+	if len(data) == 0 {
+		return []byte{0}
+	}
+	c := make([]byte, len(data))
+	copy(c, data)
+	c[len(c)-1] ^= 0xff
+	return c
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package testutil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFSGateway(t *testing.T) {
+	gw := NewIPFSGateway()
+	defer gw.Close()
+	gw.AddFile("QmTest", "", []byte("root"))
+	gw.AddFile("QmTest", "dir/file.txt", []byte("file"))
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "path root", url: gw.URL() + "/ipfs/QmTest", wantStatus: http.StatusOK, wantBody: "root"},
+		{name: "path file", url: gw.URL() + "/ipfs/QmTest/dir/file.txt", wantStatus: http.StatusOK, wantBody: "file"},
+		{name: "subdomain root", url: "http://QmTest." + gw.Host(), wantStatus: http.StatusOK, wantBody: "root"},
+		{name: "subdomain file", url: "http://QmTest." + gw.Host() + "/dir/file.txt", wantStatus: http.StatusOK, wantBody: "file"},
+		{name: "missing file", url: gw.URL() + "/ipfs/QmTest/missing.txt", wantStatus: http.StatusNotFound},
+		{name: "missing cid", url: gw.URL() + "/ipfs/QmMissing", wantStatus: http.StatusNotFound},
+		{name: "not ipfs path", url: gw.URL() + "/foo", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, gw.Client(), tt.url)
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantBody, body)
+			}
+		})
+	}
+	assert.Equal(t, len(tests), gw.Requests())
+}
+
+func TestIPFSGateway_Failures(t *testing.T) {
+	gw := NewIPFSGateway(WithIPFSFailures(2))
+	defer gw.Close()
+	gw.AddFile("QmTest", "", []byte("root"))
+
+	for range 2 {
+		status, _ := get(t, gw.Client(), gw.URL()+"/ipfs/QmTest")
+		assert.Equal(t, http.StatusBadGateway, status)
+	}
+	status, body := get(t, gw.Client(), gw.URL()+"/ipfs/QmTest")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "root", body)
+}
+
+func TestIPFSGateway_Corruption(t *testing.T) {
+	gw := NewIPFSGateway(WithIPFSCorruption())
+	defer gw.Close()
+	gw.AddFile("QmTest", "", []byte("root"))
+
+	status, body := get(t, gw.Client(), gw.URL()+"/ipfs/QmTest")
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEqual(t, "root", body)
+	assert.Len(t, body, 4)
+}
+
+func TestIPFSGateway_Latency(t *testing.T) {
+	gw := NewIPFSGateway(WithIPFSLatency(time.Hour))
+	defer gw.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gw.URL()+"/ipfs/QmTest", nil)
+	require.NoError(t, err)
+	_, err = gw.Client().Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestIPFSGateway_IPNSRecord(t *testing.T) {
+	gw := NewIPFSGateway()
+	defer gw.Close()
+	gw.AddIPNSRecord("k51Test", []byte("record"))
+
+	status, body := get(t, gw.Client(), gw.URL()+"/ipns/k51Test?format=ipns-record")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "record", body)
+
+	status, _ = get(t, gw.Client(), gw.URL()+"/ipns/k51Test")
+	assert.Equal(t, http.StatusNotImplemented, status)
+
+	status, _ = get(t, gw.Client(), gw.URL()+"/ipns/k51Missing?format=ipns-record")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+	res, err := client.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(b)
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package timeutil provides time related helpers.
+//
+// The Clock interface abstracts access to the current time and to timers,
+// so that time-dependent code can be tested without real sleeps. Production
+// code uses the clock returned by Real, and tests use a Fake clock that is
+// advanced manually.
+package timeutil
+
+import "time"
+
+// Clock provides access to the current time and to timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a timer that sends the current time on its channel
+	// after at least duration d.
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates a ticker that sends the current time on its channel
+	// every period d. It panics if d is not positive.
+	NewTicker(d time.Duration) Ticker
+
+	// Sleep pauses the current goroutine for at least duration d.
+	Sleep(d time.Duration)
+}
+
+// Timer is the interface of a timer created by a Clock. It mirrors the
+// methods of time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It returns false if the timer
+	// has already expired or been stopped.
+	Stop() bool
+
+	// Reset changes the timer to expire after duration d. It returns true
+	// if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the interface of a ticker created by a Clock. It mirrors the
+// methods of time.Ticker.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker.
+	Stop()
+
+	// Reset stops the ticker and resets its period to d.
+	Reset(d time.Duration)
+}
+
+// Real returns a clock that uses the functions from the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+// OrReal returns c if it is not nil, otherwise it returns the real clock.
+// It is intended for packages that accept an optional clock.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return realClock{}
+	}
+	return c
+}
+
+type realClock struct{}
+
+// Now implements the Clock interface.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer implements the Clock interface.
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+// NewTicker implements the Clock interface.
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// Sleep implements the Clock interface.
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+type realTimer struct{ t *time.Timer }
+
+// C implements the Timer interface.
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+
+// Stop implements the Timer interface.
+func (t realTimer) Stop() bool { return t.t.Stop() }
+
+// Reset implements the Timer interface.
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+// C implements the Ticker interface.
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+
+// Stop implements the Ticker interface.
+func (t realTicker) Stop() { t.t.Stop() }
+
+// Reset implements the Ticker interface.
+func (t realTicker) Reset(d time.Duration) { t.t.Reset(d) }
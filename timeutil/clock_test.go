@@ -0,0 +1,47 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReal(t *testing.T) {
+	c := Real()
+
+	before := time.Now()
+	assert.False(t, c.Now().Before(before))
+
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	assert.False(t, timer.Stop())
+
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	<-ticker.C()
+	ticker.Stop()
+
+	c.Sleep(time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(before), 3*time.Millisecond)
+}
+
+func TestOrReal(t *testing.T) {
+	fake := NewFake(time.Unix(0, 0))
+	assert.Equal(t, Real(), OrReal(nil))
+	assert.Same(t, fake, OrReal(fake))
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package timeutil
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock for tests. The time of a fake clock changes only when the
+// Advance or Set method is called, at which point the timers and tickers
+// that expire are fired in order.
+//
+// Because the code under test usually runs in a separate goroutine, tests
+// should call BlockUntil before advancing the clock, to make sure that the
+// code has created its timers.
+//
+// Fake is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a timer or a ticker created by a fake clock.
+type fakeWaiter struct {
+	clock    *Fake
+	ch       chan time.Time
+	deadline time.Time
+	period   time.Duration // Zero for timers.
+}
+
+// NewFake returns a fake clock set to the given time.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now implements the Clock interface.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer implements the Clock interface.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: f, ch: make(chan time.Time, 1)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.schedule(w, d)
+	return fakeTimer{w}
+}
+
+// NewTicker implements the Clock interface.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("timeutil: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: f, ch: make(chan time.Time, 1), period: d}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.schedule(w, d)
+	return fakeTicker{w}
+}
+
+// Sleep implements the Clock interface. It blocks until the clock is
+// advanced by at least d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.NewTimer(d).C()
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers that
+// expire in the meantime.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.advanceTo(f.now.Add(d))
+}
+
+// Set moves the clock to the given time, firing the timers and tickers that
+// expire in the meantime. Setting the clock back in time does not fire
+// anything.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.advanceTo(t)
+}
+
+// Waiters returns the number of active timers and tickers.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until there are at least n active timers and tickers.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// advanceTo fires the waiters that expire before t, in order of their
+// deadlines, then sets the clock to t. The lock must be held.
+func (f *Fake) advanceTo(t time.Time) {
+	for {
+		w := f.next()
+		if w == nil || w.deadline.After(t) {
+			break
+		}
+		if w.deadline.After(f.now) {
+			f.now = w.deadline
+		}
+		w.fire(f.now)
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			f.remove(w)
+		}
+	}
+	f.now = t
+}
+
+// next returns the waiter with the earliest deadline. The lock must be held.
+func (f *Fake) next() *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range f.waiters {
+		if next == nil || w.deadline.Before(next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+// schedule adds the waiter to the list of active waiters. Timers with
+// a non-positive duration fire immediately. The lock must be held.
+func (f *Fake) schedule(w *fakeWaiter, d time.Duration) {
+	if d <= 0 && w.period == 0 {
+		w.fire(f.now)
+		return
+	}
+	w.deadline = f.now.Add(d)
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+// remove removes the waiter from the list of active waiters and reports
+// whether it was active. The lock must be held.
+func (f *Fake) remove(w *fakeWaiter) bool {
+	for i, v := range f.waiters {
+		if v == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fire sends the time on the channel. Like the tickers from the time package,
+// it drops the value if the previous one was not received yet.
+func (w *fakeWaiter) fire(t time.Time) {
+	select {
+	case w.ch <- t:
+	default:
+	}
+}
+
+// drain discards a value that was sent but not received yet, so that a
+// stopped or reset waiter never delivers a stale value.
+func (w *fakeWaiter) drain() {
+	select {
+	case <-w.ch:
+	default:
+	}
+}
+
+// stop removes the waiter from the clock and reports whether it was active.
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	w.drain()
+	return w.clock.remove(w)
+}
+
+// reset reschedules the waiter to expire after d and reports whether it
+// was active. For tickers, d also becomes the new period.
+func (w *fakeWaiter) reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	if w.period > 0 {
+		w.period = d
+	}
+	w.drain()
+	active := w.clock.remove(w)
+	w.clock.schedule(w, d)
+	return active
+}
+
+type fakeTimer struct{ w *fakeWaiter }
+
+// C implements the Timer interface.
+func (t fakeTimer) C() <-chan time.Time { return t.w.ch }
+
+// Stop implements the Timer interface.
+func (t fakeTimer) Stop() bool { return t.w.stop() }
+
+// Reset implements the Timer interface.
+func (t fakeTimer) Reset(d time.Duration) bool { return t.w.reset(d) }
+
+type fakeTicker struct{ w *fakeWaiter }
+
+// C implements the Ticker interface.
+func (t fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+// Stop implements the Ticker interface.
+func (t fakeTicker) Stop() { t.w.stop() }
+
+// Reset implements the Ticker interface.
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("timeutil: non-positive interval for Ticker.Reset")
+	}
+	t.w.reset(d)
+}
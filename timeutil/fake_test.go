@@ -0,0 +1,148 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake_Timer(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFake(start)
+
+	timer := c.NewTimer(time.Second)
+	assert.Equal(t, 1, c.Waiters())
+
+	c.Advance(999 * time.Millisecond)
+	assertNoValue(t, timer.C())
+
+	c.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.Equal(t, 0, c.Waiters())
+	assert.False(t, timer.Stop())
+
+	// Reset after expiration.
+	assert.False(t, timer.Reset(time.Second))
+	c.Advance(time.Hour)
+	assert.Equal(t, start.Add(2*time.Second), <-timer.C())
+	assert.Equal(t, start.Add(time.Hour+time.Second), c.Now())
+
+	// Stop before expiration.
+	timer.Reset(time.Second)
+	assert.True(t, timer.Stop())
+	c.Advance(time.Hour)
+	assertNoValue(t, timer.C())
+
+	// Non-positive duration fires immediately.
+	timer = c.NewTimer(0)
+	assert.Equal(t, c.Now(), <-timer.C())
+}
+
+func TestFake_Timer_ResetDiscardsStaleValue(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	timer := c.NewTimer(time.Second)
+	c.Advance(time.Second)
+
+	// The value was not received before the reset.
+	timer.Reset(time.Second)
+	assertNoValue(t, timer.C())
+	c.Advance(time.Second)
+	assert.Equal(t, time.Unix(2, 0), <-timer.C())
+}
+
+func TestFake_Ticker(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFake(start)
+
+	ticker := c.NewTicker(time.Second)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-ticker.C())
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-ticker.C())
+
+	// Ticks are dropped if they are not received.
+	c.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(3*time.Second), <-ticker.C())
+	assertNoValue(t, ticker.C())
+
+	ticker.Reset(time.Minute)
+	c.Advance(time.Second)
+	assertNoValue(t, ticker.C())
+	c.Advance(time.Minute)
+	assert.Equal(t, start.Add(7*time.Second+time.Minute), <-ticker.C())
+
+	ticker.Stop()
+	assert.Equal(t, 0, c.Waiters())
+	c.Advance(time.Hour)
+	assertNoValue(t, ticker.C())
+
+	assert.Panics(t, func() { c.NewTicker(0) })
+	assert.Panics(t, func() { ticker.Reset(0) })
+}
+
+func TestFake_Order(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	t1 := c.NewTimer(2 * time.Second)
+	t2 := c.NewTimer(time.Second)
+
+	c.Advance(time.Hour)
+	assert.Equal(t, time.Unix(1, 0), <-t2.C())
+	assert.Equal(t, time.Unix(2, 0), <-t1.C())
+}
+
+func TestFake_Set(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	timer := c.NewTimer(time.Second)
+
+	c.Set(time.Unix(10, 0))
+	assert.Equal(t, time.Unix(1, 0), <-timer.C())
+	assert.Equal(t, time.Unix(10, 0), c.Now())
+
+	// Setting the clock back does not fire anything.
+	timer.Reset(time.Second)
+	c.Set(time.Unix(5, 0))
+	assertNoValue(t, timer.C())
+	assert.Equal(t, time.Unix(5, 0), c.Now())
+}
+
+func TestFake_Sleep(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		c.Sleep(time.Minute)
+		close(done)
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "sleep did not return")
+	}
+}
+
+func assertNoValue(t *testing.T, ch <-chan time.Time) {
+	t.Helper()
+	select {
+	case v := <-ch:
+		assert.Fail(t, "unexpected value", v)
+	default:
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package timeutil
+
+import (
+	"context"
+	"time"
+)
+
+// SleepContext pauses the current goroutine for at least duration d, or until
+// the context is done, whichever happens first. It returns the context error
+// if the context was done before d passed, and nil otherwise.
+func SleepContext(ctx context.Context, d time.Duration) error {
+	return SleepContextClock(ctx, Real(), d)
+}
+
+// SleepContextClock works like SleepContext, but it uses the given clock.
+func SleepContextClock(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := OrReal(clock).NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C():
+		return nil
+	}
+}
+
+// NextInterval returns the earliest time after t that is a multiple of the
+// interval since the zero time. For intervals that evenly divide a day, the
+// result is aligned to the UTC wall clock, e.g. an interval of a minute
+// returns the start of the next minute.
+//
+// Aligning loops to intervals lets multiple instances run their periodic
+// tasks at the same moments, regardless of when they were started. If the
+// interval is not positive, t is returned.
+func NextInterval(t time.Time, interval time.Duration) time.Time {
+	if interval <= 0 {
+		return t
+	}
+	return t.Truncate(interval).Add(interval)
+}
+
+// UntilNextInterval returns the duration from the current time of the clock
+// to the next interval, as returned by NextInterval. If clock is nil, the
+// real clock is used.
+func UntilNextInterval(clock Clock, interval time.Duration) time.Duration {
+	now := OrReal(clock).Now()
+	return NextInterval(now, interval).Sub(now)
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package timeutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSleepContext(t *testing.T) {
+	assert.NoError(t, SleepContext(context.Background(), time.Millisecond))
+	assert.NoError(t, SleepContext(context.Background(), 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, SleepContext(ctx, time.Hour), context.Canceled)
+	assert.ErrorIs(t, SleepContext(ctx, 0), context.Canceled)
+}
+
+func TestSleepContextClock(t *testing.T) {
+	clock := NewFake(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error)
+	go func() { errCh <- SleepContextClock(ctx, clock, time.Hour) }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.NoError(t, <-errCh)
+
+	go func() { errCh <- SleepContextClock(ctx, clock, time.Hour) }()
+	clock.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestNextInterval(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 30, 15, 0, time.UTC)
+	tests := []struct {
+		name     string
+		t        time.Time
+		interval time.Duration
+		want     time.Time
+	}{
+		{
+			name:     "minute",
+			t:        base,
+			interval: time.Minute,
+			want:     time.Date(2024, 1, 1, 12, 31, 0, 0, time.UTC),
+		},
+		{
+			name:     "hour",
+			t:        base,
+			interval: time.Hour,
+			want:     time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "already aligned",
+			t:        time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC),
+			interval: time.Minute,
+			want:     time.Date(2024, 1, 1, 12, 31, 0, 0, time.UTC),
+		},
+		{
+			name:     "non-positive interval",
+			t:        base,
+			interval: 0,
+			want:     base,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NextInterval(tt.t, tt.interval))
+		})
+	}
+}
+
+func TestUntilNextInterval(t *testing.T) {
+	clock := NewFake(time.Date(2024, 1, 1, 12, 30, 15, 0, time.UTC))
+	assert.Equal(t, 45*time.Second, UntilNextInterval(clock, time.Minute))
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package timeutil
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// TickerWithJitter returns a ticker that ticks on average every period d,
+// but every interval is randomized by up to jitter*d in either direction.
+// The jitter must be between 0 and 1; values outside that range are clamped.
+// A jitter of 0 results in a regular ticker.
+//
+// Jitter spreads out loops that run on many instances at the same interval,
+// like polling for configuration changes, so they do not hit the same
+// service at the same moment.
+//
+// If clock is nil, the real clock is used. It panics if d is not positive.
+func TickerWithJitter(clock Clock, d time.Duration, jitter float64) Ticker {
+	if d <= 0 {
+		panic("timeutil: non-positive interval for TickerWithJitter")
+	}
+	t := &jitterTicker{
+		ch:     make(chan time.Time, 1),
+		stop:   make(chan struct{}),
+		period: d,
+		jitter: min(max(jitter, 0), 1),
+	}
+	t.timer = OrReal(clock).NewTimer(t.next())
+	go t.run()
+	return t
+}
+
+type jitterTicker struct {
+	mu       sync.Mutex
+	ch       chan time.Time
+	stop     chan struct{}
+	stopOnce sync.Once
+	timer    Timer
+	period   time.Duration
+	jitter   float64
+}
+
+// C implements the Ticker interface.
+func (t *jitterTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop implements the Ticker interface.
+func (t *jitterTicker) Stop() {
+	t.stopOnce.Do(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.timer.Stop()
+		close(t.stop)
+	})
+}
+
+// Reset implements the Ticker interface.
+func (t *jitterTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("timeutil: non-positive interval for Ticker.Reset")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.stop:
+		return
+	default:
+	}
+	t.period = d
+	t.timer.Reset(t.next())
+}
+
+func (t *jitterTicker) run() {
+	for {
+		select {
+		case <-t.stop:
+			return
+		case now := <-t.timer.C():
+			t.mu.Lock()
+			select {
+			case <-t.stop:
+				t.mu.Unlock()
+				return
+			default:
+			}
+			// Like the tickers from the time package, drop the tick if the
+			// previous one was not received yet.
+			select {
+			case t.ch <- now:
+			default:
+			}
+			t.timer.Reset(t.next())
+			t.mu.Unlock()
+		}
+	}
+}
+
+// next returns the randomized interval until the next tick. The lock must be
+// held, or the ticker must not be shared yet.
+func (t *jitterTicker) next() time.Duration {
+	if t.jitter == 0 {
+		return t.period
+	}
+	delta := float64(t.period) * t.jitter * (2*rand.Float64() - 1)
+	return max(time.Duration(float64(t.period)+delta), 1)
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTickerWithJitter(t *testing.T) {
+	tests := []struct {
+		name    string
+		jitter  float64
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "no jitter", jitter: 0, wantMin: time.Minute, wantMax: time.Minute},
+		{name: "jitter", jitter: 0.5, wantMin: 30 * time.Second, wantMax: 90 * time.Second},
+		{name: "clamped jitter", jitter: 2, wantMin: time.Nanosecond, wantMax: 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFake(time.Unix(0, 0))
+			ticker := TickerWithJitter(clock, time.Minute, tt.jitter)
+			defer ticker.Stop()
+
+			for i := 0; i < 10; i++ {
+				// Wait until the next tick is scheduled.
+				clock.BlockUntil(1)
+				start := clock.Now()
+				clock.Advance(tt.wantMax)
+				tick := <-ticker.C()
+				assert.GreaterOrEqual(t, tick.Sub(start), tt.wantMin)
+				assert.LessOrEqual(t, tick.Sub(start), tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestTickerWithJitter_StopReset(t *testing.T) {
+	clock := NewFake(time.Unix(0, 0))
+	ticker := TickerWithJitter(clock, time.Minute, 0)
+
+	ticker.Reset(time.Hour)
+	clock.Advance(time.Minute)
+	assertNoValue(t, ticker.C())
+	clock.Advance(time.Hour)
+	assert.Equal(t, time.Unix(3600, 0), <-ticker.C())
+
+	ticker.Stop()
+	ticker.Stop()
+	ticker.Reset(time.Second) // Has no effect after Stop.
+	assert.Equal(t, 0, clock.Waiters())
+
+	assert.Panics(t, func() { TickerWithJitter(clock, 0, 0) })
+	assert.Panics(t, func() { ticker.Reset(0) })
+}
+
+func TestTickerWithJitter_Real(t *testing.T) {
+	ticker := TickerWithJitter(nil, time.Millisecond, 0.5)
+	defer ticker.Stop()
+	<-ticker.C()
+	<-ticker.C()
+}
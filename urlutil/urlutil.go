@@ -0,0 +1,189 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package urlutil provides helpers for handling URLs that may contain
+// credentials.
+//
+// URLs used by the library often contain API keys, either as the user
+// information or as query parameters. The Redact functions should be used
+// whenever a URL is included in an error message or a log entry.
+package urlutil
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Mask replaces redacted parts of URLs. It is the same as the mask used by
+// url.URL.Redacted.
+const Mask = "xxxxx"
+
+// secretParams is a list of query parameters that are known to hold
+// credentials. Parameters are matched case-insensitively.
+var secretParams = map[string]bool{
+	"access_token":     true,
+	"api-key":          true,
+	"api_key":          true,
+	"apikey":           true,
+	"auth":             true,
+	"key":              true,
+	"password":         true,
+	"secret":           true,
+	"sig":              true,
+	"signature":        true,
+	"token":            true,
+	"x-amz-credential": true,
+	"x-amz-signature":  true,
+	"x-goog-signature": true,
+}
+
+// defaultPorts maps schemes to their default ports, which are removed by
+// Normalize.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// Redact returns the string representation of the URL with the user
+// information and the values of known secret query parameters replaced
+// with Mask. Unlike url.URL.Redacted, it also masks the user name, because
+// many services use it to pass an API key.
+//
+// It returns an empty string if u is nil.
+func Redact(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	r := *u
+	if r.User != nil {
+		r.User = url.User(Mask)
+	}
+	r.RawQuery = redactQuery(r.RawQuery)
+	return r.String()
+}
+
+// RedactString works like Redact, but takes a URL string. Relative URLs,
+// such as file names with a query string, are supported.
+//
+// If the string cannot be parsed, everything after the scheme is masked,
+// because it is not possible to tell which part holds credentials.
+func RedactString(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		if i := strings.Index(s, "://"); i >= 0 {
+			return s[:i+3] + Mask
+		}
+		return Mask
+	}
+	return Redact(u)
+}
+
+// RedactError returns an error with the URL redacted if err is or wraps a
+// *url.Error, such as an error returned by http.Client. Other errors are
+// returned unchanged.
+//
+// If the *url.Error is wrapped, the URL is redacted in the message of the
+// whole chain. The returned error unwraps to the redacted *url.Error, so
+// the wrappers between them are not available to errors.As, but errors.Is
+// still matches every error in the original chain.
+func RedactError(err error) error {
+	var uErr *url.Error
+	if !errors.As(err, &uErr) {
+		return err
+	}
+	redacted := &url.Error{Op: uErr.Op, URL: RedactString(uErr.URL), Err: uErr.Err}
+	if uErr == err {
+		return redacted
+	}
+	return &redactedError{
+		msg:  strings.ReplaceAll(err.Error(), uErr.URL, redacted.URL),
+		err:  redacted,
+		orig: err,
+	}
+}
+
+// redactedError is a wrapped *url.Error with the URL redacted, see
+// RedactError.
+type redactedError struct {
+	msg  string
+	err  *url.Error // Redacted error.
+	orig error      // Original chain, used only by Is.
+}
+
+// Error implements the error interface.
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the redacted *url.Error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether any error in the original chain matches target.
+func (e *redactedError) Is(target error) bool {
+	return errors.Is(e.orig, target)
+}
+
+// Normalize returns a copy of the URL with the scheme and host converted
+// to lower case and the default port of the scheme removed, so that URLs
+// that refer to the same resource can be compared. The path and query are
+// not modified.
+//
+// It returns nil if u is nil.
+func Normalize(u *url.URL) *url.URL {
+	if u == nil {
+		return nil
+	}
+	n := *u
+	if u.User != nil {
+		user := *u.User
+		n.User = &user
+	}
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	if port := n.Port(); port != "" && port == defaultPorts[n.Scheme] {
+		n.Host = strings.TrimSuffix(n.Host, ":"+port)
+	}
+	return &n
+}
+
+// IsSecretParam reports whether the query parameter is known to hold
+// credentials and is masked by Redact.
+func IsSecretParam(name string) bool {
+	return secretParams[strings.ToLower(name)]
+}
+
+// redactQuery masks the values of secret parameters in a raw query. The
+// order of the parameters and the encoding of other values are preserved.
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, p := range params {
+		key, _, hasValue := strings.Cut(p, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if hasValue && IsSecretParam(name) {
+			params[i] = key + "=" + Mask
+		}
+	}
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Comparison operators, longer operators must be listed first.
+var operators = []string{"<=", ">=", "!=", "<", ">", "="}
+
+// Constraint is a parsed version constraint.
+type Constraint struct {
+	raw   string
+	terms []term
+}
+
+type term struct {
+	op string
+	v  Version
+}
+
+// ParseConstraint parses a version constraint.
+//
+// A constraint is a list of comparisons, separated by commas or spaces,
+// that must all be satisfied. A comparison is an operator, one of "=",
+// "!=", "<", "<=", ">" or ">=", followed by a version. If the operator is
+// omitted, "=" is used. For example, ">=1.2.0, <2.0.0" matches all 1.x
+// versions starting from 1.2.0.
+func ParseConstraint(s string) (Constraint, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return Constraint{}, fmt.Errorf("invalid constraint %q", s)
+	}
+	c := Constraint{raw: s, terms: make([]term, len(fields))}
+	for i, f := range fields {
+		op := "="
+		for _, o := range operators {
+			if strings.HasPrefix(f, o) {
+				op = o
+				break
+			}
+		}
+		v, err := Parse(strings.TrimPrefix(f, op))
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid constraint %q: %w", s, err)
+		}
+		c.terms[i] = term{op: op, v: v}
+	}
+	return c, nil
+}
+
+// MustParseConstraint works like ParseConstraint, but panics on error.
+func MustParseConstraint(s string) Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Check reports whether v satisfies all comparisons of the constraint.
+// The zero value of Constraint is satisfied by any version.
+func (c Constraint) Check(v Version) bool {
+	for _, t := range c.terms {
+		if !t.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the constraint as it was given to ParseConstraint.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+func (t term) check(v Version) bool {
+	cmp := Compare(v, t.v)
+	switch t.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
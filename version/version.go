@@ -0,0 +1,96 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package version provides parsing and comparison of application versions.
+//
+// Versions follow the semantic versioning scheme, with an optional "v"
+// prefix. The special "dev" version is used by development builds and is
+// always greater than any other version, so features gated on a minimum
+// version are enabled in development builds.
+//
+// The same rules are used by the "semver" HCL function, so version checks
+// in Go code and in configuration files give the same results.
+package version
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Dev is the version used by development builds.
+const Dev = "dev"
+
+// Version is a parsed version. The zero value is not a valid version.
+type Version struct {
+	raw string // Version as given to Parse.
+	sv  string // Version with the "v" prefix, empty for dev versions.
+}
+
+// Parse parses a semantic version, with or without the "v" prefix, or the
+// "dev" version.
+func Parse(s string) (Version, error) {
+	if s == Dev {
+		return Version{raw: s}, nil
+	}
+	sv := "v" + strings.TrimPrefix(s, "v")
+	if !semver.IsValid(sv) {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	return Version{raw: s, sv: sv}, nil
+}
+
+// MustParse works like Parse, but panics on error.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// IsDev reports whether v is the dev version.
+func (v Version) IsDev() bool {
+	return v.raw == Dev
+}
+
+// String returns the version as it was given to Parse.
+func (v Version) String() string {
+	return v.raw
+}
+
+// Compare returns -1, 0 or 1 if a is less than, equal to or greater than b.
+// The dev version is equal to itself and greater than any other version.
+func Compare(a, b Version) int {
+	switch {
+	case a.IsDev() && b.IsDev():
+		return 0
+	case a.IsDev():
+		return 1
+	case b.IsDev():
+		return -1
+	}
+	return semver.Compare(a.sv, b.sv)
+}
+
+// Satisfies reports whether v satisfies the constraint, see ParseConstraint
+// for the constraint syntax.
+func Satisfies(v Version, constraint string) (bool, error) {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2021-2025 Chronicle Labs, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{input: "1.2.3"},
+		{input: "v1.2.3"},
+		{input: "1.2.3-rc.1+build"},
+		{input: "dev"},
+		{input: "", wantErr: `invalid version ""`},
+		{input: "hello", wantErr: `invalid version "hello"`},
+		{input: "1.2.hello", wantErr: `invalid version "1.2.hello"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, v.String())
+			assert.Equal(t, tt.input == Dev, v.IsDev())
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.2.3", b: "1.2.3", want: 0},
+		{a: "v1.2.3", b: "1.2.3", want: 0},
+		{a: "1.2.3", b: "1.2.4", want: -1},
+		{a: "1.10.0", b: "1.9.0", want: 1},
+		{a: "1.2.3-rc.1", b: "1.2.3", want: -1},
+		{a: "dev", b: "99.0.0", want: 1},
+		{a: "1.2.3", b: "dev", want: -1},
+		{a: "dev", b: "dev", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, Compare(MustParse(tt.a), MustParse(tt.b)))
+		})
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+		wantErr    string
+	}{
+		{version: "1.2.3", constraint: "1.2.3", want: true},
+		{version: "1.2.3", constraint: "=1.2.4", want: false},
+		{version: "1.2.3", constraint: "!=1.2.4", want: true},
+		{version: "1.2.3", constraint: "<1.2.4", want: true},
+		{version: "1.2.3", constraint: "<=1.2.3", want: true},
+		{version: "1.2.3", constraint: ">1.2.3", want: false},
+		{version: "1.2.3", constraint: ">=v1.2.3", want: true},
+		{version: "1.5.0", constraint: ">=1.2.0, <2.0.0", want: true},
+		{version: "1.5.0", constraint: ">=1.2.0 <2.0.0", want: true},
+		{version: "2.0.0", constraint: ">=1.2.0, <2.0.0", want: false},
+		{version: "dev", constraint: ">=1.2.0", want: true},
+		{version: "dev", constraint: "<1.2.0", want: false},
+		{version: "dev", constraint: "1.2.0", want: false},
+		{version: "dev", constraint: "!=1.2.0", want: true},
+		{version: "dev", constraint: "dev", want: true},
+		{version: "dev", constraint: "=dev", want: true},
+		{version: "dev", constraint: "!=dev", want: false},
+		{version: "dev", constraint: ">dev", want: false},
+		{version: "dev", constraint: ">=dev", want: true},
+		{version: "1.2.3", constraint: "<dev", want: true},
+		{version: "1.2.3", constraint: "", wantErr: `invalid constraint ""`},
+		{version: "1.2.3", constraint: ">=", wantErr: `invalid constraint ">=": invalid version ""`},
+		{version: "1.2.3", constraint: ")1.2.3", wantErr: `invalid constraint ")1.2.3": invalid version ")1.2.3"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			got, err := Satisfies(MustParse(tt.version), tt.constraint)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}